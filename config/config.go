@@ -48,9 +48,12 @@ type Config struct {
 	VPC       *VPCProviderConfig
 	IKS       *IKSConfig
 	API       *APIConfig
+	TLS       *TLSConfig
+	Transport *TransportConfig
 }
 
 // ReadConfig loads the config from k8s secret ...
+// The Transport and TLS sections, if set, configure the DefaultTransportFactory.
 func ReadConfig(k8sClient k8s_utils.KubernetesClient, logger *zap.Logger) (*Config, error) {
 	data, err := k8s_utils.GetSecretData(k8sClient, utils.STORAGE_SECRET_STORE_SECRET, utils.SECRET_STORE_FILE)
 	if err != nil {
@@ -62,6 +65,9 @@ func ReadConfig(k8sClient k8s_utils.KubernetesClient, logger *zap.Logger) (*Conf
 		logger.Error("Error parsing config", zap.Error(err))
 		return nil, err
 	}
+	if err = configureDefaultTransportFactory(conf, logger); err != nil {
+		return nil, err
+	}
 
 	return conf, nil
 }
@@ -83,6 +89,14 @@ type BluemixConfig struct {
 	PrivateAPIRoute string `toml:"containers_api_route_private"`
 	Encryption      bool   `toml:"encryption"`
 	CSRFToken       string `toml:"containers_api_csrf_token" json:"-"`
+
+	// IamTokenIssuer is the expected iss claim of IAM access tokens, "https://iam.cloud.ibm.com/identity" if not set.
+	// It is the same for the public and private iam_url, and only needs to be set for other IAM environments.
+	IamTokenIssuer string `toml:"iam_token_issuer" envconfig:"IAM_TOKEN_ISSUER"`
+
+	// SkipTokenVerification disables verification of IAM access token signatures and claims.
+	// Only for test environments whose tokens are not issued by IAM.
+	SkipTokenVerification bool `toml:"skip_token_verification" envconfig:"SKIP_TOKEN_VERIFICATION"`
 }
 
 // SoftlayerConfig ...
@@ -160,13 +174,81 @@ type IKSConfig struct {
 	IKSFileProviderName  string `toml:"iks_file_provider_name" envconfig:"IKS_FILE_PROVIDER_NAME"`
 }
 
+// TLSConfig configures the TLS connections of the HTTP clients, see NewTLSTransport
+type TLSConfig struct {
+	// CABundlePath is a PEM file of CAs trusted in addition to the system roots
+	CABundlePath string `toml:"ca_bundle_path" envconfig:"TLS_CA_BUNDLE_PATH"`
+
+	// ClientCertPath and ClientKeyPath are the PEM files of the client certificate presented for mTLS
+	ClientCertPath string `toml:"client_cert_path" envconfig:"TLS_CLIENT_CERT_PATH"`
+	ClientKeyPath  string `toml:"client_key_path" envconfig:"TLS_CLIENT_KEY_PATH"`
+
+	// ServerNames overrides the names the server certificates of endpoints are verified against, keyed by
+	// host like TransportConfig.Endpoints, e.g. "10.0.0.1" = "iam.cloud.ibm.com". Applied by TransportFactory.
+	ServerNames map[string]string `toml:"server_names" envconfig:"TLS_SERVER_NAMES"`
+
+	// MinVersion is "1.2" (the default) or "1.3"
+	MinVersion string `toml:"min_version" envconfig:"TLS_MIN_VERSION"`
+
+	// CipherSuites are the names of the TLS 1.2 cipher suites allowed, e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256".
+	// By default the Go defaults are used.
+	CipherSuites []string `toml:"cipher_suites" envconfig:"TLS_CIPHER_SUITES"`
+}
+
+// TransportConfig tunes the HTTP transports built by TransportFactory. Zero values use the defaults.
+type TransportConfig struct {
+	MaxIdleConns        int    `toml:"max_idle_conns" envconfig:"HTTP_MAX_IDLE_CONNS"`
+	MaxIdleConnsPerHost int    `toml:"max_idle_conns_per_host" envconfig:"HTTP_MAX_IDLE_CONNS_PER_HOST"`
+	MaxConnsPerHost     int    `toml:"max_conns_per_host" envconfig:"HTTP_MAX_CONNS_PER_HOST"`
+	IdleConnTimeout     string `toml:"idle_conn_timeout" envconfig:"HTTP_IDLE_CONN_TIMEOUT"`
+
+	// ProxyURL is the proxy used for every endpoint instead of the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables
+	ProxyURL string `toml:"proxy_url" envconfig:"HTTP_PROXY_URL"`
+
+	DialTimeout           string `toml:"dial_timeout" envconfig:"HTTP_DIAL_TIMEOUT"`
+	TLSHandshakeTimeout   string `toml:"tls_handshake_timeout" envconfig:"HTTP_TLS_HANDSHAKE_TIMEOUT"`
+	ResponseHeaderTimeout string `toml:"response_header_timeout" envconfig:"HTTP_RESPONSE_HEADER_TIMEOUT"`
+	RequestTimeout        string `toml:"request_timeout" envconfig:"HTTP_REQUEST_TIMEOUT"`
+
+	// Endpoints overrides the timeouts for endpoints, keyed by host, e.g. "iam.cloud.ibm.com"
+	Endpoints map[string]EndpointTimeouts `toml:"endpoints"`
+}
+
+// EndpointTimeouts overrides the TransportConfig timeouts for an endpoint. Empty values use the TransportConfig ones.
+type EndpointTimeouts struct {
+	DialTimeout           string `toml:"dial_timeout"`
+	TLSHandshakeTimeout   string `toml:"tls_handshake_timeout"`
+	ResponseHeaderTimeout string `toml:"response_header_timeout"`
+	RequestTimeout        string `toml:"request_timeout"`
+}
+
 // APIConfig config
 type APIConfig struct {
 	PassthroughSecret string `toml:"PassthroughSecret" json:"-"`
 }
 
+// ParseOption configures ParseConfig
+type ParseOption func(*parseOptions)
+
+// parseOptions ...
+type parseOptions struct {
+	validate bool
+}
+
+// WithValidation makes ParseConfig run Config.Validate on the parsed config
+func WithValidation() ParseOption {
+	return func(opts *parseOptions) {
+		opts.validate = true
+	}
+}
+
 // ParseConfig loads the config from file
-func ParseConfig(logger *zap.Logger, data string) (*Config, error) {
+func ParseConfig(logger *zap.Logger, data string, opts ...ParseOption) (*Config, error) {
+	options := parseOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	configData := new(Config)
 	_, err := toml.Decode(data, configData)
 	if err != nil {
@@ -180,5 +262,12 @@ func ParseConfig(logger *zap.Logger, data string) (*Config, error) {
 		return nil, err
 	}
 
+	if options.validate {
+		if err = configData.Validate(); err != nil {
+			logger.Error("Invalid config", zap.Error(err))
+			return nil, err
+		}
+	}
+
 	return configData, nil
 }
@@ -0,0 +1,340 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config ...
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+	"github.com/IBM/secret-utils-lib/pkg/k8s_utils"
+	"github.com/IBM/secret-utils-lib/pkg/utils"
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+)
+
+// Source is a configuration layer
+type Source string
+
+const (
+	// SourceDefault is the built-in defaults, see DefaultConfig
+	SourceDefault Source = "default"
+
+	// SourceFile is a TOML file
+	SourceFile Source = "file"
+
+	// SourceSecret is the storage secret store k8s secret
+	SourceSecret Source = "secret"
+
+	// SourceEnv is the environment variables
+	SourceEnv Source = "env"
+)
+
+// redacted replaces the values of secret fields in Dump
+const redacted = "REDACTED"
+
+// Provenance is where the effective value of a field came from
+type Provenance struct {
+	// Source is the layer that set the field
+	Source Source
+
+	// Location is the file path, secret name or environment variable the value was read from
+	Location string
+}
+
+// String ...
+func (p Provenance) String() string {
+	if p.Location == "" {
+		return string(p.Source)
+	}
+	return string(p.Source) + ":" + p.Location
+}
+
+// LoadOption configures Load
+type LoadOption func(*loader)
+
+// loader ...
+type loader struct {
+	logger    *zap.Logger
+	defaults  *Config
+	files     []string
+	k8sClient *k8s_utils.KubernetesClient
+
+	conf       *Config
+	provenance map[string]Provenance
+}
+
+// WithDefaults replaces the built-in defaults
+func WithDefaults(defaults *Config) LoadOption {
+	return func(l *loader) {
+		l.defaults = defaults
+	}
+}
+
+// WithFiles adds TOML files, in increasing order of precedence
+func WithFiles(paths ...string) LoadOption {
+	return func(l *loader) {
+		l.files = append(l.files, paths...)
+	}
+}
+
+// WithSecret adds the storage secret store k8s secret
+func WithSecret(k8sClient k8s_utils.KubernetesClient) LoadOption {
+	return func(l *loader) {
+		l.k8sClient = &k8sClient
+	}
+}
+
+// LoadedConfig is the effective configuration and the provenance of its fields
+type LoadedConfig struct {
+	Config *Config
+
+	// Provenance is keyed by the field path, e.g. "VPC.VPCTimeout". Fields no layer set are absent.
+	Provenance map[string]Provenance
+}
+
+// DefaultConfig returns the built-in defaults used by Load
+func DefaultConfig() *Config {
+	return &Config{
+		Server: &ServerConfig{},
+		VPC: &VPCProviderConfig{
+			VPCTimeout:      "120s",
+			MaxRetryAttempt: 10,
+			MaxRetryGap:     60,
+		},
+		Softlayer: &SoftlayerConfig{
+			SoftlayerTimeout: "120s",
+		},
+	}
+}
+
+// Load merges the configuration layers and validates the result. In increasing order of precedence
+// the layers are: the defaults, the TOML files in the given order, the k8s secret and the environment
+// variables, with the same names ParseConfig accepts. A field set by a layer overrides the field
+// from lower layers, even if it is set to its zero value.
+// The Transport and TLS sections, if set, configure the DefaultTransportFactory.
+func Load(logger *zap.Logger, opts ...LoadOption) (*LoadedConfig, error) {
+	l := &loader{
+		logger:     logger,
+		defaults:   DefaultConfig(),
+		conf:       new(Config),
+		provenance: map[string]Provenance{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.applyDefaults()
+	for _, path := range l.files {
+		data, err := os.ReadFile(path) // #nosec G304
+		if err != nil {
+			logger.Error("Error reading config file", zap.String("path", path), zap.Error(err))
+			return nil, err
+		}
+		if err = l.applyTOML(string(data), Provenance{Source: SourceFile, Location: path}); err != nil {
+			return nil, err
+		}
+	}
+	if l.k8sClient != nil {
+		data, err := k8s_utils.GetSecretData(*l.k8sClient, utils.STORAGE_SECRET_STORE_SECRET, utils.SECRET_STORE_FILE)
+		if err != nil {
+			logger.Error("Error reading config", zap.Error(err))
+			return nil, err
+		}
+		location := utils.STORAGE_SECRET_STORE_SECRET + "/" + utils.SECRET_STORE_FILE
+		if err = l.applyTOML(data, Provenance{Source: SourceSecret, Location: location}); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := l.conf.Validate(); err != nil {
+		logger.Error("Invalid config", zap.Error(err))
+		return nil, err
+	}
+	if err := configureDefaultTransportFactory(l.conf, logger); err != nil {
+		return nil, err
+	}
+	return &LoadedConfig{Config: l.conf, Provenance: l.provenance}, nil
+}
+
+// applyDefaults starts from a copy of the defaults, with every non zero field attributed to them
+func (l *loader) applyDefaults() {
+	if l.defaults == nil {
+		return
+	}
+	l.conf = l.defaults.Clone()
+	walkFields(l.conf, func(path string, field reflect.StructField, value reflect.Value) {
+		if !value.IsZero() {
+			l.provenance[path] = Provenance{Source: SourceDefault}
+		}
+	})
+}
+
+// applyTOML copies the fields defined in data to the configuration
+func (l *loader) applyTOML(data string, from Provenance) error {
+	layer := new(Config)
+	md, err := toml.Decode(data, layer)
+	if err != nil {
+		l.logger.Error("Failed to parse config", zap.Stringer("source", from), zap.Error(err))
+		return err
+	}
+	for _, key := range md.Undecoded() {
+		l.logger.Warn("Unknown config key", zap.Stringer("source", from), zap.String("key", key.String()))
+	}
+
+	conf := reflect.ValueOf(l.conf).Elem()
+	src := reflect.ValueOf(layer).Elem()
+	for _, key := range md.Keys() {
+		if len(key) == 0 {
+			continue
+		}
+		// Keys of tables nested in a field, e.g. transport.endpoints."iam.cloud.ibm.com", set the whole field
+		if len(key) > 2 {
+			key = key[:2]
+		}
+		si, ok := tomlField(conf.Type(), key[0])
+		if !ok || src.Field(si).Kind() != reflect.Ptr || src.Field(si).IsNil() {
+			continue
+		}
+		section := conf.Field(si)
+		if section.IsNil() {
+			section.Set(reflect.New(section.Type().Elem()))
+		}
+		if len(key) == 1 {
+			continue
+		}
+		fi, ok := tomlField(section.Type().Elem(), key[1])
+		if !ok {
+			continue
+		}
+		section.Elem().Field(fi).Set(src.Field(si).Elem().Field(fi))
+		l.provenance[conf.Type().Field(si).Name+"."+section.Type().Elem().Field(fi).Name] = from
+	}
+	return nil
+}
+
+// applyEnv attributes the fields whose environment variables are set, then processes them like ParseConfig does
+func (l *loader) applyEnv() error {
+	// envconfig reads SECTION_FIELD, or SECTION_TAG, falling back to the bare envconfig tag
+	sections := reflect.TypeOf(l.conf).Elem()
+	for si := 0; si < sections.NumField(); si++ {
+		section := sections.Field(si)
+		fields := section.Type.Elem()
+		for fi := 0; fi < fields.NumField(); fi++ {
+			field := fields.Field(fi)
+			alt := strings.ToUpper(field.Tag.Get("envconfig"))
+			key := field.Name
+			if alt != "" {
+				key = alt
+			}
+			key = strings.ToUpper(section.Name + "_" + key)
+			for _, name := range []string{key, alt} {
+				if _, ok := os.LookupEnv(name); name != "" && ok {
+					l.provenance[section.Name+"."+field.Name] = Provenance{Source: SourceEnv, Location: name}
+					break
+				}
+			}
+		}
+	}
+
+	if err := envconfig.Process("", l.conf); err != nil {
+		l.logger.Error("Failed to gather environment config variable", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Dump writes the effective configuration with the provenance of each field, one field per line.
+// Secret fields, those tagged json:"-", are redacted, and so are the passwords of URLs, e.g. proxy_url.
+func (lc *LoadedConfig) Dump(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tVALUE\tSOURCE")
+	walkFields(lc.Config, func(path string, field reflect.StructField, value reflect.Value) {
+		source := "unset"
+		if p, ok := lc.Provenance[path]; ok {
+			source = p.String()
+		}
+		shown := fmt.Sprintf("%q", redactURL(fmt.Sprint(value.Interface())))
+		if value.Kind() != reflect.String {
+			shown = fmt.Sprint(value.Interface())
+		}
+		if field.Tag.Get("json") == "-" && !value.IsZero() {
+			shown = redacted
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", path, shown, source)
+	})
+	return tw.Flush()
+}
+
+// redactURL returns the value with the password redacted if it is a URL with one, or else unchanged
+func redactURL(value string) string {
+	if !strings.Contains(value, "@") {
+		return value
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return value
+}
+
+// walkFields calls fn with every field of the non nil sections of conf
+func walkFields(conf *Config, fn func(path string, field reflect.StructField, value reflect.Value)) {
+	if conf == nil {
+		return
+	}
+	sections := reflect.ValueOf(conf).Elem()
+	for si := 0; si < sections.NumField(); si++ {
+		section := sections.Field(si)
+		if section.IsNil() {
+			continue
+		}
+		fields := section.Elem()
+		for fi := 0; fi < fields.NumField(); fi++ {
+			field := fields.Type().Field(fi)
+			fn(sections.Type().Field(si).Name+"."+field.Name, field, fields.Field(fi))
+		}
+	}
+}
+
+// tomlField returns the index of the field of struct type t that the TOML decoder maps key to:
+// the field whose toml tag or name is key, or failing that, equal to key ignoring case
+func tomlField(t reflect.Type, key string) (int, bool) {
+	fold := -1
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("toml"), ",")[0]; tag != "" {
+			name = tag
+		}
+		if name == key {
+			return i, true
+		}
+		if fold < 0 && strings.EqualFold(name, key) {
+			fold = i
+		}
+	}
+	return fold, fold >= 0
+}
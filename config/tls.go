@@ -19,10 +19,21 @@ package config
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"sync"
 	"time"
+
+	"go.uber.org/zap"
 )
 
+// DefaultCertCheckInterval is how often NewTLSTransport checks the certificate files for changes
+const DefaultCertCheckInterval = 30 * time.Second
+
 // GeneralCAHttpClient returns an http.Client configured for general use
 func GeneralCAHttpClient() (*http.Client, error) {
 	// softlayer.go has been overriding http.DefaultClient and forcing 120s
@@ -32,19 +43,234 @@ func GeneralCAHttpClient() (*http.Client, error) {
 	return GeneralCAHttpClientWithTimeout(timeout)
 }
 
-// GeneralCAHttpClientWithTimeout returns an http.Client configured for general use
+// GeneralCAHttpClientWithTimeout returns an http.Client configured for general use.
+// Every call returns a client with an http.Transport of its own, built by the DefaultTransportFactory
+// from the Transport and TLS configuration, so callers may modify it without affecting other clients.
 func GeneralCAHttpClientWithTimeout(timeout time.Duration) (*http.Client, error) {
+	transport, err := DefaultTransportFactory().newHTTPTransport()
+	if err != nil {
+		return nil, err
+	}
 	httpClient := &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
 
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{
-				MinVersion: tls.VersionTLS12, // Require TLS 1.2 or higher
-			},
-		},
+	return httpClient, nil
+}
 
-		Timeout: timeout,
+// TLSVersion returns the tls.VersionTLS* constant of the MinVersion, tls.VersionTLS12 if it is empty
+func (tc *TLSConfig) TLSVersion() (uint16, error) {
+	switch tc.MinVersion {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
 	}
+	return 0, fmt.Errorf("TLS version '%s' is not one of 1.2, 1.3", tc.MinVersion)
+}
 
-	return httpClient, nil
+// CipherSuiteIDs returns the IDs of the CipherSuites. Only the suites Go considers secure are accepted.
+func (tc *TLSConfig) CipherSuiteIDs() ([]uint16, error) {
+	if len(tc.CipherSuites) == 0 {
+		return nil, nil
+	}
+	known := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	ids := make([]uint16, 0, len(tc.CipherSuites))
+	for _, name := range tc.CipherSuites {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("cipher suite '%s' is unknown or insecure", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// serverName returns the server name overridden for the host, with or without its port
+func (tc *TLSConfig) serverName(host string) string {
+	if tc == nil {
+		return ""
+	}
+	if serverName, ok := tc.ServerNames[host]; ok {
+		return serverName
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		return tc.ServerNames[hostname]
+	}
+	return ""
+}
+
+// BuildTLSConfig returns a tls.Config loaded from the current contents of the certificate files
+func (tc *TLSConfig) BuildTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12, // Require TLS 1.2 or higher
+	}
+	if tc == nil {
+		return tlsConfig, nil
+	}
+
+	var err error
+	if tlsConfig.MinVersion, err = tc.TLSVersion(); err != nil {
+		return nil, err
+	}
+	if tlsConfig.CipherSuites, err = tc.CipherSuiteIDs(); err != nil {
+		return nil, err
+	}
+
+	if tc.CABundlePath != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(tc.CABundlePath)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle '%s'", tc.CABundlePath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (tc.ClientCertPath == "") != (tc.ClientKeyPath == "") {
+		return nil, errors.New("client certificate and key must be set together")
+	}
+	if tc.ClientCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(tc.ClientCertPath, tc.ClientKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// TLSTransport is an http.RoundTripper that rebuilds its http.Transport when the CA bundle or
+// client certificate files of its TLSConfig change, so that rotated certificates are used for new
+// connections without a restart. If the changed files fail to load, the previous transport is kept.
+type TLSTransport struct {
+	tlsConf       *TLSConfig
+	newTransport  func(tlsConfig *tls.Config) *http.Transport
+	checkInterval time.Duration
+	logger        *zap.Logger
+
+	mu        sync.Mutex
+	transport *http.Transport
+	files     map[string]fileVersion
+	lastCheck time.Time
+}
+
+// fileVersion ...
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewTLSTransport returns a TLSTransport checking the certificate files every DefaultCertCheckInterval.
+// newTransport builds the http.Transport for a tls.Config, nil uses a clone of http.DefaultTransport.
+func NewTLSTransport(tlsConf *TLSConfig, newTransport func(tlsConfig *tls.Config) *http.Transport, logger *zap.Logger) (*TLSTransport, error) {
+	if newTransport == nil {
+		newTransport = func(tlsConfig *tls.Config) *http.Transport {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = tlsConfig
+			return transport
+		}
+	}
+	t := &TLSTransport{
+		tlsConf:       tlsConf,
+		newTransport:  newTransport,
+		checkInterval: DefaultCertCheckInterval,
+		logger:        logger,
+	}
+
+	t.files = t.fileVersions()
+	tlsConfig, err := tlsConf.BuildTLSConfig()
+	if err != nil {
+		logger.Error("Failed to load TLS config", zap.Error(err))
+		return nil, err
+	}
+	t.transport = newTransport(tlsConfig)
+	t.lastCheck = time.Now()
+	return t, nil
+}
+
+// RoundTrip ...
+func (t *TLSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.current().RoundTrip(req)
+}
+
+// CloseIdleConnections ...
+func (t *TLSTransport) CloseIdleConnections() {
+	t.current().CloseIdleConnections()
+}
+
+// current returns the transport, first rebuilding it if the certificate files changed
+func (t *TLSTransport) current() *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(t.lastCheck) < t.checkInterval {
+		return t.transport
+	}
+	t.lastCheck = now
+
+	files := t.fileVersions()
+	if len(files) == len(t.files) {
+		changed := false
+		for path, version := range files {
+			if t.files[path] != version {
+				changed = true
+			}
+		}
+		if !changed {
+			return t.transport
+		}
+	}
+	t.files = files
+
+	tlsConfig, err := t.tlsConf.BuildTLSConfig()
+	if err != nil {
+		t.logger.Error("Failed to reload TLS certificates, keeping the previous ones", zap.Error(err))
+		return t.transport
+	}
+	t.logger.Info("TLS certificates reloaded")
+	t.transport.CloseIdleConnections()
+	t.transport = t.newTransport(tlsConfig)
+	return t.transport
+}
+
+// fileVersions returns the versions of the certificate files that exist
+func (t *TLSTransport) fileVersions() map[string]fileVersion {
+	files := map[string]fileVersion{}
+	if t.tlsConf == nil {
+		return files
+	}
+	for _, path := range []string{t.tlsConf.CABundlePath, t.tlsConf.ClientCertPath, t.tlsConf.ClientKeyPath} {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			files[path] = fileVersion{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return files
+}
+
+// TLSHttpClientWithTimeout returns an http.Client using a TLSTransport for the TLS configuration
+func TLSHttpClientWithTimeout(tlsConf *TLSConfig, timeout time.Duration, logger *zap.Logger) (*http.Client, error) {
+	transport, err := NewTLSTransport(tlsConf, func(tlsConfig *tls.Config) *http.Transport {
+		return &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
 }
@@ -18,10 +18,23 @@
 package config
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGeneralCAHttpClient(t *testing.T) {
@@ -39,4 +52,203 @@ func TestGeneralCAHttpClientWithTimeout(t *testing.T) {
 
 	assert.NotNil(t, client)
 	assert.Equal(t, client.Timeout, time.Duration(120))
+
+	// Neither the clients nor their transports are shared, so modifying one leaves the other unchanged
+	other, _ := GeneralCAHttpClientWithTimeout(120)
+	assert.NotSame(t, client, other)
+	assert.NotSame(t, client.Transport, other.Transport)
+	transport := client.Transport.(*http.Transport)
+	otherTransport := other.Transport.(*http.Transport)
+	assert.NotSame(t, transport.TLSClientConfig, otherTransport.TLSClientConfig)
+	transport.TLSClientConfig.MinVersion = tls.VersionTLS13
+	assert.Equal(t, uint16(tls.VersionTLS12), otherTransport.TLSClientConfig.MinVersion)
+}
+
+func TestGeneralCAHttpClientTransportConfig(t *testing.T) {
+	defer SetDefaultTransportFactory(nil)
+	f, err := NewTransportFactory(&Config{
+		Transport: &TransportConfig{MaxIdleConns: 20, DialTimeout: "5s"},
+		TLS:       &TLSConfig{MinVersion: "1.3"},
+	}, testLogger)
+	require.Nil(t, err)
+	SetDefaultTransportFactory(f)
+
+	client, err := GeneralCAHttpClient()
+	require.Nil(t, err)
+	transport := client.Transport.(*http.Transport)
+	assert.Equal(t, 20, transport.MaxIdleConns)
+	assert.Equal(t, uint16(tls.VersionTLS13), transport.TLSClientConfig.MinVersion)
+}
+
+// testCA issues certificates for the mTLS tests
+type testCA struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	serial int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	return &testCA{cert: cert, key: key, serial: 1}
+}
+
+// issueClientCert writes a client certificate and key signed by the CA, returning the certificate serial number
+func (ca *testCA) issueClientCert(t *testing.T, certPath, keyPath string) int64 {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	ca.serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.Nil(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return ca.serial
+}
+
+func writeServerCA(t *testing.T, path string, server *httptest.Server) {
+	require.Nil(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	tlsConfig, err := (*TLSConfig)(nil).BuildTLSConfig()
+	require.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+
+	tlsConfig, err = (&TLSConfig{
+		MinVersion:   "1.3",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	}).BuildTLSConfig()
+	require.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, tlsConfig.CipherSuites)
+
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.Nil(t, os.WriteFile(invalid, []byte("not a certificate"), 0600))
+	for _, tc := range []*TLSConfig{
+		{MinVersion: "1.0"},
+		{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		{CABundlePath: filepath.Join(dir, "missing.pem")},
+		{CABundlePath: invalid},
+		{ClientCertPath: invalid},
+		{ClientCertPath: invalid, ClientKeyPath: invalid},
+	} {
+		_, err = tc.BuildTLSConfig()
+		assert.NotNil(t, err, "%+v", tc)
+	}
+}
+
+func TestTLSHttpClientCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	writeServerCA(t, caBundle, server)
+
+	client, err := TLSHttpClientWithTimeout(&TLSConfig{CABundlePath: caBundle}, 10*time.Second, testLogger)
+	require.Nil(t, err)
+	resp, err := client.Get(server.URL)
+	require.Nil(t, err)
+	_ = resp.Body.Close()
+
+	// The system roots do not trust the test server
+	client, err = TLSHttpClientWithTimeout(nil, 10*time.Second, testLogger)
+	require.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+}
+
+func TestTLSTransportReloadsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// The bundle initially holds a CA that did not issue the server certificate
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.Nil(t, os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: newTestCA(t).cert.Raw}), 0600))
+
+	transport, err := NewTLSTransport(&TLSConfig{CABundlePath: caBundle}, nil, testLogger)
+	require.Nil(t, err)
+	transport.checkInterval = 0
+	client := &http.Client{Transport: transport}
+
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+
+	writeServerCA(t, caBundle, server)
+	resp, err := client.Get(server.URL)
+	require.Nil(t, err)
+	_ = resp.Body.Close()
+
+	// A broken bundle keeps the last good one
+	require.Nil(t, os.WriteFile(caBundle, []byte("rotating"), 0600))
+	resp, err = client.Get(server.URL)
+	require.Nil(t, err)
+	_ = resp.Body.Close()
+}
+
+func TestTLSTransportMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	serials := make(chan int64, 10)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serials <- r.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caBundle := filepath.Join(dir, "ca.pem")
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	writeServerCA(t, caBundle, server)
+	first := ca.issueClientCert(t, certPath, keyPath)
+
+	// Without the client certificate the handshake fails
+	client, err := TLSHttpClientWithTimeout(&TLSConfig{CABundlePath: caBundle}, 10*time.Second, testLogger)
+	require.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+
+	transport, err := NewTLSTransport(&TLSConfig{CABundlePath: caBundle, ClientCertPath: certPath, ClientKeyPath: keyPath}, nil, testLogger)
+	require.Nil(t, err)
+	transport.checkInterval = 0
+	client = &http.Client{Transport: transport, Timeout: 10 * time.Second}
+
+	resp, err := client.Get(server.URL)
+	require.Nil(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, first, <-serials)
+
+	// The rotated certificate is presented on the next connection
+	second := ca.issueClientCert(t, certPath, keyPath)
+	resp, err = client.Get(server.URL)
+	require.Nil(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, second, <-serials)
 }
@@ -0,0 +1,423 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config ...
+package config
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Defaults of the TransportConfig values
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultDialTimeout         = 30 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	DefaultRequestTimeout      = 120 * time.Second
+)
+
+// transportMetricsNamespace is the namespace of the library metrics
+const transportMetricsNamespace = "ibmcloud_storage_volume_lib"
+
+// defaultEndpoint is the endpoint label of clients not built for an endpoint
+const defaultEndpoint = "default"
+
+// TransportFactory builds the HTTP clients of the library from the Transport and TLS configuration.
+// Clients are cached per endpoint host, and endpoints without timeout or server name overrides share one transport,
+// so keep-alive connections are pooled across the clients. TransportFactory is a prometheus.Collector
+// of the connection and request metrics of its clients.
+type TransportFactory struct {
+	conf   TransportConfig
+	tls    *TLSConfig
+	logger *zap.Logger
+	proxy  func(*http.Request) (*url.URL, error)
+
+	requests    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	inFlight    *prometheus.GaugeVec
+	connections *prometheus.CounterVec
+
+	mu         sync.Mutex
+	shared     http.RoundTripper
+	transports map[string]http.RoundTripper
+	clients    map[string]*http.Client
+}
+
+var _ prometheus.Collector = &TransportFactory{}
+
+var (
+	defaultFactoryMu sync.Mutex
+	defaultFactory   *TransportFactory
+)
+
+// DefaultTransportFactory returns the factory used by the IAM token exchange service and GeneralCAHttpClient.
+// Load and ReadConfig set it from the Transport and TLS sections of the config they read, if either
+// has a non zero value. Otherwise, unless SetDefaultTransportFactory is called, it uses the default TransportConfig.
+func DefaultTransportFactory() *TransportFactory {
+	defaultFactoryMu.Lock()
+	defer defaultFactoryMu.Unlock()
+	if defaultFactory == nil {
+		// The default configuration has no files to load, so it cannot fail
+		defaultFactory, _ = NewTransportFactory(nil, zap.NewNop())
+	}
+	return defaultFactory
+}
+
+// SetDefaultTransportFactory replaces the factory returned by DefaultTransportFactory
+func SetDefaultTransportFactory(factory *TransportFactory) {
+	defaultFactoryMu.Lock()
+	defer defaultFactoryMu.Unlock()
+	defaultFactory = factory
+}
+
+// configureDefaultTransportFactory sets the DefaultTransportFactory from the Transport and TLS sections
+// of conf, if either has a non zero value. ParseConfig allocates every section, so a nil check is not enough.
+// The current factory, and so its pooled connections, is kept if it was built from the same sections.
+func configureDefaultTransportFactory(conf *Config, logger *zap.Logger) error {
+	if isZeroSection(conf.Transport) && isZeroSection(conf.TLS) {
+		return nil
+	}
+	defaultFactoryMu.Lock()
+	current := defaultFactory
+	defaultFactoryMu.Unlock()
+	if current != nil && current.builtFrom(conf) {
+		return nil
+	}
+	f, err := NewTransportFactory(conf, logger)
+	if err != nil {
+		logger.Error("Failed to build HTTP transports", zap.Error(err))
+		return err
+	}
+	SetDefaultTransportFactory(f)
+	return nil
+}
+
+// NewTransportFactory returns a factory for the Transport and TLS sections of conf, which may be nil
+func NewTransportFactory(conf *Config, logger *zap.Logger) (*TransportFactory, error) {
+	f := &TransportFactory{
+		logger:     logger,
+		proxy:      http.ProxyFromEnvironment,
+		transports: map[string]http.RoundTripper{},
+		clients:    map[string]*http.Client{},
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: transportMetricsNamespace,
+				Name:      "http_client_requests_total",
+				Help:      "The number of HTTP requests completed, by status code class or error.",
+			}, []string{"endpoint", "outcome"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: transportMetricsNamespace,
+				Name:      "http_client_request_duration_seconds",
+				Help:      "Time taken by HTTP requests until the response headers are received.",
+				Buckets:   prometheus.DefBuckets,
+			}, []string{"endpoint"},
+		),
+		inFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: transportMetricsNamespace,
+				Name:      "http_client_requests_in_flight",
+				Help:      "The number of HTTP requests waiting for their response headers.",
+			}, []string{"endpoint"},
+		),
+		connections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: transportMetricsNamespace,
+				Name:      "http_client_connections_total",
+				Help:      "The number of connections used by HTTP requests, by whether they were reused from the idle pool.",
+			}, []string{"endpoint", "reused"},
+		),
+	}
+	if conf != nil {
+		f.tls = conf.TLS
+		if conf.Transport != nil {
+			f.conf = *conf.Transport.clone()
+		}
+	}
+	if err := f.conf.validateTimeouts(); err != nil {
+		return nil, err
+	}
+	if f.conf.ProxyURL != "" {
+		proxyURL, err := url.Parse(f.conf.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		f.proxy = http.ProxyURL(proxyURL)
+	}
+
+	var err error
+	if f.shared, err = f.newTransport(EndpointTimeouts{}, ""); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// builtFrom reports whether the factory was built from the Transport and TLS sections of conf
+func (f *TransportFactory) builtFrom(conf *Config) bool {
+	transport := TransportConfig{}
+	if conf.Transport != nil {
+		transport = *conf.Transport
+	}
+	return reflect.DeepEqual(f.conf, transport) && reflect.DeepEqual(f.tls, conf.TLS)
+}
+
+// Client returns the client for the endpoint, a URL or host, creating it on first use.
+// The client instruments its requests with the endpoint host.
+func (f *TransportFactory) Client(endpoint string) (*http.Client, error) {
+	host := endpointHost(endpoint)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if client, ok := f.clients[host]; ok {
+		return client, nil
+	}
+
+	timeouts, override := f.endpointTimeouts(host)
+	serverName := f.tls.serverName(host)
+	transport := f.shared
+	if override || serverName != "" {
+		var err error
+		if transport, err = f.newTransport(timeouts, serverName); err != nil {
+			f.logger.Error("Failed to build HTTP transport", zap.String("endpoint", host), zap.Error(err))
+			return nil, err
+		}
+		f.transports[host] = transport
+	}
+
+	requestTimeout, _ := parseDuration(timeouts.RequestTimeout, f.conf.RequestTimeout, DefaultRequestTimeout)
+	client := &http.Client{
+		Transport: &instrumentedTransport{next: transport, endpoint: host, factory: f},
+		Timeout:   requestTimeout,
+	}
+	f.clients[host] = client
+	return client, nil
+}
+
+// CloseIdleConnections closes the idle connections of every transport
+func (f *TransportFactory) CloseIdleConnections() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	closeIdleConnections(f.shared)
+	for _, transport := range f.transports {
+		closeIdleConnections(transport)
+	}
+}
+
+// Describe implements prometheus.Collector
+func (f *TransportFactory) Describe(ch chan<- *prometheus.Desc) {
+	f.requests.Describe(ch)
+	f.duration.Describe(ch)
+	f.inFlight.Describe(ch)
+	f.connections.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (f *TransportFactory) Collect(ch chan<- prometheus.Metric) {
+	f.requests.Collect(ch)
+	f.duration.Collect(ch)
+	f.inFlight.Collect(ch)
+	f.connections.Collect(ch)
+}
+
+// Register registers the metrics with the registerer
+func (f *TransportFactory) Register(registerer prometheus.Registerer) error {
+	return registerer.Register(f)
+}
+
+// endpointTimeouts returns the timeouts overridden for the host, with or without its port
+func (f *TransportFactory) endpointTimeouts(host string) (EndpointTimeouts, bool) {
+	if timeouts, ok := f.conf.Endpoints[host]; ok {
+		return timeouts, true
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		if timeouts, ok := f.conf.Endpoints[hostname]; ok {
+			return timeouts, true
+		}
+	}
+	return EndpointTimeouts{}, false
+}
+
+// newTransport returns a TLSTransport building http.Transports with the timeouts, and verifying the server
+// certificates against serverName if it is set
+func (f *TransportFactory) newTransport(timeouts EndpointTimeouts, serverName string) (http.RoundTripper, error) {
+	return NewTLSTransport(f.tls, f.transportBuilder(timeouts, serverName), f.logger)
+}
+
+// newHTTPTransport returns an http.Transport of its own with the default timeouts and the current contents
+// of the certificate files. It is neither cached nor instrumented, nor rebuilt when the certificates change.
+func (f *TransportFactory) newHTTPTransport() (*http.Transport, error) {
+	tlsConfig, err := f.tls.BuildTLSConfig()
+	if err != nil {
+		f.logger.Error("Failed to load TLS config", zap.Error(err))
+		return nil, err
+	}
+	return f.transportBuilder(EndpointTimeouts{}, "")(tlsConfig), nil
+}
+
+// transportBuilder returns a function building http.Transports with the timeouts, and verifying the server
+// certificates against serverName if it is set
+func (f *TransportFactory) transportBuilder(timeouts EndpointTimeouts, serverName string) func(tlsConfig *tls.Config) *http.Transport {
+	// The durations were validated by NewTransportFactory
+	dialTimeout, _ := parseDuration(timeouts.DialTimeout, f.conf.DialTimeout, DefaultDialTimeout)
+	tlsHandshakeTimeout, _ := parseDuration(timeouts.TLSHandshakeTimeout, f.conf.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout)
+	responseHeaderTimeout, _ := parseDuration(timeouts.ResponseHeaderTimeout, f.conf.ResponseHeaderTimeout, 0)
+	idleConnTimeout, _ := parseDuration("", f.conf.IdleConnTimeout, DefaultIdleConnTimeout)
+
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}
+	return func(tlsConfig *tls.Config) *http.Transport {
+		if serverName != "" {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = serverName
+		}
+		return &http.Transport{
+			Proxy:                 f.proxy,
+			DialContext:           dialer.DialContext,
+			TLSClientConfig:       tlsConfig,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          intOrDefault(f.conf.MaxIdleConns, DefaultMaxIdleConns),
+			MaxIdleConnsPerHost:   intOrDefault(f.conf.MaxIdleConnsPerHost, DefaultMaxIdleConnsPerHost),
+			MaxConnsPerHost:       f.conf.MaxConnsPerHost,
+			IdleConnTimeout:       idleConnTimeout,
+			TLSHandshakeTimeout:   tlsHandshakeTimeout,
+			ResponseHeaderTimeout: responseHeaderTimeout,
+			ExpectContinueTimeout: time.Second,
+		}
+	}
+}
+
+// instrumentedTransport records the metrics of the requests to an endpoint
+type instrumentedTransport struct {
+	next     http.RoundTripper
+	endpoint string
+	factory  *TransportFactory
+}
+
+// RoundTrip ...
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f := t.factory
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			f.connections.WithLabelValues(t.endpoint, strconv.FormatBool(info.Reused)).Inc()
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	inFlight := f.inFlight.WithLabelValues(t.endpoint)
+	inFlight.Inc()
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	inFlight.Dec()
+	f.duration.WithLabelValues(t.endpoint).Observe(time.Since(start).Seconds())
+
+	outcome := "error"
+	if err == nil {
+		outcome = strconv.Itoa(resp.StatusCode/100) + "xx"
+	}
+	f.requests.WithLabelValues(t.endpoint, outcome).Inc()
+	return resp, err
+}
+
+// CloseIdleConnections ...
+func (t *instrumentedTransport) CloseIdleConnections() {
+	closeIdleConnections(t.next)
+}
+
+// closeIdleConnections ...
+func closeIdleConnections(transport http.RoundTripper) {
+	if closer, ok := transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// isZeroSection reports whether the config section, a pointer to a struct, is nil or has only zero
+// fields. Empty maps and slices count as zero.
+func isZeroSection(section interface{}) bool {
+	v := reflect.Indirect(reflect.ValueOf(section))
+	if !v.IsValid() {
+		return true
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Map, reflect.Slice:
+			if field.Len() > 0 {
+				return false
+			}
+		default:
+			if !field.IsZero() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validateTimeouts checks the durations NewTransportFactory parses
+func (tc *TransportConfig) validateTimeouts() error {
+	v := &validator{}
+	tc.validate(v)
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// endpointHost returns the host of a URL, or the endpoint itself if it is not a URL
+func endpointHost(endpoint string) string {
+	if endpoint == "" {
+		return defaultEndpoint
+	}
+	if strings.Contains(endpoint, "://") {
+		if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+			return u.Host
+		}
+	}
+	return endpoint
+}
+
+// parseDuration parses the first non empty value, returning def if both are empty
+func parseDuration(value, fallback string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		value = fallback
+	}
+	if value == "" {
+		return def, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// intOrDefault ...
+func intOrDefault(value, def int) int {
+	if value == 0 {
+		return def
+	}
+	return value
+}
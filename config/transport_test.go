@@ -0,0 +1,229 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config ...
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransportFactory(t *testing.T) {
+	f, err := NewTransportFactory(nil, testLogger)
+	require.Nil(t, err)
+
+	iam, err := f.Client("https://iam.cloud.ibm.com/identity/token")
+	require.Nil(t, err)
+	assert.Equal(t, DefaultRequestTimeout, iam.Timeout)
+	cached, err := f.Client("iam.cloud.ibm.com")
+	require.Nil(t, err)
+	assert.Same(t, iam, cached)
+
+	// Endpoints without overrides share the transport
+	vpc, err := f.Client("https://us-south.iaas.cloud.ibm.com")
+	require.Nil(t, err)
+	assert.NotSame(t, iam, vpc)
+	assert.Same(t, iam.Transport.(*instrumentedTransport).next, vpc.Transport.(*instrumentedTransport).next)
+
+	_, err = NewTransportFactory(&Config{Transport: &TransportConfig{DialTimeout: "10"}}, testLogger)
+	assert.NotNil(t, err)
+	_, err = NewTransportFactory(&Config{TLS: &TLSConfig{MinVersion: "1.0"}}, testLogger)
+	assert.NotNil(t, err)
+}
+
+func TestTransportFactoryEndpointTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	f, err := NewTransportFactory(&Config{Transport: &TransportConfig{
+		RequestTimeout: "30s",
+		Endpoints: map[string]EndpointTimeouts{
+			"127.0.0.1": {ResponseHeaderTimeout: "50ms", RequestTimeout: "5s"},
+		},
+	}}, testLogger)
+	require.Nil(t, err)
+
+	client, err := f.Client(server.URL)
+	require.Nil(t, err)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+
+	other, err := f.Client("https://iam.cloud.ibm.com")
+	require.Nil(t, err)
+	assert.Equal(t, 30*time.Second, other.Timeout)
+	assert.NotSame(t, client.Transport.(*instrumentedTransport).next, other.Transport.(*instrumentedTransport).next)
+}
+
+func TestTransportFactoryServerNames(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	writeServerCA(t, caBundle, server)
+
+	// The test server certificate is issued for example.com and 127.0.0.1
+	f, err := NewTransportFactory(&Config{TLS: &TLSConfig{
+		CABundlePath: caBundle,
+		ServerNames:  map[string]string{"127.0.0.1": "iam.invalid", "localhost": "example.com"},
+	}}, testLogger)
+	require.Nil(t, err)
+
+	// The server name is only overridden for its endpoint
+	client, err := f.Client(server.URL)
+	require.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+
+	localhost := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	client, err = f.Client(localhost)
+	require.Nil(t, err)
+	resp, err := client.Get(localhost)
+	require.Nil(t, err)
+	_ = resp.Body.Close()
+
+	other, err := f.Client("https://us-south.iaas.cloud.ibm.com")
+	require.Nil(t, err)
+	assert.NotSame(t, client.Transport.(*instrumentedTransport).next, other.Transport.(*instrumentedTransport).next)
+	assert.Same(t, f.shared, other.Transport.(*instrumentedTransport).next)
+}
+
+func TestTransportFactoryProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proxied-Host", r.Host)
+	}))
+	defer proxy.Close()
+
+	f, err := NewTransportFactory(&Config{Transport: &TransportConfig{ProxyURL: proxy.URL}}, testLogger)
+	require.Nil(t, err)
+	client, err := f.Client("http://iam.example.com")
+	require.Nil(t, err)
+	resp, err := client.Get("http://iam.example.com/identity/token")
+	require.Nil(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "iam.example.com", resp.Header.Get("X-Proxied-Host"))
+}
+
+func TestTransportFactoryMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	f, err := NewTransportFactory(nil, testLogger)
+	require.Nil(t, err)
+	registry := prometheus.NewRegistry()
+	require.Nil(t, f.Register(registry))
+
+	client, err := f.Client(server.URL)
+	require.Nil(t, err)
+	endpoint := endpointHost(server.URL)
+	for _, path := range []string{"/", "/", "/missing"} {
+		resp, err := client.Get(server.URL + path)
+		require.Nil(t, err)
+		_ = resp.Body.Close()
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(f.connections.WithLabelValues(endpoint, "false")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(f.connections.WithLabelValues(endpoint, "true")))
+
+	server.Close()
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(f.requests.WithLabelValues(endpoint, "2xx")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(f.requests.WithLabelValues(endpoint, "4xx")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(f.requests.WithLabelValues(endpoint, "error")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(f.inFlight.WithLabelValues(endpoint)))
+	assert.Equal(t, 3, testutil.CollectAndCount(f, "ibmcloud_storage_volume_lib_http_client_requests_total"))
+
+	f.CloseIdleConnections()
+}
+
+func TestDefaultTransportFactory(t *testing.T) {
+	defer SetDefaultTransportFactory(nil)
+
+	f, err := NewTransportFactory(nil, testLogger)
+	require.Nil(t, err)
+	SetDefaultTransportFactory(f)
+	assert.Same(t, f, DefaultTransportFactory())
+}
+
+func TestLoadKeepsDefaultTransportFactory(t *testing.T) {
+	defer SetDefaultTransportFactory(nil)
+	f, err := NewTransportFactory(nil, testLogger)
+	require.Nil(t, err)
+	SetDefaultTransportFactory(f)
+
+	// ParseConfig allocates the empty TLS and Transport sections, which leave the factory unchanged
+	conf, err := ParseConfig(testLogger, "[server]\ndebug_trace=true\n")
+	require.Nil(t, err)
+	require.NotNil(t, conf.TLS)
+	require.NotNil(t, conf.Transport)
+	require.Nil(t, configureDefaultTransportFactory(conf, testLogger))
+	assert.Same(t, f, DefaultTransportFactory())
+
+	path := writeConfigFile(t, "server.toml", "[server]\ndebug_trace = true\n")
+	_, err = Load(testLogger, WithFiles(path))
+	require.Nil(t, err)
+	assert.Same(t, f, DefaultTransportFactory())
+
+	// A config with the same sections keeps the factory, and its pooled connections
+	path = writeConfigFile(t, "transport.toml", "[server]\n\n[transport]\nmax_idle_conns = 20\n")
+	_, err = Load(testLogger, WithFiles(path))
+	require.Nil(t, err)
+	configured := DefaultTransportFactory()
+	assert.NotSame(t, f, configured)
+	_, err = Load(testLogger, WithFiles(path))
+	require.Nil(t, err)
+	assert.Same(t, configured, DefaultTransportFactory())
+}
+
+func TestLoadTransportConfig(t *testing.T) {
+	path := writeConfigFile(t, "transport.toml", `
+[server]
+
+[transport]
+max_idle_conns = 20
+request_timeout = "60s"
+
+[transport.endpoints."iam.cloud.ibm.com"]
+request_timeout = "20s"
+`)
+	defer SetDefaultTransportFactory(nil)
+	loaded, err := Load(testLogger, WithFiles(path))
+	require.Nil(t, err)
+	assert.Equal(t, Provenance{Source: SourceFile, Location: path}, loaded.Provenance["Transport.Endpoints"])
+
+	// The default factory is built from the loaded config
+	f := DefaultTransportFactory()
+	client, err := f.Client("https://iam.cloud.ibm.com")
+	require.Nil(t, err)
+	assert.Equal(t, 20*time.Second, client.Timeout)
+	client, err = f.Client("https://us-south.iaas.cloud.ibm.com")
+	require.Nil(t, err)
+	assert.Equal(t, 60*time.Second, client.Timeout)
+}
@@ -0,0 +1,244 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config ...
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FieldError is a validation failure of one configuration field
+type FieldError struct {
+	// Field is the path of the field, e.g. "VPC.EndpointURL"
+	Field string
+
+	// Message describes what is wrong with the field
+	Message string
+}
+
+// Error satisfies the error contract
+func (fe FieldError) Error() string {
+	return fe.Field + ": " + fe.Message
+}
+
+// ValidationErrors are all the validation failures of a Config
+type ValidationErrors []FieldError
+
+// Error satisfies the error contract
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(ve))
+	for _, fe := range ve {
+		msgs = append(msgs, fe.Error())
+	}
+	return fmt.Sprintf("invalid config: %s", strings.Join(msgs, "; "))
+}
+
+// validator collects FieldErrors
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) addf(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf(field, "is required")
+	}
+}
+
+// url checks that a non empty value is an absolute http(s) URL
+func (v *validator) url(field, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		v.addf(field, "is not a valid URL: %v", err)
+		return
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addf(field, "'%s' is not an absolute http or https URL", value)
+	}
+}
+
+// duration checks that a non empty value is a positive duration with a unit, e.g. "120s"
+func (v *validator) duration(field, value string) {
+	if value == "" {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		v.addf(field, "'%s' is not a duration with a unit, e.g. \"120s\"", value)
+		return
+	}
+	if d <= 0 {
+		v.addf(field, "'%s' must be positive", value)
+	}
+}
+
+func (v *validator) nonNegative(field string, value int) {
+	if value < 0 {
+		v.addf(field, "must not be negative, got %d", value)
+	}
+}
+
+// Validate checks the semantics of the configuration: fields required by enabled providers,
+// URLs, durations and retry bounds. It returns ValidationErrors listing every failure, or nil.
+func (c *Config) Validate() error {
+	v := &validator{}
+	if c.Server == nil {
+		v.addf("Server", "is required")
+	}
+	if c.Bluemix != nil {
+		v.url("Bluemix.IamURL", c.Bluemix.IamURL)
+		v.url("Bluemix.APIEndpointURL", c.Bluemix.APIEndpointURL)
+		v.url("Bluemix.PrivateAPIRoute", c.Bluemix.PrivateAPIRoute)
+	}
+	if c.Softlayer != nil {
+		c.Softlayer.validate(v)
+	}
+	if c.VPC != nil {
+		c.VPC.validate(v)
+	}
+	if c.IKS != nil && c.IKS.Enabled {
+		v.required("IKS.IKSBlockProviderName", c.IKS.IKSBlockProviderName)
+	}
+	if c.TLS != nil {
+		c.TLS.validate(v)
+	}
+	if c.Transport != nil {
+		c.Transport.validate(v)
+	}
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// validate ...
+func (sc *SoftlayerConfig) validate(v *validator) {
+	if sc.SoftlayerBlockEnabled {
+		v.required("Softlayer.SoftlayerBlockProviderName", sc.SoftlayerBlockProviderName)
+	}
+	if sc.SoftlayerFileEnabled {
+		v.required("Softlayer.SoftlayerFileProviderName", sc.SoftlayerFileProviderName)
+	}
+	if sc.SoftlayerBlockEnabled || sc.SoftlayerFileEnabled {
+		v.required("Softlayer.SoftlayerEndpointURL", sc.SoftlayerEndpointURL)
+	}
+	v.url("Softlayer.SoftlayerEndpointURL", sc.SoftlayerEndpointURL)
+	v.url("Softlayer.SoftlayerIMSEndpointURL", sc.SoftlayerIMSEndpointURL)
+	v.duration("Softlayer.SoftlayerTimeout", sc.SoftlayerTimeout)
+	v.duration("Softlayer.SoftlayerVolProvisionTimeout", sc.SoftlayerVolProvisionTimeout)
+	v.duration("Softlayer.SoftlayerRetryInterval", sc.SoftlayerRetryInterval)
+}
+
+// validate ...
+func (vc *VPCProviderConfig) validate(v *validator) {
+	vpcType := strings.ToLower(vc.VPCTypeEnabled)
+	if vpcType != "" && vpcType != "gc" && vpcType != "g2" {
+		v.addf("VPC.VPCTypeEnabled", "'%s' is not one of gc, g2", vc.VPCTypeEnabled)
+	}
+	if vc.Enabled {
+		v.required("VPC.VPCBlockProviderName", vc.VPCBlockProviderName)
+		switch vpcType {
+		case "gc":
+			v.required("VPC.EndpointURL", vc.EndpointURL)
+		case "g2":
+			v.required("VPC.G2EndpointURL", vc.G2EndpointURL)
+		case "":
+			if vc.EndpointURL == "" && vc.G2EndpointURL == "" {
+				v.addf("VPC.EndpointURL", "is required, or VPC.G2EndpointURL, when VPC is enabled")
+			}
+		}
+	}
+
+	v.url("VPC.EndpointURL", vc.EndpointURL)
+	v.url("VPC.PrivateEndpointURL", vc.PrivateEndpointURL)
+	v.url("VPC.TokenExchangeURL", vc.TokenExchangeURL)
+	v.url("VPC.G2EndpointURL", vc.G2EndpointURL)
+	v.url("VPC.G2EndpointPrivateURL", vc.G2EndpointPrivateURL)
+	v.url("VPC.G2TokenExchangeURL", vc.G2TokenExchangeURL)
+	v.url("VPC.IKSTokenExchangePrivateURL", vc.IKSTokenExchangePrivateURL)
+	v.duration("VPC.VPCTimeout", vc.VPCTimeout)
+
+	v.nonNegative("VPC.MaxRetryAttempt", vc.MaxRetryAttempt)
+	v.nonNegative("VPC.MaxRetryGap", vc.MaxRetryGap)
+	v.nonNegative("VPC.MaxVPCRetryAttempt", vc.MaxVPCRetryAttempt)
+	v.nonNegative("VPC.MinVPCRetryGap", vc.MinVPCRetryGap)
+	v.nonNegative("VPC.MinVPCRetryGapAttempt", vc.MinVPCRetryGapAttempt)
+	if vc.MaxRetryGap > 0 && vc.MinVPCRetryGap > vc.MaxRetryGap {
+		v.addf("VPC.MinVPCRetryGap", "%d is greater than VPC.MaxRetryGap %d", vc.MinVPCRetryGap, vc.MaxRetryGap)
+	}
+	if vc.MaxVPCRetryAttempt > 0 && vc.MinVPCRetryGapAttempt > vc.MaxVPCRetryAttempt {
+		v.addf("VPC.MinVPCRetryGapAttempt", "%d is greater than VPC.MaxVPCRetryAttempt %d", vc.MinVPCRetryGapAttempt, vc.MaxVPCRetryAttempt)
+	}
+}
+
+// validate ...
+func (tc *TLSConfig) validate(v *validator) {
+	if _, err := tc.TLSVersion(); err != nil {
+		v.addf("TLS.MinVersion", "%v", err)
+	}
+	if _, err := tc.CipherSuiteIDs(); err != nil {
+		v.addf("TLS.CipherSuites", "%v", err)
+	}
+	if (tc.ClientCertPath == "") != (tc.ClientKeyPath == "") {
+		v.addf("TLS.ClientCertPath", "must be set together with TLS.ClientKeyPath")
+	}
+	hosts := make([]string, 0, len(tc.ServerNames))
+	for host := range tc.ServerNames {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		v.required(fmt.Sprintf("TLS.ServerNames[%s]", host), tc.ServerNames[host])
+	}
+}
+
+// validate ...
+func (tc *TransportConfig) validate(v *validator) {
+	v.nonNegative("Transport.MaxIdleConns", tc.MaxIdleConns)
+	v.nonNegative("Transport.MaxIdleConnsPerHost", tc.MaxIdleConnsPerHost)
+	v.nonNegative("Transport.MaxConnsPerHost", tc.MaxConnsPerHost)
+	v.duration("Transport.IdleConnTimeout", tc.IdleConnTimeout)
+	v.url("Transport.ProxyURL", tc.ProxyURL)
+	v.duration("Transport.DialTimeout", tc.DialTimeout)
+	v.duration("Transport.TLSHandshakeTimeout", tc.TLSHandshakeTimeout)
+	v.duration("Transport.ResponseHeaderTimeout", tc.ResponseHeaderTimeout)
+	v.duration("Transport.RequestTimeout", tc.RequestTimeout)
+
+	hosts := make([]string, 0, len(tc.Endpoints))
+	for host := range tc.Endpoints {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		timeouts := tc.Endpoints[host]
+		field := fmt.Sprintf("Transport.Endpoints[%s].", host)
+		v.duration(field+"DialTimeout", timeouts.DialTimeout)
+		v.duration(field+"TLSHandshakeTimeout", timeouts.TLSHandshakeTimeout)
+		v.duration(field+"ResponseHeaderTimeout", timeouts.ResponseHeaderTimeout)
+		v.duration(field+"RequestTimeout", timeouts.RequestTimeout)
+	}
+}
@@ -0,0 +1,205 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config ...
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Server: &ServerConfig{},
+		VPC: &VPCProviderConfig{
+			Enabled:              true,
+			VPCBlockProviderName: "vpc",
+			G2EndpointURL:        "https://us-south.iaas.cloud.ibm.com",
+			G2TokenExchangeURL:   "https://iam.cloud.ibm.com/oidc/token",
+			VPCTimeout:           "120s",
+			MaxRetryAttempt:      10,
+			MaxRetryGap:          60,
+		},
+		IKS: &IKSConfig{
+			Enabled:              true,
+			IKSBlockProviderName: "iks-vpc-classic",
+		},
+	}
+}
+
+func fieldsOf(t *testing.T, err error) []string {
+	var verrs ValidationErrors
+	require.True(t, errors.As(err, &verrs), "expected ValidationErrors, got %v", err)
+	fields := []string{}
+	for _, fe := range verrs {
+		fields = append(fields, fe.Field)
+	}
+	return fields
+}
+
+func TestValidateValid(t *testing.T) {
+	assert.Nil(t, validConfig().Validate())
+
+	pwd, err := os.Getwd()
+	require.Nil(t, err)
+	data, err := os.ReadFile(filepath.Join(pwd, "..", "etc", "libconfig.toml"))
+	require.Nil(t, err)
+	conf, err := ParseConfig(testLogger, string(data), WithValidation())
+	assert.Nil(t, err)
+	assert.NotNil(t, conf)
+}
+
+func TestValidate(t *testing.T) {
+	testcases := []struct {
+		testcasename   string
+		mutate         func(c *Config)
+		expectedFields []string
+	}{
+		{
+			testcasename:   "Missing server",
+			mutate:         func(c *Config) { c.Server = nil },
+			expectedFields: []string{"Server"},
+		},
+		{
+			testcasename:   "VPC enabled without provider name",
+			mutate:         func(c *Config) { c.VPC.VPCBlockProviderName = "" },
+			expectedFields: []string{"VPC.VPCBlockProviderName"},
+		},
+		{
+			testcasename:   "Unknown VPC type",
+			mutate:         func(c *Config) { c.VPC.VPCTypeEnabled = "g3" },
+			expectedFields: []string{"VPC.VPCTypeEnabled"},
+		},
+		{
+			testcasename:   "Missing endpoint for VPC type",
+			mutate:         func(c *Config) { c.VPC.VPCTypeEnabled = "gc" },
+			expectedFields: []string{"VPC.EndpointURL"},
+		},
+		{
+			testcasename:   "No VPC endpoint",
+			mutate:         func(c *Config) { c.VPC.G2EndpointURL = "" },
+			expectedFields: []string{"VPC.EndpointURL"},
+		},
+		{
+			testcasename: "Disabled VPC is not required to have endpoints",
+			mutate: func(c *Config) {
+				c.VPC.Enabled = false
+				c.VPC.G2EndpointURL = ""
+				c.VPC.VPCBlockProviderName = ""
+			},
+		},
+		{
+			testcasename: "Malformed URLs",
+			mutate: func(c *Config) {
+				c.VPC.G2EndpointURL = "us-south.iaas.cloud.ibm.com"
+				c.VPC.G2TokenExchangeURL = "ftp://iam.cloud.ibm.com"
+				c.Bluemix = &BluemixConfig{IamURL: "://iam"}
+			},
+			expectedFields: []string{"Bluemix.IamURL", "VPC.G2EndpointURL", "VPC.G2TokenExchangeURL"},
+		},
+		{
+			testcasename:   "Duration without unit",
+			mutate:         func(c *Config) { c.VPC.VPCTimeout = "120" },
+			expectedFields: []string{"VPC.VPCTimeout"},
+		},
+		{
+			testcasename:   "Negative duration",
+			mutate:         func(c *Config) { c.VPC.VPCTimeout = "-1s" },
+			expectedFields: []string{"VPC.VPCTimeout"},
+		},
+		{
+			testcasename: "Retry bounds",
+			mutate: func(c *Config) {
+				c.VPC.MaxRetryAttempt = -1
+				c.VPC.MinVPCRetryGap = 90
+			},
+			expectedFields: []string{"VPC.MaxRetryAttempt", "VPC.MinVPCRetryGap"},
+		},
+		{
+			testcasename:   "IKS enabled without provider name",
+			mutate:         func(c *Config) { c.IKS.IKSBlockProviderName = "" },
+			expectedFields: []string{"IKS.IKSBlockProviderName"},
+		},
+		{
+			testcasename: "Softlayer enabled without names and endpoint",
+			mutate: func(c *Config) {
+				c.Softlayer = &SoftlayerConfig{SoftlayerBlockEnabled: true, SoftlayerTimeout: "abc"}
+			},
+			expectedFields: []string{"Softlayer.SoftlayerBlockProviderName", "Softlayer.SoftlayerEndpointURL", "Softlayer.SoftlayerTimeout"},
+		},
+		{
+			testcasename: "Invalid TLS",
+			mutate: func(c *Config) {
+				c.TLS = &TLSConfig{MinVersion: "1.1", CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}, ClientKeyPath: "key.pem", ServerNames: map[string]string{"10.0.0.1": ""}}
+			},
+			expectedFields: []string{"TLS.MinVersion", "TLS.CipherSuites", "TLS.ClientCertPath", "TLS.ServerNames[10.0.0.1]"},
+		},
+		{
+			testcasename: "Invalid transport",
+			mutate: func(c *Config) {
+				c.Transport = &TransportConfig{
+					MaxIdleConns: -1,
+					ProxyURL:     "proxy:3128",
+					Endpoints:    map[string]EndpointTimeouts{"iam.cloud.ibm.com": {DialTimeout: "5"}},
+				}
+			},
+			expectedFields: []string{"Transport.MaxIdleConns", "Transport.ProxyURL", "Transport.Endpoints[iam.cloud.ibm.com].DialTimeout"},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.testcasename, func(t *testing.T) {
+			conf := validConfig()
+			testcase.mutate(conf)
+			err := conf.Validate()
+			if len(testcase.expectedFields) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, testcase.expectedFields, fieldsOf(t, err))
+		})
+	}
+}
+
+func TestValidationErrorsMessage(t *testing.T) {
+	conf := validConfig()
+	conf.Server = nil
+	conf.VPC.VPCTimeout = "120"
+	err := conf.Validate()
+	assert.Equal(t, `invalid config: Server: is required; VPC.VPCTimeout: '120' is not a duration with a unit, e.g. "120s"`, err.Error())
+}
+
+func TestParseConfigWithValidation(t *testing.T) {
+	data := `
+[server]
+[vpc]
+vpc_enabled = true
+vpc_api_timeout = "120"
+`
+	conf, err := ParseConfig(testLogger, data)
+	assert.Nil(t, err)
+	assert.NotNil(t, conf)
+
+	conf, err = ParseConfig(testLogger, data, WithValidation())
+	assert.Nil(t, conf)
+	assert.Equal(t, []string{"VPC.VPCBlockProviderName", "VPC.EndpointURL", "VPC.VPCTimeout"}, fieldsOf(t, err))
+}
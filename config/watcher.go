@@ -0,0 +1,334 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config ...
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/secret-utils-lib/pkg/k8s_utils"
+	"github.com/IBM/secret-utils-lib/pkg/utils"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// defaultWatchRetryInterval is the time the Watcher waits before watching the secret again after a failure
+const defaultWatchRetryInterval = 5 * time.Second
+
+// ConfigChange is published to the Watcher subscribers when the configuration changes
+type ConfigChange struct {
+	// Previous is the configuration that was replaced
+	Previous *Config
+
+	// Current is the new configuration
+	Current *Config
+
+	// Sections are the names of the Config fields that changed, e.g. "VPC"
+	Sections []string
+}
+
+// Changed returns true if the named section changed
+func (cc ConfigChange) Changed(section string) bool {
+	for _, s := range cc.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Watcher watches the storage secret store secret and publishes the configuration to its subscribers
+// each time the secret changes. A changed secret that fails to parse or validate is logged and
+// ignored, so the last good configuration stays current.
+type Watcher struct {
+	k8sClient     k8s_utils.KubernetesClient
+	logger        *zap.Logger
+	secretName    string
+	secretKey     string
+	retryInterval time.Duration
+
+	mu       sync.RWMutex
+	current  *Config
+	lastData string
+
+	subscribersMu sync.Mutex
+	subscribers   []*subscription
+}
+
+// subscription ...
+type subscription struct {
+	fn func(ConfigChange)
+}
+
+// NewWatcher reads and validates the configuration from the k8s secret and, like ReadConfig,
+// configures the DefaultTransportFactory from it. Call Run to start watching the secret for changes.
+func NewWatcher(k8sClient k8s_utils.KubernetesClient, logger *zap.Logger) (*Watcher, error) {
+	w := &Watcher{
+		k8sClient:     k8sClient,
+		logger:        logger,
+		secretName:    utils.STORAGE_SECRET_STORE_SECRET,
+		secretKey:     utils.SECRET_STORE_FILE,
+		retryInterval: defaultWatchRetryInterval,
+	}
+
+	data, err := k8s_utils.GetSecretData(k8sClient, w.secretName, w.secretKey)
+	if err != nil {
+		logger.Error("Error reading config", zap.Error(err))
+		return nil, err
+	}
+	data = strings.TrimSuffix(data, "\n")
+	conf, err := ParseConfig(logger, data, WithValidation())
+	if err != nil {
+		logger.Error("Error parsing config", zap.Error(err))
+		return nil, err
+	}
+	if err = configureDefaultTransportFactory(conf, logger); err != nil {
+		return nil, err
+	}
+	w.current = conf
+	w.lastData = data
+	return w, nil
+}
+
+// Current returns a copy of the last good configuration
+func (w *Watcher) Current() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current.Clone()
+}
+
+// Subscribe registers fn to be called with every configuration change, in the Run goroutine.
+// Each subscriber receives its own copy of the configurations. The returned function unsubscribes fn.
+func (w *Watcher) Subscribe(fn func(ConfigChange)) (unsubscribe func()) {
+	sub := &subscription{fn: fn}
+
+	w.subscribersMu.Lock()
+	defer w.subscribersMu.Unlock()
+	w.subscribers = append(w.subscribers, sub)
+
+	return func() {
+		w.subscribersMu.Lock()
+		defer w.subscribersMu.Unlock()
+		for i, s := range w.subscribers {
+			if s == sub {
+				w.subscribers = append(w.subscribers[:i:i], w.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Run watches the secret until ctx is done, watching it again whenever the watch ends or fails
+func (w *Watcher) Run(ctx context.Context) {
+	for {
+		err := w.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			continue
+		}
+		w.logger.Warn("Watching config secret failed, retrying", zap.Error(err), zap.Duration("retryInterval", w.retryInterval))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.retryInterval):
+		}
+	}
+}
+
+// watch watches the secret until the watch ends, returning nil if it was closed by the server
+func (w *Watcher) watch(ctx context.Context) error {
+	secrets := w.k8sClient.Clientset.CoreV1().Secrets(w.k8sClient.Namespace)
+	watcher, err := secrets.Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", w.secretName).String(),
+	})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	// The secret may have changed while it was not being watched
+	secret, err := secrets.Get(ctx, w.secretName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	w.update(secret)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				if secret, ok := event.Object.(*v1.Secret); ok && secret.Name == w.secretName {
+					w.update(secret)
+				}
+			case watch.Deleted:
+				w.logger.Warn("Config secret deleted, keeping the current config", zap.String("secret", w.secretName))
+			case watch.Error:
+				return apierrors.FromObject(event.Object)
+			}
+		}
+	}
+}
+
+// update applies the secret data if it changed
+func (w *Watcher) update(secret *v1.Secret) {
+	byteData, ok := secret.Data[w.secretKey]
+	if !ok {
+		w.logger.Error("Config secret has no config data, keeping the current config",
+			zap.String("secret", w.secretName), zap.String("key", w.secretKey))
+		return
+	}
+	if err := w.apply(strings.TrimSuffix(string(byteData), "\n")); err != nil {
+		w.logger.Error("Config secret invalid, keeping the current config", zap.Error(err))
+	}
+}
+
+// apply parses and validates the data and, if it is valid, configures the DefaultTransportFactory from it,
+// makes it the current configuration and notifies the subscribers of the changed sections
+func (w *Watcher) apply(data string) error {
+	w.mu.Lock()
+	if data == w.lastData {
+		w.mu.Unlock()
+		return nil
+	}
+	// A rejected secret is only reported once
+	w.lastData = data
+	conf, err := ParseConfig(w.logger, data, WithValidation())
+	if err == nil {
+		err = configureDefaultTransportFactory(conf, w.logger)
+	}
+	if err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	previous := w.current
+	w.current = conf
+	w.mu.Unlock()
+
+	sections := ChangedSections(previous, conf)
+	if len(sections) == 0 {
+		return nil
+	}
+	w.logger.Info("Config changed", zap.Strings("sections", sections))
+
+	w.subscribersMu.Lock()
+	subscribers := append([]*subscription(nil), w.subscribers...)
+	w.subscribersMu.Unlock()
+	for _, sub := range subscribers {
+		w.notify(sub, ConfigChange{Previous: previous.Clone(), Current: conf.Clone(), Sections: sections})
+	}
+	return nil
+}
+
+// notify calls the subscriber, recovering from its panics so other subscribers are still notified
+func (w *Watcher) notify(sub *subscription, change ConfigChange) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.logger.Error("Config subscriber panicked", zap.Error(errors.New(fmt.Sprint(r))))
+		}
+	}()
+	sub.fn(change)
+}
+
+// ChangedSections returns the names of the Config fields that differ between previous and current
+func ChangedSections(previous, current *Config) []string {
+	if previous == nil {
+		previous = &Config{}
+	}
+	if current == nil {
+		current = &Config{}
+	}
+	var sections []string
+	pv := reflect.ValueOf(previous).Elem()
+	cv := reflect.ValueOf(current).Elem()
+	for i := 0; i < pv.NumField(); i++ {
+		if !reflect.DeepEqual(pv.Field(i).Interface(), cv.Field(i).Interface()) {
+			sections = append(sections, pv.Type().Field(i).Name)
+		}
+	}
+	return sections
+}
+
+// Clone returns a deep copy of the configuration
+func (c *Config) Clone() *Config {
+	if c == nil {
+		return nil
+	}
+	return &Config{
+		Server:    clonePtr(c.Server),
+		Bluemix:   clonePtr(c.Bluemix),
+		Softlayer: clonePtr(c.Softlayer),
+		VPC:       clonePtr(c.VPC),
+		IKS:       clonePtr(c.IKS),
+		API:       clonePtr(c.API),
+		TLS:       c.TLS.clone(),
+		Transport: c.Transport.clone(),
+	}
+}
+
+// clone ...
+func (tc *TransportConfig) clone() *TransportConfig {
+	clone := clonePtr(tc)
+	if clone != nil && tc.Endpoints != nil {
+		clone.Endpoints = make(map[string]EndpointTimeouts, len(tc.Endpoints))
+		for host, timeouts := range tc.Endpoints {
+			clone.Endpoints[host] = timeouts
+		}
+	}
+	return clone
+}
+
+// clone ...
+func (tc *TLSConfig) clone() *TLSConfig {
+	clone := clonePtr(tc)
+	if clone != nil {
+		clone.CipherSuites = append([]string(nil), tc.CipherSuites...)
+		if tc.ServerNames != nil {
+			clone.ServerNames = make(map[string]string, len(tc.ServerNames))
+			for host, serverName := range tc.ServerNames {
+				clone.ServerNames[host] = serverName
+			}
+		}
+	}
+	return clone
+}
+
+// clonePtr copies the struct p points to
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	return &clone
+}
@@ -0,0 +1,212 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config ...
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/secret-utils-lib/pkg/k8s_utils"
+	"github.com/IBM/secret-utils-lib/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func readLibConfig(t *testing.T) string {
+	pwd, err := os.Getwd()
+	require.Nil(t, err)
+	data, err := os.ReadFile(filepath.Join(pwd, "..", "etc", "libconfig.toml"))
+	require.Nil(t, err)
+	return string(data)
+}
+
+// newWatchedClient returns a fake client holding the libconfig.toml secret, and a channel
+// closed when the secret is first watched
+func newWatchedClient(t *testing.T) (k8s_utils.KubernetesClient, <-chan struct{}) {
+	kc, err := k8s_utils.FakeGetk8sClientSet()
+	require.Nil(t, err)
+	pwd, err := os.Getwd()
+	require.Nil(t, err)
+	require.Nil(t, k8s_utils.FakeCreateSecret(kc, utils.DEFAULT, filepath.Join(pwd, "..", "etc", "libconfig.toml")))
+
+	watched := make(chan struct{})
+	var closed bool
+	kc.Clientset.(*fake.Clientset).PrependWatchReactor("secrets", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		if !closed {
+			closed = true
+			close(watched)
+		}
+		return false, nil, nil
+	})
+	return kc, watched
+}
+
+func updateSecret(t *testing.T, kc k8s_utils.KubernetesClient, data string) {
+	secrets := kc.Clientset.CoreV1().Secrets(kc.Namespace)
+	secret, err := secrets.Get(context.TODO(), utils.STORAGE_SECRET_STORE_SECRET, metav1.GetOptions{})
+	require.Nil(t, err)
+	secret.Data[utils.SECRET_STORE_FILE] = []byte(data)
+	_, err = secrets.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	require.Nil(t, err)
+}
+
+func TestNewWatcher(t *testing.T) {
+	kc, _ := newWatchedClient(t)
+	w, err := NewWatcher(kc, testLogger)
+	require.Nil(t, err)
+	conf := w.Current()
+	assert.Equal(t, "vpc", conf.VPC.VPCBlockProviderName)
+
+	// Current returns copies
+	conf.VPC.VPCBlockProviderName = "changed"
+	assert.Equal(t, "vpc", w.Current().VPC.VPCBlockProviderName)
+
+	kc, err = k8s_utils.FakeGetk8sClientSet()
+	require.Nil(t, err)
+	_, err = NewWatcher(kc, testLogger)
+	assert.NotNil(t, err)
+}
+
+func TestWatcherTransportFactory(t *testing.T) {
+	defer SetDefaultTransportFactory(nil)
+	kc, _ := newWatchedClient(t)
+	w, err := NewWatcher(kc, testLogger)
+	require.Nil(t, err)
+
+	// The secret data is compared without its trailing newline, as update does
+	libConfig := readLibConfig(t)
+	assert.Equal(t, strings.TrimSuffix(libConfig, "\n"), w.lastData)
+
+	f, err := NewTransportFactory(nil, testLogger)
+	require.Nil(t, err)
+	SetDefaultTransportFactory(f)
+
+	// A config without transport sections leaves the factory unchanged
+	require.Nil(t, w.apply(strings.Replace(libConfig, `iks_enabled = true`, `iks_enabled = false`, 1)))
+	assert.Same(t, f, DefaultTransportFactory())
+
+	// A config with transport sections configures it, like ReadConfig and Load
+	require.Nil(t, w.apply(libConfig+"\n[transport]\nmax_idle_conns = 20\n"))
+	assert.NotSame(t, f, DefaultTransportFactory())
+}
+
+func TestWatcherRun(t *testing.T) {
+	kc, watched := newWatchedClient(t)
+	w, err := NewWatcher(kc, testLogger)
+	require.Nil(t, err)
+
+	changes := make(chan ConfigChange, 10)
+	w.Subscribe(func(change ConfigChange) { changes <- change })
+	w.Subscribe(func(change ConfigChange) { panic("subscriber panic") })
+	unsubscribed := w.Subscribe(func(change ConfigChange) { t.Error("unsubscribed subscriber notified") })
+	unsubscribed()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	<-watched
+
+	libConfig := readLibConfig(t)
+	changed := strings.Replace(libConfig, `vpc_api_timeout = "120s"`, `vpc_api_timeout = "60s"`, 1)
+	require.NotEqual(t, libConfig, changed)
+	updateSecret(t, kc, changed)
+
+	var change ConfigChange
+	select {
+	case change = <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change not published")
+	}
+	assert.Equal(t, []string{"VPC"}, change.Sections)
+	assert.True(t, change.Changed("VPC"))
+	assert.False(t, change.Changed("IKS"))
+	assert.Equal(t, "120s", change.Previous.VPC.VPCTimeout)
+	assert.Equal(t, "60s", change.Current.VPC.VPCTimeout)
+	assert.Equal(t, "60s", w.Current().VPC.VPCTimeout)
+
+	// An invalid config is not published and the last good config is kept
+	updateSecret(t, kc, strings.Replace(changed, `vpc_api_timeout = "60s"`, `vpc_api_timeout = "60"`, 1))
+	// A config that only differs in formatting is not published either
+	updateSecret(t, kc, changed+"\n# comment")
+	updateSecret(t, kc, strings.Replace(changed, `iks_enabled = true`, `iks_enabled = false`, 1))
+
+	select {
+	case change = <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change not published")
+	}
+	assert.Equal(t, []string{"IKS"}, change.Sections)
+	assert.Equal(t, "60s", change.Current.VPC.VPCTimeout)
+	assert.False(t, w.Current().IKS.Enabled)
+	assert.Len(t, changes, 0)
+}
+
+func TestChangedSections(t *testing.T) {
+	conf := validConfig()
+	assert.Nil(t, ChangedSections(conf, conf.Clone()))
+	assert.Equal(t, []string{"Server", "VPC", "IKS"}, ChangedSections(nil, conf))
+
+	clone := conf.Clone()
+	clone.API = &APIConfig{}
+	clone.VPC.Enabled = false
+	assert.Equal(t, []string{"VPC", "API"}, ChangedSections(conf, clone))
+}
+
+func TestClone(t *testing.T) {
+	conf := &Config{
+		Server:    &ServerConfig{},
+		Bluemix:   &BluemixConfig{},
+		Softlayer: &SoftlayerConfig{},
+		VPC:       &VPCProviderConfig{},
+		IKS:       &IKSConfig{},
+		API:       &APIConfig{},
+		TLS:       &TLSConfig{CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}},
+		Transport: &TransportConfig{Endpoints: map[string]EndpointTimeouts{"iam.cloud.ibm.com": {RequestTimeout: "30s"}}},
+	}
+	clone := conf.Clone()
+	assert.Equal(t, conf, clone)
+
+	// Every section is copied
+	cv := reflect.ValueOf(clone).Elem()
+	ov := reflect.ValueOf(conf).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		assert.NotNil(t, cv.Field(i).Interface(), cv.Type().Field(i).Name)
+		assert.NotEqual(t, ov.Field(i).Pointer(), cv.Field(i).Pointer(), cv.Type().Field(i).Name)
+	}
+	clone.TLS.CipherSuites[0] = "changed"
+	assert.Equal(t, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", conf.TLS.CipherSuites[0])
+	clone.Transport.Endpoints["iam.cloud.ibm.com"] = EndpointTimeouts{}
+	assert.Equal(t, "30s", conf.Transport.Endpoints["iam.cloud.ibm.com"].RequestTimeout)
+	assert.Nil(t, (*Config)(nil).Clone())
+}
@@ -18,10 +18,14 @@
 package metrics
 
 import (
+	"errors"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -31,39 +35,133 @@ type FunctionLabel string
 
 const (
 	pluginNamespace = "ibmcloud_storage_volume_lib"
-)
 
-var (
-	functionDuration = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: pluginNamespace,
-			Name:      "function_duration_seconds",
-			Help:      "Time taken by various operation of library",
-			//Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1.0, 2.5, 5.0, 7.5, 10.0, 12.5, 15.0, 17.5, 20.0, 22.5, 25.0, 27.5, 30.0, 50.0, 75.0, 100.0, 1000.0},
-		}, []string{"function"},
-	)
-	functionCount = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: pluginNamespace,
-			Name:      "functions_total",
-			Help:      "The number of library operation  completeted successfully.",
-		}, []string{"function"},
-	)
-
-	errorsCount = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: pluginNamespace,
-			Name:      "errors_total",
-			Help:      "The number of library operation  failed due to an error.",
-		}, []string{"type"},
-	)
+	// OutcomeSuccess is the outcome label of operations which succeeded
+	OutcomeSuccess = "success"
+
+	// OutcomeError is the outcome label of operations which failed
+	OutcomeError = "error"
+
+	// OutcomeUnknown is the outcome label of operations recorded by the deprecated package
+	// functions, which are not told whether the operation succeeded
+	OutcomeUnknown = "unknown"
 )
 
-// RegisterAll registers all metrics.
+// DurationBuckets are the buckets of the function_duration_seconds histogram
+var DurationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1.0, 2.5, 5.0, 7.5, 10.0, 12.5, 15.0, 17.5, 20.0, 22.5, 25.0, 27.5, 30.0, 50.0, 75.0, 100.0, 1000.0}
+
+// Recorder holds the library metrics. It is a prometheus.Collector, so it can be registered
+// with any prometheus.Registerer.
+type Recorder struct {
+	functionDuration *prometheus.HistogramVec
+	functionCount    *prometheus.CounterVec
+	errorsCount      *prometheus.CounterVec
+	inFlight         *prometheus.GaugeVec
+}
+
+var _ prometheus.Collector = &Recorder{}
+
+// DefaultRecorder is the Recorder used by the package level functions
+var DefaultRecorder = NewRecorder()
+
+// NewRecorder returns a Recorder with its own, unregistered, metrics
+func NewRecorder() *Recorder {
+	return &Recorder{
+		functionDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: pluginNamespace,
+				Name:      "function_duration_seconds",
+				Help:      "Time taken by various operation of library",
+				Buckets:   DurationBuckets,
+			}, []string{"function", "provider", "outcome"},
+		),
+		functionCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: pluginNamespace,
+				Name:      "functions_total",
+				Help:      "The number of library operation completed, successfully or not.",
+			}, []string{"function", "provider", "outcome"},
+		),
+		errorsCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: pluginNamespace,
+				Name:      "errors_total",
+				Help:      "The number of library operation failed due to an error.",
+			}, []string{"function", "provider", "type", "reason_code"},
+		),
+		inFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: pluginNamespace,
+				Name:      "functions_in_flight",
+				Help:      "The number of library operation in progress.",
+			}, []string{"function", "provider"},
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (r *Recorder) Describe(ch chan<- *prometheus.Desc) {
+	r.functionDuration.Describe(ch)
+	r.functionCount.Describe(ch)
+	r.errorsCount.Describe(ch)
+	r.inFlight.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (r *Recorder) Collect(ch chan<- prometheus.Metric) {
+	r.functionDuration.Collect(ch)
+	r.functionCount.Collect(ch)
+	r.errorsCount.Collect(ch)
+	r.inFlight.Collect(ch)
+}
+
+// Register registers the metrics with the registerer
+func (r *Recorder) Register(registerer prometheus.Registerer) error {
+	return registerer.Register(r)
+}
+
+// Start marks the start of an operation, counting it as in flight. The returned function
+// must be called with the outcome of the operation to record it.
+func (r *Recorder) Start(function, providerName string) func(err error) {
+	start := time.Now()
+	inFlight := r.inFlight.WithLabelValues(function, providerName)
+	inFlight.Inc()
+	return func(err error) {
+		inFlight.Dec()
+		r.Observe(function, providerName, time.Since(start), err)
+	}
+}
+
+// Observe records the duration and outcome of a completed operation
+func (r *Recorder) Observe(function, providerName string, duration time.Duration, err error) {
+	outcome := Outcome(err)
+	r.functionDuration.WithLabelValues(function, providerName, outcome).Observe(duration.Seconds())
+	r.functionCount.WithLabelValues(function, providerName, outcome).Inc()
+	if err != nil {
+		r.errorsCount.WithLabelValues(function, providerName, util.GetErrorType(err), string(ErrorReasonCode(err))).Inc()
+	}
+}
+
+// Outcome returns the outcome label of an operation which returned err
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+// ErrorReasonCode returns the reason code of the provider.Error in the chain of err, else ErrorUnclassified
+func ErrorReasonCode(err error) reasoncode.ReasonCode {
+	var perr provider.Error
+	if errors.As(err, &perr) {
+		return perr.Code()
+	}
+	return reasoncode.ErrorUnclassified
+}
+
+// RegisterAll registers all metrics of the DefaultRecorder with the global prometheus registry.
 func RegisterAll() {
-	prometheus.MustRegister(functionDuration)
-	prometheus.MustRegister(functionCount)
-	prometheus.MustRegister(errorsCount)
+	prometheus.MustRegister(DefaultRecorder)
 }
 
 // UpdateDurationFromStart records the duration of the step identified by the
@@ -74,20 +172,26 @@ func UpdateDurationFromStart(logger *zap.Logger, label string, start time.Time)
 	UpdateDuration(label, duration)
 }
 
-// UpdateDuration records the duration of the step identified by the label
+// UpdateDuration records the duration of the step identified by the label, with the unknown outcome
+//
+// Deprecated: use Recorder.Start or Recorder.Observe, which also record the provider and outcome
 func UpdateDuration(label string, duration time.Duration) {
-	functionDuration.WithLabelValues(label).Set(duration.Seconds())
+	DefaultRecorder.functionDuration.WithLabelValues(label, "", OutcomeUnknown).Observe(duration.Seconds())
 }
 
-// RegisterError records any errors for any lib operation.
+// RegisterError records any errors for any lib operation, by error type and reason code.
+//
+// Deprecated: use Recorder.Start or Recorder.Observe
 func RegisterError(errType string, err error) {
 	if err != nil {
-		errType = err.Error() // TODO Get the error code
+		errType = util.GetErrorType(err)
 	}
-	errorsCount.WithLabelValues(errType).Add(1.0)
+	DefaultRecorder.errorsCount.WithLabelValues("", "", errType, string(ErrorReasonCode(err))).Inc()
 }
 
-// RegisterFunction records number of operation.
+// RegisterFunction records number of operation, with the unknown outcome.
+//
+// Deprecated: use Recorder.Start or Recorder.Observe
 func RegisterFunction(label string) {
-	functionCount.WithLabelValues(label).Add(1.0)
+	DefaultRecorder.functionCount.WithLabelValues(label, "", OutcomeUnknown).Inc()
 }
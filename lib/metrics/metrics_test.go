@@ -0,0 +1,99 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package metrics ...
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRecorderRegister(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewRecorder()
+	assert.NoError(t, recorder.Register(registry))
+	assert.Error(t, recorder.Register(registry))
+
+	// A second recorder has its own metrics but the same names
+	assert.NoError(t, NewRecorder().Register(prometheus.NewRegistry()))
+}
+
+func TestRecorderStart(t *testing.T) {
+	recorder := NewRecorder()
+
+	done := recorder.Start("CreateVolume", "vpc")
+	assert.Equal(t, 1.0, testutil.ToFloat64(recorder.inFlight.WithLabelValues("CreateVolume", "vpc")))
+	done(nil)
+	assert.Equal(t, 0.0, testutil.ToFloat64(recorder.inFlight.WithLabelValues("CreateVolume", "vpc")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recorder.functionCount.WithLabelValues("CreateVolume", "vpc", OutcomeSuccess)))
+
+	err := util.Message{Code: "StorageFindFailedWithVolumeId", Type: util.EntityNotFound, Description: "volume 'vol-1' not found"}
+	recorder.Start("GetVolume", "vpc")(err)
+	recorder.Start("GetVolume", "vpc")(fmt.Errorf("wrapped: %w", util.NewError(reasoncode.ErrorRateLimitExceeded, "rate limited")))
+	recorder.Start("GetVolume", "vpc")(errors.New("volume 'vol-2' not found"))
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(recorder.functionCount.WithLabelValues("GetVolume", "vpc", OutcomeError)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recorder.errorsCount.WithLabelValues("GetVolume", "vpc", util.EntityNotFound, string(reasoncode.ErrorUnclassified))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recorder.errorsCount.WithLabelValues("GetVolume", "vpc", util.ErrorTypeFailed, string(reasoncode.ErrorRateLimitExceeded))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recorder.errorsCount.WithLabelValues("GetVolume", "vpc", util.ErrorTypeFailed, string(reasoncode.ErrorUnclassified))))
+
+	// Error messages are never used as labels
+	assert.Equal(t, 3, testutil.CollectAndCount(recorder.errorsCount))
+}
+
+func TestRecorderObserve(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewRecorder()
+	assert.NoError(t, recorder.Register(registry))
+
+	recorder.Observe("AttachVolume", "vpc", 3*time.Second, nil)
+	recorder.Observe("AttachVolume", "vpc", 7*time.Second, nil)
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "ibmcloud_storage_volume_lib_function_duration_seconds" {
+			histogram := family.GetMetric()[0].GetHistogram()
+			assert.Equal(t, uint64(2), histogram.GetSampleCount())
+			assert.Equal(t, 10.0, histogram.GetSampleSum())
+			assert.Len(t, histogram.GetBucket(), len(DurationBuckets))
+			return
+		}
+	}
+	t.Fatal("function_duration_seconds not gathered")
+}
+
+func TestPackageFunctions(t *testing.T) {
+	UpdateDurationFromStart(zap.NewNop(), "legacy", time.Now())
+	RegisterFunction("legacy")
+	RegisterError("legacy", util.NewError(reasoncode.ErrorBadRequest, "bad request"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(DefaultRecorder.functionCount.WithLabelValues("legacy", "", OutcomeUnknown)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(DefaultRecorder.functionCount.WithLabelValues("legacy", "", OutcomeSuccess)))
+	assert.Equal(t, 1, testutil.CollectAndCount(DefaultRecorder.functionDuration))
+	assert.Equal(t, 1.0, testutil.ToFloat64(DefaultRecorder.errorsCount.WithLabelValues("", "", util.ErrorTypeFailed, string(reasoncode.ErrorBadRequest))))
+	assert.Equal(t, OutcomeError, Outcome(errors.New("failed")))
+	assert.Equal(t, reasoncode.ErrorUnclassified, ErrorReasonCode(nil))
+}
@@ -0,0 +1,177 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+)
+
+// Steps of CloneVolume, reported by the CloneStepProperty of its errors
+const (
+	CloneStepGetSource      = "getSourceVolume"
+	CloneStepCreateSnapshot = "createSnapshot"
+	CloneStepWaitSnapshot   = "waitForSnapshotReady"
+	CloneStepCreateVolume   = "createVolume"
+	CloneStepWaitVolume     = "waitForVolumeAvailable"
+	CloneStepDeleteSnapshot = "deleteSnapshot"
+)
+
+// Properties of the errors returned by CloneVolume
+const (
+	// CloneStepProperty is the step that failed
+	CloneStepProperty = "step"
+
+	// CloneSourceVolumeProperty is the ID of the volume being cloned
+	CloneSourceVolumeProperty = "sourceVolumeID"
+
+	// CloneRolledBackProperty is "true" if everything the clone created was deleted again
+	CloneRolledBackProperty = "rolledBack"
+
+	// CloneSnapshotProperty is the ID of the snapshot left behind when only deleting it failed
+	CloneSnapshotProperty = "snapshotID"
+)
+
+// CloneVolume is the default implementation of VolumeManager.CloneVolume on top of the other
+// Session methods. See CloneVolumeWithContext.
+func CloneVolume(sess Session, sourceVolumeID string, template Volume) (*Volume, error) {
+	return CloneVolumeWithContext(context.Background(), NewContextSession(sess), sourceVolumeID, template)
+}
+
+// CloneVolumeWithContext clones the source volume into a new volume created from the template:
+// it snapshots the source volume, restores the snapshot into the new volume and deletes the snapshot
+// once the new volume is available. Capacity, Az, Region and Profile default to those of the source volume.
+//
+// If a step fails, the snapshot and volume created so far are deleted and an Error with reason code
+// reasoncode.ErrorVolumeCloneFailed is returned, which wraps the error of the step and of the rollback.
+// If only deleting the snapshot fails, the clone is kept and returned together with the Error,
+// whose CloneSnapshotProperty is the ID of the snapshot left behind.
+func CloneVolumeWithContext(ctx context.Context, sess ContextSession, sourceVolumeID string, template Volume) (*Volume, error) {
+	source, err := sess.GetVolume(ctx, sourceVolumeID)
+	if err == nil && source == nil {
+		err = fmt.Errorf("volume '%s' not found", sourceVolumeID)
+	}
+	if err != nil {
+		return nil, newCloneError(sourceVolumeID, CloneStepGetSource, err, nil)
+	}
+
+	var (
+		snapshot *Snapshot
+		volume   *Volume
+	)
+	// rollback deletes what was created, even if ctx is done
+	rollback := func(step string, err error) error {
+		rctx := context.WithoutCancel(ctx)
+		var rerrs []error
+		if volume != nil {
+			rerrs = append(rerrs, sess.DeleteVolume(rctx, volume))
+		}
+		if snapshot != nil {
+			rerrs = append(rerrs, sess.DeleteSnapshot(rctx, snapshot))
+		}
+		return newCloneError(sourceVolumeID, step, err, errors.Join(rerrs...))
+	}
+
+	snapshot, err = sess.CreateSnapshot(ctx, sourceVolumeID, SnapshotParameters{
+		Name: "clone-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+	})
+	if err == nil && snapshot == nil {
+		err = noResultError("snapshot")
+	}
+	if err != nil {
+		return nil, rollback(CloneStepCreateSnapshot, err)
+	}
+	if ready, err := sess.WaitForSnapshotReady(ctx, snapshot.SnapshotID, sourceVolumeID); err != nil {
+		return nil, rollback(CloneStepWaitSnapshot, err)
+	} else if ready != nil {
+		snapshot = ready
+	}
+
+	request := cloneRequest(template, *source)
+	request.Snapshot = *snapshot
+	volume, err = sess.CreateVolume(ctx, request)
+	if err == nil && volume == nil {
+		err = noResultError("volume")
+	}
+	if err != nil {
+		return nil, rollback(CloneStepCreateVolume, err)
+	}
+	available, err := sess.WaitForVolumeAvailable(ctx, volume.VolumeID)
+	if err != nil {
+		return nil, rollback(CloneStepWaitVolume, err)
+	}
+	if available != nil {
+		volume = available
+	}
+
+	if err := sess.DeleteSnapshot(ctx, snapshot); err != nil {
+		cerr := newCloneError(sourceVolumeID, CloneStepDeleteSnapshot, err, nil)
+		cerr.Fault.Properties[CloneRolledBackProperty] = "false"
+		cerr.Fault.Properties[CloneSnapshotProperty] = snapshot.SnapshotID
+		return volume, cerr
+	}
+	return volume, nil
+}
+
+// noResultError is the error of a create step for which the provider returned neither a result nor an error
+func noResultError(resource string) Error {
+	return Error{Fault: Fault{
+		Message:    fmt.Sprintf("The provider returned no %s", resource),
+		ReasonCode: reasoncode.ErrorEmptyProviderResult,
+	}}
+}
+
+// cloneRequest returns the volume request for a clone, filling in the template from the source volume
+func cloneRequest(template Volume, source Volume) Volume {
+	request := template
+	request.VolumeID = ""
+	if request.Capacity == nil {
+		request.Capacity = source.Capacity
+	}
+	if request.Az == "" {
+		request.Az = source.Az
+	}
+	if request.Region == "" {
+		request.Region = source.Region
+	}
+	if request.VPCVolume.Profile == nil {
+		request.VPCVolume.Profile = source.VPCVolume.Profile
+	}
+	return request
+}
+
+// newCloneError ...
+func newCloneError(sourceVolumeID string, step string, err error, rollbackErr error) Error {
+	return Error{
+		Fault: Fault{
+			Message:    fmt.Sprintf("Failed to clone volume '%s': %s failed: %s", sourceVolumeID, step, err),
+			ReasonCode: reasoncode.ErrorVolumeCloneFailed,
+			Wrapped:    wrappedMessages(err, rollbackErr),
+			Properties: map[string]string{
+				CloneStepProperty:         step,
+				CloneSourceVolumeProperty: sourceVolumeID,
+				CloneRolledBackProperty:   strconv.FormatBool(rollbackErr == nil),
+			},
+		},
+	}.WithWrapped(err, rollbackErr)
+}
@@ -0,0 +1,175 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cloneSession is a Session recording the calls CloneVolume makes, which fails the step named by fail
+type cloneSession struct {
+	DefaultVolumeProvider
+	fail             string
+	empty            string
+	request          Volume
+	deletedVolumes   []string
+	deletedSnapshots []string
+}
+
+func (s *cloneSession) failed(step string) error {
+	if s.fail == step {
+		return errors.New(step + " failed")
+	}
+	return nil
+}
+
+func (s *cloneSession) GetVolume(id string) (*Volume, error) {
+	capacity := 10
+	return &Volume{VolumeID: id, Capacity: &capacity, Az: "us-south-1"}, s.failed(CloneStepGetSource)
+}
+
+func (s *cloneSession) CreateSnapshot(sourceVolumeID string, snapshotParameters SnapshotParameters) (*Snapshot, error) {
+	if err := s.failed(CloneStepCreateSnapshot); err != nil {
+		return nil, err
+	}
+	if s.empty == CloneStepCreateSnapshot {
+		return nil, nil
+	}
+	return &Snapshot{VolumeID: sourceVolumeID, SnapshotID: "snap"}, nil
+}
+
+func (s *cloneSession) WaitForSnapshotReady(snapshotID string, sourceVolumeID ...string) (*Snapshot, error) {
+	return &Snapshot{SnapshotID: snapshotID, ReadyToUse: true}, s.failed(CloneStepWaitSnapshot)
+}
+
+func (s *cloneSession) CreateVolume(volumeRequest Volume) (*Volume, error) {
+	s.request = volumeRequest
+	if err := s.failed(CloneStepCreateVolume); err != nil {
+		return nil, err
+	}
+	if s.empty == CloneStepCreateVolume {
+		return nil, nil
+	}
+	return &Volume{VolumeID: "clone"}, nil
+}
+
+func (s *cloneSession) WaitForVolumeAvailable(volumeID string) (*Volume, error) {
+	return &Volume{VolumeID: volumeID, VPCVolume: VPCVolume{Status: "available"}}, s.failed(CloneStepWaitVolume)
+}
+
+func (s *cloneSession) DeleteVolume(volume *Volume) error {
+	s.deletedVolumes = append(s.deletedVolumes, volume.VolumeID)
+	return nil
+}
+
+func (s *cloneSession) DeleteSnapshot(snapshot *Snapshot) error {
+	s.deletedSnapshots = append(s.deletedSnapshots, snapshot.SnapshotID)
+	return s.failed(CloneStepDeleteSnapshot)
+}
+
+func TestCloneVolume(t *testing.T) {
+	sess := &cloneSession{}
+	name := "copy"
+	volume, err := CloneVolume(sess, "source", Volume{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "clone", volume.VolumeID)
+	assert.Equal(t, "available", volume.Status)
+
+	assert.Equal(t, &name, sess.request.Name)
+	assert.Equal(t, 10, *sess.request.Capacity)
+	assert.Equal(t, "us-south-1", sess.request.Az)
+	assert.Equal(t, "snap", sess.request.SnapshotID)
+	assert.True(t, sess.request.ReadyToUse)
+	assert.Equal(t, []string{"snap"}, sess.deletedSnapshots)
+	assert.Empty(t, sess.deletedVolumes)
+}
+
+func TestCloneVolumeRollback(t *testing.T) {
+	testcases := []struct {
+		step             string
+		deletedVolumes   []string
+		deletedSnapshots []string
+		rolledBack       string
+	}{
+		{step: CloneStepGetSource, rolledBack: "true"},
+		{step: CloneStepCreateSnapshot, rolledBack: "true"},
+		{step: CloneStepWaitSnapshot, deletedSnapshots: []string{"snap"}, rolledBack: "true"},
+		{step: CloneStepCreateVolume, deletedSnapshots: []string{"snap"}, rolledBack: "true"},
+		{step: CloneStepWaitVolume, deletedVolumes: []string{"clone"}, deletedSnapshots: []string{"snap"}, rolledBack: "true"},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.step, func(t *testing.T) {
+			sess := &cloneSession{fail: testcase.step}
+			volume, err := CloneVolume(sess, "source", Volume{})
+			assert.Nil(t, volume)
+			assert.True(t, errors.Is(err, reasoncode.ErrorVolumeCloneFailed))
+			assert.ErrorContains(t, err, testcase.step+" failed")
+
+			var perr Error
+			require.True(t, errors.As(err, &perr))
+			assert.Equal(t, testcase.step, perr.Properties()[CloneStepProperty])
+			assert.Equal(t, "source", perr.Properties()[CloneSourceVolumeProperty])
+			assert.Equal(t, testcase.rolledBack, perr.Properties()[CloneRolledBackProperty])
+			assert.Equal(t, testcase.deletedVolumes, sess.deletedVolumes)
+			assert.Equal(t, testcase.deletedSnapshots, sess.deletedSnapshots)
+		})
+	}
+}
+
+func TestCloneVolumeKeepsCloneIfSnapshotDeleteFails(t *testing.T) {
+	sess := &cloneSession{fail: CloneStepDeleteSnapshot}
+	volume, err := CloneVolume(sess, "source", Volume{})
+	require.NotNil(t, volume)
+	assert.Equal(t, "clone", volume.VolumeID)
+	assert.True(t, errors.Is(err, reasoncode.ErrorVolumeCloneFailed))
+
+	var perr Error
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, CloneStepDeleteSnapshot, perr.Properties()[CloneStepProperty])
+	assert.Equal(t, "false", perr.Properties()[CloneRolledBackProperty])
+	assert.Equal(t, "snap", perr.Properties()[CloneSnapshotProperty])
+	assert.Empty(t, sess.deletedVolumes)
+	assert.Equal(t, []string{"snap"}, sess.deletedSnapshots)
+}
+
+func TestCloneVolumeNoResult(t *testing.T) {
+	testcases := []struct {
+		step             string
+		deletedSnapshots []string
+	}{
+		{step: CloneStepCreateSnapshot},
+		{step: CloneStepCreateVolume, deletedSnapshots: []string{"snap"}},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.step, func(t *testing.T) {
+			sess := &cloneSession{empty: testcase.step}
+			volume, err := CloneVolume(sess, "source", Volume{})
+			assert.Nil(t, volume)
+			assert.True(t, errors.Is(err, reasoncode.ErrorVolumeCloneFailed))
+			assert.True(t, errors.Is(err, reasoncode.ErrorEmptyProviderResult))
+			assert.False(t, errors.Is(err, reasoncode.ErrorBadRequest))
+			assert.Equal(t, testcase.deletedSnapshots, sess.deletedSnapshots)
+			assert.Empty(t, sess.deletedVolumes)
+		})
+	}
+}
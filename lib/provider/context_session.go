@@ -0,0 +1,166 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"net/http"
+)
+
+// ContextVolumeManager is the context.Context aware variant of VolumeManager
+type ContextVolumeManager interface {
+	// Provider name
+	ProviderName() VolumeProvider
+
+	// Type returns the underlying volume type
+	Type() VolumeType
+
+	// Get the volume profile by using profile name
+	GetVolumeProfileByName(ctx context.Context, name string) (*Profile, error)
+
+	// List the volume profiles
+	ListVolumeProfiles(ctx context.Context, limit int, start string) (*ProfileList, error)
+
+	// Create the volume with authorization by passing required information in the volume object
+	CreateVolume(ctx context.Context, VolumeRequest Volume) (*Volume, error)
+
+	// Create the volume from snapshot with snapshot tags
+	CreateVolumeFromSnapshot(ctx context.Context, snapshot Snapshot, tags map[string]string) (*Volume, error)
+
+	// UpdateVolume the volume
+	UpdateVolume(ctx context.Context, volume Volume) error
+
+	// Delete the volume
+	DeleteVolume(ctx context.Context, volume *Volume) error
+
+	// Get the volume by using ID
+	GetVolume(ctx context.Context, id string) (*Volume, error)
+
+	// Get the volume by using Name
+	GetVolumeByName(ctx context.Context, name string) (*Volume, error)
+
+	// Get volume lists by using filters
+	ListVolumes(ctx context.Context, limit int, start string, tags map[string]string) (*VolumeList, error)
+
+	// GetVolumeByRequestID fetch the volume by request ID
+	GetVolumeByRequestID(ctx context.Context, requestID string) (*Volume, error)
+
+	// AuthorizeVolume allows aceess to volume  based on given authorization
+	AuthorizeVolume(ctx context.Context, volumeAuthorization VolumeAuthorization) error
+
+	// Expand the volume with authorization by passing required information in the volume object
+	ExpandVolume(ctx context.Context, expandVolumeRequest ExpandVolumeRequest) (int64, error)
+
+	// WaitForVolumeAvailable waits for the volume to become available
+	// Return error if wait is timed out, ctx is done OR there is other error
+	WaitForVolumeAvailable(ctx context.Context, volumeID string) (*Volume, error)
+
+	// CloneVolume creates a new volume from the template with the content of the source volume
+	CloneVolume(ctx context.Context, sourceVolumeID string, template Volume) (*Volume, error)
+}
+
+// ContextVolumeAttachManager is the context.Context aware variant of VolumeAttachManager
+type ContextVolumeAttachManager interface {
+	//AttachVolume attaches a volume/ fileset to a server, without waiting for the attachment to complete
+	AttachVolume(ctx context.Context, attachRequest VolumeAttachmentRequest) (*VolumeAttachmentResponse, error)
+
+	//DetachVolume detaches the volume/ fileset from the server, without waiting for the detachment to complete
+	DetachVolume(ctx context.Context, detachRequest VolumeAttachmentRequest) (*http.Response, error)
+
+	//WaitForAttachVolume waits for the volume to be attached to the host
+	//Return error if wait is timed out, ctx is done OR there is other error
+	WaitForAttachVolume(ctx context.Context, attachRequest VolumeAttachmentRequest) (*VolumeAttachmentResponse, error)
+
+	//WaitForDetachVolume waits for the volume to be detached from the host
+	//Return error if wait is timed out, ctx is done OR there is other error
+	WaitForDetachVolume(ctx context.Context, detachRequest VolumeAttachmentRequest) error
+
+	//GetVolumeAttachment retirves the current status of given volume attach request
+	GetVolumeAttachment(ctx context.Context, attachRequest VolumeAttachmentRequest) (*VolumeAttachmentResponse, error)
+}
+
+// ContextSnapshotManager is the context.Context aware variant of SnapshotManager
+type ContextSnapshotManager interface {
+	// Create the snapshot on the volume
+	CreateSnapshot(ctx context.Context, sourceVolumeID string, snapshotParameters SnapshotParameters) (*Snapshot, error)
+
+	// Delete the snapshot
+	DeleteSnapshot(ctx context.Context, snapshot *Snapshot) error
+
+	// Get the snapshot
+	GetSnapshot(ctx context.Context, snapshotID string, sourceVolumeID ...string) (*Snapshot, error)
+
+	// Get the snapshot By name
+	GetSnapshotByName(ctx context.Context, snapshotName string, sourceVolumeID ...string) (*Snapshot, error)
+
+	// Snapshot list by using tags
+	ListSnapshots(ctx context.Context, limit int, start string, tags map[string]string) (*SnapshotList, error)
+
+	// WaitForSnapshotReady waits for the snapshot to be ReadyToUse
+	// Return error if wait is timed out, ctx is done OR there is other error
+	WaitForSnapshotReady(ctx context.Context, snapshotID string, sourceVolumeID ...string) (*Snapshot, error)
+}
+
+// ContextVolumeFileAccessPointManager is the context.Context aware variant of VolumeFileAccessPointManager
+type ContextVolumeFileAccessPointManager interface {
+	//CreateVolumeAccessPoint to create a access point
+	CreateVolumeAccessPoint(ctx context.Context, accessPointRequest VolumeAccessPointRequest) (*VolumeAccessPointResponse, error)
+
+	//DeleteVolumeAccessPoint method delete a access point
+	DeleteVolumeAccessPoint(ctx context.Context, deleteAccessPointRequest VolumeAccessPointRequest) (*http.Response, error)
+
+	//WaitForCreateVolumeAccessPoint waits for the volume access point to be created
+	//Return error if wait is timed out, ctx is done OR there is other error
+	WaitForCreateVolumeAccessPoint(ctx context.Context, accessPointRequest VolumeAccessPointRequest) (*VolumeAccessPointResponse, error)
+
+	//WaitForDeleteVolumeAccessPoint waits for the volume access point to be deleted
+	//Return error if wait is timed out, ctx is done OR there is other error
+	WaitForDeleteVolumeAccessPoint(ctx context.Context, deleteAccessPointRequest VolumeAccessPointRequest) error
+
+	//GetVolumeAccessPoint retrieves the current status of given volume AccessPoint request
+	GetVolumeAccessPoint(ctx context.Context, accessPointRequest VolumeAccessPointRequest) (*VolumeAccessPointResponse, error)
+
+	//GetSubnetForVolumeAccessPoint retrieves the subnet for volume AccessPoint
+	GetSubnetForVolumeAccessPoint(ctx context.Context, subnetRequest SubnetRequest) (string, error)
+
+	//GetSecurityGroupForVolumeAccessPoint retrieves the securityGroup for volume AccessPoint
+	GetSecurityGroupForVolumeAccessPoint(ctx context.Context, securityGroupRequest SecurityGroupRequest) (string, error)
+}
+
+// ContextSession is the context.Context aware variant of Session. Every call takes a ctx
+// which can cancel it or give it a deadline, including the Wait* calls.
+//
+//go:generate counterfeiter -o fake/fake_context_session.go --fake-name FakeContextSession . ContextSession
+type ContextSession interface {
+	ContextVolumeManager
+	ContextVolumeAttachManager
+	ContextSnapshotManager
+	ContextVolumeFileAccessPointManager
+
+	// GetProviderDisplayName returns the name of the provider that is being used
+	GetProviderDisplayName() VolumeProvider
+
+	// Close is called when the ContextSession is nolonger required
+	Close()
+}
+
+// ContextSessionProvider is implemented by a Session which also has a native ContextSession.
+// NewContextSession returns the native ContextSession instead of an adapter for such sessions.
+type ContextSessionProvider interface {
+	ContextSession() ContextSession
+}
@@ -0,0 +1,345 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+)
+
+// contextSessionAdapter implements ContextSession on top of a Session which does not take a context.
+// Each call runs the Session method in its own goroutine and returns ctx.Err() as soon as ctx is done.
+// The abandoned Session call carries on in the background until the provider returns, so a cancelled
+// create can still create the resource. The Wait* calls call the Wait* methods of the Session, which apply
+// the timeouts of the provider, unless ctx has a deadline. Those cannot be stopped at the deadline, so the
+// Wait* calls then poll the Get* methods of the Session with the waiter until ctx is done instead.
+type contextSessionAdapter struct {
+	sess Session
+}
+
+var _ ContextSession = &contextSessionAdapter{}
+
+// NewContextSession returns a ContextSession for the given Session, so that providers can
+// migrate to the context aware interface gradually. If the Session implements
+// ContextSessionProvider its native ContextSession is returned.
+func NewContextSession(sess Session) ContextSession {
+	if csp, ok := sess.(ContextSessionProvider); ok {
+		return csp.ContextSession()
+	}
+	return &contextSessionAdapter{sess: sess}
+}
+
+// callResult carries the outcome of a Session call from the goroutine that made it
+type callResult[T any] struct {
+	value    T
+	err      error
+	panicked interface{}
+}
+
+// callWithContext runs call in a goroutine and waits for it or for ctx to be done.
+// A panic in call is re-raised on the calling goroutine so that callers can recover it.
+func callWithContext[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	done := make(chan callResult[T], 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- callResult[T]{panicked: r}
+			}
+		}()
+		value, err := call()
+		done <- callResult[T]{value: value, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		if result.panicked != nil {
+			panic(result.panicked)
+		}
+		return result.value, result.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// deadlineWaitOptions returns the WaitOptions of a wait bounded by the deadline of ctx rather than DefaultWaitTimeout.
+// It returns false if ctx has no deadline, in which case the Wait* methods of the Session are called instead.
+func deadlineWaitOptions[S ~string](ctx context.Context) (WaitOptions[S], bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return WaitOptions[S]{}, false
+	}
+	return WaitOptions[S]{Timeout: time.Until(deadline)}, true
+}
+
+// errWithContext is callWithContext for calls which only return an error
+func errWithContext(ctx context.Context, call func() error) error {
+	_, err := callWithContext(ctx, func() (struct{}, error) {
+		return struct{}{}, call()
+	})
+	return err
+}
+
+// ProviderName returns provider
+func (a *contextSessionAdapter) ProviderName() VolumeProvider {
+	return a.sess.ProviderName()
+}
+
+// Type returns the underlying volume type
+func (a *contextSessionAdapter) Type() VolumeType {
+	return a.sess.Type()
+}
+
+// GetProviderDisplayName gets provider by displayname
+func (a *contextSessionAdapter) GetProviderDisplayName() VolumeProvider {
+	return a.sess.GetProviderDisplayName()
+}
+
+// Close is called when the ContextSession is nolonger required
+func (a *contextSessionAdapter) Close() {
+	a.sess.Close()
+}
+
+// GetVolumeProfileByName gets volume profile by name
+func (a *contextSessionAdapter) GetVolumeProfileByName(ctx context.Context, name string) (*Profile, error) {
+	return callWithContext(ctx, func() (*Profile, error) { return a.sess.GetVolumeProfileByName(name) })
+}
+
+// ListVolumeProfiles lists the volume profiles
+func (a *contextSessionAdapter) ListVolumeProfiles(ctx context.Context, limit int, start string) (*ProfileList, error) {
+	return callWithContext(ctx, func() (*ProfileList, error) { return a.sess.ListVolumeProfiles(limit, start) })
+}
+
+// CreateVolume creates a volume
+func (a *contextSessionAdapter) CreateVolume(ctx context.Context, volumeRequest Volume) (*Volume, error) {
+	return callWithContext(ctx, func() (*Volume, error) { return a.sess.CreateVolume(volumeRequest) })
+}
+
+// CreateVolumeFromSnapshot creates a volume from snapshot
+func (a *contextSessionAdapter) CreateVolumeFromSnapshot(ctx context.Context, snapshot Snapshot, tags map[string]string) (*Volume, error) {
+	return callWithContext(ctx, func() (*Volume, error) { return a.sess.CreateVolumeFromSnapshot(snapshot, tags) })
+}
+
+// UpdateVolume the volume
+func (a *contextSessionAdapter) UpdateVolume(ctx context.Context, volume Volume) error {
+	return errWithContext(ctx, func() error { return a.sess.UpdateVolume(volume) })
+}
+
+// DeleteVolume deletes the volume
+func (a *contextSessionAdapter) DeleteVolume(ctx context.Context, volume *Volume) error {
+	return errWithContext(ctx, func() error { return a.sess.DeleteVolume(volume) })
+}
+
+// GetVolume by using ID
+func (a *contextSessionAdapter) GetVolume(ctx context.Context, id string) (*Volume, error) {
+	return callWithContext(ctx, func() (*Volume, error) { return a.sess.GetVolume(id) })
+}
+
+// GetVolumeByName gets volume by name
+func (a *contextSessionAdapter) GetVolumeByName(ctx context.Context, name string) (*Volume, error) {
+	return callWithContext(ctx, func() (*Volume, error) { return a.sess.GetVolumeByName(name) })
+}
+
+// ListVolumes Get volume lists by using filters
+func (a *contextSessionAdapter) ListVolumes(ctx context.Context, limit int, start string, tags map[string]string) (*VolumeList, error) {
+	return callWithContext(ctx, func() (*VolumeList, error) { return a.sess.ListVolumes(limit, start, tags) })
+}
+
+// GetVolumeByRequestID fetch the volume by request ID
+func (a *contextSessionAdapter) GetVolumeByRequestID(ctx context.Context, requestID string) (*Volume, error) {
+	return callWithContext(ctx, func() (*Volume, error) { return a.sess.GetVolumeByRequestID(requestID) })
+}
+
+// AuthorizeVolume allows aceess to volume  based on given authorization
+func (a *contextSessionAdapter) AuthorizeVolume(ctx context.Context, volumeAuthorization VolumeAuthorization) error {
+	return errWithContext(ctx, func() error { return a.sess.AuthorizeVolume(volumeAuthorization) })
+}
+
+// ExpandVolume expand the volume
+func (a *contextSessionAdapter) ExpandVolume(ctx context.Context, expandVolumeRequest ExpandVolumeRequest) (int64, error) {
+	return callWithContext(ctx, func() (int64, error) { return a.sess.ExpandVolume(expandVolumeRequest) })
+}
+
+// WaitForVolumeAvailable waits for the volume to become available
+func (a *contextSessionAdapter) WaitForVolumeAvailable(ctx context.Context, volumeID string) (*Volume, error) {
+	opts, ok := deadlineWaitOptions[state.VolumeStatus](ctx)
+	if !ok {
+		return callWithContext(ctx, func() (*Volume, error) { return a.sess.WaitForVolumeAvailable(volumeID) })
+	}
+	return WaitForVolumeState(ctx, func(ctx context.Context) (*Volume, error) {
+		return a.GetVolume(ctx, volumeID)
+	}, state.VolumeAvailable, opts)
+}
+
+// CloneVolume clones the volume
+func (a *contextSessionAdapter) CloneVolume(ctx context.Context, sourceVolumeID string, template Volume) (*Volume, error) {
+	return callWithContext(ctx, func() (*Volume, error) { return a.sess.CloneVolume(sourceVolumeID, template) })
+}
+
+// AttachVolume attaches a volume
+func (a *contextSessionAdapter) AttachVolume(ctx context.Context, attachRequest VolumeAttachmentRequest) (*VolumeAttachmentResponse, error) {
+	return callWithContext(ctx, func() (*VolumeAttachmentResponse, error) { return a.sess.AttachVolume(attachRequest) })
+}
+
+// DetachVolume detaches the volume
+func (a *contextSessionAdapter) DetachVolume(ctx context.Context, detachRequest VolumeAttachmentRequest) (*http.Response, error) {
+	return callWithContext(ctx, func() (*http.Response, error) { return a.sess.DetachVolume(detachRequest) })
+}
+
+// WaitForAttachVolume waits for the volume to be attached to the host
+func (a *contextSessionAdapter) WaitForAttachVolume(ctx context.Context, attachRequest VolumeAttachmentRequest) (*VolumeAttachmentResponse, error) {
+	opts, ok := deadlineWaitOptions[state.AttachmentStatus](ctx)
+	if !ok {
+		return callWithContext(ctx, func() (*VolumeAttachmentResponse, error) { return a.sess.WaitForAttachVolume(attachRequest) })
+	}
+	return WaitForAttachmentState(ctx, func(ctx context.Context) (*VolumeAttachmentResponse, error) {
+		return a.GetVolumeAttachment(ctx, attachRequest)
+	}, state.AttachmentAttached, opts)
+}
+
+// WaitForDetachVolume waits for the volume to be detached from the host. While polling, an attachment
+// which is not found is detached, but no attachment and no error is an unknown state which fails the wait.
+func (a *contextSessionAdapter) WaitForDetachVolume(ctx context.Context, detachRequest VolumeAttachmentRequest) error {
+	opts, ok := deadlineWaitOptions[state.AttachmentStatus](ctx)
+	if !ok {
+		return errWithContext(ctx, func() error { return a.sess.WaitForDetachVolume(detachRequest) })
+	}
+	_, err := WaitForAttachmentState(ctx, func(ctx context.Context) (*VolumeAttachmentResponse, error) {
+		attachment, err := a.GetVolumeAttachment(ctx, detachRequest)
+		if isNotFound(err) {
+			return &VolumeAttachmentResponse{Status: string(state.AttachmentDetached)}, nil
+		}
+		return attachment, err
+	}, state.AttachmentDetached, opts)
+	return err
+}
+
+// GetVolumeAttachment retirves the current status of given volume attach request
+func (a *contextSessionAdapter) GetVolumeAttachment(ctx context.Context, attachRequest VolumeAttachmentRequest) (*VolumeAttachmentResponse, error) {
+	return callWithContext(ctx, func() (*VolumeAttachmentResponse, error) { return a.sess.GetVolumeAttachment(attachRequest) })
+}
+
+// CreateSnapshot on the volume
+func (a *contextSessionAdapter) CreateSnapshot(ctx context.Context, sourceVolumeID string, snapshotParameters SnapshotParameters) (*Snapshot, error) {
+	return callWithContext(ctx, func() (*Snapshot, error) { return a.sess.CreateSnapshot(sourceVolumeID, snapshotParameters) })
+}
+
+// DeleteSnapshot deletes the snapshot
+func (a *contextSessionAdapter) DeleteSnapshot(ctx context.Context, snapshot *Snapshot) error {
+	return errWithContext(ctx, func() error { return a.sess.DeleteSnapshot(snapshot) })
+}
+
+// GetSnapshot gets the snapshot
+func (a *contextSessionAdapter) GetSnapshot(ctx context.Context, snapshotID string, sourceVolumeID ...string) (*Snapshot, error) {
+	return callWithContext(ctx, func() (*Snapshot, error) { return a.sess.GetSnapshot(snapshotID, sourceVolumeID...) })
+}
+
+// GetSnapshotByName gets the snapshot by name
+func (a *contextSessionAdapter) GetSnapshotByName(ctx context.Context, snapshotName string, sourceVolumeID ...string) (*Snapshot, error) {
+	return callWithContext(ctx, func() (*Snapshot, error) { return a.sess.GetSnapshotByName(snapshotName, sourceVolumeID...) })
+}
+
+// ListSnapshots list the snapshots
+func (a *contextSessionAdapter) ListSnapshots(ctx context.Context, limit int, start string, tags map[string]string) (*SnapshotList, error) {
+	return callWithContext(ctx, func() (*SnapshotList, error) { return a.sess.ListSnapshots(limit, start, tags) })
+}
+
+// WaitForSnapshotReady waits for the snapshot to be ReadyToUse
+func (a *contextSessionAdapter) WaitForSnapshotReady(ctx context.Context, snapshotID string, sourceVolumeID ...string) (*Snapshot, error) {
+	opts, ok := deadlineWaitOptions[state.SnapshotStatus](ctx)
+	if !ok {
+		return callWithContext(ctx, func() (*Snapshot, error) { return a.sess.WaitForSnapshotReady(snapshotID, sourceVolumeID...) })
+	}
+	return WaitForSnapshotState(ctx, func(ctx context.Context) (*Snapshot, error) {
+		return a.GetSnapshot(ctx, snapshotID, sourceVolumeID...)
+	}, state.SnapshotStable, opts)
+}
+
+// CreateVolumeAccessPoint to create access point
+func (a *contextSessionAdapter) CreateVolumeAccessPoint(ctx context.Context, accessPointRequest VolumeAccessPointRequest) (*VolumeAccessPointResponse, error) {
+	return callWithContext(ctx, func() (*VolumeAccessPointResponse, error) { return a.sess.CreateVolumeAccessPoint(accessPointRequest) })
+}
+
+// DeleteVolumeAccessPoint method delete a access point
+func (a *contextSessionAdapter) DeleteVolumeAccessPoint(ctx context.Context, deleteAccessPointRequest VolumeAccessPointRequest) (*http.Response, error) {
+	return callWithContext(ctx, func() (*http.Response, error) { return a.sess.DeleteVolumeAccessPoint(deleteAccessPointRequest) })
+}
+
+// WaitForCreateVolumeAccessPoint waits for the volume access point to be stable
+func (a *contextSessionAdapter) WaitForCreateVolumeAccessPoint(ctx context.Context, accessPointRequest VolumeAccessPointRequest) (*VolumeAccessPointResponse, error) {
+	opts, ok := deadlineWaitOptions[state.AccessPointStatus](ctx)
+	if !ok {
+		return callWithContext(ctx, func() (*VolumeAccessPointResponse, error) {
+			return a.sess.WaitForCreateVolumeAccessPoint(accessPointRequest)
+		})
+	}
+	return WaitForAccessPointState(ctx, func(ctx context.Context) (*VolumeAccessPointResponse, error) {
+		return a.GetVolumeAccessPoint(ctx, accessPointRequest)
+	}, state.AccessPointStable, opts)
+}
+
+// WaitForDeleteVolumeAccessPoint waits for the volume access point to be deleted. While polling, an access point
+// which is not found is deleted, but no access point and no error is an unknown state which fails the wait.
+func (a *contextSessionAdapter) WaitForDeleteVolumeAccessPoint(ctx context.Context, deleteAccessPointRequest VolumeAccessPointRequest) error {
+	opts, ok := deadlineWaitOptions[state.AccessPointStatus](ctx)
+	if !ok {
+		return errWithContext(ctx, func() error { return a.sess.WaitForDeleteVolumeAccessPoint(deleteAccessPointRequest) })
+	}
+	_, err := WaitForAccessPointState(ctx, func(ctx context.Context) (*VolumeAccessPointResponse, error) {
+		accessPoint, err := a.GetVolumeAccessPoint(ctx, deleteAccessPointRequest)
+		if isNotFound(err) {
+			return &VolumeAccessPointResponse{Status: string(state.AccessPointDeleted)}, nil
+		}
+		return accessPoint, err
+	}, state.AccessPointDeleted, opts)
+	return err
+}
+
+// GetVolumeAccessPoint retrieves the current status of given volume AccessPoint request
+func (a *contextSessionAdapter) GetVolumeAccessPoint(ctx context.Context, accessPointRequest VolumeAccessPointRequest) (*VolumeAccessPointResponse, error) {
+	return callWithContext(ctx, func() (*VolumeAccessPointResponse, error) { return a.sess.GetVolumeAccessPoint(accessPointRequest) })
+}
+
+// GetSubnetForVolumeAccessPoint retrieves the subnet for volume AccessPoint
+func (a *contextSessionAdapter) GetSubnetForVolumeAccessPoint(ctx context.Context, subnetRequest SubnetRequest) (string, error) {
+	return callWithContext(ctx, func() (string, error) { return a.sess.GetSubnetForVolumeAccessPoint(subnetRequest) })
+}
+
+// GetSecurityGroupForVolumeAccessPoint retrieves the securityGroup for volume AccessPoint
+func (a *contextSessionAdapter) GetSecurityGroupForVolumeAccessPoint(ctx context.Context, securityGroupRequest SecurityGroupRequest) (string, error) {
+	return callWithContext(ctx, func() (string, error) { return a.sess.GetSecurityGroupForVolumeAccessPoint(securityGroupRequest) })
+}
+
+// notFoundError is implemented by the errors of providers which report that an entity does not exist, e.g. util.Message
+type notFoundError interface {
+	NotFound() bool
+}
+
+// isNotFound reports whether err, or an error it wraps, reports that an entity does not exist
+func isNotFound(err error) bool {
+	var nerr notFoundError
+	return errors.As(err, &nerr) && nerr.NotFound()
+}
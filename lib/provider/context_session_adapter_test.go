@@ -0,0 +1,192 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingSession is a Session whose GetVolumeAttachment blocks until released
+type blockingSession struct {
+	DefaultVolumeProvider
+	release chan struct{}
+	waited  bool
+}
+
+func (s *blockingSession) GetVolumeAttachment(attachRequest VolumeAttachmentRequest) (*VolumeAttachmentResponse, error) {
+	<-s.release
+	return &VolumeAttachmentResponse{VolumeAttachmentRequest: attachRequest, Status: "attached"}, nil
+}
+
+func (s *blockingSession) WaitForAttachVolume(attachRequest VolumeAttachmentRequest) (*VolumeAttachmentResponse, error) {
+	s.waited = true
+	return s.GetVolumeAttachment(attachRequest)
+}
+
+func (s *blockingSession) GetVolume(id string) (*Volume, error) {
+	if id == "panic" {
+		panic("provider bug")
+	}
+	return nil, errors.New("volume not found")
+}
+
+// nativeSession is a Session which also has a native ContextSession
+type nativeSession struct {
+	DefaultVolumeProvider
+	native ContextSession
+}
+
+func (s *nativeSession) ContextSession() ContextSession {
+	return s.native
+}
+
+func TestNewContextSession(t *testing.T) {
+	sess := &DefaultVolumeProvider{}
+	cs := NewContextSession(sess)
+	assert.IsType(t, &contextSessionAdapter{}, cs)
+	assert.Empty(t, cs.ProviderName())
+	assert.Empty(t, cs.Type())
+	assert.Empty(t, cs.GetProviderDisplayName())
+	cs.Close()
+
+	native := &contextSessionAdapter{sess: sess}
+	assert.Equal(t, native, NewContextSession(&nativeSession{native: native}))
+}
+
+func TestContextSessionAdapterDelegates(t *testing.T) {
+	cs := NewContextSession(&blockingSession{release: make(chan struct{})})
+	ctx := context.Background()
+
+	volume, err := cs.GetVolume(ctx, "vol")
+	assert.Nil(t, volume)
+	assert.EqualError(t, err, "volume not found")
+
+	capacity, err := cs.ExpandVolume(ctx, ExpandVolumeRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), capacity)
+
+	assert.NoError(t, cs.DeleteVolume(ctx, &Volume{}))
+	assert.NoError(t, NewContextSession(&DefaultVolumeProvider{}).WaitForDetachVolume(ctx, VolumeAttachmentRequest{}))
+}
+
+func TestContextSessionAdapterCancel(t *testing.T) {
+	sess := &blockingSession{release: make(chan struct{})}
+	cs := NewContextSession(sess)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	attachment, err := cs.WaitForAttachVolume(ctx, VolumeAttachmentRequest{VolumeID: "vol"})
+	assert.Nil(t, attachment)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitTimedOut))
+
+	// An already cancelled context never reaches the session
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, err = cs.GetVolume(cancelled, "panic")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	close(sess.release)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	attachment, err = cs.WaitForAttachVolume(ctx, VolumeAttachmentRequest{VolumeID: "vol"})
+	assert.NoError(t, err)
+	assert.Equal(t, "attached", attachment.Status)
+	assert.False(t, sess.waited)
+
+	// Without a deadline the wait of the Session is called
+	attachment, err = cs.WaitForAttachVolume(context.Background(), VolumeAttachmentRequest{VolumeID: "vol"})
+	assert.NoError(t, err)
+	assert.Equal(t, "attached", attachment.Status)
+	assert.True(t, sess.waited)
+}
+
+func TestContextSessionAdapterPanic(t *testing.T) {
+	cs := NewContextSession(&blockingSession{release: make(chan struct{})})
+
+	assert.PanicsWithValue(t, "provider bug", func() {
+		_, _ = cs.GetVolume(context.Background(), "panic")
+	})
+}
+
+// notFoundMessage is a provider error reporting that the entity looked up does not exist
+type notFoundMessage struct{}
+
+func (notFoundMessage) Error() string { return "not found" }
+
+func (notFoundMessage) NotFound() bool { return true }
+
+// detachingSession is a Session whose attachments and access points are gone after the given number of polls
+type detachingSession struct {
+	DefaultVolumeProvider
+	polls int
+	gone  int
+}
+
+func (s *detachingSession) GetVolumeAttachment(attachRequest VolumeAttachmentRequest) (*VolumeAttachmentResponse, error) {
+	s.polls++
+	if s.polls > s.gone {
+		return nil, notFoundMessage{}
+	}
+	return &VolumeAttachmentResponse{Status: "detaching"}, nil
+}
+
+func (s *detachingSession) GetVolumeAccessPoint(accessPointRequest VolumeAccessPointRequest) (*VolumeAccessPointResponse, error) {
+	s.polls++
+	if s.polls > s.gone {
+		return nil, notFoundMessage{}
+	}
+	return &VolumeAccessPointResponse{Status: "deleting"}, nil
+}
+
+func TestContextSessionAdapterWaits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Entities which no longer exist are detached or deleted
+	sess := &detachingSession{}
+	cs := NewContextSession(sess)
+	assert.NoError(t, cs.WaitForDetachVolume(ctx, VolumeAttachmentRequest{VolumeID: "vol"}))
+	assert.NoError(t, cs.WaitForDeleteVolumeAccessPoint(ctx, VolumeAccessPointRequest{VolumeID: "vol"}))
+	assert.Equal(t, 2, sess.polls)
+
+	// No entity and no error is not taken for detached or deleted
+	cs = NewContextSession(&DefaultVolumeProvider{})
+	err := cs.WaitForDetachVolume(ctx, VolumeAttachmentRequest{VolumeID: "vol"})
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitFailed))
+	err = cs.WaitForDeleteVolumeAccessPoint(ctx, VolumeAccessPointRequest{VolumeID: "vol"})
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitFailed))
+
+	// The polling stops when ctx is done
+	sess = &detachingSession{gone: 100}
+	cs = NewContextSession(sess)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = cs.WaitForDetachVolume(ctx, VolumeAttachmentRequest{VolumeID: "vol"})
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitTimedOut))
+	_, err = cs.WaitForCreateVolumeAccessPoint(ctx, VolumeAccessPointRequest{VolumeID: "vol"})
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitTimedOut))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, sess.polls)
+}
@@ -93,6 +93,10 @@ type Volume struct {
 	// IscsiTargetIPAddresses list of target IP addresses for iscsi. Applicable for Iscsi block storage only
 	IscsiTargetIPAddresses []string `json:"iscsiTargetIpAddresses,omitempty"`
 
+	// IdempotencyKey identifies the create request, e.g. by the PVC UID, so that retrying it does not create another volume.
+	// It is honoured by the middleware.Idempotency layer.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+
 	// Only for VPC volume provider
 	VPCVolume
 
@@ -122,6 +126,10 @@ type Snapshot struct {
 	// status of snapshot
 	ReadyToUse bool `json:"readyToUse"`
 
+	// lifecycle status of the snapshot reported by the provider, e.g. pending, stable or failed.
+	// See state.SnapshotStatus and State().
+	SnapshotStatus string `json:"snapshotStatus,omitempty"`
+
 	// VPC contains vpc fields
 	VPC
 }
@@ -149,6 +157,12 @@ type VolumeList struct {
 	Volumes []*Volume `json:"volumes"`
 }
 
+// ProfileList ...
+type ProfileList struct {
+	Next     string     `json:"next,omitempty"`
+	Profiles []*Profile `json:"profiles"`
+}
+
 // ExpandVolumeRequest ...
 type ExpandVolumeRequest struct {
 	// VolumeID id for the volume
@@ -17,15 +17,41 @@
 // Package provider ...
 package provider
 
-import "net/http"
+import (
+	"net/http"
 
-// DefaultVolumeProvider Implementation
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+)
+
+// DefaultVolumeProvider Implementation, for provider sessions to embed so that they only implement
+// the methods they support. Embedders must create it with NewDefaultVolumeProvider: its
+// WaitForVolumeAvailable, WaitForSnapshotReady and CloneVolume call the Get*, Create* and Delete*
+// methods of the embedding session, which the zero value has no way to reach, so the zero value
+// fails them with ErrorUnsupportedMethod.
 type DefaultVolumeProvider struct {
 	sess *Session
 }
 
 var _ Session = &DefaultVolumeProvider{sess: nil}
 
+// NewDefaultVolumeProvider returns a DefaultVolumeProvider whose WaitForVolumeAvailable, WaitForSnapshotReady
+// and CloneVolume call the methods of sess, normally the provider session which embeds it.
+// Go does not dispatch the calls of an embedded type to the methods of the type embedding it.
+func NewDefaultVolumeProvider(sess Session) DefaultVolumeProvider {
+	return DefaultVolumeProvider{sess: &sess}
+}
+
+// session returns the Session the default implementations call
+func (volprov *DefaultVolumeProvider) session() (Session, error) {
+	if volprov.sess == nil || *volprov.sess == nil {
+		return nil, Error{Fault: Fault{
+			Message:    "The DefaultVolumeProvider has no Session to call, create it with NewDefaultVolumeProvider",
+			ReasonCode: reasoncode.ErrorUnsupportedMethod,
+		}}
+	}
+	return *volprov.sess, nil
+}
+
 // ProviderName returns provider
 func (volprov *DefaultVolumeProvider) ProviderName() VolumeProvider {
 	return VolumeProvider("")
@@ -41,6 +67,11 @@ func (volprov *DefaultVolumeProvider) GetVolumeProfileByName(profileName string)
 	return nil, nil
 }
 
+// ListVolumeProfiles lists the volume profiles
+func (volprov *DefaultVolumeProvider) ListVolumeProfiles(limit int, start string) (*ProfileList, error) {
+	return nil, nil
+}
+
 // CreateVolume creates a volume
 func (volprov *DefaultVolumeProvider) CreateVolume(VolumeRequest Volume) (*Volume, error) {
 	return nil, nil
@@ -152,6 +183,36 @@ func (volprov *DefaultVolumeProvider) ExpandVolume(expandVolumeRequest ExpandVol
 	return 0, nil
 }
 
+// WaitForVolumeAvailable waits for the volume to become available, see WaitForVolumeAvailable.
+// It fails with ErrorUnsupportedMethod unless the DefaultVolumeProvider was created with NewDefaultVolumeProvider.
+func (volprov *DefaultVolumeProvider) WaitForVolumeAvailable(volumeID string) (*Volume, error) {
+	sess, err := volprov.session()
+	if err != nil {
+		return nil, err
+	}
+	return WaitForVolumeAvailable(sess, volumeID)
+}
+
+// CloneVolume clones the volume by snapshotting it and restoring the snapshot, see CloneVolume.
+// It fails with ErrorUnsupportedMethod unless the DefaultVolumeProvider was created with NewDefaultVolumeProvider.
+func (volprov *DefaultVolumeProvider) CloneVolume(sourceVolumeID string, template Volume) (*Volume, error) {
+	sess, err := volprov.session()
+	if err != nil {
+		return nil, err
+	}
+	return CloneVolume(sess, sourceVolumeID, template)
+}
+
+// WaitForSnapshotReady waits for the snapshot to be ReadyToUse, see WaitForSnapshotReady.
+// It fails with ErrorUnsupportedMethod unless the DefaultVolumeProvider was created with NewDefaultVolumeProvider.
+func (volprov *DefaultVolumeProvider) WaitForSnapshotReady(snapshotID string, sourceVolumeID ...string) (*Snapshot, error) {
+	sess, err := volprov.session()
+	if err != nil {
+		return nil, err
+	}
+	return WaitForSnapshotReady(sess, snapshotID, sourceVolumeID...)
+}
+
 // GetProviderDisplayName gets provider by displayname
 func (volprov *DefaultVolumeProvider) GetProviderDisplayName() VolumeProvider {
 	return ""
@@ -0,0 +1,65 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider_test ...
+package provider_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// embeddingSession is a provider session which gets the methods it does not implement from DefaultVolumeProvider
+type embeddingSession struct {
+	provider.DefaultVolumeProvider
+}
+
+// newEmbeddingSession returns an embeddingSession whose DefaultVolumeProvider calls it
+func newEmbeddingSession() *embeddingSession {
+	sess := &embeddingSession{}
+	sess.DefaultVolumeProvider = provider.NewDefaultVolumeProvider(sess)
+	return sess
+}
+
+func (s *embeddingSession) GetVolume(id string) (*provider.Volume, error) {
+	return &provider.Volume{VolumeID: id, VPCVolume: provider.VPCVolume{Status: "available"}}, nil
+}
+
+func (s *embeddingSession) GetSnapshot(snapshotID string, sourceVolumeID ...string) (*provider.Snapshot, error) {
+	return &provider.Snapshot{SnapshotID: snapshotID, ReadyToUse: true}, nil
+}
+
+func TestNewDefaultVolumeProvider(t *testing.T) {
+	sess := newEmbeddingSession()
+
+	volume, err := sess.WaitForVolumeAvailable("vol-id")
+	require.NoError(t, err)
+	assert.Equal(t, "vol-id", volume.VolumeID)
+
+	snapshot, err := sess.WaitForSnapshotReady("snap-id")
+	require.NoError(t, err)
+	assert.True(t, snapshot.ReadyToUse)
+
+	// CloneVolume fails rather than panics when the stubs of DefaultVolumeProvider return nothing
+	volume, err = sess.CloneVolume("vol-id", provider.Volume{})
+	assert.Nil(t, volume)
+	assert.True(t, errors.Is(err, reasoncode.ErrorEmptyProviderResult))
+}
@@ -18,8 +18,10 @@
 package provider
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -243,3 +245,27 @@ func TestGetSecurityGroupForVolumeAccessPoint(t *testing.T) {
 	securityGroupID, _ := ccf.GetSecurityGroupForVolumeAccessPoint(SecurityGroupRequest{})
 	assert.Equal(t, securityGroupID, "")
 }
+
+func TestWaitForSnapshotReady(t *testing.T) {
+	ccf := &DefaultVolumeProvider{sess: nil}
+
+	snapshot, err := ccf.WaitForSnapshotReady("snap-id")
+	assert.Nil(t, snapshot)
+	assert.True(t, errors.Is(err, reasoncode.ErrorUnsupportedMethod))
+}
+
+func TestWaitForVolumeAvailable(t *testing.T) {
+	ccf := &DefaultVolumeProvider{sess: nil}
+
+	volume, err := ccf.WaitForVolumeAvailable("vol-id")
+	assert.Nil(t, volume)
+	assert.True(t, errors.Is(err, reasoncode.ErrorUnsupportedMethod))
+}
+
+func TestCloneVolumeUnsupported(t *testing.T) {
+	ccf := &DefaultVolumeProvider{sess: nil}
+
+	volume, err := ccf.CloneVolume("vol-id", Volume{})
+	assert.Nil(t, volume)
+	assert.True(t, errors.Is(err, reasoncode.ErrorUnsupportedMethod))
+}
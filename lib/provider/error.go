@@ -26,6 +26,10 @@ import (
 type Error struct {
 	// Fault ...
 	Fault Fault
+
+	// wrapped holds the original wrapped errors, whose messages are flattened into Fault.Wrapped.
+	// It is a pointer so that the field itself does not add to the types Error cannot be compared with.
+	wrapped *[]error
 }
 
 // Fault encodes a fault condition.
@@ -74,3 +78,42 @@ func (err Error) Wrapped() []string {
 func (err Error) Properties() map[string]string {
 	return err.Fault.Properties
 }
+
+// WithWrapped returns a copy of the error which also wraps the given errors, so that they
+// are reachable through errors.Is and errors.As. Fault is left unchanged.
+func (err Error) WithWrapped(wrapped ...error) Error {
+	previous := err.Unwrap()
+	werrs := make([]error, 0, len(previous)+len(wrapped))
+	werrs = append(werrs, previous...)
+	for _, w := range wrapped {
+		if w != nil {
+			werrs = append(werrs, w)
+		}
+	}
+	if len(werrs) > 0 {
+		err.wrapped = &werrs
+	}
+	return err
+}
+
+// Unwrap returns the wrapped errors
+func (err Error) Unwrap() []error {
+	if err.wrapped == nil {
+		return nil
+	}
+	return *err.wrapped
+}
+
+// Is reports whether the error has the reason code of target, which may be a
+// reasoncode.ReasonCode or another Error, e.g. errors.Is(err, reasoncode.ErrorRateLimitExceeded)
+func (err Error) Is(target error) bool {
+	switch t := target.(type) {
+	case reasoncode.ReasonCode:
+		return err.Code() == t
+	case Error:
+		return err.Code() == t.Code()
+	case *Error:
+		return t != nil && err.Code() == t.Code()
+	}
+	return false
+}
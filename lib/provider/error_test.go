@@ -0,0 +1,86 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorUnwrap(t *testing.T) {
+	opErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	err := Error{Fault: Fault{ReasonCode: reasoncode.EndpointNotReachable, Message: "IAM unreachable"}}.
+		WithWrapped(fmt.Errorf("token exchange: %w", context.DeadlineExceeded), nil, opErr)
+
+	assert.Len(t, err.Unwrap(), 2)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	var target *net.OpError
+	if assert.True(t, errors.As(err, &target)) {
+		assert.Equal(t, "dial", target.Op)
+	}
+
+	// A provider.Error wrapped by another error is still reachable
+	outer := fmt.Errorf("attach: %w", err)
+	var perr Error
+	if assert.True(t, errors.As(outer, &perr)) {
+		assert.Equal(t, reasoncode.EndpointNotReachable, perr.Code())
+	}
+	assert.True(t, errors.Is(outer, opErr))
+
+	assert.Nil(t, Error{}.Unwrap())
+	assert.Nil(t, Error{}.WithWrapped(nil).Unwrap())
+
+	// Copies wrapping more errors leave the original unchanged
+	more := err.WithWrapped(errors.New("more"))
+	assert.Len(t, more.Unwrap(), 3)
+	assert.Len(t, err.Unwrap(), 2)
+}
+
+func TestErrorIs(t *testing.T) {
+	err := Error{Fault: Fault{ReasonCode: reasoncode.ErrorRateLimitExceeded, Message: "rate limited"}}
+
+	assert.True(t, errors.Is(err, reasoncode.ErrorRateLimitExceeded))
+	assert.False(t, errors.Is(err, reasoncode.ErrorUnauthorised))
+	assert.True(t, errors.Is(fmt.Errorf("wrapped: %w", err), reasoncode.ErrorRateLimitExceeded))
+	assert.True(t, errors.Is(err, Error{Fault: Fault{ReasonCode: reasoncode.ErrorRateLimitExceeded}}))
+	assert.True(t, errors.Is(err, &Error{Fault: Fault{ReasonCode: reasoncode.ErrorRateLimitExceeded}}))
+	assert.False(t, errors.Is(err, errors.New("rate limited")))
+
+	// An empty reason code is ErrorUnclassified
+	assert.True(t, errors.Is(Error{}, reasoncode.ErrorUnclassified))
+
+	// Nested provider errors match on any level
+	outer := Error{Fault: Fault{ReasonCode: reasoncode.ErrorFailedTokenExchange}}.WithWrapped(err)
+	assert.True(t, errors.Is(outer, reasoncode.ErrorFailedTokenExchange))
+	assert.True(t, errors.Is(outer, reasoncode.ErrorRateLimitExceeded))
+}
+
+func TestErrorSerialization(t *testing.T) {
+	err := Error{Fault: Fault{ReasonCode: reasoncode.ErrorBadRequest, Message: "bad", Wrapped: []string{"cause"}}}.
+		WithWrapped(errors.New("cause"))
+	data, jsonErr := json.Marshal(err)
+	assert.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"Fault":{"msg":"bad","code":"ErrorBadRequest","wrapped":["cause"]}}`, string(data))
+}
@@ -0,0 +1,2848 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+)
+
+type FakeContextSession struct {
+	AttachVolumeStub        func(context.Context, provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error)
+	attachVolumeMutex       sync.RWMutex
+	attachVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}
+	attachVolumeReturns struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}
+	attachVolumeReturnsOnCall map[int]struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}
+	AuthorizeVolumeStub        func(context.Context, provider.VolumeAuthorization) error
+	authorizeVolumeMutex       sync.RWMutex
+	authorizeVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAuthorization
+	}
+	authorizeVolumeReturns struct {
+		result1 error
+	}
+	authorizeVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CloneVolumeStub        func(context.Context, string, provider.Volume) (*provider.Volume, error)
+	cloneVolumeMutex       sync.RWMutex
+	cloneVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 provider.Volume
+	}
+	cloneVolumeReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	cloneVolumeReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	CloseStub        func()
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+	}
+	CreateSnapshotStub        func(context.Context, string, provider.SnapshotParameters) (*provider.Snapshot, error)
+	createSnapshotMutex       sync.RWMutex
+	createSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 provider.SnapshotParameters
+	}
+	createSnapshotReturns struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	createSnapshotReturnsOnCall map[int]struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	CreateVolumeStub        func(context.Context, provider.Volume) (*provider.Volume, error)
+	createVolumeMutex       sync.RWMutex
+	createVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.Volume
+	}
+	createVolumeReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	createVolumeReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	CreateVolumeAccessPointStub        func(context.Context, provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error)
+	createVolumeAccessPointMutex       sync.RWMutex
+	createVolumeAccessPointArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}
+	createVolumeAccessPointReturns struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}
+	createVolumeAccessPointReturnsOnCall map[int]struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}
+	CreateVolumeFromSnapshotStub        func(context.Context, provider.Snapshot, map[string]string) (*provider.Volume, error)
+	createVolumeFromSnapshotMutex       sync.RWMutex
+	createVolumeFromSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.Snapshot
+		arg3 map[string]string
+	}
+	createVolumeFromSnapshotReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	createVolumeFromSnapshotReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	DeleteSnapshotStub        func(context.Context, *provider.Snapshot) error
+	deleteSnapshotMutex       sync.RWMutex
+	deleteSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 *provider.Snapshot
+	}
+	deleteSnapshotReturns struct {
+		result1 error
+	}
+	deleteSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteVolumeStub        func(context.Context, *provider.Volume) error
+	deleteVolumeMutex       sync.RWMutex
+	deleteVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 *provider.Volume
+	}
+	deleteVolumeReturns struct {
+		result1 error
+	}
+	deleteVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteVolumeAccessPointStub        func(context.Context, provider.VolumeAccessPointRequest) (*http.Response, error)
+	deleteVolumeAccessPointMutex       sync.RWMutex
+	deleteVolumeAccessPointArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}
+	deleteVolumeAccessPointReturns struct {
+		result1 *http.Response
+		result2 error
+	}
+	deleteVolumeAccessPointReturnsOnCall map[int]struct {
+		result1 *http.Response
+		result2 error
+	}
+	DetachVolumeStub        func(context.Context, provider.VolumeAttachmentRequest) (*http.Response, error)
+	detachVolumeMutex       sync.RWMutex
+	detachVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}
+	detachVolumeReturns struct {
+		result1 *http.Response
+		result2 error
+	}
+	detachVolumeReturnsOnCall map[int]struct {
+		result1 *http.Response
+		result2 error
+	}
+	ExpandVolumeStub        func(context.Context, provider.ExpandVolumeRequest) (int64, error)
+	expandVolumeMutex       sync.RWMutex
+	expandVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.ExpandVolumeRequest
+	}
+	expandVolumeReturns struct {
+		result1 int64
+		result2 error
+	}
+	expandVolumeReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	GetProviderDisplayNameStub        func() provider.VolumeProvider
+	getProviderDisplayNameMutex       sync.RWMutex
+	getProviderDisplayNameArgsForCall []struct {
+	}
+	getProviderDisplayNameReturns struct {
+		result1 provider.VolumeProvider
+	}
+	getProviderDisplayNameReturnsOnCall map[int]struct {
+		result1 provider.VolumeProvider
+	}
+	GetSecurityGroupForVolumeAccessPointStub        func(context.Context, provider.SecurityGroupRequest) (string, error)
+	getSecurityGroupForVolumeAccessPointMutex       sync.RWMutex
+	getSecurityGroupForVolumeAccessPointArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.SecurityGroupRequest
+	}
+	getSecurityGroupForVolumeAccessPointReturns struct {
+		result1 string
+		result2 error
+	}
+	getSecurityGroupForVolumeAccessPointReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	GetSnapshotStub        func(context.Context, string, ...string) (*provider.Snapshot, error)
+	getSnapshotMutex       sync.RWMutex
+	getSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}
+	getSnapshotReturns struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	getSnapshotReturnsOnCall map[int]struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	GetSnapshotByNameStub        func(context.Context, string, ...string) (*provider.Snapshot, error)
+	getSnapshotByNameMutex       sync.RWMutex
+	getSnapshotByNameArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}
+	getSnapshotByNameReturns struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	getSnapshotByNameReturnsOnCall map[int]struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	GetSubnetForVolumeAccessPointStub        func(context.Context, provider.SubnetRequest) (string, error)
+	getSubnetForVolumeAccessPointMutex       sync.RWMutex
+	getSubnetForVolumeAccessPointArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.SubnetRequest
+	}
+	getSubnetForVolumeAccessPointReturns struct {
+		result1 string
+		result2 error
+	}
+	getSubnetForVolumeAccessPointReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	GetVolumeStub        func(context.Context, string) (*provider.Volume, error)
+	getVolumeMutex       sync.RWMutex
+	getVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getVolumeReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	getVolumeReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	GetVolumeAccessPointStub        func(context.Context, provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error)
+	getVolumeAccessPointMutex       sync.RWMutex
+	getVolumeAccessPointArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}
+	getVolumeAccessPointReturns struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}
+	getVolumeAccessPointReturnsOnCall map[int]struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}
+	GetVolumeAttachmentStub        func(context.Context, provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error)
+	getVolumeAttachmentMutex       sync.RWMutex
+	getVolumeAttachmentArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}
+	getVolumeAttachmentReturns struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}
+	getVolumeAttachmentReturnsOnCall map[int]struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}
+	GetVolumeByNameStub        func(context.Context, string) (*provider.Volume, error)
+	getVolumeByNameMutex       sync.RWMutex
+	getVolumeByNameArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getVolumeByNameReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	getVolumeByNameReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	GetVolumeByRequestIDStub        func(context.Context, string) (*provider.Volume, error)
+	getVolumeByRequestIDMutex       sync.RWMutex
+	getVolumeByRequestIDArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getVolumeByRequestIDReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	getVolumeByRequestIDReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	GetVolumeProfileByNameStub        func(context.Context, string) (*provider.Profile, error)
+	getVolumeProfileByNameMutex       sync.RWMutex
+	getVolumeProfileByNameArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getVolumeProfileByNameReturns struct {
+		result1 *provider.Profile
+		result2 error
+	}
+	getVolumeProfileByNameReturnsOnCall map[int]struct {
+		result1 *provider.Profile
+		result2 error
+	}
+	ListSnapshotsStub        func(context.Context, int, string, map[string]string) (*provider.SnapshotList, error)
+	listSnapshotsMutex       sync.RWMutex
+	listSnapshotsArgsForCall []struct {
+		arg1 context.Context
+		arg2 int
+		arg3 string
+		arg4 map[string]string
+	}
+	listSnapshotsReturns struct {
+		result1 *provider.SnapshotList
+		result2 error
+	}
+	listSnapshotsReturnsOnCall map[int]struct {
+		result1 *provider.SnapshotList
+		result2 error
+	}
+	ListVolumeProfilesStub        func(context.Context, int, string) (*provider.ProfileList, error)
+	listVolumeProfilesMutex       sync.RWMutex
+	listVolumeProfilesArgsForCall []struct {
+		arg1 context.Context
+		arg2 int
+		arg3 string
+	}
+	listVolumeProfilesReturns struct {
+		result1 *provider.ProfileList
+		result2 error
+	}
+	listVolumeProfilesReturnsOnCall map[int]struct {
+		result1 *provider.ProfileList
+		result2 error
+	}
+	ListVolumesStub        func(context.Context, int, string, map[string]string) (*provider.VolumeList, error)
+	listVolumesMutex       sync.RWMutex
+	listVolumesArgsForCall []struct {
+		arg1 context.Context
+		arg2 int
+		arg3 string
+		arg4 map[string]string
+	}
+	listVolumesReturns struct {
+		result1 *provider.VolumeList
+		result2 error
+	}
+	listVolumesReturnsOnCall map[int]struct {
+		result1 *provider.VolumeList
+		result2 error
+	}
+	ProviderNameStub        func() provider.VolumeProvider
+	providerNameMutex       sync.RWMutex
+	providerNameArgsForCall []struct {
+	}
+	providerNameReturns struct {
+		result1 provider.VolumeProvider
+	}
+	providerNameReturnsOnCall map[int]struct {
+		result1 provider.VolumeProvider
+	}
+	TypeStub        func() provider.VolumeType
+	typeMutex       sync.RWMutex
+	typeArgsForCall []struct {
+	}
+	typeReturns struct {
+		result1 provider.VolumeType
+	}
+	typeReturnsOnCall map[int]struct {
+		result1 provider.VolumeType
+	}
+	UpdateVolumeStub        func(context.Context, provider.Volume) error
+	updateVolumeMutex       sync.RWMutex
+	updateVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.Volume
+	}
+	updateVolumeReturns struct {
+		result1 error
+	}
+	updateVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WaitForAttachVolumeStub        func(context.Context, provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error)
+	waitForAttachVolumeMutex       sync.RWMutex
+	waitForAttachVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}
+	waitForAttachVolumeReturns struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}
+	waitForAttachVolumeReturnsOnCall map[int]struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}
+	WaitForCreateVolumeAccessPointStub        func(context.Context, provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error)
+	waitForCreateVolumeAccessPointMutex       sync.RWMutex
+	waitForCreateVolumeAccessPointArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}
+	waitForCreateVolumeAccessPointReturns struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}
+	waitForCreateVolumeAccessPointReturnsOnCall map[int]struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}
+	WaitForDeleteVolumeAccessPointStub        func(context.Context, provider.VolumeAccessPointRequest) error
+	waitForDeleteVolumeAccessPointMutex       sync.RWMutex
+	waitForDeleteVolumeAccessPointArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}
+	waitForDeleteVolumeAccessPointReturns struct {
+		result1 error
+	}
+	waitForDeleteVolumeAccessPointReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WaitForDetachVolumeStub        func(context.Context, provider.VolumeAttachmentRequest) error
+	waitForDetachVolumeMutex       sync.RWMutex
+	waitForDetachVolumeArgsForCall []struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}
+	waitForDetachVolumeReturns struct {
+		result1 error
+	}
+	waitForDetachVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WaitForSnapshotReadyStub        func(context.Context, string, ...string) (*provider.Snapshot, error)
+	waitForSnapshotReadyMutex       sync.RWMutex
+	waitForSnapshotReadyArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}
+	waitForSnapshotReadyReturns struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	waitForSnapshotReadyReturnsOnCall map[int]struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	WaitForVolumeAvailableStub        func(context.Context, string) (*provider.Volume, error)
+	waitForVolumeAvailableMutex       sync.RWMutex
+	waitForVolumeAvailableArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	waitForVolumeAvailableReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	waitForVolumeAvailableReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeContextSession) AttachVolume(arg1 context.Context, arg2 provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	fake.attachVolumeMutex.Lock()
+	ret, specificReturn := fake.attachVolumeReturnsOnCall[len(fake.attachVolumeArgsForCall)]
+	fake.attachVolumeArgsForCall = append(fake.attachVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}{arg1, arg2})
+	stub := fake.AttachVolumeStub
+	fakeReturns := fake.attachVolumeReturns
+	fake.recordInvocation("AttachVolume", []interface{}{arg1, arg2})
+	fake.attachVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) AttachVolumeCallCount() int {
+	fake.attachVolumeMutex.RLock()
+	defer fake.attachVolumeMutex.RUnlock()
+	return len(fake.attachVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) AttachVolumeCalls(stub func(context.Context, provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error)) {
+	fake.attachVolumeMutex.Lock()
+	defer fake.attachVolumeMutex.Unlock()
+	fake.AttachVolumeStub = stub
+}
+
+func (fake *FakeContextSession) AttachVolumeArgsForCall(i int) (context.Context, provider.VolumeAttachmentRequest) {
+	fake.attachVolumeMutex.RLock()
+	defer fake.attachVolumeMutex.RUnlock()
+	argsForCall := fake.attachVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) AttachVolumeReturns(result1 *provider.VolumeAttachmentResponse, result2 error) {
+	fake.attachVolumeMutex.Lock()
+	defer fake.attachVolumeMutex.Unlock()
+	fake.AttachVolumeStub = nil
+	fake.attachVolumeReturns = struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) AttachVolumeReturnsOnCall(i int, result1 *provider.VolumeAttachmentResponse, result2 error) {
+	fake.attachVolumeMutex.Lock()
+	defer fake.attachVolumeMutex.Unlock()
+	fake.AttachVolumeStub = nil
+	if fake.attachVolumeReturnsOnCall == nil {
+		fake.attachVolumeReturnsOnCall = make(map[int]struct {
+			result1 *provider.VolumeAttachmentResponse
+			result2 error
+		})
+	}
+	fake.attachVolumeReturnsOnCall[i] = struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) AuthorizeVolume(arg1 context.Context, arg2 provider.VolumeAuthorization) error {
+	fake.authorizeVolumeMutex.Lock()
+	ret, specificReturn := fake.authorizeVolumeReturnsOnCall[len(fake.authorizeVolumeArgsForCall)]
+	fake.authorizeVolumeArgsForCall = append(fake.authorizeVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAuthorization
+	}{arg1, arg2})
+	stub := fake.AuthorizeVolumeStub
+	fakeReturns := fake.authorizeVolumeReturns
+	fake.recordInvocation("AuthorizeVolume", []interface{}{arg1, arg2})
+	fake.authorizeVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContextSession) AuthorizeVolumeCallCount() int {
+	fake.authorizeVolumeMutex.RLock()
+	defer fake.authorizeVolumeMutex.RUnlock()
+	return len(fake.authorizeVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) AuthorizeVolumeCalls(stub func(context.Context, provider.VolumeAuthorization) error) {
+	fake.authorizeVolumeMutex.Lock()
+	defer fake.authorizeVolumeMutex.Unlock()
+	fake.AuthorizeVolumeStub = stub
+}
+
+func (fake *FakeContextSession) AuthorizeVolumeArgsForCall(i int) (context.Context, provider.VolumeAuthorization) {
+	fake.authorizeVolumeMutex.RLock()
+	defer fake.authorizeVolumeMutex.RUnlock()
+	argsForCall := fake.authorizeVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) AuthorizeVolumeReturns(result1 error) {
+	fake.authorizeVolumeMutex.Lock()
+	defer fake.authorizeVolumeMutex.Unlock()
+	fake.AuthorizeVolumeStub = nil
+	fake.authorizeVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) AuthorizeVolumeReturnsOnCall(i int, result1 error) {
+	fake.authorizeVolumeMutex.Lock()
+	defer fake.authorizeVolumeMutex.Unlock()
+	fake.AuthorizeVolumeStub = nil
+	if fake.authorizeVolumeReturnsOnCall == nil {
+		fake.authorizeVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.authorizeVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) CloneVolume(arg1 context.Context, arg2 string, arg3 provider.Volume) (*provider.Volume, error) {
+	fake.cloneVolumeMutex.Lock()
+	ret, specificReturn := fake.cloneVolumeReturnsOnCall[len(fake.cloneVolumeArgsForCall)]
+	fake.cloneVolumeArgsForCall = append(fake.cloneVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 provider.Volume
+	}{arg1, arg2, arg3})
+	stub := fake.CloneVolumeStub
+	fakeReturns := fake.cloneVolumeReturns
+	fake.recordInvocation("CloneVolume", []interface{}{arg1, arg2, arg3})
+	fake.cloneVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) CloneVolumeCallCount() int {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	return len(fake.cloneVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) CloneVolumeCalls(stub func(context.Context, string, provider.Volume) (*provider.Volume, error)) {
+	fake.cloneVolumeMutex.Lock()
+	defer fake.cloneVolumeMutex.Unlock()
+	fake.CloneVolumeStub = stub
+}
+
+func (fake *FakeContextSession) CloneVolumeArgsForCall(i int) (context.Context, string, provider.Volume) {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	argsForCall := fake.cloneVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContextSession) CloneVolumeReturns(result1 *provider.Volume, result2 error) {
+	fake.cloneVolumeMutex.Lock()
+	defer fake.cloneVolumeMutex.Unlock()
+	fake.CloneVolumeStub = nil
+	fake.cloneVolumeReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) CloneVolumeReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.cloneVolumeMutex.Lock()
+	defer fake.cloneVolumeMutex.Unlock()
+	fake.CloneVolumeStub = nil
+	if fake.cloneVolumeReturnsOnCall == nil {
+		fake.cloneVolumeReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.cloneVolumeReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) Close() {
+	fake.closeMutex.Lock()
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+	}{})
+	stub := fake.CloseStub
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		fake.CloseStub()
+	}
+}
+
+func (fake *FakeContextSession) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeContextSession) CloseCalls(stub func()) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
+func (fake *FakeContextSession) CreateSnapshot(arg1 context.Context, arg2 string, arg3 provider.SnapshotParameters) (*provider.Snapshot, error) {
+	fake.createSnapshotMutex.Lock()
+	ret, specificReturn := fake.createSnapshotReturnsOnCall[len(fake.createSnapshotArgsForCall)]
+	fake.createSnapshotArgsForCall = append(fake.createSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 provider.SnapshotParameters
+	}{arg1, arg2, arg3})
+	stub := fake.CreateSnapshotStub
+	fakeReturns := fake.createSnapshotReturns
+	fake.recordInvocation("CreateSnapshot", []interface{}{arg1, arg2, arg3})
+	fake.createSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) CreateSnapshotCallCount() int {
+	fake.createSnapshotMutex.RLock()
+	defer fake.createSnapshotMutex.RUnlock()
+	return len(fake.createSnapshotArgsForCall)
+}
+
+func (fake *FakeContextSession) CreateSnapshotCalls(stub func(context.Context, string, provider.SnapshotParameters) (*provider.Snapshot, error)) {
+	fake.createSnapshotMutex.Lock()
+	defer fake.createSnapshotMutex.Unlock()
+	fake.CreateSnapshotStub = stub
+}
+
+func (fake *FakeContextSession) CreateSnapshotArgsForCall(i int) (context.Context, string, provider.SnapshotParameters) {
+	fake.createSnapshotMutex.RLock()
+	defer fake.createSnapshotMutex.RUnlock()
+	argsForCall := fake.createSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContextSession) CreateSnapshotReturns(result1 *provider.Snapshot, result2 error) {
+	fake.createSnapshotMutex.Lock()
+	defer fake.createSnapshotMutex.Unlock()
+	fake.CreateSnapshotStub = nil
+	fake.createSnapshotReturns = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) CreateSnapshotReturnsOnCall(i int, result1 *provider.Snapshot, result2 error) {
+	fake.createSnapshotMutex.Lock()
+	defer fake.createSnapshotMutex.Unlock()
+	fake.CreateSnapshotStub = nil
+	if fake.createSnapshotReturnsOnCall == nil {
+		fake.createSnapshotReturnsOnCall = make(map[int]struct {
+			result1 *provider.Snapshot
+			result2 error
+		})
+	}
+	fake.createSnapshotReturnsOnCall[i] = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) CreateVolume(arg1 context.Context, arg2 provider.Volume) (*provider.Volume, error) {
+	fake.createVolumeMutex.Lock()
+	ret, specificReturn := fake.createVolumeReturnsOnCall[len(fake.createVolumeArgsForCall)]
+	fake.createVolumeArgsForCall = append(fake.createVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.Volume
+	}{arg1, arg2})
+	stub := fake.CreateVolumeStub
+	fakeReturns := fake.createVolumeReturns
+	fake.recordInvocation("CreateVolume", []interface{}{arg1, arg2})
+	fake.createVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) CreateVolumeCallCount() int {
+	fake.createVolumeMutex.RLock()
+	defer fake.createVolumeMutex.RUnlock()
+	return len(fake.createVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) CreateVolumeCalls(stub func(context.Context, provider.Volume) (*provider.Volume, error)) {
+	fake.createVolumeMutex.Lock()
+	defer fake.createVolumeMutex.Unlock()
+	fake.CreateVolumeStub = stub
+}
+
+func (fake *FakeContextSession) CreateVolumeArgsForCall(i int) (context.Context, provider.Volume) {
+	fake.createVolumeMutex.RLock()
+	defer fake.createVolumeMutex.RUnlock()
+	argsForCall := fake.createVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) CreateVolumeReturns(result1 *provider.Volume, result2 error) {
+	fake.createVolumeMutex.Lock()
+	defer fake.createVolumeMutex.Unlock()
+	fake.CreateVolumeStub = nil
+	fake.createVolumeReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) CreateVolumeReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.createVolumeMutex.Lock()
+	defer fake.createVolumeMutex.Unlock()
+	fake.CreateVolumeStub = nil
+	if fake.createVolumeReturnsOnCall == nil {
+		fake.createVolumeReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.createVolumeReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) CreateVolumeAccessPoint(arg1 context.Context, arg2 provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	fake.createVolumeAccessPointMutex.Lock()
+	ret, specificReturn := fake.createVolumeAccessPointReturnsOnCall[len(fake.createVolumeAccessPointArgsForCall)]
+	fake.createVolumeAccessPointArgsForCall = append(fake.createVolumeAccessPointArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}{arg1, arg2})
+	stub := fake.CreateVolumeAccessPointStub
+	fakeReturns := fake.createVolumeAccessPointReturns
+	fake.recordInvocation("CreateVolumeAccessPoint", []interface{}{arg1, arg2})
+	fake.createVolumeAccessPointMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) CreateVolumeAccessPointCallCount() int {
+	fake.createVolumeAccessPointMutex.RLock()
+	defer fake.createVolumeAccessPointMutex.RUnlock()
+	return len(fake.createVolumeAccessPointArgsForCall)
+}
+
+func (fake *FakeContextSession) CreateVolumeAccessPointCalls(stub func(context.Context, provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error)) {
+	fake.createVolumeAccessPointMutex.Lock()
+	defer fake.createVolumeAccessPointMutex.Unlock()
+	fake.CreateVolumeAccessPointStub = stub
+}
+
+func (fake *FakeContextSession) CreateVolumeAccessPointArgsForCall(i int) (context.Context, provider.VolumeAccessPointRequest) {
+	fake.createVolumeAccessPointMutex.RLock()
+	defer fake.createVolumeAccessPointMutex.RUnlock()
+	argsForCall := fake.createVolumeAccessPointArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) CreateVolumeAccessPointReturns(result1 *provider.VolumeAccessPointResponse, result2 error) {
+	fake.createVolumeAccessPointMutex.Lock()
+	defer fake.createVolumeAccessPointMutex.Unlock()
+	fake.CreateVolumeAccessPointStub = nil
+	fake.createVolumeAccessPointReturns = struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) CreateVolumeAccessPointReturnsOnCall(i int, result1 *provider.VolumeAccessPointResponse, result2 error) {
+	fake.createVolumeAccessPointMutex.Lock()
+	defer fake.createVolumeAccessPointMutex.Unlock()
+	fake.CreateVolumeAccessPointStub = nil
+	if fake.createVolumeAccessPointReturnsOnCall == nil {
+		fake.createVolumeAccessPointReturnsOnCall = make(map[int]struct {
+			result1 *provider.VolumeAccessPointResponse
+			result2 error
+		})
+	}
+	fake.createVolumeAccessPointReturnsOnCall[i] = struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) CreateVolumeFromSnapshot(arg1 context.Context, arg2 provider.Snapshot, arg3 map[string]string) (*provider.Volume, error) {
+	fake.createVolumeFromSnapshotMutex.Lock()
+	ret, specificReturn := fake.createVolumeFromSnapshotReturnsOnCall[len(fake.createVolumeFromSnapshotArgsForCall)]
+	fake.createVolumeFromSnapshotArgsForCall = append(fake.createVolumeFromSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.Snapshot
+		arg3 map[string]string
+	}{arg1, arg2, arg3})
+	stub := fake.CreateVolumeFromSnapshotStub
+	fakeReturns := fake.createVolumeFromSnapshotReturns
+	fake.recordInvocation("CreateVolumeFromSnapshot", []interface{}{arg1, arg2, arg3})
+	fake.createVolumeFromSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) CreateVolumeFromSnapshotCallCount() int {
+	fake.createVolumeFromSnapshotMutex.RLock()
+	defer fake.createVolumeFromSnapshotMutex.RUnlock()
+	return len(fake.createVolumeFromSnapshotArgsForCall)
+}
+
+func (fake *FakeContextSession) CreateVolumeFromSnapshotCalls(stub func(context.Context, provider.Snapshot, map[string]string) (*provider.Volume, error)) {
+	fake.createVolumeFromSnapshotMutex.Lock()
+	defer fake.createVolumeFromSnapshotMutex.Unlock()
+	fake.CreateVolumeFromSnapshotStub = stub
+}
+
+func (fake *FakeContextSession) CreateVolumeFromSnapshotArgsForCall(i int) (context.Context, provider.Snapshot, map[string]string) {
+	fake.createVolumeFromSnapshotMutex.RLock()
+	defer fake.createVolumeFromSnapshotMutex.RUnlock()
+	argsForCall := fake.createVolumeFromSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContextSession) CreateVolumeFromSnapshotReturns(result1 *provider.Volume, result2 error) {
+	fake.createVolumeFromSnapshotMutex.Lock()
+	defer fake.createVolumeFromSnapshotMutex.Unlock()
+	fake.CreateVolumeFromSnapshotStub = nil
+	fake.createVolumeFromSnapshotReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) CreateVolumeFromSnapshotReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.createVolumeFromSnapshotMutex.Lock()
+	defer fake.createVolumeFromSnapshotMutex.Unlock()
+	fake.CreateVolumeFromSnapshotStub = nil
+	if fake.createVolumeFromSnapshotReturnsOnCall == nil {
+		fake.createVolumeFromSnapshotReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.createVolumeFromSnapshotReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) DeleteSnapshot(arg1 context.Context, arg2 *provider.Snapshot) error {
+	fake.deleteSnapshotMutex.Lock()
+	ret, specificReturn := fake.deleteSnapshotReturnsOnCall[len(fake.deleteSnapshotArgsForCall)]
+	fake.deleteSnapshotArgsForCall = append(fake.deleteSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 *provider.Snapshot
+	}{arg1, arg2})
+	stub := fake.DeleteSnapshotStub
+	fakeReturns := fake.deleteSnapshotReturns
+	fake.recordInvocation("DeleteSnapshot", []interface{}{arg1, arg2})
+	fake.deleteSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContextSession) DeleteSnapshotCallCount() int {
+	fake.deleteSnapshotMutex.RLock()
+	defer fake.deleteSnapshotMutex.RUnlock()
+	return len(fake.deleteSnapshotArgsForCall)
+}
+
+func (fake *FakeContextSession) DeleteSnapshotCalls(stub func(context.Context, *provider.Snapshot) error) {
+	fake.deleteSnapshotMutex.Lock()
+	defer fake.deleteSnapshotMutex.Unlock()
+	fake.DeleteSnapshotStub = stub
+}
+
+func (fake *FakeContextSession) DeleteSnapshotArgsForCall(i int) (context.Context, *provider.Snapshot) {
+	fake.deleteSnapshotMutex.RLock()
+	defer fake.deleteSnapshotMutex.RUnlock()
+	argsForCall := fake.deleteSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) DeleteSnapshotReturns(result1 error) {
+	fake.deleteSnapshotMutex.Lock()
+	defer fake.deleteSnapshotMutex.Unlock()
+	fake.DeleteSnapshotStub = nil
+	fake.deleteSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) DeleteSnapshotReturnsOnCall(i int, result1 error) {
+	fake.deleteSnapshotMutex.Lock()
+	defer fake.deleteSnapshotMutex.Unlock()
+	fake.DeleteSnapshotStub = nil
+	if fake.deleteSnapshotReturnsOnCall == nil {
+		fake.deleteSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) DeleteVolume(arg1 context.Context, arg2 *provider.Volume) error {
+	fake.deleteVolumeMutex.Lock()
+	ret, specificReturn := fake.deleteVolumeReturnsOnCall[len(fake.deleteVolumeArgsForCall)]
+	fake.deleteVolumeArgsForCall = append(fake.deleteVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 *provider.Volume
+	}{arg1, arg2})
+	stub := fake.DeleteVolumeStub
+	fakeReturns := fake.deleteVolumeReturns
+	fake.recordInvocation("DeleteVolume", []interface{}{arg1, arg2})
+	fake.deleteVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContextSession) DeleteVolumeCallCount() int {
+	fake.deleteVolumeMutex.RLock()
+	defer fake.deleteVolumeMutex.RUnlock()
+	return len(fake.deleteVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) DeleteVolumeCalls(stub func(context.Context, *provider.Volume) error) {
+	fake.deleteVolumeMutex.Lock()
+	defer fake.deleteVolumeMutex.Unlock()
+	fake.DeleteVolumeStub = stub
+}
+
+func (fake *FakeContextSession) DeleteVolumeArgsForCall(i int) (context.Context, *provider.Volume) {
+	fake.deleteVolumeMutex.RLock()
+	defer fake.deleteVolumeMutex.RUnlock()
+	argsForCall := fake.deleteVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) DeleteVolumeReturns(result1 error) {
+	fake.deleteVolumeMutex.Lock()
+	defer fake.deleteVolumeMutex.Unlock()
+	fake.DeleteVolumeStub = nil
+	fake.deleteVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) DeleteVolumeReturnsOnCall(i int, result1 error) {
+	fake.deleteVolumeMutex.Lock()
+	defer fake.deleteVolumeMutex.Unlock()
+	fake.DeleteVolumeStub = nil
+	if fake.deleteVolumeReturnsOnCall == nil {
+		fake.deleteVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) DeleteVolumeAccessPoint(arg1 context.Context, arg2 provider.VolumeAccessPointRequest) (*http.Response, error) {
+	fake.deleteVolumeAccessPointMutex.Lock()
+	ret, specificReturn := fake.deleteVolumeAccessPointReturnsOnCall[len(fake.deleteVolumeAccessPointArgsForCall)]
+	fake.deleteVolumeAccessPointArgsForCall = append(fake.deleteVolumeAccessPointArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}{arg1, arg2})
+	stub := fake.DeleteVolumeAccessPointStub
+	fakeReturns := fake.deleteVolumeAccessPointReturns
+	fake.recordInvocation("DeleteVolumeAccessPoint", []interface{}{arg1, arg2})
+	fake.deleteVolumeAccessPointMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) DeleteVolumeAccessPointCallCount() int {
+	fake.deleteVolumeAccessPointMutex.RLock()
+	defer fake.deleteVolumeAccessPointMutex.RUnlock()
+	return len(fake.deleteVolumeAccessPointArgsForCall)
+}
+
+func (fake *FakeContextSession) DeleteVolumeAccessPointCalls(stub func(context.Context, provider.VolumeAccessPointRequest) (*http.Response, error)) {
+	fake.deleteVolumeAccessPointMutex.Lock()
+	defer fake.deleteVolumeAccessPointMutex.Unlock()
+	fake.DeleteVolumeAccessPointStub = stub
+}
+
+func (fake *FakeContextSession) DeleteVolumeAccessPointArgsForCall(i int) (context.Context, provider.VolumeAccessPointRequest) {
+	fake.deleteVolumeAccessPointMutex.RLock()
+	defer fake.deleteVolumeAccessPointMutex.RUnlock()
+	argsForCall := fake.deleteVolumeAccessPointArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) DeleteVolumeAccessPointReturns(result1 *http.Response, result2 error) {
+	fake.deleteVolumeAccessPointMutex.Lock()
+	defer fake.deleteVolumeAccessPointMutex.Unlock()
+	fake.DeleteVolumeAccessPointStub = nil
+	fake.deleteVolumeAccessPointReturns = struct {
+		result1 *http.Response
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) DeleteVolumeAccessPointReturnsOnCall(i int, result1 *http.Response, result2 error) {
+	fake.deleteVolumeAccessPointMutex.Lock()
+	defer fake.deleteVolumeAccessPointMutex.Unlock()
+	fake.DeleteVolumeAccessPointStub = nil
+	if fake.deleteVolumeAccessPointReturnsOnCall == nil {
+		fake.deleteVolumeAccessPointReturnsOnCall = make(map[int]struct {
+			result1 *http.Response
+			result2 error
+		})
+	}
+	fake.deleteVolumeAccessPointReturnsOnCall[i] = struct {
+		result1 *http.Response
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) DetachVolume(arg1 context.Context, arg2 provider.VolumeAttachmentRequest) (*http.Response, error) {
+	fake.detachVolumeMutex.Lock()
+	ret, specificReturn := fake.detachVolumeReturnsOnCall[len(fake.detachVolumeArgsForCall)]
+	fake.detachVolumeArgsForCall = append(fake.detachVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}{arg1, arg2})
+	stub := fake.DetachVolumeStub
+	fakeReturns := fake.detachVolumeReturns
+	fake.recordInvocation("DetachVolume", []interface{}{arg1, arg2})
+	fake.detachVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) DetachVolumeCallCount() int {
+	fake.detachVolumeMutex.RLock()
+	defer fake.detachVolumeMutex.RUnlock()
+	return len(fake.detachVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) DetachVolumeCalls(stub func(context.Context, provider.VolumeAttachmentRequest) (*http.Response, error)) {
+	fake.detachVolumeMutex.Lock()
+	defer fake.detachVolumeMutex.Unlock()
+	fake.DetachVolumeStub = stub
+}
+
+func (fake *FakeContextSession) DetachVolumeArgsForCall(i int) (context.Context, provider.VolumeAttachmentRequest) {
+	fake.detachVolumeMutex.RLock()
+	defer fake.detachVolumeMutex.RUnlock()
+	argsForCall := fake.detachVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) DetachVolumeReturns(result1 *http.Response, result2 error) {
+	fake.detachVolumeMutex.Lock()
+	defer fake.detachVolumeMutex.Unlock()
+	fake.DetachVolumeStub = nil
+	fake.detachVolumeReturns = struct {
+		result1 *http.Response
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) DetachVolumeReturnsOnCall(i int, result1 *http.Response, result2 error) {
+	fake.detachVolumeMutex.Lock()
+	defer fake.detachVolumeMutex.Unlock()
+	fake.DetachVolumeStub = nil
+	if fake.detachVolumeReturnsOnCall == nil {
+		fake.detachVolumeReturnsOnCall = make(map[int]struct {
+			result1 *http.Response
+			result2 error
+		})
+	}
+	fake.detachVolumeReturnsOnCall[i] = struct {
+		result1 *http.Response
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) ExpandVolume(arg1 context.Context, arg2 provider.ExpandVolumeRequest) (int64, error) {
+	fake.expandVolumeMutex.Lock()
+	ret, specificReturn := fake.expandVolumeReturnsOnCall[len(fake.expandVolumeArgsForCall)]
+	fake.expandVolumeArgsForCall = append(fake.expandVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.ExpandVolumeRequest
+	}{arg1, arg2})
+	stub := fake.ExpandVolumeStub
+	fakeReturns := fake.expandVolumeReturns
+	fake.recordInvocation("ExpandVolume", []interface{}{arg1, arg2})
+	fake.expandVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) ExpandVolumeCallCount() int {
+	fake.expandVolumeMutex.RLock()
+	defer fake.expandVolumeMutex.RUnlock()
+	return len(fake.expandVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) ExpandVolumeCalls(stub func(context.Context, provider.ExpandVolumeRequest) (int64, error)) {
+	fake.expandVolumeMutex.Lock()
+	defer fake.expandVolumeMutex.Unlock()
+	fake.ExpandVolumeStub = stub
+}
+
+func (fake *FakeContextSession) ExpandVolumeArgsForCall(i int) (context.Context, provider.ExpandVolumeRequest) {
+	fake.expandVolumeMutex.RLock()
+	defer fake.expandVolumeMutex.RUnlock()
+	argsForCall := fake.expandVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) ExpandVolumeReturns(result1 int64, result2 error) {
+	fake.expandVolumeMutex.Lock()
+	defer fake.expandVolumeMutex.Unlock()
+	fake.ExpandVolumeStub = nil
+	fake.expandVolumeReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) ExpandVolumeReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.expandVolumeMutex.Lock()
+	defer fake.expandVolumeMutex.Unlock()
+	fake.ExpandVolumeStub = nil
+	if fake.expandVolumeReturnsOnCall == nil {
+		fake.expandVolumeReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.expandVolumeReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetProviderDisplayName() provider.VolumeProvider {
+	fake.getProviderDisplayNameMutex.Lock()
+	ret, specificReturn := fake.getProviderDisplayNameReturnsOnCall[len(fake.getProviderDisplayNameArgsForCall)]
+	fake.getProviderDisplayNameArgsForCall = append(fake.getProviderDisplayNameArgsForCall, struct {
+	}{})
+	stub := fake.GetProviderDisplayNameStub
+	fakeReturns := fake.getProviderDisplayNameReturns
+	fake.recordInvocation("GetProviderDisplayName", []interface{}{})
+	fake.getProviderDisplayNameMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContextSession) GetProviderDisplayNameCallCount() int {
+	fake.getProviderDisplayNameMutex.RLock()
+	defer fake.getProviderDisplayNameMutex.RUnlock()
+	return len(fake.getProviderDisplayNameArgsForCall)
+}
+
+func (fake *FakeContextSession) GetProviderDisplayNameCalls(stub func() provider.VolumeProvider) {
+	fake.getProviderDisplayNameMutex.Lock()
+	defer fake.getProviderDisplayNameMutex.Unlock()
+	fake.GetProviderDisplayNameStub = stub
+}
+
+func (fake *FakeContextSession) GetProviderDisplayNameReturns(result1 provider.VolumeProvider) {
+	fake.getProviderDisplayNameMutex.Lock()
+	defer fake.getProviderDisplayNameMutex.Unlock()
+	fake.GetProviderDisplayNameStub = nil
+	fake.getProviderDisplayNameReturns = struct {
+		result1 provider.VolumeProvider
+	}{result1}
+}
+
+func (fake *FakeContextSession) GetProviderDisplayNameReturnsOnCall(i int, result1 provider.VolumeProvider) {
+	fake.getProviderDisplayNameMutex.Lock()
+	defer fake.getProviderDisplayNameMutex.Unlock()
+	fake.GetProviderDisplayNameStub = nil
+	if fake.getProviderDisplayNameReturnsOnCall == nil {
+		fake.getProviderDisplayNameReturnsOnCall = make(map[int]struct {
+			result1 provider.VolumeProvider
+		})
+	}
+	fake.getProviderDisplayNameReturnsOnCall[i] = struct {
+		result1 provider.VolumeProvider
+	}{result1}
+}
+
+func (fake *FakeContextSession) GetSecurityGroupForVolumeAccessPoint(arg1 context.Context, arg2 provider.SecurityGroupRequest) (string, error) {
+	fake.getSecurityGroupForVolumeAccessPointMutex.Lock()
+	ret, specificReturn := fake.getSecurityGroupForVolumeAccessPointReturnsOnCall[len(fake.getSecurityGroupForVolumeAccessPointArgsForCall)]
+	fake.getSecurityGroupForVolumeAccessPointArgsForCall = append(fake.getSecurityGroupForVolumeAccessPointArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.SecurityGroupRequest
+	}{arg1, arg2})
+	stub := fake.GetSecurityGroupForVolumeAccessPointStub
+	fakeReturns := fake.getSecurityGroupForVolumeAccessPointReturns
+	fake.recordInvocation("GetSecurityGroupForVolumeAccessPoint", []interface{}{arg1, arg2})
+	fake.getSecurityGroupForVolumeAccessPointMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetSecurityGroupForVolumeAccessPointCallCount() int {
+	fake.getSecurityGroupForVolumeAccessPointMutex.RLock()
+	defer fake.getSecurityGroupForVolumeAccessPointMutex.RUnlock()
+	return len(fake.getSecurityGroupForVolumeAccessPointArgsForCall)
+}
+
+func (fake *FakeContextSession) GetSecurityGroupForVolumeAccessPointCalls(stub func(context.Context, provider.SecurityGroupRequest) (string, error)) {
+	fake.getSecurityGroupForVolumeAccessPointMutex.Lock()
+	defer fake.getSecurityGroupForVolumeAccessPointMutex.Unlock()
+	fake.GetSecurityGroupForVolumeAccessPointStub = stub
+}
+
+func (fake *FakeContextSession) GetSecurityGroupForVolumeAccessPointArgsForCall(i int) (context.Context, provider.SecurityGroupRequest) {
+	fake.getSecurityGroupForVolumeAccessPointMutex.RLock()
+	defer fake.getSecurityGroupForVolumeAccessPointMutex.RUnlock()
+	argsForCall := fake.getSecurityGroupForVolumeAccessPointArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) GetSecurityGroupForVolumeAccessPointReturns(result1 string, result2 error) {
+	fake.getSecurityGroupForVolumeAccessPointMutex.Lock()
+	defer fake.getSecurityGroupForVolumeAccessPointMutex.Unlock()
+	fake.GetSecurityGroupForVolumeAccessPointStub = nil
+	fake.getSecurityGroupForVolumeAccessPointReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetSecurityGroupForVolumeAccessPointReturnsOnCall(i int, result1 string, result2 error) {
+	fake.getSecurityGroupForVolumeAccessPointMutex.Lock()
+	defer fake.getSecurityGroupForVolumeAccessPointMutex.Unlock()
+	fake.GetSecurityGroupForVolumeAccessPointStub = nil
+	if fake.getSecurityGroupForVolumeAccessPointReturnsOnCall == nil {
+		fake.getSecurityGroupForVolumeAccessPointReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getSecurityGroupForVolumeAccessPointReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetSnapshot(arg1 context.Context, arg2 string, arg3 ...string) (*provider.Snapshot, error) {
+	fake.getSnapshotMutex.Lock()
+	ret, specificReturn := fake.getSnapshotReturnsOnCall[len(fake.getSnapshotArgsForCall)]
+	fake.getSnapshotArgsForCall = append(fake.getSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}{arg1, arg2, arg3})
+	stub := fake.GetSnapshotStub
+	fakeReturns := fake.getSnapshotReturns
+	fake.recordInvocation("GetSnapshot", []interface{}{arg1, arg2, arg3})
+	fake.getSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetSnapshotCallCount() int {
+	fake.getSnapshotMutex.RLock()
+	defer fake.getSnapshotMutex.RUnlock()
+	return len(fake.getSnapshotArgsForCall)
+}
+
+func (fake *FakeContextSession) GetSnapshotCalls(stub func(context.Context, string, ...string) (*provider.Snapshot, error)) {
+	fake.getSnapshotMutex.Lock()
+	defer fake.getSnapshotMutex.Unlock()
+	fake.GetSnapshotStub = stub
+}
+
+func (fake *FakeContextSession) GetSnapshotArgsForCall(i int) (context.Context, string, []string) {
+	fake.getSnapshotMutex.RLock()
+	defer fake.getSnapshotMutex.RUnlock()
+	argsForCall := fake.getSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContextSession) GetSnapshotReturns(result1 *provider.Snapshot, result2 error) {
+	fake.getSnapshotMutex.Lock()
+	defer fake.getSnapshotMutex.Unlock()
+	fake.GetSnapshotStub = nil
+	fake.getSnapshotReturns = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetSnapshotReturnsOnCall(i int, result1 *provider.Snapshot, result2 error) {
+	fake.getSnapshotMutex.Lock()
+	defer fake.getSnapshotMutex.Unlock()
+	fake.GetSnapshotStub = nil
+	if fake.getSnapshotReturnsOnCall == nil {
+		fake.getSnapshotReturnsOnCall = make(map[int]struct {
+			result1 *provider.Snapshot
+			result2 error
+		})
+	}
+	fake.getSnapshotReturnsOnCall[i] = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetSnapshotByName(arg1 context.Context, arg2 string, arg3 ...string) (*provider.Snapshot, error) {
+	fake.getSnapshotByNameMutex.Lock()
+	ret, specificReturn := fake.getSnapshotByNameReturnsOnCall[len(fake.getSnapshotByNameArgsForCall)]
+	fake.getSnapshotByNameArgsForCall = append(fake.getSnapshotByNameArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}{arg1, arg2, arg3})
+	stub := fake.GetSnapshotByNameStub
+	fakeReturns := fake.getSnapshotByNameReturns
+	fake.recordInvocation("GetSnapshotByName", []interface{}{arg1, arg2, arg3})
+	fake.getSnapshotByNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetSnapshotByNameCallCount() int {
+	fake.getSnapshotByNameMutex.RLock()
+	defer fake.getSnapshotByNameMutex.RUnlock()
+	return len(fake.getSnapshotByNameArgsForCall)
+}
+
+func (fake *FakeContextSession) GetSnapshotByNameCalls(stub func(context.Context, string, ...string) (*provider.Snapshot, error)) {
+	fake.getSnapshotByNameMutex.Lock()
+	defer fake.getSnapshotByNameMutex.Unlock()
+	fake.GetSnapshotByNameStub = stub
+}
+
+func (fake *FakeContextSession) GetSnapshotByNameArgsForCall(i int) (context.Context, string, []string) {
+	fake.getSnapshotByNameMutex.RLock()
+	defer fake.getSnapshotByNameMutex.RUnlock()
+	argsForCall := fake.getSnapshotByNameArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContextSession) GetSnapshotByNameReturns(result1 *provider.Snapshot, result2 error) {
+	fake.getSnapshotByNameMutex.Lock()
+	defer fake.getSnapshotByNameMutex.Unlock()
+	fake.GetSnapshotByNameStub = nil
+	fake.getSnapshotByNameReturns = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetSnapshotByNameReturnsOnCall(i int, result1 *provider.Snapshot, result2 error) {
+	fake.getSnapshotByNameMutex.Lock()
+	defer fake.getSnapshotByNameMutex.Unlock()
+	fake.GetSnapshotByNameStub = nil
+	if fake.getSnapshotByNameReturnsOnCall == nil {
+		fake.getSnapshotByNameReturnsOnCall = make(map[int]struct {
+			result1 *provider.Snapshot
+			result2 error
+		})
+	}
+	fake.getSnapshotByNameReturnsOnCall[i] = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetSubnetForVolumeAccessPoint(arg1 context.Context, arg2 provider.SubnetRequest) (string, error) {
+	fake.getSubnetForVolumeAccessPointMutex.Lock()
+	ret, specificReturn := fake.getSubnetForVolumeAccessPointReturnsOnCall[len(fake.getSubnetForVolumeAccessPointArgsForCall)]
+	fake.getSubnetForVolumeAccessPointArgsForCall = append(fake.getSubnetForVolumeAccessPointArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.SubnetRequest
+	}{arg1, arg2})
+	stub := fake.GetSubnetForVolumeAccessPointStub
+	fakeReturns := fake.getSubnetForVolumeAccessPointReturns
+	fake.recordInvocation("GetSubnetForVolumeAccessPoint", []interface{}{arg1, arg2})
+	fake.getSubnetForVolumeAccessPointMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetSubnetForVolumeAccessPointCallCount() int {
+	fake.getSubnetForVolumeAccessPointMutex.RLock()
+	defer fake.getSubnetForVolumeAccessPointMutex.RUnlock()
+	return len(fake.getSubnetForVolumeAccessPointArgsForCall)
+}
+
+func (fake *FakeContextSession) GetSubnetForVolumeAccessPointCalls(stub func(context.Context, provider.SubnetRequest) (string, error)) {
+	fake.getSubnetForVolumeAccessPointMutex.Lock()
+	defer fake.getSubnetForVolumeAccessPointMutex.Unlock()
+	fake.GetSubnetForVolumeAccessPointStub = stub
+}
+
+func (fake *FakeContextSession) GetSubnetForVolumeAccessPointArgsForCall(i int) (context.Context, provider.SubnetRequest) {
+	fake.getSubnetForVolumeAccessPointMutex.RLock()
+	defer fake.getSubnetForVolumeAccessPointMutex.RUnlock()
+	argsForCall := fake.getSubnetForVolumeAccessPointArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) GetSubnetForVolumeAccessPointReturns(result1 string, result2 error) {
+	fake.getSubnetForVolumeAccessPointMutex.Lock()
+	defer fake.getSubnetForVolumeAccessPointMutex.Unlock()
+	fake.GetSubnetForVolumeAccessPointStub = nil
+	fake.getSubnetForVolumeAccessPointReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetSubnetForVolumeAccessPointReturnsOnCall(i int, result1 string, result2 error) {
+	fake.getSubnetForVolumeAccessPointMutex.Lock()
+	defer fake.getSubnetForVolumeAccessPointMutex.Unlock()
+	fake.GetSubnetForVolumeAccessPointStub = nil
+	if fake.getSubnetForVolumeAccessPointReturnsOnCall == nil {
+		fake.getSubnetForVolumeAccessPointReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getSubnetForVolumeAccessPointReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolume(arg1 context.Context, arg2 string) (*provider.Volume, error) {
+	fake.getVolumeMutex.Lock()
+	ret, specificReturn := fake.getVolumeReturnsOnCall[len(fake.getVolumeArgsForCall)]
+	fake.getVolumeArgsForCall = append(fake.getVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetVolumeStub
+	fakeReturns := fake.getVolumeReturns
+	fake.recordInvocation("GetVolume", []interface{}{arg1, arg2})
+	fake.getVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetVolumeCallCount() int {
+	fake.getVolumeMutex.RLock()
+	defer fake.getVolumeMutex.RUnlock()
+	return len(fake.getVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) GetVolumeCalls(stub func(context.Context, string) (*provider.Volume, error)) {
+	fake.getVolumeMutex.Lock()
+	defer fake.getVolumeMutex.Unlock()
+	fake.GetVolumeStub = stub
+}
+
+func (fake *FakeContextSession) GetVolumeArgsForCall(i int) (context.Context, string) {
+	fake.getVolumeMutex.RLock()
+	defer fake.getVolumeMutex.RUnlock()
+	argsForCall := fake.getVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) GetVolumeReturns(result1 *provider.Volume, result2 error) {
+	fake.getVolumeMutex.Lock()
+	defer fake.getVolumeMutex.Unlock()
+	fake.GetVolumeStub = nil
+	fake.getVolumeReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.getVolumeMutex.Lock()
+	defer fake.getVolumeMutex.Unlock()
+	fake.GetVolumeStub = nil
+	if fake.getVolumeReturnsOnCall == nil {
+		fake.getVolumeReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.getVolumeReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeAccessPoint(arg1 context.Context, arg2 provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	fake.getVolumeAccessPointMutex.Lock()
+	ret, specificReturn := fake.getVolumeAccessPointReturnsOnCall[len(fake.getVolumeAccessPointArgsForCall)]
+	fake.getVolumeAccessPointArgsForCall = append(fake.getVolumeAccessPointArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}{arg1, arg2})
+	stub := fake.GetVolumeAccessPointStub
+	fakeReturns := fake.getVolumeAccessPointReturns
+	fake.recordInvocation("GetVolumeAccessPoint", []interface{}{arg1, arg2})
+	fake.getVolumeAccessPointMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetVolumeAccessPointCallCount() int {
+	fake.getVolumeAccessPointMutex.RLock()
+	defer fake.getVolumeAccessPointMutex.RUnlock()
+	return len(fake.getVolumeAccessPointArgsForCall)
+}
+
+func (fake *FakeContextSession) GetVolumeAccessPointCalls(stub func(context.Context, provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error)) {
+	fake.getVolumeAccessPointMutex.Lock()
+	defer fake.getVolumeAccessPointMutex.Unlock()
+	fake.GetVolumeAccessPointStub = stub
+}
+
+func (fake *FakeContextSession) GetVolumeAccessPointArgsForCall(i int) (context.Context, provider.VolumeAccessPointRequest) {
+	fake.getVolumeAccessPointMutex.RLock()
+	defer fake.getVolumeAccessPointMutex.RUnlock()
+	argsForCall := fake.getVolumeAccessPointArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) GetVolumeAccessPointReturns(result1 *provider.VolumeAccessPointResponse, result2 error) {
+	fake.getVolumeAccessPointMutex.Lock()
+	defer fake.getVolumeAccessPointMutex.Unlock()
+	fake.GetVolumeAccessPointStub = nil
+	fake.getVolumeAccessPointReturns = struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeAccessPointReturnsOnCall(i int, result1 *provider.VolumeAccessPointResponse, result2 error) {
+	fake.getVolumeAccessPointMutex.Lock()
+	defer fake.getVolumeAccessPointMutex.Unlock()
+	fake.GetVolumeAccessPointStub = nil
+	if fake.getVolumeAccessPointReturnsOnCall == nil {
+		fake.getVolumeAccessPointReturnsOnCall = make(map[int]struct {
+			result1 *provider.VolumeAccessPointResponse
+			result2 error
+		})
+	}
+	fake.getVolumeAccessPointReturnsOnCall[i] = struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeAttachment(arg1 context.Context, arg2 provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	fake.getVolumeAttachmentMutex.Lock()
+	ret, specificReturn := fake.getVolumeAttachmentReturnsOnCall[len(fake.getVolumeAttachmentArgsForCall)]
+	fake.getVolumeAttachmentArgsForCall = append(fake.getVolumeAttachmentArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}{arg1, arg2})
+	stub := fake.GetVolumeAttachmentStub
+	fakeReturns := fake.getVolumeAttachmentReturns
+	fake.recordInvocation("GetVolumeAttachment", []interface{}{arg1, arg2})
+	fake.getVolumeAttachmentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetVolumeAttachmentCallCount() int {
+	fake.getVolumeAttachmentMutex.RLock()
+	defer fake.getVolumeAttachmentMutex.RUnlock()
+	return len(fake.getVolumeAttachmentArgsForCall)
+}
+
+func (fake *FakeContextSession) GetVolumeAttachmentCalls(stub func(context.Context, provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error)) {
+	fake.getVolumeAttachmentMutex.Lock()
+	defer fake.getVolumeAttachmentMutex.Unlock()
+	fake.GetVolumeAttachmentStub = stub
+}
+
+func (fake *FakeContextSession) GetVolumeAttachmentArgsForCall(i int) (context.Context, provider.VolumeAttachmentRequest) {
+	fake.getVolumeAttachmentMutex.RLock()
+	defer fake.getVolumeAttachmentMutex.RUnlock()
+	argsForCall := fake.getVolumeAttachmentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) GetVolumeAttachmentReturns(result1 *provider.VolumeAttachmentResponse, result2 error) {
+	fake.getVolumeAttachmentMutex.Lock()
+	defer fake.getVolumeAttachmentMutex.Unlock()
+	fake.GetVolumeAttachmentStub = nil
+	fake.getVolumeAttachmentReturns = struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeAttachmentReturnsOnCall(i int, result1 *provider.VolumeAttachmentResponse, result2 error) {
+	fake.getVolumeAttachmentMutex.Lock()
+	defer fake.getVolumeAttachmentMutex.Unlock()
+	fake.GetVolumeAttachmentStub = nil
+	if fake.getVolumeAttachmentReturnsOnCall == nil {
+		fake.getVolumeAttachmentReturnsOnCall = make(map[int]struct {
+			result1 *provider.VolumeAttachmentResponse
+			result2 error
+		})
+	}
+	fake.getVolumeAttachmentReturnsOnCall[i] = struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeByName(arg1 context.Context, arg2 string) (*provider.Volume, error) {
+	fake.getVolumeByNameMutex.Lock()
+	ret, specificReturn := fake.getVolumeByNameReturnsOnCall[len(fake.getVolumeByNameArgsForCall)]
+	fake.getVolumeByNameArgsForCall = append(fake.getVolumeByNameArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetVolumeByNameStub
+	fakeReturns := fake.getVolumeByNameReturns
+	fake.recordInvocation("GetVolumeByName", []interface{}{arg1, arg2})
+	fake.getVolumeByNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetVolumeByNameCallCount() int {
+	fake.getVolumeByNameMutex.RLock()
+	defer fake.getVolumeByNameMutex.RUnlock()
+	return len(fake.getVolumeByNameArgsForCall)
+}
+
+func (fake *FakeContextSession) GetVolumeByNameCalls(stub func(context.Context, string) (*provider.Volume, error)) {
+	fake.getVolumeByNameMutex.Lock()
+	defer fake.getVolumeByNameMutex.Unlock()
+	fake.GetVolumeByNameStub = stub
+}
+
+func (fake *FakeContextSession) GetVolumeByNameArgsForCall(i int) (context.Context, string) {
+	fake.getVolumeByNameMutex.RLock()
+	defer fake.getVolumeByNameMutex.RUnlock()
+	argsForCall := fake.getVolumeByNameArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) GetVolumeByNameReturns(result1 *provider.Volume, result2 error) {
+	fake.getVolumeByNameMutex.Lock()
+	defer fake.getVolumeByNameMutex.Unlock()
+	fake.GetVolumeByNameStub = nil
+	fake.getVolumeByNameReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeByNameReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.getVolumeByNameMutex.Lock()
+	defer fake.getVolumeByNameMutex.Unlock()
+	fake.GetVolumeByNameStub = nil
+	if fake.getVolumeByNameReturnsOnCall == nil {
+		fake.getVolumeByNameReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.getVolumeByNameReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeByRequestID(arg1 context.Context, arg2 string) (*provider.Volume, error) {
+	fake.getVolumeByRequestIDMutex.Lock()
+	ret, specificReturn := fake.getVolumeByRequestIDReturnsOnCall[len(fake.getVolumeByRequestIDArgsForCall)]
+	fake.getVolumeByRequestIDArgsForCall = append(fake.getVolumeByRequestIDArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetVolumeByRequestIDStub
+	fakeReturns := fake.getVolumeByRequestIDReturns
+	fake.recordInvocation("GetVolumeByRequestID", []interface{}{arg1, arg2})
+	fake.getVolumeByRequestIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetVolumeByRequestIDCallCount() int {
+	fake.getVolumeByRequestIDMutex.RLock()
+	defer fake.getVolumeByRequestIDMutex.RUnlock()
+	return len(fake.getVolumeByRequestIDArgsForCall)
+}
+
+func (fake *FakeContextSession) GetVolumeByRequestIDCalls(stub func(context.Context, string) (*provider.Volume, error)) {
+	fake.getVolumeByRequestIDMutex.Lock()
+	defer fake.getVolumeByRequestIDMutex.Unlock()
+	fake.GetVolumeByRequestIDStub = stub
+}
+
+func (fake *FakeContextSession) GetVolumeByRequestIDArgsForCall(i int) (context.Context, string) {
+	fake.getVolumeByRequestIDMutex.RLock()
+	defer fake.getVolumeByRequestIDMutex.RUnlock()
+	argsForCall := fake.getVolumeByRequestIDArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) GetVolumeByRequestIDReturns(result1 *provider.Volume, result2 error) {
+	fake.getVolumeByRequestIDMutex.Lock()
+	defer fake.getVolumeByRequestIDMutex.Unlock()
+	fake.GetVolumeByRequestIDStub = nil
+	fake.getVolumeByRequestIDReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeByRequestIDReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.getVolumeByRequestIDMutex.Lock()
+	defer fake.getVolumeByRequestIDMutex.Unlock()
+	fake.GetVolumeByRequestIDStub = nil
+	if fake.getVolumeByRequestIDReturnsOnCall == nil {
+		fake.getVolumeByRequestIDReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.getVolumeByRequestIDReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeProfileByName(arg1 context.Context, arg2 string) (*provider.Profile, error) {
+	fake.getVolumeProfileByNameMutex.Lock()
+	ret, specificReturn := fake.getVolumeProfileByNameReturnsOnCall[len(fake.getVolumeProfileByNameArgsForCall)]
+	fake.getVolumeProfileByNameArgsForCall = append(fake.getVolumeProfileByNameArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetVolumeProfileByNameStub
+	fakeReturns := fake.getVolumeProfileByNameReturns
+	fake.recordInvocation("GetVolumeProfileByName", []interface{}{arg1, arg2})
+	fake.getVolumeProfileByNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) GetVolumeProfileByNameCallCount() int {
+	fake.getVolumeProfileByNameMutex.RLock()
+	defer fake.getVolumeProfileByNameMutex.RUnlock()
+	return len(fake.getVolumeProfileByNameArgsForCall)
+}
+
+func (fake *FakeContextSession) GetVolumeProfileByNameCalls(stub func(context.Context, string) (*provider.Profile, error)) {
+	fake.getVolumeProfileByNameMutex.Lock()
+	defer fake.getVolumeProfileByNameMutex.Unlock()
+	fake.GetVolumeProfileByNameStub = stub
+}
+
+func (fake *FakeContextSession) GetVolumeProfileByNameArgsForCall(i int) (context.Context, string) {
+	fake.getVolumeProfileByNameMutex.RLock()
+	defer fake.getVolumeProfileByNameMutex.RUnlock()
+	argsForCall := fake.getVolumeProfileByNameArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) GetVolumeProfileByNameReturns(result1 *provider.Profile, result2 error) {
+	fake.getVolumeProfileByNameMutex.Lock()
+	defer fake.getVolumeProfileByNameMutex.Unlock()
+	fake.GetVolumeProfileByNameStub = nil
+	fake.getVolumeProfileByNameReturns = struct {
+		result1 *provider.Profile
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) GetVolumeProfileByNameReturnsOnCall(i int, result1 *provider.Profile, result2 error) {
+	fake.getVolumeProfileByNameMutex.Lock()
+	defer fake.getVolumeProfileByNameMutex.Unlock()
+	fake.GetVolumeProfileByNameStub = nil
+	if fake.getVolumeProfileByNameReturnsOnCall == nil {
+		fake.getVolumeProfileByNameReturnsOnCall = make(map[int]struct {
+			result1 *provider.Profile
+			result2 error
+		})
+	}
+	fake.getVolumeProfileByNameReturnsOnCall[i] = struct {
+		result1 *provider.Profile
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) ListSnapshots(arg1 context.Context, arg2 int, arg3 string, arg4 map[string]string) (*provider.SnapshotList, error) {
+	fake.listSnapshotsMutex.Lock()
+	ret, specificReturn := fake.listSnapshotsReturnsOnCall[len(fake.listSnapshotsArgsForCall)]
+	fake.listSnapshotsArgsForCall = append(fake.listSnapshotsArgsForCall, struct {
+		arg1 context.Context
+		arg2 int
+		arg3 string
+		arg4 map[string]string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ListSnapshotsStub
+	fakeReturns := fake.listSnapshotsReturns
+	fake.recordInvocation("ListSnapshots", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listSnapshotsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) ListSnapshotsCallCount() int {
+	fake.listSnapshotsMutex.RLock()
+	defer fake.listSnapshotsMutex.RUnlock()
+	return len(fake.listSnapshotsArgsForCall)
+}
+
+func (fake *FakeContextSession) ListSnapshotsCalls(stub func(context.Context, int, string, map[string]string) (*provider.SnapshotList, error)) {
+	fake.listSnapshotsMutex.Lock()
+	defer fake.listSnapshotsMutex.Unlock()
+	fake.ListSnapshotsStub = stub
+}
+
+func (fake *FakeContextSession) ListSnapshotsArgsForCall(i int) (context.Context, int, string, map[string]string) {
+	fake.listSnapshotsMutex.RLock()
+	defer fake.listSnapshotsMutex.RUnlock()
+	argsForCall := fake.listSnapshotsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeContextSession) ListSnapshotsReturns(result1 *provider.SnapshotList, result2 error) {
+	fake.listSnapshotsMutex.Lock()
+	defer fake.listSnapshotsMutex.Unlock()
+	fake.ListSnapshotsStub = nil
+	fake.listSnapshotsReturns = struct {
+		result1 *provider.SnapshotList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) ListSnapshotsReturnsOnCall(i int, result1 *provider.SnapshotList, result2 error) {
+	fake.listSnapshotsMutex.Lock()
+	defer fake.listSnapshotsMutex.Unlock()
+	fake.ListSnapshotsStub = nil
+	if fake.listSnapshotsReturnsOnCall == nil {
+		fake.listSnapshotsReturnsOnCall = make(map[int]struct {
+			result1 *provider.SnapshotList
+			result2 error
+		})
+	}
+	fake.listSnapshotsReturnsOnCall[i] = struct {
+		result1 *provider.SnapshotList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) ListVolumeProfiles(arg1 context.Context, arg2 int, arg3 string) (*provider.ProfileList, error) {
+	fake.listVolumeProfilesMutex.Lock()
+	ret, specificReturn := fake.listVolumeProfilesReturnsOnCall[len(fake.listVolumeProfilesArgsForCall)]
+	fake.listVolumeProfilesArgsForCall = append(fake.listVolumeProfilesArgsForCall, struct {
+		arg1 context.Context
+		arg2 int
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ListVolumeProfilesStub
+	fakeReturns := fake.listVolumeProfilesReturns
+	fake.recordInvocation("ListVolumeProfiles", []interface{}{arg1, arg2, arg3})
+	fake.listVolumeProfilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) ListVolumeProfilesCallCount() int {
+	fake.listVolumeProfilesMutex.RLock()
+	defer fake.listVolumeProfilesMutex.RUnlock()
+	return len(fake.listVolumeProfilesArgsForCall)
+}
+
+func (fake *FakeContextSession) ListVolumeProfilesCalls(stub func(context.Context, int, string) (*provider.ProfileList, error)) {
+	fake.listVolumeProfilesMutex.Lock()
+	defer fake.listVolumeProfilesMutex.Unlock()
+	fake.ListVolumeProfilesStub = stub
+}
+
+func (fake *FakeContextSession) ListVolumeProfilesArgsForCall(i int) (context.Context, int, string) {
+	fake.listVolumeProfilesMutex.RLock()
+	defer fake.listVolumeProfilesMutex.RUnlock()
+	argsForCall := fake.listVolumeProfilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContextSession) ListVolumeProfilesReturns(result1 *provider.ProfileList, result2 error) {
+	fake.listVolumeProfilesMutex.Lock()
+	defer fake.listVolumeProfilesMutex.Unlock()
+	fake.ListVolumeProfilesStub = nil
+	fake.listVolumeProfilesReturns = struct {
+		result1 *provider.ProfileList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) ListVolumeProfilesReturnsOnCall(i int, result1 *provider.ProfileList, result2 error) {
+	fake.listVolumeProfilesMutex.Lock()
+	defer fake.listVolumeProfilesMutex.Unlock()
+	fake.ListVolumeProfilesStub = nil
+	if fake.listVolumeProfilesReturnsOnCall == nil {
+		fake.listVolumeProfilesReturnsOnCall = make(map[int]struct {
+			result1 *provider.ProfileList
+			result2 error
+		})
+	}
+	fake.listVolumeProfilesReturnsOnCall[i] = struct {
+		result1 *provider.ProfileList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) ListVolumes(arg1 context.Context, arg2 int, arg3 string, arg4 map[string]string) (*provider.VolumeList, error) {
+	fake.listVolumesMutex.Lock()
+	ret, specificReturn := fake.listVolumesReturnsOnCall[len(fake.listVolumesArgsForCall)]
+	fake.listVolumesArgsForCall = append(fake.listVolumesArgsForCall, struct {
+		arg1 context.Context
+		arg2 int
+		arg3 string
+		arg4 map[string]string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ListVolumesStub
+	fakeReturns := fake.listVolumesReturns
+	fake.recordInvocation("ListVolumes", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listVolumesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) ListVolumesCallCount() int {
+	fake.listVolumesMutex.RLock()
+	defer fake.listVolumesMutex.RUnlock()
+	return len(fake.listVolumesArgsForCall)
+}
+
+func (fake *FakeContextSession) ListVolumesCalls(stub func(context.Context, int, string, map[string]string) (*provider.VolumeList, error)) {
+	fake.listVolumesMutex.Lock()
+	defer fake.listVolumesMutex.Unlock()
+	fake.ListVolumesStub = stub
+}
+
+func (fake *FakeContextSession) ListVolumesArgsForCall(i int) (context.Context, int, string, map[string]string) {
+	fake.listVolumesMutex.RLock()
+	defer fake.listVolumesMutex.RUnlock()
+	argsForCall := fake.listVolumesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeContextSession) ListVolumesReturns(result1 *provider.VolumeList, result2 error) {
+	fake.listVolumesMutex.Lock()
+	defer fake.listVolumesMutex.Unlock()
+	fake.ListVolumesStub = nil
+	fake.listVolumesReturns = struct {
+		result1 *provider.VolumeList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) ListVolumesReturnsOnCall(i int, result1 *provider.VolumeList, result2 error) {
+	fake.listVolumesMutex.Lock()
+	defer fake.listVolumesMutex.Unlock()
+	fake.ListVolumesStub = nil
+	if fake.listVolumesReturnsOnCall == nil {
+		fake.listVolumesReturnsOnCall = make(map[int]struct {
+			result1 *provider.VolumeList
+			result2 error
+		})
+	}
+	fake.listVolumesReturnsOnCall[i] = struct {
+		result1 *provider.VolumeList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) ProviderName() provider.VolumeProvider {
+	fake.providerNameMutex.Lock()
+	ret, specificReturn := fake.providerNameReturnsOnCall[len(fake.providerNameArgsForCall)]
+	fake.providerNameArgsForCall = append(fake.providerNameArgsForCall, struct {
+	}{})
+	stub := fake.ProviderNameStub
+	fakeReturns := fake.providerNameReturns
+	fake.recordInvocation("ProviderName", []interface{}{})
+	fake.providerNameMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContextSession) ProviderNameCallCount() int {
+	fake.providerNameMutex.RLock()
+	defer fake.providerNameMutex.RUnlock()
+	return len(fake.providerNameArgsForCall)
+}
+
+func (fake *FakeContextSession) ProviderNameCalls(stub func() provider.VolumeProvider) {
+	fake.providerNameMutex.Lock()
+	defer fake.providerNameMutex.Unlock()
+	fake.ProviderNameStub = stub
+}
+
+func (fake *FakeContextSession) ProviderNameReturns(result1 provider.VolumeProvider) {
+	fake.providerNameMutex.Lock()
+	defer fake.providerNameMutex.Unlock()
+	fake.ProviderNameStub = nil
+	fake.providerNameReturns = struct {
+		result1 provider.VolumeProvider
+	}{result1}
+}
+
+func (fake *FakeContextSession) ProviderNameReturnsOnCall(i int, result1 provider.VolumeProvider) {
+	fake.providerNameMutex.Lock()
+	defer fake.providerNameMutex.Unlock()
+	fake.ProviderNameStub = nil
+	if fake.providerNameReturnsOnCall == nil {
+		fake.providerNameReturnsOnCall = make(map[int]struct {
+			result1 provider.VolumeProvider
+		})
+	}
+	fake.providerNameReturnsOnCall[i] = struct {
+		result1 provider.VolumeProvider
+	}{result1}
+}
+
+func (fake *FakeContextSession) Type() provider.VolumeType {
+	fake.typeMutex.Lock()
+	ret, specificReturn := fake.typeReturnsOnCall[len(fake.typeArgsForCall)]
+	fake.typeArgsForCall = append(fake.typeArgsForCall, struct {
+	}{})
+	stub := fake.TypeStub
+	fakeReturns := fake.typeReturns
+	fake.recordInvocation("Type", []interface{}{})
+	fake.typeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContextSession) TypeCallCount() int {
+	fake.typeMutex.RLock()
+	defer fake.typeMutex.RUnlock()
+	return len(fake.typeArgsForCall)
+}
+
+func (fake *FakeContextSession) TypeCalls(stub func() provider.VolumeType) {
+	fake.typeMutex.Lock()
+	defer fake.typeMutex.Unlock()
+	fake.TypeStub = stub
+}
+
+func (fake *FakeContextSession) TypeReturns(result1 provider.VolumeType) {
+	fake.typeMutex.Lock()
+	defer fake.typeMutex.Unlock()
+	fake.TypeStub = nil
+	fake.typeReturns = struct {
+		result1 provider.VolumeType
+	}{result1}
+}
+
+func (fake *FakeContextSession) TypeReturnsOnCall(i int, result1 provider.VolumeType) {
+	fake.typeMutex.Lock()
+	defer fake.typeMutex.Unlock()
+	fake.TypeStub = nil
+	if fake.typeReturnsOnCall == nil {
+		fake.typeReturnsOnCall = make(map[int]struct {
+			result1 provider.VolumeType
+		})
+	}
+	fake.typeReturnsOnCall[i] = struct {
+		result1 provider.VolumeType
+	}{result1}
+}
+
+func (fake *FakeContextSession) UpdateVolume(arg1 context.Context, arg2 provider.Volume) error {
+	fake.updateVolumeMutex.Lock()
+	ret, specificReturn := fake.updateVolumeReturnsOnCall[len(fake.updateVolumeArgsForCall)]
+	fake.updateVolumeArgsForCall = append(fake.updateVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.Volume
+	}{arg1, arg2})
+	stub := fake.UpdateVolumeStub
+	fakeReturns := fake.updateVolumeReturns
+	fake.recordInvocation("UpdateVolume", []interface{}{arg1, arg2})
+	fake.updateVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContextSession) UpdateVolumeCallCount() int {
+	fake.updateVolumeMutex.RLock()
+	defer fake.updateVolumeMutex.RUnlock()
+	return len(fake.updateVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) UpdateVolumeCalls(stub func(context.Context, provider.Volume) error) {
+	fake.updateVolumeMutex.Lock()
+	defer fake.updateVolumeMutex.Unlock()
+	fake.UpdateVolumeStub = stub
+}
+
+func (fake *FakeContextSession) UpdateVolumeArgsForCall(i int) (context.Context, provider.Volume) {
+	fake.updateVolumeMutex.RLock()
+	defer fake.updateVolumeMutex.RUnlock()
+	argsForCall := fake.updateVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) UpdateVolumeReturns(result1 error) {
+	fake.updateVolumeMutex.Lock()
+	defer fake.updateVolumeMutex.Unlock()
+	fake.UpdateVolumeStub = nil
+	fake.updateVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) UpdateVolumeReturnsOnCall(i int, result1 error) {
+	fake.updateVolumeMutex.Lock()
+	defer fake.updateVolumeMutex.Unlock()
+	fake.UpdateVolumeStub = nil
+	if fake.updateVolumeReturnsOnCall == nil {
+		fake.updateVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) WaitForAttachVolume(arg1 context.Context, arg2 provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	fake.waitForAttachVolumeMutex.Lock()
+	ret, specificReturn := fake.waitForAttachVolumeReturnsOnCall[len(fake.waitForAttachVolumeArgsForCall)]
+	fake.waitForAttachVolumeArgsForCall = append(fake.waitForAttachVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}{arg1, arg2})
+	stub := fake.WaitForAttachVolumeStub
+	fakeReturns := fake.waitForAttachVolumeReturns
+	fake.recordInvocation("WaitForAttachVolume", []interface{}{arg1, arg2})
+	fake.waitForAttachVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) WaitForAttachVolumeCallCount() int {
+	fake.waitForAttachVolumeMutex.RLock()
+	defer fake.waitForAttachVolumeMutex.RUnlock()
+	return len(fake.waitForAttachVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) WaitForAttachVolumeCalls(stub func(context.Context, provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error)) {
+	fake.waitForAttachVolumeMutex.Lock()
+	defer fake.waitForAttachVolumeMutex.Unlock()
+	fake.WaitForAttachVolumeStub = stub
+}
+
+func (fake *FakeContextSession) WaitForAttachVolumeArgsForCall(i int) (context.Context, provider.VolumeAttachmentRequest) {
+	fake.waitForAttachVolumeMutex.RLock()
+	defer fake.waitForAttachVolumeMutex.RUnlock()
+	argsForCall := fake.waitForAttachVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) WaitForAttachVolumeReturns(result1 *provider.VolumeAttachmentResponse, result2 error) {
+	fake.waitForAttachVolumeMutex.Lock()
+	defer fake.waitForAttachVolumeMutex.Unlock()
+	fake.WaitForAttachVolumeStub = nil
+	fake.waitForAttachVolumeReturns = struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) WaitForAttachVolumeReturnsOnCall(i int, result1 *provider.VolumeAttachmentResponse, result2 error) {
+	fake.waitForAttachVolumeMutex.Lock()
+	defer fake.waitForAttachVolumeMutex.Unlock()
+	fake.WaitForAttachVolumeStub = nil
+	if fake.waitForAttachVolumeReturnsOnCall == nil {
+		fake.waitForAttachVolumeReturnsOnCall = make(map[int]struct {
+			result1 *provider.VolumeAttachmentResponse
+			result2 error
+		})
+	}
+	fake.waitForAttachVolumeReturnsOnCall[i] = struct {
+		result1 *provider.VolumeAttachmentResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) WaitForCreateVolumeAccessPoint(arg1 context.Context, arg2 provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	fake.waitForCreateVolumeAccessPointMutex.Lock()
+	ret, specificReturn := fake.waitForCreateVolumeAccessPointReturnsOnCall[len(fake.waitForCreateVolumeAccessPointArgsForCall)]
+	fake.waitForCreateVolumeAccessPointArgsForCall = append(fake.waitForCreateVolumeAccessPointArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}{arg1, arg2})
+	stub := fake.WaitForCreateVolumeAccessPointStub
+	fakeReturns := fake.waitForCreateVolumeAccessPointReturns
+	fake.recordInvocation("WaitForCreateVolumeAccessPoint", []interface{}{arg1, arg2})
+	fake.waitForCreateVolumeAccessPointMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) WaitForCreateVolumeAccessPointCallCount() int {
+	fake.waitForCreateVolumeAccessPointMutex.RLock()
+	defer fake.waitForCreateVolumeAccessPointMutex.RUnlock()
+	return len(fake.waitForCreateVolumeAccessPointArgsForCall)
+}
+
+func (fake *FakeContextSession) WaitForCreateVolumeAccessPointCalls(stub func(context.Context, provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error)) {
+	fake.waitForCreateVolumeAccessPointMutex.Lock()
+	defer fake.waitForCreateVolumeAccessPointMutex.Unlock()
+	fake.WaitForCreateVolumeAccessPointStub = stub
+}
+
+func (fake *FakeContextSession) WaitForCreateVolumeAccessPointArgsForCall(i int) (context.Context, provider.VolumeAccessPointRequest) {
+	fake.waitForCreateVolumeAccessPointMutex.RLock()
+	defer fake.waitForCreateVolumeAccessPointMutex.RUnlock()
+	argsForCall := fake.waitForCreateVolumeAccessPointArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) WaitForCreateVolumeAccessPointReturns(result1 *provider.VolumeAccessPointResponse, result2 error) {
+	fake.waitForCreateVolumeAccessPointMutex.Lock()
+	defer fake.waitForCreateVolumeAccessPointMutex.Unlock()
+	fake.WaitForCreateVolumeAccessPointStub = nil
+	fake.waitForCreateVolumeAccessPointReturns = struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) WaitForCreateVolumeAccessPointReturnsOnCall(i int, result1 *provider.VolumeAccessPointResponse, result2 error) {
+	fake.waitForCreateVolumeAccessPointMutex.Lock()
+	defer fake.waitForCreateVolumeAccessPointMutex.Unlock()
+	fake.WaitForCreateVolumeAccessPointStub = nil
+	if fake.waitForCreateVolumeAccessPointReturnsOnCall == nil {
+		fake.waitForCreateVolumeAccessPointReturnsOnCall = make(map[int]struct {
+			result1 *provider.VolumeAccessPointResponse
+			result2 error
+		})
+	}
+	fake.waitForCreateVolumeAccessPointReturnsOnCall[i] = struct {
+		result1 *provider.VolumeAccessPointResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) WaitForDeleteVolumeAccessPoint(arg1 context.Context, arg2 provider.VolumeAccessPointRequest) error {
+	fake.waitForDeleteVolumeAccessPointMutex.Lock()
+	ret, specificReturn := fake.waitForDeleteVolumeAccessPointReturnsOnCall[len(fake.waitForDeleteVolumeAccessPointArgsForCall)]
+	fake.waitForDeleteVolumeAccessPointArgsForCall = append(fake.waitForDeleteVolumeAccessPointArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAccessPointRequest
+	}{arg1, arg2})
+	stub := fake.WaitForDeleteVolumeAccessPointStub
+	fakeReturns := fake.waitForDeleteVolumeAccessPointReturns
+	fake.recordInvocation("WaitForDeleteVolumeAccessPoint", []interface{}{arg1, arg2})
+	fake.waitForDeleteVolumeAccessPointMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContextSession) WaitForDeleteVolumeAccessPointCallCount() int {
+	fake.waitForDeleteVolumeAccessPointMutex.RLock()
+	defer fake.waitForDeleteVolumeAccessPointMutex.RUnlock()
+	return len(fake.waitForDeleteVolumeAccessPointArgsForCall)
+}
+
+func (fake *FakeContextSession) WaitForDeleteVolumeAccessPointCalls(stub func(context.Context, provider.VolumeAccessPointRequest) error) {
+	fake.waitForDeleteVolumeAccessPointMutex.Lock()
+	defer fake.waitForDeleteVolumeAccessPointMutex.Unlock()
+	fake.WaitForDeleteVolumeAccessPointStub = stub
+}
+
+func (fake *FakeContextSession) WaitForDeleteVolumeAccessPointArgsForCall(i int) (context.Context, provider.VolumeAccessPointRequest) {
+	fake.waitForDeleteVolumeAccessPointMutex.RLock()
+	defer fake.waitForDeleteVolumeAccessPointMutex.RUnlock()
+	argsForCall := fake.waitForDeleteVolumeAccessPointArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) WaitForDeleteVolumeAccessPointReturns(result1 error) {
+	fake.waitForDeleteVolumeAccessPointMutex.Lock()
+	defer fake.waitForDeleteVolumeAccessPointMutex.Unlock()
+	fake.WaitForDeleteVolumeAccessPointStub = nil
+	fake.waitForDeleteVolumeAccessPointReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) WaitForDeleteVolumeAccessPointReturnsOnCall(i int, result1 error) {
+	fake.waitForDeleteVolumeAccessPointMutex.Lock()
+	defer fake.waitForDeleteVolumeAccessPointMutex.Unlock()
+	fake.WaitForDeleteVolumeAccessPointStub = nil
+	if fake.waitForDeleteVolumeAccessPointReturnsOnCall == nil {
+		fake.waitForDeleteVolumeAccessPointReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForDeleteVolumeAccessPointReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) WaitForDetachVolume(arg1 context.Context, arg2 provider.VolumeAttachmentRequest) error {
+	fake.waitForDetachVolumeMutex.Lock()
+	ret, specificReturn := fake.waitForDetachVolumeReturnsOnCall[len(fake.waitForDetachVolumeArgsForCall)]
+	fake.waitForDetachVolumeArgsForCall = append(fake.waitForDetachVolumeArgsForCall, struct {
+		arg1 context.Context
+		arg2 provider.VolumeAttachmentRequest
+	}{arg1, arg2})
+	stub := fake.WaitForDetachVolumeStub
+	fakeReturns := fake.waitForDetachVolumeReturns
+	fake.recordInvocation("WaitForDetachVolume", []interface{}{arg1, arg2})
+	fake.waitForDetachVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContextSession) WaitForDetachVolumeCallCount() int {
+	fake.waitForDetachVolumeMutex.RLock()
+	defer fake.waitForDetachVolumeMutex.RUnlock()
+	return len(fake.waitForDetachVolumeArgsForCall)
+}
+
+func (fake *FakeContextSession) WaitForDetachVolumeCalls(stub func(context.Context, provider.VolumeAttachmentRequest) error) {
+	fake.waitForDetachVolumeMutex.Lock()
+	defer fake.waitForDetachVolumeMutex.Unlock()
+	fake.WaitForDetachVolumeStub = stub
+}
+
+func (fake *FakeContextSession) WaitForDetachVolumeArgsForCall(i int) (context.Context, provider.VolumeAttachmentRequest) {
+	fake.waitForDetachVolumeMutex.RLock()
+	defer fake.waitForDetachVolumeMutex.RUnlock()
+	argsForCall := fake.waitForDetachVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) WaitForDetachVolumeReturns(result1 error) {
+	fake.waitForDetachVolumeMutex.Lock()
+	defer fake.waitForDetachVolumeMutex.Unlock()
+	fake.WaitForDetachVolumeStub = nil
+	fake.waitForDetachVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) WaitForDetachVolumeReturnsOnCall(i int, result1 error) {
+	fake.waitForDetachVolumeMutex.Lock()
+	defer fake.waitForDetachVolumeMutex.Unlock()
+	fake.WaitForDetachVolumeStub = nil
+	if fake.waitForDetachVolumeReturnsOnCall == nil {
+		fake.waitForDetachVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForDetachVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextSession) WaitForSnapshotReady(arg1 context.Context, arg2 string, arg3 ...string) (*provider.Snapshot, error) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	ret, specificReturn := fake.waitForSnapshotReadyReturnsOnCall[len(fake.waitForSnapshotReadyArgsForCall)]
+	fake.waitForSnapshotReadyArgsForCall = append(fake.waitForSnapshotReadyArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}{arg1, arg2, arg3})
+	stub := fake.WaitForSnapshotReadyStub
+	fakeReturns := fake.waitForSnapshotReadyReturns
+	fake.recordInvocation("WaitForSnapshotReady", []interface{}{arg1, arg2, arg3})
+	fake.waitForSnapshotReadyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) WaitForSnapshotReadyCallCount() int {
+	fake.waitForSnapshotReadyMutex.RLock()
+	defer fake.waitForSnapshotReadyMutex.RUnlock()
+	return len(fake.waitForSnapshotReadyArgsForCall)
+}
+
+func (fake *FakeContextSession) WaitForSnapshotReadyCalls(stub func(context.Context, string, ...string) (*provider.Snapshot, error)) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	defer fake.waitForSnapshotReadyMutex.Unlock()
+	fake.WaitForSnapshotReadyStub = stub
+}
+
+func (fake *FakeContextSession) WaitForSnapshotReadyArgsForCall(i int) (context.Context, string, []string) {
+	fake.waitForSnapshotReadyMutex.RLock()
+	defer fake.waitForSnapshotReadyMutex.RUnlock()
+	argsForCall := fake.waitForSnapshotReadyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContextSession) WaitForSnapshotReadyReturns(result1 *provider.Snapshot, result2 error) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	defer fake.waitForSnapshotReadyMutex.Unlock()
+	fake.WaitForSnapshotReadyStub = nil
+	fake.waitForSnapshotReadyReturns = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) WaitForSnapshotReadyReturnsOnCall(i int, result1 *provider.Snapshot, result2 error) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	defer fake.waitForSnapshotReadyMutex.Unlock()
+	fake.WaitForSnapshotReadyStub = nil
+	if fake.waitForSnapshotReadyReturnsOnCall == nil {
+		fake.waitForSnapshotReadyReturnsOnCall = make(map[int]struct {
+			result1 *provider.Snapshot
+			result2 error
+		})
+	}
+	fake.waitForSnapshotReadyReturnsOnCall[i] = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) WaitForVolumeAvailable(arg1 context.Context, arg2 string) (*provider.Volume, error) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	ret, specificReturn := fake.waitForVolumeAvailableReturnsOnCall[len(fake.waitForVolumeAvailableArgsForCall)]
+	fake.waitForVolumeAvailableArgsForCall = append(fake.waitForVolumeAvailableArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.WaitForVolumeAvailableStub
+	fakeReturns := fake.waitForVolumeAvailableReturns
+	fake.recordInvocation("WaitForVolumeAvailable", []interface{}{arg1, arg2})
+	fake.waitForVolumeAvailableMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContextSession) WaitForVolumeAvailableCallCount() int {
+	fake.waitForVolumeAvailableMutex.RLock()
+	defer fake.waitForVolumeAvailableMutex.RUnlock()
+	return len(fake.waitForVolumeAvailableArgsForCall)
+}
+
+func (fake *FakeContextSession) WaitForVolumeAvailableCalls(stub func(context.Context, string) (*provider.Volume, error)) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	defer fake.waitForVolumeAvailableMutex.Unlock()
+	fake.WaitForVolumeAvailableStub = stub
+}
+
+func (fake *FakeContextSession) WaitForVolumeAvailableArgsForCall(i int) (context.Context, string) {
+	fake.waitForVolumeAvailableMutex.RLock()
+	defer fake.waitForVolumeAvailableMutex.RUnlock()
+	argsForCall := fake.waitForVolumeAvailableArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContextSession) WaitForVolumeAvailableReturns(result1 *provider.Volume, result2 error) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	defer fake.waitForVolumeAvailableMutex.Unlock()
+	fake.WaitForVolumeAvailableStub = nil
+	fake.waitForVolumeAvailableReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) WaitForVolumeAvailableReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	defer fake.waitForVolumeAvailableMutex.Unlock()
+	fake.WaitForVolumeAvailableStub = nil
+	if fake.waitForVolumeAvailableReturnsOnCall == nil {
+		fake.waitForVolumeAvailableReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.waitForVolumeAvailableReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextSession) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.attachVolumeMutex.RLock()
+	defer fake.attachVolumeMutex.RUnlock()
+	fake.authorizeVolumeMutex.RLock()
+	defer fake.authorizeVolumeMutex.RUnlock()
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	fake.createSnapshotMutex.RLock()
+	defer fake.createSnapshotMutex.RUnlock()
+	fake.createVolumeMutex.RLock()
+	defer fake.createVolumeMutex.RUnlock()
+	fake.createVolumeAccessPointMutex.RLock()
+	defer fake.createVolumeAccessPointMutex.RUnlock()
+	fake.createVolumeFromSnapshotMutex.RLock()
+	defer fake.createVolumeFromSnapshotMutex.RUnlock()
+	fake.deleteSnapshotMutex.RLock()
+	defer fake.deleteSnapshotMutex.RUnlock()
+	fake.deleteVolumeMutex.RLock()
+	defer fake.deleteVolumeMutex.RUnlock()
+	fake.deleteVolumeAccessPointMutex.RLock()
+	defer fake.deleteVolumeAccessPointMutex.RUnlock()
+	fake.detachVolumeMutex.RLock()
+	defer fake.detachVolumeMutex.RUnlock()
+	fake.expandVolumeMutex.RLock()
+	defer fake.expandVolumeMutex.RUnlock()
+	fake.getProviderDisplayNameMutex.RLock()
+	defer fake.getProviderDisplayNameMutex.RUnlock()
+	fake.getSecurityGroupForVolumeAccessPointMutex.RLock()
+	defer fake.getSecurityGroupForVolumeAccessPointMutex.RUnlock()
+	fake.getSnapshotMutex.RLock()
+	defer fake.getSnapshotMutex.RUnlock()
+	fake.getSnapshotByNameMutex.RLock()
+	defer fake.getSnapshotByNameMutex.RUnlock()
+	fake.getSubnetForVolumeAccessPointMutex.RLock()
+	defer fake.getSubnetForVolumeAccessPointMutex.RUnlock()
+	fake.getVolumeMutex.RLock()
+	defer fake.getVolumeMutex.RUnlock()
+	fake.getVolumeAccessPointMutex.RLock()
+	defer fake.getVolumeAccessPointMutex.RUnlock()
+	fake.getVolumeAttachmentMutex.RLock()
+	defer fake.getVolumeAttachmentMutex.RUnlock()
+	fake.getVolumeByNameMutex.RLock()
+	defer fake.getVolumeByNameMutex.RUnlock()
+	fake.getVolumeByRequestIDMutex.RLock()
+	defer fake.getVolumeByRequestIDMutex.RUnlock()
+	fake.getVolumeProfileByNameMutex.RLock()
+	defer fake.getVolumeProfileByNameMutex.RUnlock()
+	fake.listSnapshotsMutex.RLock()
+	defer fake.listSnapshotsMutex.RUnlock()
+	fake.listVolumeProfilesMutex.RLock()
+	defer fake.listVolumeProfilesMutex.RUnlock()
+	fake.listVolumesMutex.RLock()
+	defer fake.listVolumesMutex.RUnlock()
+	fake.providerNameMutex.RLock()
+	defer fake.providerNameMutex.RUnlock()
+	fake.typeMutex.RLock()
+	defer fake.typeMutex.RUnlock()
+	fake.updateVolumeMutex.RLock()
+	defer fake.updateVolumeMutex.RUnlock()
+	fake.waitForAttachVolumeMutex.RLock()
+	defer fake.waitForAttachVolumeMutex.RUnlock()
+	fake.waitForCreateVolumeAccessPointMutex.RLock()
+	defer fake.waitForCreateVolumeAccessPointMutex.RUnlock()
+	fake.waitForDeleteVolumeAccessPointMutex.RLock()
+	defer fake.waitForDeleteVolumeAccessPointMutex.RUnlock()
+	fake.waitForDetachVolumeMutex.RLock()
+	defer fake.waitForDetachVolumeMutex.RUnlock()
+	fake.waitForSnapshotReadyMutex.RLock()
+	defer fake.waitForSnapshotReadyMutex.RUnlock()
+	fake.waitForVolumeAvailableMutex.RLock()
+	defer fake.waitForVolumeAvailableMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeContextSession) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ provider.ContextSession = new(FakeContextSession)
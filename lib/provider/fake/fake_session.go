@@ -33,6 +33,20 @@ type FakeSession struct {
 	authorizeVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CloneVolumeStub        func(string, provider.Volume) (*provider.Volume, error)
+	cloneVolumeMutex       sync.RWMutex
+	cloneVolumeArgsForCall []struct {
+		arg1 string
+		arg2 provider.Volume
+	}
+	cloneVolumeReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	cloneVolumeReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
 	CloseStub        func()
 	closeMutex       sync.RWMutex
 	closeArgsForCall []struct {
@@ -309,6 +323,20 @@ type FakeSession struct {
 		result1 *provider.SnapshotList
 		result2 error
 	}
+	ListVolumeProfilesStub        func(int, string) (*provider.ProfileList, error)
+	listVolumeProfilesMutex       sync.RWMutex
+	listVolumeProfilesArgsForCall []struct {
+		arg1 int
+		arg2 string
+	}
+	listVolumeProfilesReturns struct {
+		result1 *provider.ProfileList
+		result2 error
+	}
+	listVolumeProfilesReturnsOnCall map[int]struct {
+		result1 *provider.ProfileList
+		result2 error
+	}
 	ListVolumesStub        func(int, string, map[string]string) (*provider.VolumeList, error)
 	listVolumesMutex       sync.RWMutex
 	listVolumesArgsForCall []struct {
@@ -403,6 +431,33 @@ type FakeSession struct {
 	waitForDetachVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	WaitForSnapshotReadyStub        func(string, ...string) (*provider.Snapshot, error)
+	waitForSnapshotReadyMutex       sync.RWMutex
+	waitForSnapshotReadyArgsForCall []struct {
+		arg1 string
+		arg2 []string
+	}
+	waitForSnapshotReadyReturns struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	waitForSnapshotReadyReturnsOnCall map[int]struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	WaitForVolumeAvailableStub        func(string) (*provider.Volume, error)
+	waitForVolumeAvailableMutex       sync.RWMutex
+	waitForVolumeAvailableArgsForCall []struct {
+		arg1 string
+	}
+	waitForVolumeAvailableReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	waitForVolumeAvailableReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -532,6 +587,71 @@ func (fake *FakeSession) AuthorizeVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeSession) CloneVolume(arg1 string, arg2 provider.Volume) (*provider.Volume, error) {
+	fake.cloneVolumeMutex.Lock()
+	ret, specificReturn := fake.cloneVolumeReturnsOnCall[len(fake.cloneVolumeArgsForCall)]
+	fake.cloneVolumeArgsForCall = append(fake.cloneVolumeArgsForCall, struct {
+		arg1 string
+		arg2 provider.Volume
+	}{arg1, arg2})
+	stub := fake.CloneVolumeStub
+	fakeReturns := fake.cloneVolumeReturns
+	fake.recordInvocation("CloneVolume", []interface{}{arg1, arg2})
+	fake.cloneVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSession) CloneVolumeCallCount() int {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	return len(fake.cloneVolumeArgsForCall)
+}
+
+func (fake *FakeSession) CloneVolumeCalls(stub func(string, provider.Volume) (*provider.Volume, error)) {
+	fake.cloneVolumeMutex.Lock()
+	defer fake.cloneVolumeMutex.Unlock()
+	fake.CloneVolumeStub = stub
+}
+
+func (fake *FakeSession) CloneVolumeArgsForCall(i int) (string, provider.Volume) {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	argsForCall := fake.cloneVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSession) CloneVolumeReturns(result1 *provider.Volume, result2 error) {
+	fake.cloneVolumeMutex.Lock()
+	defer fake.cloneVolumeMutex.Unlock()
+	fake.CloneVolumeStub = nil
+	fake.cloneVolumeReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSession) CloneVolumeReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.cloneVolumeMutex.Lock()
+	defer fake.cloneVolumeMutex.Unlock()
+	fake.CloneVolumeStub = nil
+	if fake.cloneVolumeReturnsOnCall == nil {
+		fake.cloneVolumeReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.cloneVolumeReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeSession) Close() {
 	fake.closeMutex.Lock()
 	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
@@ -1889,6 +2009,71 @@ func (fake *FakeSession) ListSnapshotsReturnsOnCall(i int, result1 *provider.Sna
 	}{result1, result2}
 }
 
+func (fake *FakeSession) ListVolumeProfiles(arg1 int, arg2 string) (*provider.ProfileList, error) {
+	fake.listVolumeProfilesMutex.Lock()
+	ret, specificReturn := fake.listVolumeProfilesReturnsOnCall[len(fake.listVolumeProfilesArgsForCall)]
+	fake.listVolumeProfilesArgsForCall = append(fake.listVolumeProfilesArgsForCall, struct {
+		arg1 int
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListVolumeProfilesStub
+	fakeReturns := fake.listVolumeProfilesReturns
+	fake.recordInvocation("ListVolumeProfiles", []interface{}{arg1, arg2})
+	fake.listVolumeProfilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSession) ListVolumeProfilesCallCount() int {
+	fake.listVolumeProfilesMutex.RLock()
+	defer fake.listVolumeProfilesMutex.RUnlock()
+	return len(fake.listVolumeProfilesArgsForCall)
+}
+
+func (fake *FakeSession) ListVolumeProfilesCalls(stub func(int, string) (*provider.ProfileList, error)) {
+	fake.listVolumeProfilesMutex.Lock()
+	defer fake.listVolumeProfilesMutex.Unlock()
+	fake.ListVolumeProfilesStub = stub
+}
+
+func (fake *FakeSession) ListVolumeProfilesArgsForCall(i int) (int, string) {
+	fake.listVolumeProfilesMutex.RLock()
+	defer fake.listVolumeProfilesMutex.RUnlock()
+	argsForCall := fake.listVolumeProfilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSession) ListVolumeProfilesReturns(result1 *provider.ProfileList, result2 error) {
+	fake.listVolumeProfilesMutex.Lock()
+	defer fake.listVolumeProfilesMutex.Unlock()
+	fake.ListVolumeProfilesStub = nil
+	fake.listVolumeProfilesReturns = struct {
+		result1 *provider.ProfileList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSession) ListVolumeProfilesReturnsOnCall(i int, result1 *provider.ProfileList, result2 error) {
+	fake.listVolumeProfilesMutex.Lock()
+	defer fake.listVolumeProfilesMutex.Unlock()
+	fake.ListVolumeProfilesStub = nil
+	if fake.listVolumeProfilesReturnsOnCall == nil {
+		fake.listVolumeProfilesReturnsOnCall = make(map[int]struct {
+			result1 *provider.ProfileList
+			result2 error
+		})
+	}
+	fake.listVolumeProfilesReturnsOnCall[i] = struct {
+		result1 *provider.ProfileList
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeSession) ListVolumes(arg1 int, arg2 string, arg3 map[string]string) (*provider.VolumeList, error) {
 	fake.listVolumesMutex.Lock()
 	ret, specificReturn := fake.listVolumesReturnsOnCall[len(fake.listVolumesArgsForCall)]
@@ -2372,6 +2557,135 @@ func (fake *FakeSession) WaitForDetachVolumeReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeSession) WaitForSnapshotReady(arg1 string, arg2 ...string) (*provider.Snapshot, error) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	ret, specificReturn := fake.waitForSnapshotReadyReturnsOnCall[len(fake.waitForSnapshotReadyArgsForCall)]
+	fake.waitForSnapshotReadyArgsForCall = append(fake.waitForSnapshotReadyArgsForCall, struct {
+		arg1 string
+		arg2 []string
+	}{arg1, arg2})
+	stub := fake.WaitForSnapshotReadyStub
+	fakeReturns := fake.waitForSnapshotReadyReturns
+	fake.recordInvocation("WaitForSnapshotReady", []interface{}{arg1, arg2})
+	fake.waitForSnapshotReadyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSession) WaitForSnapshotReadyCallCount() int {
+	fake.waitForSnapshotReadyMutex.RLock()
+	defer fake.waitForSnapshotReadyMutex.RUnlock()
+	return len(fake.waitForSnapshotReadyArgsForCall)
+}
+
+func (fake *FakeSession) WaitForSnapshotReadyCalls(stub func(string, ...string) (*provider.Snapshot, error)) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	defer fake.waitForSnapshotReadyMutex.Unlock()
+	fake.WaitForSnapshotReadyStub = stub
+}
+
+func (fake *FakeSession) WaitForSnapshotReadyArgsForCall(i int) (string, []string) {
+	fake.waitForSnapshotReadyMutex.RLock()
+	defer fake.waitForSnapshotReadyMutex.RUnlock()
+	argsForCall := fake.waitForSnapshotReadyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSession) WaitForSnapshotReadyReturns(result1 *provider.Snapshot, result2 error) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	defer fake.waitForSnapshotReadyMutex.Unlock()
+	fake.WaitForSnapshotReadyStub = nil
+	fake.waitForSnapshotReadyReturns = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSession) WaitForSnapshotReadyReturnsOnCall(i int, result1 *provider.Snapshot, result2 error) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	defer fake.waitForSnapshotReadyMutex.Unlock()
+	fake.WaitForSnapshotReadyStub = nil
+	if fake.waitForSnapshotReadyReturnsOnCall == nil {
+		fake.waitForSnapshotReadyReturnsOnCall = make(map[int]struct {
+			result1 *provider.Snapshot
+			result2 error
+		})
+	}
+	fake.waitForSnapshotReadyReturnsOnCall[i] = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSession) WaitForVolumeAvailable(arg1 string) (*provider.Volume, error) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	ret, specificReturn := fake.waitForVolumeAvailableReturnsOnCall[len(fake.waitForVolumeAvailableArgsForCall)]
+	fake.waitForVolumeAvailableArgsForCall = append(fake.waitForVolumeAvailableArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.WaitForVolumeAvailableStub
+	fakeReturns := fake.waitForVolumeAvailableReturns
+	fake.recordInvocation("WaitForVolumeAvailable", []interface{}{arg1})
+	fake.waitForVolumeAvailableMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSession) WaitForVolumeAvailableCallCount() int {
+	fake.waitForVolumeAvailableMutex.RLock()
+	defer fake.waitForVolumeAvailableMutex.RUnlock()
+	return len(fake.waitForVolumeAvailableArgsForCall)
+}
+
+func (fake *FakeSession) WaitForVolumeAvailableCalls(stub func(string) (*provider.Volume, error)) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	defer fake.waitForVolumeAvailableMutex.Unlock()
+	fake.WaitForVolumeAvailableStub = stub
+}
+
+func (fake *FakeSession) WaitForVolumeAvailableArgsForCall(i int) string {
+	fake.waitForVolumeAvailableMutex.RLock()
+	defer fake.waitForVolumeAvailableMutex.RUnlock()
+	argsForCall := fake.waitForVolumeAvailableArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSession) WaitForVolumeAvailableReturns(result1 *provider.Volume, result2 error) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	defer fake.waitForVolumeAvailableMutex.Unlock()
+	fake.WaitForVolumeAvailableStub = nil
+	fake.waitForVolumeAvailableReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSession) WaitForVolumeAvailableReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	defer fake.waitForVolumeAvailableMutex.Unlock()
+	fake.WaitForVolumeAvailableStub = nil
+	if fake.waitForVolumeAvailableReturnsOnCall == nil {
+		fake.waitForVolumeAvailableReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.waitForVolumeAvailableReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeSession) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -2379,6 +2693,8 @@ func (fake *FakeSession) Invocations() map[string][][]interface{} {
 	defer fake.attachVolumeMutex.RUnlock()
 	fake.authorizeVolumeMutex.RLock()
 	defer fake.authorizeVolumeMutex.RUnlock()
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
 	fake.closeMutex.RLock()
 	defer fake.closeMutex.RUnlock()
 	fake.createSnapshotMutex.RLock()
@@ -2423,6 +2739,8 @@ func (fake *FakeSession) Invocations() map[string][][]interface{} {
 	defer fake.getVolumeProfileByNameMutex.RUnlock()
 	fake.listSnapshotsMutex.RLock()
 	defer fake.listSnapshotsMutex.RUnlock()
+	fake.listVolumeProfilesMutex.RLock()
+	defer fake.listVolumeProfilesMutex.RUnlock()
 	fake.listVolumesMutex.RLock()
 	defer fake.listVolumesMutex.RUnlock()
 	fake.providerNameMutex.RLock()
@@ -2439,6 +2757,10 @@ func (fake *FakeSession) Invocations() map[string][][]interface{} {
 	defer fake.waitForDeleteVolumeAccessPointMutex.RUnlock()
 	fake.waitForDetachVolumeMutex.RLock()
 	defer fake.waitForDetachVolumeMutex.RUnlock()
+	fake.waitForSnapshotReadyMutex.RLock()
+	defer fake.waitForSnapshotReadyMutex.RUnlock()
+	fake.waitForVolumeAvailableMutex.RLock()
+	defer fake.waitForVolumeAvailableMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -33,6 +33,20 @@ type Context struct {
 	authorizeVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CloneVolumeStub        func(string, provider.Volume) (*provider.Volume, error)
+	cloneVolumeMutex       sync.RWMutex
+	cloneVolumeArgsForCall []struct {
+		arg1 string
+		arg2 provider.Volume
+	}
+	cloneVolumeReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	cloneVolumeReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
 	CreateSnapshotStub        func(string, provider.SnapshotParameters) (*provider.Snapshot, error)
 	createSnapshotMutex       sync.RWMutex
 	createSnapshotArgsForCall []struct {
@@ -295,6 +309,20 @@ type Context struct {
 		result1 *provider.SnapshotList
 		result2 error
 	}
+	ListVolumeProfilesStub        func(int, string) (*provider.ProfileList, error)
+	listVolumeProfilesMutex       sync.RWMutex
+	listVolumeProfilesArgsForCall []struct {
+		arg1 int
+		arg2 string
+	}
+	listVolumeProfilesReturns struct {
+		result1 *provider.ProfileList
+		result2 error
+	}
+	listVolumeProfilesReturnsOnCall map[int]struct {
+		result1 *provider.ProfileList
+		result2 error
+	}
 	ListVolumesStub        func(int, string, map[string]string) (*provider.VolumeList, error)
 	listVolumesMutex       sync.RWMutex
 	listVolumesArgsForCall []struct {
@@ -389,6 +417,33 @@ type Context struct {
 	waitForDetachVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	WaitForSnapshotReadyStub        func(string, ...string) (*provider.Snapshot, error)
+	waitForSnapshotReadyMutex       sync.RWMutex
+	waitForSnapshotReadyArgsForCall []struct {
+		arg1 string
+		arg2 []string
+	}
+	waitForSnapshotReadyReturns struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	waitForSnapshotReadyReturnsOnCall map[int]struct {
+		result1 *provider.Snapshot
+		result2 error
+	}
+	WaitForVolumeAvailableStub        func(string) (*provider.Volume, error)
+	waitForVolumeAvailableMutex       sync.RWMutex
+	waitForVolumeAvailableArgsForCall []struct {
+		arg1 string
+	}
+	waitForVolumeAvailableReturns struct {
+		result1 *provider.Volume
+		result2 error
+	}
+	waitForVolumeAvailableReturnsOnCall map[int]struct {
+		result1 *provider.Volume
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -518,6 +573,71 @@ func (fake *Context) AuthorizeVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Context) CloneVolume(arg1 string, arg2 provider.Volume) (*provider.Volume, error) {
+	fake.cloneVolumeMutex.Lock()
+	ret, specificReturn := fake.cloneVolumeReturnsOnCall[len(fake.cloneVolumeArgsForCall)]
+	fake.cloneVolumeArgsForCall = append(fake.cloneVolumeArgsForCall, struct {
+		arg1 string
+		arg2 provider.Volume
+	}{arg1, arg2})
+	stub := fake.CloneVolumeStub
+	fakeReturns := fake.cloneVolumeReturns
+	fake.recordInvocation("CloneVolume", []interface{}{arg1, arg2})
+	fake.cloneVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Context) CloneVolumeCallCount() int {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	return len(fake.cloneVolumeArgsForCall)
+}
+
+func (fake *Context) CloneVolumeCalls(stub func(string, provider.Volume) (*provider.Volume, error)) {
+	fake.cloneVolumeMutex.Lock()
+	defer fake.cloneVolumeMutex.Unlock()
+	fake.CloneVolumeStub = stub
+}
+
+func (fake *Context) CloneVolumeArgsForCall(i int) (string, provider.Volume) {
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
+	argsForCall := fake.cloneVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Context) CloneVolumeReturns(result1 *provider.Volume, result2 error) {
+	fake.cloneVolumeMutex.Lock()
+	defer fake.cloneVolumeMutex.Unlock()
+	fake.CloneVolumeStub = nil
+	fake.cloneVolumeReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Context) CloneVolumeReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.cloneVolumeMutex.Lock()
+	defer fake.cloneVolumeMutex.Unlock()
+	fake.CloneVolumeStub = nil
+	if fake.cloneVolumeReturnsOnCall == nil {
+		fake.cloneVolumeReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.cloneVolumeReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Context) CreateSnapshot(arg1 string, arg2 provider.SnapshotParameters) (*provider.Snapshot, error) {
 	fake.createSnapshotMutex.Lock()
 	ret, specificReturn := fake.createSnapshotReturnsOnCall[len(fake.createSnapshotArgsForCall)]
@@ -1798,6 +1918,71 @@ func (fake *Context) ListSnapshotsReturnsOnCall(i int, result1 *provider.Snapsho
 	}{result1, result2}
 }
 
+func (fake *Context) ListVolumeProfiles(arg1 int, arg2 string) (*provider.ProfileList, error) {
+	fake.listVolumeProfilesMutex.Lock()
+	ret, specificReturn := fake.listVolumeProfilesReturnsOnCall[len(fake.listVolumeProfilesArgsForCall)]
+	fake.listVolumeProfilesArgsForCall = append(fake.listVolumeProfilesArgsForCall, struct {
+		arg1 int
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListVolumeProfilesStub
+	fakeReturns := fake.listVolumeProfilesReturns
+	fake.recordInvocation("ListVolumeProfiles", []interface{}{arg1, arg2})
+	fake.listVolumeProfilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Context) ListVolumeProfilesCallCount() int {
+	fake.listVolumeProfilesMutex.RLock()
+	defer fake.listVolumeProfilesMutex.RUnlock()
+	return len(fake.listVolumeProfilesArgsForCall)
+}
+
+func (fake *Context) ListVolumeProfilesCalls(stub func(int, string) (*provider.ProfileList, error)) {
+	fake.listVolumeProfilesMutex.Lock()
+	defer fake.listVolumeProfilesMutex.Unlock()
+	fake.ListVolumeProfilesStub = stub
+}
+
+func (fake *Context) ListVolumeProfilesArgsForCall(i int) (int, string) {
+	fake.listVolumeProfilesMutex.RLock()
+	defer fake.listVolumeProfilesMutex.RUnlock()
+	argsForCall := fake.listVolumeProfilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Context) ListVolumeProfilesReturns(result1 *provider.ProfileList, result2 error) {
+	fake.listVolumeProfilesMutex.Lock()
+	defer fake.listVolumeProfilesMutex.Unlock()
+	fake.ListVolumeProfilesStub = nil
+	fake.listVolumeProfilesReturns = struct {
+		result1 *provider.ProfileList
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Context) ListVolumeProfilesReturnsOnCall(i int, result1 *provider.ProfileList, result2 error) {
+	fake.listVolumeProfilesMutex.Lock()
+	defer fake.listVolumeProfilesMutex.Unlock()
+	fake.ListVolumeProfilesStub = nil
+	if fake.listVolumeProfilesReturnsOnCall == nil {
+		fake.listVolumeProfilesReturnsOnCall = make(map[int]struct {
+			result1 *provider.ProfileList
+			result2 error
+		})
+	}
+	fake.listVolumeProfilesReturnsOnCall[i] = struct {
+		result1 *provider.ProfileList
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Context) ListVolumes(arg1 int, arg2 string, arg3 map[string]string) (*provider.VolumeList, error) {
 	fake.listVolumesMutex.Lock()
 	ret, specificReturn := fake.listVolumesReturnsOnCall[len(fake.listVolumesArgsForCall)]
@@ -2281,6 +2466,135 @@ func (fake *Context) WaitForDetachVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Context) WaitForSnapshotReady(arg1 string, arg2 ...string) (*provider.Snapshot, error) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	ret, specificReturn := fake.waitForSnapshotReadyReturnsOnCall[len(fake.waitForSnapshotReadyArgsForCall)]
+	fake.waitForSnapshotReadyArgsForCall = append(fake.waitForSnapshotReadyArgsForCall, struct {
+		arg1 string
+		arg2 []string
+	}{arg1, arg2})
+	stub := fake.WaitForSnapshotReadyStub
+	fakeReturns := fake.waitForSnapshotReadyReturns
+	fake.recordInvocation("WaitForSnapshotReady", []interface{}{arg1, arg2})
+	fake.waitForSnapshotReadyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Context) WaitForSnapshotReadyCallCount() int {
+	fake.waitForSnapshotReadyMutex.RLock()
+	defer fake.waitForSnapshotReadyMutex.RUnlock()
+	return len(fake.waitForSnapshotReadyArgsForCall)
+}
+
+func (fake *Context) WaitForSnapshotReadyCalls(stub func(string, ...string) (*provider.Snapshot, error)) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	defer fake.waitForSnapshotReadyMutex.Unlock()
+	fake.WaitForSnapshotReadyStub = stub
+}
+
+func (fake *Context) WaitForSnapshotReadyArgsForCall(i int) (string, []string) {
+	fake.waitForSnapshotReadyMutex.RLock()
+	defer fake.waitForSnapshotReadyMutex.RUnlock()
+	argsForCall := fake.waitForSnapshotReadyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Context) WaitForSnapshotReadyReturns(result1 *provider.Snapshot, result2 error) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	defer fake.waitForSnapshotReadyMutex.Unlock()
+	fake.WaitForSnapshotReadyStub = nil
+	fake.waitForSnapshotReadyReturns = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Context) WaitForSnapshotReadyReturnsOnCall(i int, result1 *provider.Snapshot, result2 error) {
+	fake.waitForSnapshotReadyMutex.Lock()
+	defer fake.waitForSnapshotReadyMutex.Unlock()
+	fake.WaitForSnapshotReadyStub = nil
+	if fake.waitForSnapshotReadyReturnsOnCall == nil {
+		fake.waitForSnapshotReadyReturnsOnCall = make(map[int]struct {
+			result1 *provider.Snapshot
+			result2 error
+		})
+	}
+	fake.waitForSnapshotReadyReturnsOnCall[i] = struct {
+		result1 *provider.Snapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Context) WaitForVolumeAvailable(arg1 string) (*provider.Volume, error) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	ret, specificReturn := fake.waitForVolumeAvailableReturnsOnCall[len(fake.waitForVolumeAvailableArgsForCall)]
+	fake.waitForVolumeAvailableArgsForCall = append(fake.waitForVolumeAvailableArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.WaitForVolumeAvailableStub
+	fakeReturns := fake.waitForVolumeAvailableReturns
+	fake.recordInvocation("WaitForVolumeAvailable", []interface{}{arg1})
+	fake.waitForVolumeAvailableMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Context) WaitForVolumeAvailableCallCount() int {
+	fake.waitForVolumeAvailableMutex.RLock()
+	defer fake.waitForVolumeAvailableMutex.RUnlock()
+	return len(fake.waitForVolumeAvailableArgsForCall)
+}
+
+func (fake *Context) WaitForVolumeAvailableCalls(stub func(string) (*provider.Volume, error)) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	defer fake.waitForVolumeAvailableMutex.Unlock()
+	fake.WaitForVolumeAvailableStub = stub
+}
+
+func (fake *Context) WaitForVolumeAvailableArgsForCall(i int) string {
+	fake.waitForVolumeAvailableMutex.RLock()
+	defer fake.waitForVolumeAvailableMutex.RUnlock()
+	argsForCall := fake.waitForVolumeAvailableArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Context) WaitForVolumeAvailableReturns(result1 *provider.Volume, result2 error) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	defer fake.waitForVolumeAvailableMutex.Unlock()
+	fake.WaitForVolumeAvailableStub = nil
+	fake.waitForVolumeAvailableReturns = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Context) WaitForVolumeAvailableReturnsOnCall(i int, result1 *provider.Volume, result2 error) {
+	fake.waitForVolumeAvailableMutex.Lock()
+	defer fake.waitForVolumeAvailableMutex.Unlock()
+	fake.WaitForVolumeAvailableStub = nil
+	if fake.waitForVolumeAvailableReturnsOnCall == nil {
+		fake.waitForVolumeAvailableReturnsOnCall = make(map[int]struct {
+			result1 *provider.Volume
+			result2 error
+		})
+	}
+	fake.waitForVolumeAvailableReturnsOnCall[i] = struct {
+		result1 *provider.Volume
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Context) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -2288,6 +2602,8 @@ func (fake *Context) Invocations() map[string][][]interface{} {
 	defer fake.attachVolumeMutex.RUnlock()
 	fake.authorizeVolumeMutex.RLock()
 	defer fake.authorizeVolumeMutex.RUnlock()
+	fake.cloneVolumeMutex.RLock()
+	defer fake.cloneVolumeMutex.RUnlock()
 	fake.createSnapshotMutex.RLock()
 	defer fake.createSnapshotMutex.RUnlock()
 	fake.createVolumeMutex.RLock()
@@ -2328,6 +2644,8 @@ func (fake *Context) Invocations() map[string][][]interface{} {
 	defer fake.getVolumeProfileByNameMutex.RUnlock()
 	fake.listSnapshotsMutex.RLock()
 	defer fake.listSnapshotsMutex.RUnlock()
+	fake.listVolumeProfilesMutex.RLock()
+	defer fake.listVolumeProfilesMutex.RUnlock()
 	fake.listVolumesMutex.RLock()
 	defer fake.listVolumesMutex.RUnlock()
 	fake.providerNameMutex.RLock()
@@ -2344,6 +2662,10 @@ func (fake *Context) Invocations() map[string][][]interface{} {
 	defer fake.waitForDeleteVolumeAccessPointMutex.RUnlock()
 	fake.waitForDetachVolumeMutex.RLock()
 	defer fake.waitForDetachVolumeMutex.RUnlock()
+	fake.waitForSnapshotReadyMutex.RLock()
+	defer fake.waitForSnapshotReadyMutex.RUnlock()
+	fake.waitForVolumeAvailableMutex.RLock()
+	defer fake.waitForVolumeAvailableMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,169 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/metrics"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/tracing"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// Recovery converts a panic in the rest of the chain into a provider.Error with
+// reasoncode.ErrorPanic. It should be the outermost middleware.
+func Recovery(logger *zap.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("Recovered from panic in provider session", zap.String("method", call.Method),
+						zap.String("provider", string(call.Provider)), zap.Any("panic", r), zap.ByteString("stack", debug.Stack()))
+					err = util.NewErrorWithProperties(reasoncode.ErrorPanic, fmt.Sprintf("%s panicked: %v", call.Method, r),
+						map[string]string{"method": call.Method, "provider": string(call.Provider)})
+				}
+			}()
+			return next(call)
+		}
+	}
+}
+
+// Logging logs entry to and exit from every call, including the time taken and any error
+func Logging(logger *zap.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) error {
+			callLogger := logger.With(zap.String("method", call.Method), zap.String("provider", string(call.Provider)))
+			callLogger.Info("Entry", zap.Reflect("args", call.Args))
+			start := time.Now()
+			err := next(call)
+			if err != nil {
+				callLogger.Error("Exit with error", zap.Duration("duration", time.Since(start)), util.ZapError(err))
+				return err
+			}
+			callLogger.Info("Exit", zap.Duration("duration", time.Since(start)))
+			return nil
+		}
+	}
+}
+
+// Metrics records every call with the recorder, metrics.DefaultRecorder if nil: its duration and
+// outcome, any error by type and reason code, and the number of calls in flight, labelled by method
+// name and provider
+func Metrics(recorder *metrics.Recorder) Middleware {
+	if recorder == nil {
+		recorder = metrics.DefaultRecorder
+	}
+	return func(next Handler) Handler {
+		return func(call *Call) (err error) {
+			done := recorder.Start(call.Method, string(call.Provider))
+			defer func() {
+				if r := recover(); r != nil {
+					done(util.NewError(reasoncode.ErrorPanic, fmt.Sprintf("%s panicked: %v", call.Method, r)))
+					panic(r)
+				}
+				done(err)
+			}()
+			return next(call)
+		}
+	}
+}
+
+// Tracing starts a span for every call, as a child of the span in the Call's Context, with the provider
+// name, the volume and instance IDs found in the arguments and the request ID in the Context as attributes.
+// Spans go to the global OpenTelemetry TracerProvider, see package tracing.
+func Tracing() Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) (err error) {
+			attrs := append(callAttributes(call), tracing.ProviderKey.String(string(call.Provider)))
+			parent := call.Context
+			ctx, span := tracing.Start(parent, "Session."+call.Method, attrs...)
+			call.Context = ctx
+			defer func() {
+				call.Context = parent
+				if r := recover(); r != nil {
+					tracing.End(span, util.NewError(reasoncode.ErrorPanic, fmt.Sprintf("%s panicked: %v", call.Method, r)))
+					panic(r)
+				}
+				tracing.End(span, err)
+			}()
+			return next(call)
+		}
+	}
+}
+
+// callAttributes returns the volume and instance IDs found in the arguments of the call
+func callAttributes(call *Call) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	volumeID := func(id string) {
+		if id != "" {
+			attrs = append(attrs, tracing.VolumeIDKey.String(id))
+		}
+	}
+	for i, arg := range call.Args {
+		switch arg := arg.(type) {
+		case provider.Volume:
+			volumeID(arg.VolumeID)
+		case *provider.Volume:
+			if arg != nil {
+				volumeID(arg.VolumeID)
+			}
+		case provider.VolumeAttachmentRequest:
+			volumeID(arg.VolumeID)
+			if arg.InstanceID != "" {
+				attrs = append(attrs, tracing.InstanceIDKey.String(arg.InstanceID))
+			}
+		case provider.VolumeAccessPointRequest:
+			volumeID(arg.VolumeID)
+		case provider.ExpandVolumeRequest:
+			volumeID(arg.VolumeID)
+		case string:
+			// The volume ID is the first argument of GetVolume and CreateSnapshot
+			if i == 0 && (call.Method == "GetVolume" || call.Method == "CreateSnapshot") {
+				volumeID(arg)
+			}
+		}
+	}
+	return attrs
+}
+
+// Retry retries a failed call with the retrier while retryable reports the error as retryable.
+// It stops retrying when the Context of the call is done. Each attempt is made with the context
+// of its retrier span, so that the spans of the attempt are its children.
+func Retry(retrier *util.ErrorRetrier, retryable func(call *Call, err error) bool) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) error {
+			ctx := call.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			defer func(callCtx context.Context) { call.Context = callCtx }(call.Context)
+			return retrier.ErrorRetryWithAttemptContext(ctx, func(attemptCtx context.Context) (error, bool) {
+				call.Context = attemptCtx
+				err := next(call)
+				return err, err != nil && !retryable(call, err)
+			})
+		}
+	}
+}
@@ -0,0 +1,175 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/metrics"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRecovery(t *testing.T) {
+	handler := Recovery(zap.NewNop())(func(call *Call) error {
+		panic("provider bug")
+	})
+	err := handler(&Call{Method: "GetVolume", Provider: "vpc"})
+	assert.Equal(t, reasoncode.ErrorPanic, util.ErrorReasonCode(err))
+	assert.Equal(t, "GetVolume panicked: provider bug", err.Error())
+	assert.Equal(t, map[string]string{"method": "GetVolume", "provider": "vpc"}, err.(provider.Error).Properties())
+
+	handler = Recovery(zap.NewNop())(func(call *Call) error { return nil })
+	assert.NoError(t, handler(&Call{Method: "GetVolume"}))
+}
+
+func TestLogging(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	handler := Logging(zap.New(core))(func(call *Call) error {
+		if call.Args[0] == "missing" {
+			return util.NewError(reasoncode.ErrorBadRequest, "volume not found")
+		}
+		return nil
+	})
+
+	assert.NoError(t, handler(&Call{Method: "GetVolume", Provider: "vpc", Args: []interface{}{"vol"}}))
+	assert.Error(t, handler(&Call{Method: "GetVolume", Provider: "vpc", Args: []interface{}{"missing"}}))
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 4)
+	assert.Equal(t, "Entry", entries[0].Message)
+	assert.Equal(t, "GetVolume", entries[0].ContextMap()["method"])
+	assert.Equal(t, "Exit", entries[1].Message)
+	assert.Equal(t, "Exit with error", entries[3].Message)
+	assert.Equal(t, zapcore.ErrorLevel, entries[3].Level)
+	assert.Contains(t, entries[3].ContextMap(), "error")
+}
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewRecorder()
+	assert.NoError(t, recorder.Register(registry))
+
+	expected := errors.New("failed")
+	handler := Metrics(recorder)(func(call *Call) error { return expected })
+	assert.Equal(t, expected, handler(&Call{Method: "GetVolume", Provider: "vpc"}))
+
+	handler = Metrics(recorder)(func(call *Call) error { return nil })
+	assert.NoError(t, handler(&Call{Method: "GetVolume", Provider: "vpc"}))
+
+	handler = Metrics(recorder)(func(call *Call) error { panic("provider bug") })
+	assert.Panics(t, func() { _ = handler(&Call{Method: "GetVolume", Provider: "vpc"}) })
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP ibmcloud_storage_volume_lib_functions_in_flight The number of library operation in progress.
+# TYPE ibmcloud_storage_volume_lib_functions_in_flight gauge
+ibmcloud_storage_volume_lib_functions_in_flight{function="GetVolume",provider="vpc"} 0
+# HELP ibmcloud_storage_volume_lib_functions_total The number of library operation completed, successfully or not.
+# TYPE ibmcloud_storage_volume_lib_functions_total counter
+ibmcloud_storage_volume_lib_functions_total{function="GetVolume",outcome="error",provider="vpc"} 2
+ibmcloud_storage_volume_lib_functions_total{function="GetVolume",outcome="success",provider="vpc"} 1
+# HELP ibmcloud_storage_volume_lib_errors_total The number of library operation failed due to an error.
+# TYPE ibmcloud_storage_volume_lib_errors_total counter
+ibmcloud_storage_volume_lib_errors_total{function="GetVolume",provider="vpc",reason_code="ErrorPanic",type="ErrorTypeConversionFailed"} 1
+ibmcloud_storage_volume_lib_errors_total{function="GetVolume",provider="vpc",reason_code="ErrorUnclassified",type="ErrorTypeConversionFailed"} 1
+`), "ibmcloud_storage_volume_lib_functions_in_flight", "ibmcloud_storage_volume_lib_functions_total", "ibmcloud_storage_volume_lib_errors_total"))
+}
+
+func TestRetry(t *testing.T) {
+	retrier := util.NewErrorRetrier(3, time.Millisecond, zap.NewNop())
+	temporary := util.NewError(reasoncode.ErrorTemporaryConnectionProblem, "reset")
+	retryable := func(call *Call, err error) bool {
+		return util.ErrorReasonCode(err) == reasoncode.ErrorTemporaryConnectionProblem
+	}
+
+	attempts := 0
+	err := Retry(retrier, retryable)(func(call *Call) error {
+		attempts++
+		if attempts < 2 {
+			return temporary
+		}
+		return nil
+	})(&Call{Method: "GetVolume"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	attempts = 0
+	err = Retry(retrier, retryable)(func(call *Call) error {
+		attempts++
+		return temporary
+	})(&Call{Method: "GetVolume"})
+	assert.Equal(t, temporary, err)
+	assert.Equal(t, 3, attempts)
+
+	// Each attempt is made with the context of its attempt span, and the context of the call is restored
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	call := &Call{Context: ctx, Method: "GetVolume"}
+	err = Retry(retrier, retryable)(func(call *Call) error {
+		assert.NotEqual(t, ctx, call.Context)
+		assert.Equal(t, "value", call.Context.Value(ctxKey{}))
+		return nil
+	})(call)
+	assert.NoError(t, err)
+	assert.Equal(t, ctx, call.Context)
+
+	attempts = 0
+	fatal := util.NewError(reasoncode.ErrorBadRequest, "bad request")
+	err = Retry(retrier, retryable)(func(call *Call) error {
+		attempts++
+		return fatal
+	})(&Call{Method: "GetVolume"})
+	assert.Equal(t, fatal, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	retrier := util.NewErrorRetrier(10, time.Hour, zap.NewNop())
+	temporary := util.NewError(reasoncode.ErrorTemporaryConnectionProblem, "reset")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	attempts := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Retry(retrier, func(call *Call, err error) bool { return true })(func(call *Call) error {
+			attempts++
+			cancel()
+			return temporary
+		})(&Call{Context: ctx, Method: "GetVolume"})
+	}()
+
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, reasoncode.ErrorTemporaryConnectionProblem, util.ErrorReasonCode(err))
+		assert.Equal(t, 1, attempts)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Retry kept retrying after the context was cancelled")
+	}
+}
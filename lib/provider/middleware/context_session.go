@@ -0,0 +1,341 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+)
+
+// contextSession is a provider.ContextSession whose calls pass through a middleware chain
+type contextSession struct {
+	next  provider.ContextSession
+	chain Middleware
+}
+
+var _ provider.ContextSession = &contextSession{}
+
+// WrapContextSession returns a provider.ContextSession which passes every volume, attachment, snapshot
+// and access point call of cs through the middlewares, the first being the outermost. ProviderName,
+// Type, GetProviderDisplayName and Close are delegated directly.
+func WrapContextSession(cs provider.ContextSession, middlewares ...Middleware) provider.ContextSession {
+	return &contextSession{next: cs, chain: Chain(middlewares...)}
+}
+
+// invoke runs call through the chain
+func (s *contextSession) invoke(ctx context.Context, method string, call func(call *Call) error, args ...interface{}) error {
+	return s.chain(call)(&Call{Context: ctx, Method: method, Provider: s.next.ProviderName(), Args: args})
+}
+
+// ProviderName returns provider
+func (s *contextSession) ProviderName() provider.VolumeProvider {
+	return s.next.ProviderName()
+}
+
+// Type returns the underlying volume type
+func (s *contextSession) Type() provider.VolumeType {
+	return s.next.Type()
+}
+
+// GetProviderDisplayName returns the name of the provider that is being used
+func (s *contextSession) GetProviderDisplayName() provider.VolumeProvider {
+	return s.next.GetProviderDisplayName()
+}
+
+// Close is called when the ContextSession is nolonger required
+func (s *contextSession) Close() {
+	s.next.Close()
+}
+
+// GetVolumeProfileByName gets volume profile by name
+func (s *contextSession) GetVolumeProfileByName(ctx context.Context, name string) (profile *provider.Profile, err error) {
+	err = s.invoke(ctx, "GetVolumeProfileByName", func(call *Call) error {
+		profile, err = s.next.GetVolumeProfileByName(call.Context, name)
+		return err
+	}, name)
+	return profile, err
+}
+
+// ListVolumeProfiles lists the volume profiles
+func (s *contextSession) ListVolumeProfiles(ctx context.Context, limit int, start string) (profiles *provider.ProfileList, err error) {
+	err = s.invoke(ctx, "ListVolumeProfiles", func(call *Call) error {
+		profiles, err = s.next.ListVolumeProfiles(call.Context, limit, start)
+		return err
+	}, limit, start)
+	return profiles, err
+}
+
+// CreateVolume creates a volume
+func (s *contextSession) CreateVolume(ctx context.Context, volumeRequest provider.Volume) (volume *provider.Volume, err error) {
+	err = s.invoke(ctx, "CreateVolume", func(call *Call) error {
+		volume, err = s.next.CreateVolume(call.Context, volumeRequest)
+		return err
+	}, volumeRequest)
+	return volume, err
+}
+
+// CreateVolumeFromSnapshot creates a volume from snapshot
+func (s *contextSession) CreateVolumeFromSnapshot(ctx context.Context, snapshot provider.Snapshot, tags map[string]string) (volume *provider.Volume, err error) {
+	err = s.invoke(ctx, "CreateVolumeFromSnapshot", func(call *Call) error {
+		volume, err = s.next.CreateVolumeFromSnapshot(call.Context, snapshot, tags)
+		return err
+	}, snapshot, tags)
+	return volume, err
+}
+
+// UpdateVolume updates the volume
+func (s *contextSession) UpdateVolume(ctx context.Context, volume provider.Volume) error {
+	return s.invoke(ctx, "UpdateVolume", func(call *Call) error {
+		return s.next.UpdateVolume(call.Context, volume)
+	}, volume)
+}
+
+// DeleteVolume deletes the volume
+func (s *contextSession) DeleteVolume(ctx context.Context, volume *provider.Volume) error {
+	return s.invoke(ctx, "DeleteVolume", func(call *Call) error {
+		return s.next.DeleteVolume(call.Context, volume)
+	}, volume)
+}
+
+// GetVolume by using ID
+func (s *contextSession) GetVolume(ctx context.Context, id string) (volume *provider.Volume, err error) {
+	err = s.invoke(ctx, "GetVolume", func(call *Call) error {
+		volume, err = s.next.GetVolume(call.Context, id)
+		return err
+	}, id)
+	return volume, err
+}
+
+// GetVolumeByName gets volume by name
+func (s *contextSession) GetVolumeByName(ctx context.Context, name string) (volume *provider.Volume, err error) {
+	err = s.invoke(ctx, "GetVolumeByName", func(call *Call) error {
+		volume, err = s.next.GetVolumeByName(call.Context, name)
+		return err
+	}, name)
+	return volume, err
+}
+
+// ListVolumes Get volume lists by using filters
+func (s *contextSession) ListVolumes(ctx context.Context, limit int, start string, tags map[string]string) (volumes *provider.VolumeList, err error) {
+	err = s.invoke(ctx, "ListVolumes", func(call *Call) error {
+		volumes, err = s.next.ListVolumes(call.Context, limit, start, tags)
+		return err
+	}, limit, start, tags)
+	return volumes, err
+}
+
+// GetVolumeByRequestID fetch the volume by request ID
+func (s *contextSession) GetVolumeByRequestID(ctx context.Context, requestID string) (volume *provider.Volume, err error) {
+	err = s.invoke(ctx, "GetVolumeByRequestID", func(call *Call) error {
+		volume, err = s.next.GetVolumeByRequestID(call.Context, requestID)
+		return err
+	}, requestID)
+	return volume, err
+}
+
+// AuthorizeVolume allows aceess to volume  based on given authorization
+func (s *contextSession) AuthorizeVolume(ctx context.Context, volumeAuthorization provider.VolumeAuthorization) error {
+	return s.invoke(ctx, "AuthorizeVolume", func(call *Call) error {
+		return s.next.AuthorizeVolume(call.Context, volumeAuthorization)
+	}, volumeAuthorization)
+}
+
+// ExpandVolume expands the volume
+func (s *contextSession) ExpandVolume(ctx context.Context, expandVolumeRequest provider.ExpandVolumeRequest) (capacity int64, err error) {
+	err = s.invoke(ctx, "ExpandVolume", func(call *Call) error {
+		capacity, err = s.next.ExpandVolume(call.Context, expandVolumeRequest)
+		return err
+	}, expandVolumeRequest)
+	return capacity, err
+}
+
+// WaitForVolumeAvailable waits for the volume to become available
+func (s *contextSession) WaitForVolumeAvailable(ctx context.Context, volumeID string) (volume *provider.Volume, err error) {
+	err = s.invoke(ctx, "WaitForVolumeAvailable", func(call *Call) error {
+		volume, err = s.next.WaitForVolumeAvailable(call.Context, volumeID)
+		return err
+	}, volumeID)
+	return volume, err
+}
+
+// CloneVolume clones the volume
+func (s *contextSession) CloneVolume(ctx context.Context, sourceVolumeID string, template provider.Volume) (volume *provider.Volume, err error) {
+	err = s.invoke(ctx, "CloneVolume", func(call *Call) error {
+		volume, err = s.next.CloneVolume(call.Context, sourceVolumeID, template)
+		return err
+	}, sourceVolumeID, template)
+	return volume, err
+}
+
+// AttachVolume attaches a volume
+func (s *contextSession) AttachVolume(ctx context.Context, attachRequest provider.VolumeAttachmentRequest) (attachment *provider.VolumeAttachmentResponse, err error) {
+	err = s.invoke(ctx, "AttachVolume", func(call *Call) error {
+		attachment, err = s.next.AttachVolume(call.Context, attachRequest)
+		return err
+	}, attachRequest)
+	return attachment, err
+}
+
+// DetachVolume detaches the volume
+func (s *contextSession) DetachVolume(ctx context.Context, detachRequest provider.VolumeAttachmentRequest) (response *http.Response, err error) {
+	err = s.invoke(ctx, "DetachVolume", func(call *Call) error {
+		response, err = s.next.DetachVolume(call.Context, detachRequest)
+		return err
+	}, detachRequest)
+	return response, err
+}
+
+// WaitForAttachVolume waits for the volume to be attached to the host
+func (s *contextSession) WaitForAttachVolume(ctx context.Context, attachRequest provider.VolumeAttachmentRequest) (attachment *provider.VolumeAttachmentResponse, err error) {
+	err = s.invoke(ctx, "WaitForAttachVolume", func(call *Call) error {
+		attachment, err = s.next.WaitForAttachVolume(call.Context, attachRequest)
+		return err
+	}, attachRequest)
+	return attachment, err
+}
+
+// WaitForDetachVolume waits for the volume to be detached from the host
+func (s *contextSession) WaitForDetachVolume(ctx context.Context, detachRequest provider.VolumeAttachmentRequest) error {
+	return s.invoke(ctx, "WaitForDetachVolume", func(call *Call) error {
+		return s.next.WaitForDetachVolume(call.Context, detachRequest)
+	}, detachRequest)
+}
+
+// GetVolumeAttachment retirves the current status of given volume attach request
+func (s *contextSession) GetVolumeAttachment(ctx context.Context, attachRequest provider.VolumeAttachmentRequest) (attachment *provider.VolumeAttachmentResponse, err error) {
+	err = s.invoke(ctx, "GetVolumeAttachment", func(call *Call) error {
+		attachment, err = s.next.GetVolumeAttachment(call.Context, attachRequest)
+		return err
+	}, attachRequest)
+	return attachment, err
+}
+
+// CreateSnapshot creates the snapshot on the volume
+func (s *contextSession) CreateSnapshot(ctx context.Context, sourceVolumeID string, snapshotParameters provider.SnapshotParameters) (snapshot *provider.Snapshot, err error) {
+	err = s.invoke(ctx, "CreateSnapshot", func(call *Call) error {
+		snapshot, err = s.next.CreateSnapshot(call.Context, sourceVolumeID, snapshotParameters)
+		return err
+	}, sourceVolumeID, snapshotParameters)
+	return snapshot, err
+}
+
+// DeleteSnapshot deletes the snapshot
+func (s *contextSession) DeleteSnapshot(ctx context.Context, snapshot *provider.Snapshot) error {
+	return s.invoke(ctx, "DeleteSnapshot", func(call *Call) error {
+		return s.next.DeleteSnapshot(call.Context, snapshot)
+	}, snapshot)
+}
+
+// GetSnapshot gets the snapshot
+func (s *contextSession) GetSnapshot(ctx context.Context, snapshotID string, sourceVolumeID ...string) (snapshot *provider.Snapshot, err error) {
+	err = s.invoke(ctx, "GetSnapshot", func(call *Call) error {
+		snapshot, err = s.next.GetSnapshot(call.Context, snapshotID, sourceVolumeID...)
+		return err
+	}, snapshotID, sourceVolumeID)
+	return snapshot, err
+}
+
+// GetSnapshotByName gets the snapshot by name
+func (s *contextSession) GetSnapshotByName(ctx context.Context, snapshotName string, sourceVolumeID ...string) (snapshot *provider.Snapshot, err error) {
+	err = s.invoke(ctx, "GetSnapshotByName", func(call *Call) error {
+		snapshot, err = s.next.GetSnapshotByName(call.Context, snapshotName, sourceVolumeID...)
+		return err
+	}, snapshotName, sourceVolumeID)
+	return snapshot, err
+}
+
+// ListSnapshots lists snapshots by using tags
+func (s *contextSession) ListSnapshots(ctx context.Context, limit int, start string, tags map[string]string) (snapshots *provider.SnapshotList, err error) {
+	err = s.invoke(ctx, "ListSnapshots", func(call *Call) error {
+		snapshots, err = s.next.ListSnapshots(call.Context, limit, start, tags)
+		return err
+	}, limit, start, tags)
+	return snapshots, err
+}
+
+// WaitForSnapshotReady waits for the snapshot to be ReadyToUse
+func (s *contextSession) WaitForSnapshotReady(ctx context.Context, snapshotID string, sourceVolumeID ...string) (snapshot *provider.Snapshot, err error) {
+	err = s.invoke(ctx, "WaitForSnapshotReady", func(call *Call) error {
+		snapshot, err = s.next.WaitForSnapshotReady(call.Context, snapshotID, sourceVolumeID...)
+		return err
+	}, snapshotID, sourceVolumeID)
+	return snapshot, err
+}
+
+// CreateVolumeAccessPoint creates a volume access point
+func (s *contextSession) CreateVolumeAccessPoint(ctx context.Context, accessPointRequest provider.VolumeAccessPointRequest) (accessPoint *provider.VolumeAccessPointResponse, err error) {
+	err = s.invoke(ctx, "CreateVolumeAccessPoint", func(call *Call) error {
+		accessPoint, err = s.next.CreateVolumeAccessPoint(call.Context, accessPointRequest)
+		return err
+	}, accessPointRequest)
+	return accessPoint, err
+}
+
+// DeleteVolumeAccessPoint deletes a volume access point
+func (s *contextSession) DeleteVolumeAccessPoint(ctx context.Context, deleteAccessPointRequest provider.VolumeAccessPointRequest) (response *http.Response, err error) {
+	err = s.invoke(ctx, "DeleteVolumeAccessPoint", func(call *Call) error {
+		response, err = s.next.DeleteVolumeAccessPoint(call.Context, deleteAccessPointRequest)
+		return err
+	}, deleteAccessPointRequest)
+	return response, err
+}
+
+// WaitForCreateVolumeAccessPoint waits for the volume access point to be created
+func (s *contextSession) WaitForCreateVolumeAccessPoint(ctx context.Context, accessPointRequest provider.VolumeAccessPointRequest) (accessPoint *provider.VolumeAccessPointResponse, err error) {
+	err = s.invoke(ctx, "WaitForCreateVolumeAccessPoint", func(call *Call) error {
+		accessPoint, err = s.next.WaitForCreateVolumeAccessPoint(call.Context, accessPointRequest)
+		return err
+	}, accessPointRequest)
+	return accessPoint, err
+}
+
+// WaitForDeleteVolumeAccessPoint waits for the volume access point to be deleted
+func (s *contextSession) WaitForDeleteVolumeAccessPoint(ctx context.Context, deleteAccessPointRequest provider.VolumeAccessPointRequest) error {
+	return s.invoke(ctx, "WaitForDeleteVolumeAccessPoint", func(call *Call) error {
+		return s.next.WaitForDeleteVolumeAccessPoint(call.Context, deleteAccessPointRequest)
+	}, deleteAccessPointRequest)
+}
+
+// GetVolumeAccessPoint retrieves the volume access point
+func (s *contextSession) GetVolumeAccessPoint(ctx context.Context, accessPointRequest provider.VolumeAccessPointRequest) (accessPoint *provider.VolumeAccessPointResponse, err error) {
+	err = s.invoke(ctx, "GetVolumeAccessPoint", func(call *Call) error {
+		accessPoint, err = s.next.GetVolumeAccessPoint(call.Context, accessPointRequest)
+		return err
+	}, accessPointRequest)
+	return accessPoint, err
+}
+
+// GetSubnetForVolumeAccessPoint returns the subnet for the volume access point
+func (s *contextSession) GetSubnetForVolumeAccessPoint(ctx context.Context, subnetRequest provider.SubnetRequest) (subnet string, err error) {
+	err = s.invoke(ctx, "GetSubnetForVolumeAccessPoint", func(call *Call) error {
+		subnet, err = s.next.GetSubnetForVolumeAccessPoint(call.Context, subnetRequest)
+		return err
+	}, subnetRequest)
+	return subnet, err
+}
+
+// GetSecurityGroupForVolumeAccessPoint returns the security group for the volume access point
+func (s *contextSession) GetSecurityGroupForVolumeAccessPoint(ctx context.Context, securityGroupRequest provider.SecurityGroupRequest) (securityGroup string, err error) {
+	err = s.invoke(ctx, "GetSecurityGroupForVolumeAccessPoint", func(call *Call) error {
+		securityGroup, err = s.next.GetSecurityGroupForVolumeAccessPoint(call.Context, securityGroupRequest)
+		return err
+	}, securityGroupRequest)
+	return securityGroup, err
+}
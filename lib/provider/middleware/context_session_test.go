@@ -0,0 +1,131 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/fake"
+	"github.com/IBM/ibmcloud-volume-interface/lib/tracing"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+func TestWrapContextSession(t *testing.T) {
+	fakeSession := &fake.FakeContextSession{}
+	fakeSession.ProviderNameReturns("vpc")
+	fakeSession.GetVolumeReturns(&provider.Volume{VolumeID: "vol"}, nil)
+
+	type ctxKey struct{}
+	replaceContext := func(next Handler) Handler {
+		return func(call *Call) error {
+			call.Context = context.WithValue(call.Context, ctxKey{}, "replaced")
+			return next(call)
+		}
+	}
+	cs := WrapContextSession(fakeSession, Recovery(zap.NewNop()), replaceContext)
+	assert.Equal(t, provider.VolumeProvider("vpc"), cs.ProviderName())
+
+	volume, err := cs.GetVolume(context.Background(), "vol")
+	assert.NoError(t, err)
+	assert.Equal(t, "vol", volume.VolumeID)
+	ctx, id := fakeSession.GetVolumeArgsForCall(0)
+	assert.Equal(t, "vol", id)
+	assert.Equal(t, "replaced", ctx.Value(ctxKey{}))
+
+	cs.Close()
+	assert.Equal(t, 1, fakeSession.CloseCallCount())
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	fakeSession := &fake.FakeContextSession{}
+	fakeSession.ProviderNameReturns("vpc")
+	var innerSpan trace.SpanContext
+	fakeSession.AttachVolumeStub = func(ctx context.Context, request provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+		innerSpan = trace.SpanContextFromContext(ctx)
+		return nil, util.NewError(reasoncode.ErrorVolumeAttachFailed, "attach failed")
+	}
+	cs := WrapContextSession(fakeSession, Tracing())
+
+	ctx, parent := tracing.Start(context.WithValue(context.Background(), provider.RequestID, "req-1"), "CSI.ControllerPublishVolume")
+	_, err := cs.AttachVolume(ctx, provider.VolumeAttachmentRequest{VolumeID: "vol-1", InstanceID: "ins-1"})
+	assert.Error(t, err)
+	_, err = cs.GetVolume(ctx, "vol-2")
+	assert.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 3) {
+		attach := spans[0]
+		assert.Equal(t, "Session.AttachVolume", attach.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), attach.Parent().SpanID())
+		assert.Equal(t, attach.SpanContext().SpanID(), innerSpan.SpanID())
+		assert.Equal(t, codes.Error, attach.Status().Code)
+		assert.Subset(t, attach.Attributes(), []interface{}{
+			tracing.VolumeIDKey.String("vol-1"),
+			tracing.InstanceIDKey.String("ins-1"),
+			tracing.ProviderKey.String("vpc"),
+			tracing.RequestIDKey.String("req-1"),
+			tracing.ReasonCodeKey.String("ErrorVolumeAttachFailed"),
+		})
+
+		get := spans[1]
+		assert.Equal(t, "Session.GetVolume", get.Name())
+		assert.Contains(t, get.Attributes(), tracing.VolumeIDKey.String("vol-2"))
+		assert.Equal(t, codes.Unset, get.Status().Code)
+	}
+
+	// Session calls without a span in the Wrap context are traced as root spans
+	sess := Wrap(context.Background(), &fake.FakeSession{}, Tracing())
+	_, _ = sess.CreateSnapshot("vol-3", provider.SnapshotParameters{})
+	spans = recorder.Ended()
+	if assert.Len(t, spans, 4) {
+		assert.Equal(t, "Session.CreateSnapshot", spans[3].Name())
+		assert.False(t, spans[3].Parent().IsValid())
+		assert.Contains(t, spans[3].Attributes(), tracing.VolumeIDKey.String("vol-3"))
+	}
+
+	// Session calls get the request ID and parent span of the Wrap context, even once it is cancelled
+	openCtx, cancel := context.WithCancel(context.WithValue(context.Background(), provider.RequestID, "req-2"))
+	openCtx, open := tracing.Start(openCtx, "CSI.NodeStageVolume")
+	sess = Wrap(openCtx, &fake.FakeSession{}, Tracing())
+	cancel()
+	_, err = sess.GetVolume("vol-4")
+	assert.NoError(t, err)
+	open.End()
+	spans = recorder.Ended()
+	if assert.Len(t, spans, 6) {
+		assert.Equal(t, "Session.GetVolume", spans[4].Name())
+		assert.Equal(t, open.SpanContext().SpanID(), spans[4].Parent().SpanID())
+		assert.Contains(t, spans[4].Attributes(), tracing.RequestIDKey.String("req-2"))
+	}
+}
@@ -0,0 +1,221 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+)
+
+// Idempotency makes CreateVolume calls with an IdempotencyKey safe to retry. The first call with a key
+// records the key, a fingerprint of the request and the request ID in the store before the volume is created,
+// and the volume ID once it has been. A later call with the same key and parameters returns the recorded volume,
+// or, if the first call did not return a volume, looks the volume up by request ID and then by name before
+// creating it again. A call with the same key and different parameters fails with ErrorIdempotencyKeyMismatch.
+// Calls with the same key are serialized within the process. Keys are forgotten once the store expires
+// their records. It is safe for concurrent use.
+type Idempotency struct {
+	store IdempotencyStore
+
+	mu    sync.Mutex
+	locks map[string]*idempotencyLock
+}
+
+// idempotencyLock serializes the calls with an idempotency key
+type idempotencyLock struct {
+	held    chan struct{}
+	waiters int
+}
+
+// NewIdempotency returns an Idempotency layer that keeps its records in the store
+func NewIdempotency(store IdempotencyStore) *Idempotency {
+	return &Idempotency{store: store, locks: map[string]*idempotencyLock{}}
+}
+
+// Wrap returns a provider.Session whose CreateVolume calls go through the idempotency layer.
+// Every other call is passed to sess. Session calls have no context, so no request ID is recorded
+// against the key, and a volume whose create call did not return is only looked up by name.
+func (i *Idempotency) Wrap(sess provider.Session) provider.Session {
+	return &idempotentSession{Session: sess, idempotency: i, cs: provider.NewContextSession(sess)}
+}
+
+// WrapContextSession is Wrap for a provider.ContextSession. The request ID stored in ctx under
+// provider.RequestID is recorded against the key.
+func (i *Idempotency) WrapContextSession(cs provider.ContextSession) provider.ContextSession {
+	return &idempotentContextSession{ContextSession: cs, idempotency: i}
+}
+
+// createVolume creates the volume, or returns the volume already created for its IdempotencyKey.
+// If the ID of a created volume cannot be saved, the volume is returned with the error.
+func (i *Idempotency) createVolume(ctx context.Context, cs provider.ContextSession, volume provider.Volume) (*provider.Volume, error) {
+	key := volume.IdempotencyKey
+	if key == "" {
+		return cs.CreateVolume(ctx, volume)
+	}
+	fingerprint, err := volumeFingerprint(volume)
+	if err != nil {
+		return nil, util.NewError(reasoncode.ErrorBadRequest, "The volume request could not be fingerprinted", err)
+	}
+
+	unlock, err := i.lock(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	record, err := i.store.Get(key)
+	if err != nil {
+		return nil, storeError("read", key, err)
+	}
+	if record != nil {
+		if record.Fingerprint != fingerprint {
+			return nil, util.NewErrorWithProperties(reasoncode.ErrorIdempotencyKeyMismatch,
+				"The idempotency key was already used for a volume request with different parameters",
+				map[string]string{"idempotencyKey": key, "volumeID": record.VolumeID})
+		}
+		if record.VolumeID != "" {
+			return cs.GetVolume(ctx, record.VolumeID)
+		}
+		existing, err := i.findVolume(ctx, cs, *record, volume)
+		if existing != nil || err != nil {
+			return existing, err
+		}
+	} else {
+		requestID, _ := ctx.Value(provider.RequestID).(string)
+		record = &IdempotencyRecord{Key: key, Fingerprint: fingerprint, RequestID: requestID, CreatedAt: time.Now()}
+		if err = i.store.Put(*record); err != nil {
+			return nil, storeError("save", key, err)
+		}
+	}
+
+	created, err := cs.CreateVolume(ctx, volume)
+	if err != nil || created == nil {
+		return created, err
+	}
+	return created, i.recordVolume(*record, created)
+}
+
+// findVolume looks up the volume of a record saved by a create request that did not return,
+// by request ID and then by name. It returns nil if there is no such volume.
+func (i *Idempotency) findVolume(ctx context.Context, cs provider.ContextSession, record IdempotencyRecord, volume provider.Volume) (*provider.Volume, error) {
+	lookups := []func() (*provider.Volume, error){}
+	if record.RequestID != "" {
+		lookups = append(lookups, func() (*provider.Volume, error) { return cs.GetVolumeByRequestID(ctx, record.RequestID) })
+	}
+	if name := util.SafeStringValue(volume.Name); name != "" {
+		lookups = append(lookups, func() (*provider.Volume, error) { return cs.GetVolumeByName(ctx, name) })
+	}
+	for _, lookup := range lookups {
+		existing, err := lookup()
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+		if err == nil && existing != nil && existing.VolumeID != "" {
+			return existing, i.recordVolume(record, existing)
+		}
+	}
+	return nil, nil
+}
+
+// recordVolume saves the ID of the volume created for the record
+func (i *Idempotency) recordVolume(record IdempotencyRecord, volume *provider.Volume) error {
+	record.VolumeID = volume.VolumeID
+	if err := i.store.Put(record); err != nil {
+		return storeError("save", record.Key, err)
+	}
+	return nil
+}
+
+// lock waits until no other call with the key is in progress, or until ctx is done
+func (i *Idempotency) lock(ctx context.Context, key string) (func(), error) {
+	i.mu.Lock()
+	l, ok := i.locks[key]
+	if !ok {
+		l = &idempotencyLock{held: make(chan struct{}, 1)}
+		i.locks[key] = l
+	}
+	l.waiters++
+	i.mu.Unlock()
+
+	release := func() {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		if l.waiters--; l.waiters == 0 {
+			delete(i.locks, key)
+		}
+	}
+	select {
+	case l.held <- struct{}{}:
+		return func() {
+			<-l.held
+			release()
+		}, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// volumeFingerprint returns a hash of the parameters of the create request
+func volumeFingerprint(volume provider.Volume) (string, error) {
+	volume.IdempotencyKey = ""
+	volume.CreationTime = time.Time{}
+	data, err := json.Marshal(volume)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// storeError returns the error for an idempotency record that could not be read or saved
+func storeError(action, key string, err error) error {
+	return util.NewErrorWithProperties(reasoncode.ErrorIdempotencyStoreFailed,
+		"The idempotency record could not be "+action, map[string]string{"idempotencyKey": key}, err)
+}
+
+// idempotentSession is a provider.Session whose CreateVolume calls go through an Idempotency layer
+type idempotentSession struct {
+	provider.Session
+	idempotency *Idempotency
+	cs          provider.ContextSession
+}
+
+// CreateVolume creates the volume, or returns the volume already created for its IdempotencyKey
+func (s *idempotentSession) CreateVolume(volume provider.Volume) (*provider.Volume, error) {
+	return s.idempotency.createVolume(context.Background(), s.cs, volume)
+}
+
+// idempotentContextSession is a provider.ContextSession whose CreateVolume calls go through an Idempotency layer
+type idempotentContextSession struct {
+	provider.ContextSession
+	idempotency *Idempotency
+}
+
+// CreateVolume creates the volume, or returns the volume already created for its IdempotencyKey
+func (s *idempotentContextSession) CreateVolume(ctx context.Context, volume provider.Volume) (*provider.Volume, error) {
+	return s.idempotency.createVolume(ctx, s.ContextSession, volume)
+}
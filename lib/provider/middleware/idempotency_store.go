@@ -0,0 +1,252 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord is what an IdempotencyStore keeps for an idempotency key
+type IdempotencyRecord struct {
+	// Key is the idempotency key of the create request
+	Key string `json:"key"`
+
+	// Fingerprint identifies the parameters of the create request
+	Fingerprint string `json:"fingerprint"`
+
+	// RequestID is the request ID in the context of the create request, if any
+	RequestID string `json:"requestID,omitempty"`
+
+	// VolumeID is the ID of the created volume. It is empty until the provider has returned the volume.
+	VolumeID string `json:"volumeID,omitempty"`
+
+	// CreatedAt is when the record was first saved, set by the store if it is zero.
+	// The record expires once it is older than the retention of the store.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DefaultIdempotencyRetention is how long the stores keep an IdempotencyRecord by default. Calls retried with
+// the key after that are not deduplicated.
+const DefaultIdempotencyRetention = 24 * time.Hour
+
+// IdempotencyStore keeps the IdempotencyRecord of each idempotency key. Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Get returns the record of the key, or nil if there is none
+	Get(key string) (*IdempotencyRecord, error)
+
+	// Put saves the record, replacing any record of the same key
+	Put(record IdempotencyRecord) error
+
+	// Delete removes the record of the key, if any
+	Delete(key string) error
+}
+
+// idempotencyRecords are the records of a store, which expire once they are older than the retention
+type idempotencyRecords struct {
+	retention time.Duration
+	records   map[string]IdempotencyRecord
+}
+
+// newIdempotencyRecords returns an empty set of records, kept for DefaultIdempotencyRetention if retention is 0
+func newIdempotencyRecords(retention time.Duration) idempotencyRecords {
+	if retention <= 0 {
+		retention = DefaultIdempotencyRetention
+	}
+	return idempotencyRecords{retention: retention, records: map[string]IdempotencyRecord{}}
+}
+
+// get returns the record of the key, or nil if there is none or it has expired
+func (r idempotencyRecords) get(key string) *IdempotencyRecord {
+	record, ok := r.records[key]
+	if !ok || r.expired(record, time.Now()) {
+		return nil
+	}
+	return &record
+}
+
+// put saves the record, setting its CreatedAt if it is not set, and removes the expired records
+func (r idempotencyRecords) put(record IdempotencyRecord) {
+	now := time.Now()
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = now
+	}
+	r.records[record.Key] = record
+	for key, record := range r.records {
+		if r.expired(record, now) {
+			delete(r.records, key)
+		}
+	}
+}
+
+// expired reports whether the record is older than the retention
+func (r idempotencyRecords) expired(record IdempotencyRecord, now time.Time) bool {
+	return now.Sub(record.CreatedAt) > r.retention
+}
+
+// clone returns a copy of the records
+func (r idempotencyRecords) clone() idempotencyRecords {
+	clone := idempotencyRecords{retention: r.retention, records: make(map[string]IdempotencyRecord, len(r.records))}
+	for key, record := range r.records {
+		clone.records[key] = record
+	}
+	return clone
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore which keeps the records in memory, for the life of the process
+// or until they expire
+type MemoryIdempotencyStore struct {
+	mu      sync.RWMutex
+	records idempotencyRecords
+}
+
+// NewMemoryIdempotencyStore returns an empty MemoryIdempotencyStore keeping records for the retention,
+// DefaultIdempotencyRetention if it is 0
+func NewMemoryIdempotencyStore(retention time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: newIdempotencyRecords(retention)}
+}
+
+// Get returns the record of the key, or nil if there is none
+func (s *MemoryIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.records.get(key), nil
+}
+
+// Put saves the record, replacing any record of the same key, and removes the expired records
+func (s *MemoryIdempotencyStore) Put(record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records.put(record)
+	return nil
+}
+
+// Delete removes the record of the key, if any
+func (s *MemoryIdempotencyStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records.records, key)
+	return nil
+}
+
+// FileIdempotencyStore is an IdempotencyStore which keeps the records in a JSON file, so that they survive restarts.
+// The file is rewritten on every change by renaming a temporary file over it, without the expired records, so
+// its size is bounded by the records saved within the retention. It must not be shared between processes.
+type FileIdempotencyStore struct {
+	path string
+
+	mu      sync.RWMutex
+	records idempotencyRecords
+}
+
+// NewFileIdempotencyStore returns a FileIdempotencyStore of the records in the file at path, keeping records
+// for the retention, DefaultIdempotencyRetention if it is 0. The file is created on the first change if it
+// does not exist.
+func NewFileIdempotencyStore(path string, retention time.Duration) (*FileIdempotencyStore, error) {
+	s := &FileIdempotencyStore{path: path, records: newIdempotencyRecords(retention)}
+	data, err := os.ReadFile(path) // #nosec G304
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []IdempotencyRecord
+	if err = json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, record := range records {
+		if !s.records.expired(record, now) {
+			s.records.records[record.Key] = record
+		}
+	}
+	return s, nil
+}
+
+// Get returns the record of the key, or nil if there is none
+func (s *FileIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.records.get(key), nil
+}
+
+// Put saves the record, replacing any record of the same key, and removes the expired records
+func (s *FileIdempotencyStore) Put(record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := s.records.clone()
+	records.put(record)
+	if err := s.save(records); err != nil {
+		return err
+	}
+	s.records = records
+	return nil
+}
+
+// Delete removes the record of the key, if any
+func (s *FileIdempotencyStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, existed := s.records.records[key]; !existed {
+		return nil
+	}
+	records := s.records.clone()
+	delete(records.records, key)
+	if err := s.save(records); err != nil {
+		return err
+	}
+	s.records = records
+	return nil
+}
+
+// save writes the records to the file. It must be called with s.mu held.
+func (s *FileIdempotencyStore) save(r idempotencyRecords) error {
+	records := make([]IdempotencyRecord, 0, len(r.records))
+	for _, record := range r.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Key < records[j].Key })
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // #nosec G104
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close() // #nosec G104
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close() // #nosec G104
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
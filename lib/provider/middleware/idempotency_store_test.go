@@ -0,0 +1,133 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middleware
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	testIdempotencyStore(t, NewMemoryIdempotencyStore(0))
+}
+
+func TestFileIdempotencyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	store, err := NewFileIdempotencyStore(path, 0)
+	require.NoError(t, err)
+	testIdempotencyStore(t, store)
+
+	// Records survive reopening the file
+	require.NoError(t, store.Put(IdempotencyRecord{Key: "pvc-2", Fingerprint: "abc", VolumeID: "vol-2"}))
+	reopened, err := NewFileIdempotencyStore(path, 0)
+	require.NoError(t, err)
+	record, err := reopened.Get("pvc-2")
+	require.NoError(t, err)
+	assert.Equal(t, "vol-2", record.VolumeID)
+	record, err = reopened.Get("pvc-1")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+	_, err = NewFileIdempotencyStore(path, 0)
+	assert.Error(t, err)
+
+	// A failed save leaves the records unchanged
+	store, err = NewFileIdempotencyStore(filepath.Join(t.TempDir(), "missing", "idempotency.json"), 0)
+	require.NoError(t, err)
+	assert.Error(t, store.Put(IdempotencyRecord{Key: "pvc-3"}))
+	record, err = store.Get("pvc-3")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+func TestMemoryIdempotencyStoreRetention(t *testing.T) {
+	testIdempotencyStoreRetention(t, NewMemoryIdempotencyStore(time.Hour))
+}
+
+func TestFileIdempotencyStoreRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	store, err := NewFileIdempotencyStore(path, time.Hour)
+	require.NoError(t, err)
+	testIdempotencyStoreRetention(t, store)
+
+	// Expired records are not saved, nor read back from the file
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "pvc-old")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"key":"pvc-old","createdAt":"2020-01-01T00:00:00Z"},{"key":"pvc-new","createdAt":"`+
+		time.Now().UTC().Format(time.RFC3339)+`"}]`), 0600))
+	reopened, err := NewFileIdempotencyStore(path, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, reopened.records.records, 1)
+}
+
+// testIdempotencyStoreRetention checks that a store with a retention of an hour expires older records
+func testIdempotencyStoreRetention(t *testing.T, store IdempotencyStore) {
+	require.NoError(t, store.Put(IdempotencyRecord{Key: "pvc-new"}))
+	record, err := store.Get("pvc-new")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), record.CreatedAt, time.Minute)
+
+	require.NoError(t, store.Put(IdempotencyRecord{Key: "pvc-old", CreatedAt: time.Now().Add(-2 * time.Hour)}))
+	record, err = store.Get("pvc-old")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	// Expired records are removed when a record is saved
+	require.NoError(t, store.Put(IdempotencyRecord{Key: "pvc-other"}))
+	var records map[string]IdempotencyRecord
+	switch store := store.(type) {
+	case *MemoryIdempotencyStore:
+		records = store.records.records
+	case *FileIdempotencyStore:
+		records = store.records.records
+	}
+	assert.Len(t, records, 2)
+	assert.NotContains(t, records, "pvc-old")
+}
+
+// testIdempotencyStore checks the IdempotencyStore contract
+func testIdempotencyStore(t *testing.T, store IdempotencyStore) {
+	record, err := store.Get("pvc-1")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, store.Put(IdempotencyRecord{Key: "pvc-1", Fingerprint: "abc", RequestID: "req-1", CreatedAt: createdAt}))
+	require.NoError(t, store.Put(IdempotencyRecord{Key: "pvc-1", Fingerprint: "abc", RequestID: "req-1", VolumeID: "vol-1", CreatedAt: createdAt}))
+	record, err = store.Get("pvc-1")
+	require.NoError(t, err)
+	assert.Equal(t, IdempotencyRecord{Key: "pvc-1", Fingerprint: "abc", RequestID: "req-1", VolumeID: "vol-1", CreatedAt: createdAt}, *record)
+
+	// Changing a returned record does not change the stored one
+	record.VolumeID = "changed"
+	record, err = store.Get("pvc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "vol-1", record.VolumeID)
+
+	require.NoError(t, store.Delete("pvc-1"))
+	require.NoError(t, store.Delete("pvc-1"))
+	record, err = store.Get("pvc-1")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+}
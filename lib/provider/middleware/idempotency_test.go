@@ -0,0 +1,183 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/fake"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// idempotentVolume returns a create request for a volume with the idempotency key
+func idempotentVolume(key string, capacity int) provider.Volume {
+	name := "pvc-" + key
+	return provider.Volume{Name: &name, Capacity: &capacity, IdempotencyKey: key}
+}
+
+func TestIdempotency(t *testing.T) {
+	fakeSession := &fake.FakeSession{}
+	fakeSession.CreateVolumeStub = func(volume provider.Volume) (*provider.Volume, error) {
+		return &provider.Volume{VolumeID: "vol-" + volume.IdempotencyKey, Name: volume.Name, Capacity: volume.Capacity}, nil
+	}
+	fakeSession.GetVolumeStub = func(id string) (*provider.Volume, error) {
+		return &provider.Volume{VolumeID: id}, nil
+	}
+	store := NewMemoryIdempotencyStore(0)
+	sess := NewIdempotency(store).Wrap(fakeSession)
+
+	volume, err := sess.CreateVolume(idempotentVolume("1", 10))
+	require.NoError(t, err)
+	assert.Equal(t, "vol-1", volume.VolumeID)
+	record, err := store.Get("1")
+	require.NoError(t, err)
+	assert.Equal(t, "vol-1", record.VolumeID)
+	assert.NotEmpty(t, record.Fingerprint)
+	// Session calls have no request ID
+	assert.Empty(t, record.RequestID)
+
+	// A replay returns the recorded volume
+	volume, err = sess.CreateVolume(idempotentVolume("1", 10))
+	require.NoError(t, err)
+	assert.Equal(t, "vol-1", volume.VolumeID)
+	assert.Equal(t, 1, fakeSession.CreateVolumeCallCount())
+	assert.Equal(t, "vol-1", fakeSession.GetVolumeArgsForCall(0))
+
+	// Reusing the key with different parameters fails
+	_, err = sess.CreateVolume(idempotentVolume("1", 20))
+	assert.Equal(t, reasoncode.ErrorIdempotencyKeyMismatch, util.ErrorReasonCode(err))
+	assert.Equal(t, 1, fakeSession.CreateVolumeCallCount())
+
+	// Requests without a key are passed through
+	capacity := 10
+	_, err = sess.CreateVolume(provider.Volume{Capacity: &capacity})
+	assert.NoError(t, err)
+	_, err = sess.CreateVolume(provider.Volume{Capacity: &capacity})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, fakeSession.CreateVolumeCallCount())
+}
+
+func TestIdempotencyRetry(t *testing.T) {
+	var created *provider.Volume
+	fakeSession := &fake.FakeContextSession{}
+	fakeSession.CreateVolumeStub = func(ctx context.Context, volume provider.Volume) (*provider.Volume, error) {
+		created = &provider.Volume{VolumeID: "vol-1", Name: volume.Name}
+		return nil, util.NewError(reasoncode.Timeout, "create timed out")
+	}
+	fakeSession.GetVolumeByRequestIDStub = func(ctx context.Context, requestID string) (*provider.Volume, error) {
+		if created == nil || requestID != "req-1" {
+			return nil, util.Message{Type: util.EntityNotFound}
+		}
+		return created, nil
+	}
+	store := NewMemoryIdempotencyStore(0)
+	cs := NewIdempotency(store).WrapContextSession(fakeSession)
+	ctx := context.WithValue(context.Background(), provider.RequestID, "req-1")
+
+	_, err := cs.CreateVolume(ctx, idempotentVolume("1", 10))
+	assert.Equal(t, reasoncode.Timeout, util.ErrorReasonCode(err))
+	record, err := store.Get("1")
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", record.RequestID)
+	assert.Empty(t, record.VolumeID)
+
+	// The retry finds the volume created by the timed out request
+	volume, err := cs.CreateVolume(context.Background(), idempotentVolume("1", 10))
+	require.NoError(t, err)
+	assert.Equal(t, "vol-1", volume.VolumeID)
+	assert.Equal(t, 1, fakeSession.CreateVolumeCallCount())
+	record, err = store.Get("1")
+	require.NoError(t, err)
+	assert.Equal(t, "vol-1", record.VolumeID)
+
+	// A request which did not create a volume is retried, after looking the volume up by name
+	fakeSession.CreateVolumeStub = nil
+	fakeSession.CreateVolumeReturnsOnCall(1, nil, errors.New("create failed"))
+	fakeSession.CreateVolumeReturnsOnCall(2, &provider.Volume{VolumeID: "vol-2"}, nil)
+	fakeSession.GetVolumeByNameReturns(nil, util.Message{Type: util.EntityNotFound})
+	_, err = cs.CreateVolume(context.Background(), idempotentVolume("2", 10))
+	assert.EqualError(t, err, "create failed")
+	volume, err = cs.CreateVolume(context.Background(), idempotentVolume("2", 10))
+	require.NoError(t, err)
+	assert.Equal(t, "vol-2", volume.VolumeID)
+	_, name := fakeSession.GetVolumeByNameArgsForCall(0)
+	assert.Equal(t, "pvc-2", name)
+
+	// Lookup errors other than not found are returned
+	fakeSession.GetVolumeByNameReturns(nil, errors.New("lookup failed"))
+	fakeSession.CreateVolumeReturnsOnCall(3, nil, errors.New("create failed"))
+	_, err = cs.CreateVolume(context.Background(), idempotentVolume("3", 10))
+	assert.EqualError(t, err, "create failed")
+	_, err = cs.CreateVolume(context.Background(), idempotentVolume("3", 10))
+	assert.EqualError(t, err, "lookup failed")
+	assert.Equal(t, 4, fakeSession.CreateVolumeCallCount())
+}
+
+func TestIdempotencyConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	fakeSession := &fake.FakeContextSession{}
+	fakeSession.CreateVolumeStub = func(ctx context.Context, volume provider.Volume) (*provider.Volume, error) {
+		<-release
+		return &provider.Volume{VolumeID: "vol-1"}, nil
+	}
+	fakeSession.GetVolumeStub = func(ctx context.Context, id string) (*provider.Volume, error) {
+		return &provider.Volume{VolumeID: id}, nil
+	}
+	cs := NewIdempotency(NewMemoryIdempotencyStore(0)).WrapContextSession(fakeSession)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			volume, err := cs.CreateVolume(context.Background(), idempotentVolume("1", 10))
+			if assert.NoError(t, err) {
+				assert.Equal(t, "vol-1", volume.VolumeID)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// A caller whose ctx is done stops waiting for the call in progress
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := cs.CreateVolume(ctx, idempotentVolume("1", 10))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 1, fakeSession.CreateVolumeCallCount())
+}
+
+func TestIdempotencyStoreFailure(t *testing.T) {
+	fakeSession := &fake.FakeSession{}
+	store, err := NewFileIdempotencyStore(t.TempDir()+"/missing/idempotency.json", 0)
+	require.NoError(t, err)
+	sess := NewIdempotency(store).Wrap(fakeSession)
+
+	_, err = sess.CreateVolume(idempotentVolume("1", 10))
+	assert.Equal(t, reasoncode.ErrorIdempotencyStoreFailed, util.ErrorReasonCode(err))
+	assert.Equal(t, 0, fakeSession.CreateVolumeCallCount())
+}
@@ -0,0 +1,62 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware provides composable decorators for provider.Session
+package middleware
+
+import (
+	"context"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+)
+
+// Call describes a single provider.Session or provider.ContextSession method invocation
+// passing through a middleware chain
+type Call struct {
+	// Context is the context of a ContextSession call, or for Session calls one without cancellation derived
+	// from the context given to Wrap.
+	// A middleware may replace it, e.g. with a child span, before calling next: the wrapped
+	// ContextSession is called with the Context of the Call which reaches it.
+	Context context.Context
+
+	// Method is the name of the Session method, e.g. "CreateVolume"
+	Method string
+
+	// Provider is the name of the wrapped Session's provider
+	Provider provider.VolumeProvider
+
+	// Args are the arguments the method was called with
+	Args []interface{}
+}
+
+// Handler performs a Session call. The innermost Handler invokes the wrapped Session method,
+// its results are captured by the caller and only the error is passed back through the chain.
+type Handler func(call *Call) error
+
+// Middleware decorates a Handler. It may act before and after calling next, replace the
+// returned error, call next more than once, or not call it at all.
+type Middleware func(next Handler) Handler
+
+// Chain composes the middlewares into one. The first middleware is the outermost, so
+// Chain(a, b)(h) runs a, then b, then h.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next Handler) Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
@@ -0,0 +1,49 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingMiddleware(name string, trace *[]string) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) error {
+			*trace = append(*trace, name+" before")
+			err := next(call)
+			*trace = append(*trace, name+" after")
+			return err
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var trace []string
+	handler := Chain(recordingMiddleware("outer", &trace), recordingMiddleware("inner", &trace))(func(call *Call) error {
+		trace = append(trace, call.Method)
+		return nil
+	})
+	assert.NoError(t, handler(&Call{Method: "GetVolume"}))
+	assert.Equal(t, []string{"outer before", "inner before", "GetVolume", "inner after", "outer after"}, trace)
+
+	called := false
+	assert.NoError(t, Chain()(func(*Call) error { called = true; return nil })(&Call{}))
+	assert.True(t, called)
+}
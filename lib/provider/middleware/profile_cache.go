@@ -0,0 +1,274 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultProfileTTL is how long profiles and profile lists are cached if ProfileCacheConfig.TTL is not set
+	DefaultProfileTTL = 10 * time.Minute
+
+	// DefaultProfileNegativeTTL is how long unknown profile names are cached if ProfileCacheConfig.NegativeTTL is not set
+	DefaultProfileNegativeTTL = time.Minute
+)
+
+// ProfileCacheConfig configures a ProfileCache
+type ProfileCacheConfig struct {
+	// TTL is how long profiles and profile lists are cached, DefaultProfileTTL if not set
+	TTL time.Duration
+
+	// NegativeTTL is how long the error for an unknown profile name is cached, DefaultProfileNegativeTTL if not set.
+	// Unknown names are not cached if it is negative.
+	NegativeTTL time.Duration
+
+	// IsNotFound reports whether an error of GetVolumeProfileByName means the profile does not exist.
+	// By default an util.Message of type util.EntityNotFound or with a 404 status code does.
+	IsNotFound func(err error) bool
+}
+
+// ProfileCache caches the results of GetVolumeProfileByName and ListVolumeProfiles of the sessions
+// it wraps, keyed by provider name, so that it can be shared by every session of a process.
+// Other errors are not cached, and concurrent lookups of the same profile share one provider call.
+// Expired entries are swept when new ones are stored. It is safe for concurrent use.
+type ProfileCache struct {
+	config ProfileCacheConfig
+
+	mu        sync.RWMutex
+	entries   map[string]profileCacheEntry
+	nextSweep time.Time
+
+	// generation is incremented by Invalidate. Provider calls started before are not joined by later
+	// lookups, and their results are not stored.
+	generation uint64
+	group      singleflight.Group
+}
+
+// profileCacheEntry is a cached *provider.Profile, *provider.ProfileList or not found error
+type profileCacheEntry struct {
+	value     interface{}
+	err       error
+	expiresAt time.Time
+}
+
+// NewProfileCache returns an empty ProfileCache
+func NewProfileCache(config ProfileCacheConfig) *ProfileCache {
+	if config.TTL <= 0 {
+		config.TTL = DefaultProfileTTL
+	}
+	if config.NegativeTTL == 0 {
+		config.NegativeTTL = DefaultProfileNegativeTTL
+	}
+	if config.IsNotFound == nil {
+		config.IsNotFound = isNotFound
+	}
+	return &ProfileCache{
+		config:  config,
+		entries: map[string]profileCacheEntry{},
+	}
+}
+
+// Invalidate empties the cache. The results of provider calls in progress are not cached.
+func (c *ProfileCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]profileCacheEntry{}
+	c.generation++
+}
+
+// Wrap returns a provider.Session whose GetVolumeProfileByName and ListVolumeProfiles calls go through the cache.
+// Every other call is passed to sess.
+func (c *ProfileCache) Wrap(sess provider.Session) provider.Session {
+	return &profileCachingSession{Session: sess, cache: c}
+}
+
+// WrapContextSession is Wrap for a provider.ContextSession. A call stops waiting for a shared
+// provider call when its ctx is done.
+func (c *ProfileCache) WrapContextSession(cs provider.ContextSession) provider.ContextSession {
+	return &profileCachingContextSession{ContextSession: cs, cache: c}
+}
+
+// profile returns the cached profile, or gets it and caches it
+func (c *ProfileCache) profile(ctx context.Context, providerName provider.VolumeProvider, name string, get func() (*provider.Profile, error)) (*provider.Profile, error) {
+	value, err := c.lookup(ctx, profileKey(providerName, name), func(uint64) (interface{}, error) {
+		profile, err := get()
+		if err != nil {
+			return nil, err
+		}
+		return profile, nil
+	})
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return copyProfile(value.(*provider.Profile)), nil
+}
+
+// list returns the cached page of profiles, or lists it and caches it. The profiles in the page are cached too.
+func (c *ProfileCache) list(ctx context.Context, providerName provider.VolumeProvider, limit int, start string, list func() (*provider.ProfileList, error)) (*provider.ProfileList, error) {
+	key := string(providerName) + "\x00list\x00" + strconv.Itoa(limit) + "\x00" + start
+	value, err := c.lookup(ctx, key, func(generation uint64) (interface{}, error) {
+		profiles, err := list()
+		if err != nil || profiles == nil {
+			return nil, err
+		}
+		expiresAt := time.Now().Add(c.config.TTL)
+		entries := map[string]profileCacheEntry{}
+		for _, profile := range profiles.Profiles {
+			if profile != nil {
+				entries[profileKey(providerName, profile.Name)] = profileCacheEntry{value: copyProfile(profile), expiresAt: expiresAt}
+			}
+		}
+		c.store(generation, entries)
+		return profiles, nil
+	})
+	if err != nil || value == nil {
+		return nil, err
+	}
+	profiles := value.(*provider.ProfileList)
+	page := &provider.ProfileList{Next: profiles.Next, Profiles: make([]*provider.Profile, 0, len(profiles.Profiles))}
+	for _, profile := range profiles.Profiles {
+		page.Profiles = append(page.Profiles, copyProfile(profile))
+	}
+	return page, nil
+}
+
+// lookup returns the cached value or error for the key, or calls fetch once for all concurrent callers
+// and caches its result: values for TTL, and not found errors for NegativeTTL. fetch is given the
+// generation of the cache it was called for, to store other entries with.
+func (c *ProfileCache) lookup(ctx context.Context, key string, fetch func(generation uint64) (interface{}, error)) (interface{}, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.value, entry.err
+	}
+
+	flight := key + "\x00" + strconv.FormatUint(generation, 10)
+	results := c.group.DoChan(flight, func() (interface{}, error) {
+		value, err := fetch(generation)
+		switch {
+		case err == nil && value != nil:
+			c.store(generation, map[string]profileCacheEntry{key: {value: value, expiresAt: time.Now().Add(c.config.TTL)}})
+		case err != nil && c.config.NegativeTTL > 0 && c.config.IsNotFound(err):
+			c.store(generation, map[string]profileCacheEntry{key: {err: err, expiresAt: time.Now().Add(c.config.NegativeTTL)}})
+		}
+		return value, err
+	})
+	select {
+	case result := <-results:
+		return result.Val, result.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// store caches the entries fetched for the generation, unless the cache has been invalidated since.
+// Expired entries are swept first, at most once per NegativeTTL or TTL, whichever is shorter.
+func (c *ProfileCache) store(generation uint64, entries map[string]profileCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if now := time.Now(); !now.Before(c.nextSweep) {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		interval := c.config.TTL
+		if c.config.NegativeTTL > 0 && c.config.NegativeTTL < interval {
+			interval = c.config.NegativeTTL
+		}
+		c.nextSweep = now.Add(interval)
+	}
+	for key, entry := range entries {
+		c.entries[key] = entry
+	}
+}
+
+// profileKey is the cache key of the named profile of the provider
+func profileKey(providerName provider.VolumeProvider, name string) string {
+	return string(providerName) + "\x00profile\x00" + name
+}
+
+// copyProfile returns a copy of the profile, so that callers cannot change the cached one
+func copyProfile(profile *provider.Profile) *provider.Profile {
+	if profile == nil {
+		return nil
+	}
+	profileCopy := *profile
+	return &profileCopy
+}
+
+// isNotFound reports whether err is a util.Message for a missing entity. It is the default ProfileCacheConfig.IsNotFound.
+func isNotFound(err error) bool {
+	var msg util.Message
+	return errors.As(err, &msg) && (msg.Type == util.EntityNotFound || msg.RC == http.StatusNotFound)
+}
+
+// profileCachingSession is a provider.Session whose profile calls go through a ProfileCache
+type profileCachingSession struct {
+	provider.Session
+	cache *ProfileCache
+}
+
+// GetVolumeProfileByName gets the volume profile from the cache
+func (s *profileCachingSession) GetVolumeProfileByName(name string) (*provider.Profile, error) {
+	return s.cache.profile(context.Background(), s.ProviderName(), name, func() (*provider.Profile, error) {
+		return s.Session.GetVolumeProfileByName(name)
+	})
+}
+
+// ListVolumeProfiles lists the volume profiles from the cache
+func (s *profileCachingSession) ListVolumeProfiles(limit int, start string) (*provider.ProfileList, error) {
+	return s.cache.list(context.Background(), s.ProviderName(), limit, start, func() (*provider.ProfileList, error) {
+		return s.Session.ListVolumeProfiles(limit, start)
+	})
+}
+
+// profileCachingContextSession is a provider.ContextSession whose profile calls go through a ProfileCache
+type profileCachingContextSession struct {
+	provider.ContextSession
+	cache *ProfileCache
+}
+
+// GetVolumeProfileByName gets the volume profile from the cache
+func (s *profileCachingContextSession) GetVolumeProfileByName(ctx context.Context, name string) (*provider.Profile, error) {
+	return s.cache.profile(ctx, s.ProviderName(), name, func() (*provider.Profile, error) {
+		return s.ContextSession.GetVolumeProfileByName(context.WithoutCancel(ctx), name)
+	})
+}
+
+// ListVolumeProfiles lists the volume profiles from the cache
+func (s *profileCachingContextSession) ListVolumeProfiles(ctx context.Context, limit int, start string) (*provider.ProfileList, error) {
+	return s.cache.list(ctx, s.ProviderName(), limit, start, func() (*provider.ProfileList, error) {
+		return s.ContextSession.ListVolumeProfiles(context.WithoutCancel(ctx), limit, start)
+	})
+}
@@ -0,0 +1,236 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/fake"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileCache(t *testing.T) {
+	fakeSession := &fake.FakeSession{}
+	fakeSession.ProviderNameReturns("vpc")
+	fakeSession.GetVolumeProfileByNameStub = func(name string) (*provider.Profile, error) {
+		switch name {
+		case "general-purpose":
+			return &provider.Profile{Name: name, Family: "tiered"}, nil
+		case "broken":
+			return nil, errors.New("connection reset")
+		}
+		return nil, util.Message{Code: "VolumeProfileNotFound", Type: util.EntityNotFound, RC: 404}
+	}
+
+	cache := NewProfileCache(ProfileCacheConfig{})
+	sess := cache.Wrap(fakeSession)
+
+	profile, err := sess.GetVolumeProfileByName("general-purpose")
+	assert.NoError(t, err)
+	assert.Equal(t, "tiered", profile.Family)
+	profile.Family = "changed"
+	profile, err = sess.GetVolumeProfileByName("general-purpose")
+	assert.NoError(t, err)
+	assert.Equal(t, "tiered", profile.Family)
+	assert.Equal(t, 1, fakeSession.GetVolumeProfileByNameCallCount())
+
+	// Not found errors are cached, other errors are not
+	for i := 0; i < 2; i++ {
+		_, err = sess.GetVolumeProfileByName("unknown")
+		assert.Error(t, err)
+		_, err = sess.GetVolumeProfileByName("broken")
+		assert.EqualError(t, err, "connection reset")
+	}
+	assert.Equal(t, 4, fakeSession.GetVolumeProfileByNameCallCount())
+
+	// Caches of different providers are separate
+	otherSession := &fake.FakeSession{}
+	otherSession.ProviderNameReturns("classic")
+	otherSession.GetVolumeProfileByNameReturns(&provider.Profile{Name: "general-purpose"}, nil)
+	_, err = cache.Wrap(otherSession).GetVolumeProfileByName("general-purpose")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, otherSession.GetVolumeProfileByNameCallCount())
+
+	cache.Invalidate()
+	_, err = sess.GetVolumeProfileByName("general-purpose")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, fakeSession.GetVolumeProfileByNameCallCount())
+
+	// Calls other than the profile calls are not cached
+	fakeSession.GetVolumeReturns(&provider.Volume{VolumeID: "vol"}, nil)
+	volume, err := sess.GetVolume("vol")
+	assert.NoError(t, err)
+	assert.Equal(t, "vol", volume.VolumeID)
+	assert.Equal(t, 1, fakeSession.GetVolumeCallCount())
+}
+
+func TestProfileCacheExpiry(t *testing.T) {
+	fakeSession := &fake.FakeSession{}
+	fakeSession.GetVolumeProfileByNameStub = func(name string) (*provider.Profile, error) {
+		if name == "unknown" {
+			return nil, util.Message{Type: util.EntityNotFound}
+		}
+		return &provider.Profile{Name: name}, nil
+	}
+
+	sess := NewProfileCache(ProfileCacheConfig{TTL: 20 * time.Millisecond, NegativeTTL: -1}).Wrap(fakeSession)
+	_, err := sess.GetVolumeProfileByName("general-purpose")
+	assert.NoError(t, err)
+	_, err = sess.GetVolumeProfileByName("general-purpose")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fakeSession.GetVolumeProfileByNameCallCount())
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = sess.GetVolumeProfileByName("general-purpose")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fakeSession.GetVolumeProfileByNameCallCount())
+
+	// A negative NegativeTTL disables negative caching
+	_, err = sess.GetVolumeProfileByName("unknown")
+	assert.Error(t, err)
+	_, err = sess.GetVolumeProfileByName("unknown")
+	assert.Error(t, err)
+	assert.Equal(t, 4, fakeSession.GetVolumeProfileByNameCallCount())
+}
+
+func TestProfileCacheSweep(t *testing.T) {
+	fakeSession := &fake.FakeSession{}
+	fakeSession.GetVolumeProfileByNameStub = func(name string) (*provider.Profile, error) {
+		if name == "general-purpose" {
+			return &provider.Profile{Name: name}, nil
+		}
+		return nil, util.Message{Type: util.EntityNotFound}
+	}
+
+	cache := NewProfileCache(ProfileCacheConfig{TTL: 20 * time.Millisecond, NegativeTTL: 10 * time.Millisecond})
+	sess := cache.Wrap(fakeSession)
+	for _, name := range []string{"general-purpose", "unknown-1", "unknown-2"} {
+		_, _ = sess.GetVolumeProfileByName(name)
+	}
+	assert.Len(t, cache.entries, 3)
+
+	// Expired entries are deleted when the next entry is stored
+	time.Sleep(30 * time.Millisecond)
+	_, _ = sess.GetVolumeProfileByName("unknown-3")
+	assert.Len(t, cache.entries, 1)
+	assert.Contains(t, cache.entries, profileKey("", "unknown-3"))
+}
+
+func TestProfileCacheInvalidateInFlight(t *testing.T) {
+	release := make(chan struct{})
+	var calls int32
+	fakeSession := &fake.FakeSession{}
+	fakeSession.GetVolumeProfileByNameStub = func(name string) (*provider.Profile, error) {
+		call := atomic.AddInt32(&calls, 1)
+		<-release
+		return &provider.Profile{Name: name, Family: string(rune('0' + call))}, nil
+	}
+
+	cache := NewProfileCache(ProfileCacheConfig{})
+	sess := cache.Wrap(fakeSession)
+	families := make(chan string, 2)
+	get := func() {
+		profile, err := sess.GetVolumeProfileByName("general-purpose")
+		assert.NoError(t, err)
+		families <- profile.Family
+	}
+
+	// A lookup after Invalidate does not join the call started before it
+	go get()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 1 }, time.Second, time.Millisecond)
+	cache.Invalidate()
+	go get()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 2 }, time.Second, time.Millisecond)
+	close(release)
+	assert.ElementsMatch(t, []string{"1", "2"}, []string{<-families, <-families})
+
+	// Only the result of the call started after Invalidate is cached
+	profile, err := sess.GetVolumeProfileByName("general-purpose")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", profile.Family)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestProfileCacheList(t *testing.T) {
+	fakeSession := &fake.FakeSession{}
+	fakeSession.ListVolumeProfilesReturns(&provider.ProfileList{
+		Next:     "10iops-tier",
+		Profiles: []*provider.Profile{{Name: "5iops-tier"}, {Name: "custom"}},
+	}, nil)
+
+	sess := NewProfileCache(ProfileCacheConfig{}).Wrap(fakeSession)
+	for i := 0; i < 2; i++ {
+		profiles, err := sess.ListVolumeProfiles(2, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "10iops-tier", profiles.Next)
+		assert.Len(t, profiles.Profiles, 2)
+	}
+	assert.Equal(t, 1, fakeSession.ListVolumeProfilesCallCount())
+
+	// Pages are cached by limit and start
+	_, err := sess.ListVolumeProfiles(2, "10iops-tier")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fakeSession.ListVolumeProfilesCallCount())
+
+	// Listed profiles are cached by name
+	profile, err := sess.GetVolumeProfileByName("custom")
+	assert.NoError(t, err)
+	assert.Equal(t, "custom", profile.Name)
+	assert.Equal(t, 0, fakeSession.GetVolumeProfileByNameCallCount())
+}
+
+func TestProfileCacheContextSession(t *testing.T) {
+	release := make(chan struct{})
+	var calls int32
+	fakeSession := &fake.FakeContextSession{}
+	fakeSession.ProviderNameReturns("vpc")
+	fakeSession.GetVolumeProfileByNameStub = func(ctx context.Context, name string) (*provider.Profile, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return &provider.Profile{Name: name}, ctx.Err()
+	}
+	cs := NewProfileCache(ProfileCacheConfig{}).WrapContextSession(fakeSession)
+
+	// A caller whose ctx is done stops waiting without cancelling the shared call
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cs.GetVolumeProfileByName(ctx, "general-purpose")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			profile, err := cs.GetVolumeProfileByName(context.Background(), "general-purpose")
+			if assert.NoError(t, err) {
+				assert.Equal(t, "general-purpose", profile.Name)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,349 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+)
+
+// session is a provider.Session whose calls pass through a middleware chain
+type session struct {
+	ctx   context.Context
+	next  provider.Session
+	chain Middleware
+}
+
+var _ provider.Session = &session{}
+
+// Wrap returns a provider.Session which passes every volume, attachment, snapshot and access point
+// call of sess through the middlewares, the first being the outermost. ProviderName, Type,
+// GetProviderDisplayName and Close are delegated directly.
+// The Context of the calls is derived from ctx, usually the one sess was opened with, so that its
+// values, e.g. the provider.RequestID and the trace span, reach the middlewares. Its cancellation
+// and deadline are not: Session calls cannot be cancelled.
+func Wrap(ctx context.Context, sess provider.Session, middlewares ...Middleware) provider.Session {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &session{ctx: context.WithoutCancel(ctx), next: sess, chain: Chain(middlewares...)}
+}
+
+// invoke runs call through the chain
+func (s *session) invoke(method string, call func() error, args ...interface{}) error {
+	handler := s.chain(func(*Call) error { return call() })
+	return handler(&Call{Context: s.ctx, Method: method, Provider: s.next.ProviderName(), Args: args})
+}
+
+// ProviderName returns provider
+func (s *session) ProviderName() provider.VolumeProvider {
+	return s.next.ProviderName()
+}
+
+// Type returns the underlying volume type
+func (s *session) Type() provider.VolumeType {
+	return s.next.Type()
+}
+
+// GetProviderDisplayName returns the name of the provider that is being used
+func (s *session) GetProviderDisplayName() provider.VolumeProvider {
+	return s.next.GetProviderDisplayName()
+}
+
+// Close is called when the Session is nolonger required
+func (s *session) Close() {
+	s.next.Close()
+}
+
+// GetVolumeProfileByName gets volume profile by name
+func (s *session) GetVolumeProfileByName(name string) (profile *provider.Profile, err error) {
+	err = s.invoke("GetVolumeProfileByName", func() error {
+		profile, err = s.next.GetVolumeProfileByName(name)
+		return err
+	}, name)
+	return profile, err
+}
+
+// ListVolumeProfiles lists the volume profiles
+func (s *session) ListVolumeProfiles(limit int, start string) (profiles *provider.ProfileList, err error) {
+	err = s.invoke("ListVolumeProfiles", func() error {
+		profiles, err = s.next.ListVolumeProfiles(limit, start)
+		return err
+	}, limit, start)
+	return profiles, err
+}
+
+// CreateVolume creates a volume
+func (s *session) CreateVolume(volumeRequest provider.Volume) (volume *provider.Volume, err error) {
+	err = s.invoke("CreateVolume", func() error {
+		volume, err = s.next.CreateVolume(volumeRequest)
+		return err
+	}, volumeRequest)
+	return volume, err
+}
+
+// CreateVolumeFromSnapshot creates a volume from snapshot
+func (s *session) CreateVolumeFromSnapshot(snapshot provider.Snapshot, tags map[string]string) (volume *provider.Volume, err error) {
+	err = s.invoke("CreateVolumeFromSnapshot", func() error {
+		volume, err = s.next.CreateVolumeFromSnapshot(snapshot, tags)
+		return err
+	}, snapshot, tags)
+	return volume, err
+}
+
+// UpdateVolume updates the volume
+func (s *session) UpdateVolume(volume provider.Volume) error {
+	return s.invoke("UpdateVolume", func() error {
+		return s.next.UpdateVolume(volume)
+	}, volume)
+}
+
+// DeleteVolume deletes the volume
+func (s *session) DeleteVolume(volume *provider.Volume) error {
+	return s.invoke("DeleteVolume", func() error {
+		return s.next.DeleteVolume(volume)
+	}, volume)
+}
+
+// GetVolume by using ID
+func (s *session) GetVolume(id string) (volume *provider.Volume, err error) {
+	err = s.invoke("GetVolume", func() error {
+		volume, err = s.next.GetVolume(id)
+		return err
+	}, id)
+	return volume, err
+}
+
+// GetVolumeByName gets volume by name
+func (s *session) GetVolumeByName(name string) (volume *provider.Volume, err error) {
+	err = s.invoke("GetVolumeByName", func() error {
+		volume, err = s.next.GetVolumeByName(name)
+		return err
+	}, name)
+	return volume, err
+}
+
+// ListVolumes Get volume lists by using filters
+func (s *session) ListVolumes(limit int, start string, tags map[string]string) (volumes *provider.VolumeList, err error) {
+	err = s.invoke("ListVolumes", func() error {
+		volumes, err = s.next.ListVolumes(limit, start, tags)
+		return err
+	}, limit, start, tags)
+	return volumes, err
+}
+
+// GetVolumeByRequestID fetch the volume by request ID
+func (s *session) GetVolumeByRequestID(requestID string) (volume *provider.Volume, err error) {
+	err = s.invoke("GetVolumeByRequestID", func() error {
+		volume, err = s.next.GetVolumeByRequestID(requestID)
+		return err
+	}, requestID)
+	return volume, err
+}
+
+// AuthorizeVolume allows aceess to volume  based on given authorization
+func (s *session) AuthorizeVolume(volumeAuthorization provider.VolumeAuthorization) error {
+	return s.invoke("AuthorizeVolume", func() error {
+		return s.next.AuthorizeVolume(volumeAuthorization)
+	}, volumeAuthorization)
+}
+
+// ExpandVolume expands the volume
+func (s *session) ExpandVolume(expandVolumeRequest provider.ExpandVolumeRequest) (capacity int64, err error) {
+	err = s.invoke("ExpandVolume", func() error {
+		capacity, err = s.next.ExpandVolume(expandVolumeRequest)
+		return err
+	}, expandVolumeRequest)
+	return capacity, err
+}
+
+// WaitForVolumeAvailable waits for the volume to become available
+func (s *session) WaitForVolumeAvailable(volumeID string) (volume *provider.Volume, err error) {
+	err = s.invoke("WaitForVolumeAvailable", func() error {
+		volume, err = s.next.WaitForVolumeAvailable(volumeID)
+		return err
+	}, volumeID)
+	return volume, err
+}
+
+// CloneVolume clones the volume
+func (s *session) CloneVolume(sourceVolumeID string, template provider.Volume) (volume *provider.Volume, err error) {
+	err = s.invoke("CloneVolume", func() error {
+		volume, err = s.next.CloneVolume(sourceVolumeID, template)
+		return err
+	}, sourceVolumeID, template)
+	return volume, err
+}
+
+// AttachVolume attaches a volume
+func (s *session) AttachVolume(attachRequest provider.VolumeAttachmentRequest) (attachment *provider.VolumeAttachmentResponse, err error) {
+	err = s.invoke("AttachVolume", func() error {
+		attachment, err = s.next.AttachVolume(attachRequest)
+		return err
+	}, attachRequest)
+	return attachment, err
+}
+
+// DetachVolume detaches the volume
+func (s *session) DetachVolume(detachRequest provider.VolumeAttachmentRequest) (response *http.Response, err error) {
+	err = s.invoke("DetachVolume", func() error {
+		response, err = s.next.DetachVolume(detachRequest)
+		return err
+	}, detachRequest)
+	return response, err
+}
+
+// WaitForAttachVolume waits for the volume to be attached to the host
+func (s *session) WaitForAttachVolume(attachRequest provider.VolumeAttachmentRequest) (attachment *provider.VolumeAttachmentResponse, err error) {
+	err = s.invoke("WaitForAttachVolume", func() error {
+		attachment, err = s.next.WaitForAttachVolume(attachRequest)
+		return err
+	}, attachRequest)
+	return attachment, err
+}
+
+// WaitForDetachVolume waits for the volume to be detached from the host
+func (s *session) WaitForDetachVolume(detachRequest provider.VolumeAttachmentRequest) error {
+	return s.invoke("WaitForDetachVolume", func() error {
+		return s.next.WaitForDetachVolume(detachRequest)
+	}, detachRequest)
+}
+
+// GetVolumeAttachment retirves the current status of given volume attach request
+func (s *session) GetVolumeAttachment(attachRequest provider.VolumeAttachmentRequest) (attachment *provider.VolumeAttachmentResponse, err error) {
+	err = s.invoke("GetVolumeAttachment", func() error {
+		attachment, err = s.next.GetVolumeAttachment(attachRequest)
+		return err
+	}, attachRequest)
+	return attachment, err
+}
+
+// CreateSnapshot creates the snapshot on the volume
+func (s *session) CreateSnapshot(sourceVolumeID string, snapshotParameters provider.SnapshotParameters) (snapshot *provider.Snapshot, err error) {
+	err = s.invoke("CreateSnapshot", func() error {
+		snapshot, err = s.next.CreateSnapshot(sourceVolumeID, snapshotParameters)
+		return err
+	}, sourceVolumeID, snapshotParameters)
+	return snapshot, err
+}
+
+// DeleteSnapshot deletes the snapshot
+func (s *session) DeleteSnapshot(snapshot *provider.Snapshot) error {
+	return s.invoke("DeleteSnapshot", func() error {
+		return s.next.DeleteSnapshot(snapshot)
+	}, snapshot)
+}
+
+// GetSnapshot gets the snapshot
+func (s *session) GetSnapshot(snapshotID string, sourceVolumeID ...string) (snapshot *provider.Snapshot, err error) {
+	err = s.invoke("GetSnapshot", func() error {
+		snapshot, err = s.next.GetSnapshot(snapshotID, sourceVolumeID...)
+		return err
+	}, snapshotID, sourceVolumeID)
+	return snapshot, err
+}
+
+// GetSnapshotByName gets the snapshot by name
+func (s *session) GetSnapshotByName(snapshotName string, sourceVolumeID ...string) (snapshot *provider.Snapshot, err error) {
+	err = s.invoke("GetSnapshotByName", func() error {
+		snapshot, err = s.next.GetSnapshotByName(snapshotName, sourceVolumeID...)
+		return err
+	}, snapshotName, sourceVolumeID)
+	return snapshot, err
+}
+
+// ListSnapshots lists snapshots by using tags
+func (s *session) ListSnapshots(limit int, start string, tags map[string]string) (snapshots *provider.SnapshotList, err error) {
+	err = s.invoke("ListSnapshots", func() error {
+		snapshots, err = s.next.ListSnapshots(limit, start, tags)
+		return err
+	}, limit, start, tags)
+	return snapshots, err
+}
+
+// WaitForSnapshotReady waits for the snapshot to be ReadyToUse
+func (s *session) WaitForSnapshotReady(snapshotID string, sourceVolumeID ...string) (snapshot *provider.Snapshot, err error) {
+	err = s.invoke("WaitForSnapshotReady", func() error {
+		snapshot, err = s.next.WaitForSnapshotReady(snapshotID, sourceVolumeID...)
+		return err
+	}, snapshotID, sourceVolumeID)
+	return snapshot, err
+}
+
+// CreateVolumeAccessPoint creates a volume access point
+func (s *session) CreateVolumeAccessPoint(accessPointRequest provider.VolumeAccessPointRequest) (accessPoint *provider.VolumeAccessPointResponse, err error) {
+	err = s.invoke("CreateVolumeAccessPoint", func() error {
+		accessPoint, err = s.next.CreateVolumeAccessPoint(accessPointRequest)
+		return err
+	}, accessPointRequest)
+	return accessPoint, err
+}
+
+// DeleteVolumeAccessPoint deletes a volume access point
+func (s *session) DeleteVolumeAccessPoint(deleteAccessPointRequest provider.VolumeAccessPointRequest) (response *http.Response, err error) {
+	err = s.invoke("DeleteVolumeAccessPoint", func() error {
+		response, err = s.next.DeleteVolumeAccessPoint(deleteAccessPointRequest)
+		return err
+	}, deleteAccessPointRequest)
+	return response, err
+}
+
+// WaitForCreateVolumeAccessPoint waits for the volume access point to be created
+func (s *session) WaitForCreateVolumeAccessPoint(accessPointRequest provider.VolumeAccessPointRequest) (accessPoint *provider.VolumeAccessPointResponse, err error) {
+	err = s.invoke("WaitForCreateVolumeAccessPoint", func() error {
+		accessPoint, err = s.next.WaitForCreateVolumeAccessPoint(accessPointRequest)
+		return err
+	}, accessPointRequest)
+	return accessPoint, err
+}
+
+// WaitForDeleteVolumeAccessPoint waits for the volume access point to be deleted
+func (s *session) WaitForDeleteVolumeAccessPoint(deleteAccessPointRequest provider.VolumeAccessPointRequest) error {
+	return s.invoke("WaitForDeleteVolumeAccessPoint", func() error {
+		return s.next.WaitForDeleteVolumeAccessPoint(deleteAccessPointRequest)
+	}, deleteAccessPointRequest)
+}
+
+// GetVolumeAccessPoint retrieves the volume access point
+func (s *session) GetVolumeAccessPoint(accessPointRequest provider.VolumeAccessPointRequest) (accessPoint *provider.VolumeAccessPointResponse, err error) {
+	err = s.invoke("GetVolumeAccessPoint", func() error {
+		accessPoint, err = s.next.GetVolumeAccessPoint(accessPointRequest)
+		return err
+	}, accessPointRequest)
+	return accessPoint, err
+}
+
+// GetSubnetForVolumeAccessPoint returns the subnet for the volume access point
+func (s *session) GetSubnetForVolumeAccessPoint(subnetRequest provider.SubnetRequest) (subnet string, err error) {
+	err = s.invoke("GetSubnetForVolumeAccessPoint", func() error {
+		subnet, err = s.next.GetSubnetForVolumeAccessPoint(subnetRequest)
+		return err
+	}, subnetRequest)
+	return subnet, err
+}
+
+// GetSecurityGroupForVolumeAccessPoint returns the security group for the volume access point
+func (s *session) GetSecurityGroupForVolumeAccessPoint(securityGroupRequest provider.SecurityGroupRequest) (securityGroup string, err error) {
+	err = s.invoke("GetSecurityGroupForVolumeAccessPoint", func() error {
+		securityGroup, err = s.next.GetSecurityGroupForVolumeAccessPoint(securityGroupRequest)
+		return err
+	}, securityGroupRequest)
+	return securityGroup, err
+}
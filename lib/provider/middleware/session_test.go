@@ -0,0 +1,71 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package middleware ...
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/fake"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestWrap(t *testing.T) {
+	fakeSession := &fake.FakeSession{}
+	fakeSession.ProviderNameReturns("vpc")
+	fakeSession.GetVolumeReturns(&provider.Volume{VolumeID: "vol"}, nil)
+	fakeSession.DeleteVolumeReturns(errors.New("delete failed"))
+	fakeSession.GetSnapshotStub = func(id string, sourceVolumeID ...string) (*provider.Snapshot, error) {
+		panic("provider bug")
+	}
+
+	var calls []*Call
+	capture := func(next Handler) Handler {
+		return func(call *Call) error {
+			calls = append(calls, call)
+			return next(call)
+		}
+	}
+	sess := Wrap(context.Background(), fakeSession, Recovery(zap.NewNop()), Logging(zap.NewNop()), Metrics(nil), capture)
+	assert.Equal(t, provider.VolumeProvider("vpc"), sess.ProviderName())
+
+	volume, err := sess.GetVolume("vol")
+	assert.NoError(t, err)
+	assert.Equal(t, "vol", volume.VolumeID)
+	assert.Equal(t, "vol", fakeSession.GetVolumeArgsForCall(0))
+
+	assert.EqualError(t, sess.DeleteVolume(volume), "delete failed")
+	assert.Equal(t, volume, fakeSession.DeleteVolumeArgsForCall(0))
+
+	snapshot, err := sess.GetSnapshot("snap", "vol")
+	assert.Nil(t, snapshot)
+	assert.Equal(t, reasoncode.ErrorPanic, util.ErrorReasonCode(err))
+
+	assert.Len(t, calls, 3)
+	assert.Equal(t, &Call{Context: context.WithoutCancel(context.Background()), Method: "GetVolume", Provider: "vpc", Args: []interface{}{"vol"}}, calls[0])
+	assert.Equal(t, "DeleteVolume", calls[1].Method)
+	assert.Equal(t, &Call{Context: context.WithoutCancel(context.Background()), Method: "GetSnapshot", Provider: "vpc", Args: []interface{}{"snap", []string{"vol"}}}, calls[2])
+
+	sess.Close()
+	assert.Equal(t, 1, fakeSession.CloseCallCount())
+}
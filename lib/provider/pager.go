@@ -0,0 +1,158 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+)
+
+const (
+	// DefaultPageSize is the limit of the first page listed if PageOptions.PageSize is not set
+	DefaultPageSize = 50
+
+	// DefaultMaxPageSize bounds the page size if PageOptions.MaxPageSize is not set
+	DefaultMaxPageSize = 100
+)
+
+// PageOptions configures the pagination helpers
+type PageOptions struct {
+	// PageSize is the limit of the first page, DefaultPageSize if not set.
+	// The limit doubles with every page up to MaxPageSize, and drops to the size of the
+	// pages the provider returns if it caps them below the limit.
+	PageSize int
+
+	// MaxPageSize bounds the limit, DefaultMaxPageSize if not set
+	MaxPageSize int
+
+	// Start is the token of the first page, the first page of the list if empty
+	Start string
+
+	// Tags filters the list
+	Tags map[string]string
+}
+
+// IterVolumes returns an iterator over the volumes listed by ListVolumes, which lists the pages lazily.
+// It yields the error and stops if ListVolumes fails, ctx is done, or ListVolumes returns a Next token
+// that was already listed.
+func IterVolumes(ctx context.Context, vm VolumeManager, opts PageOptions) iter.Seq2[*Volume, error] {
+	return paginate(ctx, func(limit int, start string) ([]*Volume, int, string, error) {
+		list, err := vm.ListVolumes(limit, start, opts.Tags)
+		if err != nil || list == nil {
+			return nil, 0, "", err
+		}
+		return list.Volumes, len(list.Volumes), list.Next, nil
+	}, opts)
+}
+
+// IterSnapshots returns an iterator over the snapshots listed by ListSnapshots, which lists the pages lazily.
+// It yields the error and stops if ListSnapshots fails, ctx is done, or ListSnapshots returns a Next token
+// that was already listed.
+func IterSnapshots(ctx context.Context, sm SnapshotManager, opts PageOptions) iter.Seq2[*Snapshot, error] {
+	return paginate(ctx, func(limit int, start string) ([]*Snapshot, int, string, error) {
+		list, err := sm.ListSnapshots(limit, start, opts.Tags)
+		if err != nil || list == nil {
+			return nil, 0, "", err
+		}
+		return list.Snapshots, len(list.Snapshots), list.Next, nil
+	}, opts)
+}
+
+// ForEachVolume calls fn for each volume listed by ListVolumes, until fn or the listing fails
+func ForEachVolume(ctx context.Context, vm VolumeManager, opts PageOptions, fn func(*Volume) error) error {
+	return forEach(IterVolumes(ctx, vm, opts), fn)
+}
+
+// ForEachSnapshot calls fn for each snapshot listed by ListSnapshots, until fn or the listing fails
+func ForEachSnapshot(ctx context.Context, sm SnapshotManager, opts PageOptions, fn func(*Snapshot) error) error {
+	return forEach(IterSnapshots(ctx, sm, opts), fn)
+}
+
+// forEach ...
+func forEach[T any](seq iter.Seq2[T, error], fn func(T) error) error {
+	for item, err := range seq {
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paginate returns an iterator over the items of the pages returned by list. list also returns the number
+// of items the provider listed in the page, which differs from the number of items if it filtered them.
+func paginate[T any](ctx context.Context, list func(limit int, start string) ([]T, int, string, error), opts PageOptions) iter.Seq2[T, error] {
+	maxPageSize := opts.MaxPageSize
+	if maxPageSize <= 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+	size := opts.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		maxSize := maxPageSize
+		limit := min(size, maxSize)
+		start := opts.Start
+		seen := map[string]bool{start: true}
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			items, listed, next, err := list(limit, start)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			if seen[next] {
+				yield(zero, Error{
+					Fault: Fault{
+						Message:    fmt.Sprintf("The list returned the token '%s' of a page that was already listed", next),
+						ReasonCode: reasoncode.ErrorRepeatedPageToken,
+						Properties: map[string]string{"start": start, "next": next},
+					},
+				})
+				return
+			}
+			seen[next] = true
+			start = next
+
+			if listed > 0 && listed < limit {
+				// the provider caps its pages below the limit
+				maxSize = listed
+			}
+			limit = min(2*limit, maxSize)
+		}
+	}
+}
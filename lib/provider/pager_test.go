@@ -0,0 +1,181 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedSession is a Session listing count volumes and snapshots in pages of at most pageCap items
+type pagedSession struct {
+	DefaultVolumeProvider
+	count   int
+	pageCap int
+	loopAt  string
+	limits  []int
+	starts  []string
+}
+
+func (s *pagedSession) page(limit int, start string) (first, last int, next string, err error) {
+	s.limits = append(s.limits, limit)
+	s.starts = append(s.starts, start)
+	if start != "" {
+		if first, err = strconv.Atoi(start); err != nil {
+			return 0, 0, "", errors.New("bad start")
+		}
+	}
+	last = min(first+limit, first+s.pageCap, s.count)
+	if last < s.count {
+		next = strconv.Itoa(last)
+	}
+	if s.loopAt != "" && start == s.loopAt {
+		next = start
+	}
+	return first, last, next, nil
+}
+
+func (s *pagedSession) ListVolumes(limit int, start string, tags map[string]string) (*VolumeList, error) {
+	first, last, next, err := s.page(limit, start)
+	if err != nil {
+		return nil, err
+	}
+	list := &VolumeList{Next: next}
+	for i := first; i < last; i++ {
+		list.Volumes = append(list.Volumes, &Volume{VolumeID: strconv.Itoa(i)})
+	}
+	return list, nil
+}
+
+func (s *pagedSession) ListSnapshots(limit int, start string, tags map[string]string) (*SnapshotList, error) {
+	first, last, next, err := s.page(limit, start)
+	if err != nil {
+		return nil, err
+	}
+	list := &SnapshotList{Next: next}
+	for i := first; i < last; i++ {
+		list.Snapshots = append(list.Snapshots, &Snapshot{SnapshotID: strconv.Itoa(i)})
+	}
+	return list, nil
+}
+
+func TestIterVolumes(t *testing.T) {
+	sess := &pagedSession{count: 20, pageCap: 5}
+	var ids []string
+	for volume, err := range IterVolumes(context.Background(), sess, PageOptions{PageSize: 2}) {
+		require.NoError(t, err)
+		ids = append(ids, volume.VolumeID)
+	}
+	assert.Len(t, ids, 20)
+	assert.Equal(t, "19", ids[19])
+	assert.Equal(t, []int{2, 4, 8, 5, 5}, sess.limits)
+
+	sess = &pagedSession{count: 300, pageCap: 1000}
+	count := 0
+	for _, err := range IterVolumes(context.Background(), sess, PageOptions{}) {
+		require.NoError(t, err)
+		count++
+	}
+	assert.Equal(t, 300, count)
+	assert.Equal(t, []int{DefaultPageSize, DefaultMaxPageSize, DefaultMaxPageSize, DefaultMaxPageSize}, sess.limits)
+}
+
+func TestIterVolumesStops(t *testing.T) {
+	sess := &pagedSession{count: 20, pageCap: 5}
+	count := 0
+	for range IterVolumes(context.Background(), sess, PageOptions{PageSize: 5}) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+	assert.Len(t, sess.limits, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sess = &pagedSession{count: 20, pageCap: 5}
+	var err error
+	count = 0
+	for _, err = range IterVolumes(ctx, sess, PageOptions{PageSize: 5}) {
+		if err != nil {
+			break
+		}
+		count++
+		cancel()
+	}
+	assert.Equal(t, 5, count)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	sess = &pagedSession{count: 20, pageCap: 5}
+	for _, err = range IterVolumes(context.Background(), sess, PageOptions{Start: "x"}) {
+	}
+	assert.EqualError(t, err, "bad start")
+}
+
+func TestIterVolumesRepeatedToken(t *testing.T) {
+	sess := &pagedSession{count: 20, pageCap: 5, loopAt: "10"}
+	count := 0
+	var err error
+	for _, err = range IterVolumes(context.Background(), sess, PageOptions{PageSize: 5}) {
+		if err != nil {
+			break
+		}
+		count++
+	}
+	assert.Equal(t, 15, count)
+	assert.True(t, errors.Is(err, reasoncode.ErrorRepeatedPageToken))
+	assert.Equal(t, []string{"", "5", "10"}, sess.starts)
+}
+
+func TestIterSnapshots(t *testing.T) {
+	sess := &pagedSession{count: 7, pageCap: 3}
+	var ids []string
+	for snapshot, err := range IterSnapshots(context.Background(), sess, PageOptions{PageSize: 3, Start: "1"}) {
+		require.NoError(t, err)
+		ids = append(ids, snapshot.SnapshotID)
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4", "5", "6"}, ids)
+}
+
+func TestForEach(t *testing.T) {
+	sess := &pagedSession{count: 7, pageCap: 3}
+	count := 0
+	assert.NoError(t, ForEachVolume(context.Background(), sess, PageOptions{}, func(*Volume) error {
+		count++
+		return nil
+	}))
+	assert.Equal(t, 7, count)
+
+	stop := errors.New("stop")
+	count = 0
+	assert.Equal(t, stop, ForEachSnapshot(context.Background(), sess, PageOptions{}, func(snapshot *Snapshot) error {
+		count++
+		if snapshot.SnapshotID == "4" {
+			return stop
+		}
+		return nil
+	}))
+	assert.Equal(t, 5, count)
+}
@@ -0,0 +1,231 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+)
+
+// Types of the CapIops of a Profile
+const (
+	// CapIopsTypeRange allows the values from Min to Max in multiples of Step
+	CapIopsTypeRange = "range"
+
+	// CapIopsTypeFixed allows Value only
+	CapIopsTypeFixed = "fixed"
+
+	// CapIopsTypeDependent derives the value from the capacity: Value IOPS per GiB
+	CapIopsTypeDependent = "dependent"
+)
+
+// Fields of the requests checked against a Profile
+const (
+	ProfileFieldCapacity = "capacity"
+	ProfileFieldIops     = "iops"
+)
+
+// ProfileProperty is the property of the errors of ValidateVolumeRequest naming the profile.
+// Each violation adds a property named after its field, and one named after its field with
+// a "Suggested" suffix listing the nearest valid values.
+const ProfileProperty = "profile"
+
+// ProfileViolation is a field of a request which the profile does not allow
+type ProfileViolation struct {
+	// Field is ProfileFieldCapacity or ProfileFieldIops
+	Field string
+
+	// Value requested
+	Value int64
+
+	// Reason the value is not allowed
+	Reason string
+
+	// Suggested are the nearest values the profile allows, in ascending order
+	Suggested []int64
+}
+
+// String ...
+func (v ProfileViolation) String() string {
+	msg := fmt.Sprintf("%s %d %s", v.Field, v.Value, v.Reason)
+	if len(v.Suggested) > 0 {
+		msg += ", nearest valid values are " + joinInt64(v.Suggested, ", ")
+	}
+	return msg
+}
+
+// Allows reports whether the range allows the value. Ranges without a Type are treated as CapIopsTypeRange,
+// unset Min and Max are not checked, and a dependent range allows every value.
+func (c CapIops) Allows(value int64) bool {
+	switch c.Type {
+	case CapIopsTypeFixed:
+		return value == int64(c.Value)
+	case CapIopsTypeDependent:
+		return true
+	}
+	if (c.Min > 0 && value < int64(c.Min)) || (c.Max > 0 && value > int64(c.Max)) {
+		return false
+	}
+	return (value-int64(c.Min))%c.step() == 0
+}
+
+// Nearest returns the values the range allows which are nearest to value, below and above it,
+// or value alone if the range allows it
+func (c CapIops) Nearest(value int64) []int64 {
+	switch c.Type {
+	case CapIopsTypeFixed:
+		return []int64{int64(c.Value)}
+	case CapIopsTypeDependent:
+		return []int64{value}
+	}
+	minimum, step := int64(c.Min), c.step()
+	maximum := int64(c.Max)
+	if maximum > 0 {
+		// the largest value the range allows
+		maximum = minimum + (maximum-minimum)/step*step
+	}
+	switch {
+	case value <= minimum:
+		return []int64{minimum}
+	case maximum > 0 && value >= maximum:
+		return []int64{maximum}
+	}
+	below := minimum + (value-minimum)/step*step
+	if below == value {
+		return []int64{value}
+	}
+	above := below + step
+	if maximum > 0 && above > maximum {
+		return []int64{below}
+	}
+	return []int64{below, above}
+}
+
+// step ...
+func (c CapIops) step() int64 {
+	if c.Step <= 0 {
+		return 1
+	}
+	return int64(c.Step)
+}
+
+// CheckVolumeRequest returns the capacity and IOPS of the volume request which the profile does not allow.
+// Unset fields are not checked.
+func CheckVolumeRequest(volume Volume, profile Profile) []ProfileViolation {
+	var violations []ProfileViolation
+	if volume.Capacity != nil {
+		violations = appendViolation(violations, ProfileFieldCapacity, int64(*volume.Capacity), profile.Capacity, "GiB")
+	}
+	if volume.Iops != nil && *volume.Iops != "" {
+		iops, err := strconv.ParseInt(*volume.Iops, 10, 64)
+		switch {
+		case err != nil:
+			violations = append(violations, ProfileViolation{Field: ProfileFieldIops, Reason: fmt.Sprintf("'%s' is not a number", *volume.Iops)})
+		case profile.Iops.Type == CapIopsTypeDependent:
+			violation := ProfileViolation{
+				Field:  ProfileFieldIops,
+				Value:  iops,
+				Reason: fmt.Sprintf("cannot be set, profile '%s' has %d IOPS per GiB", profile.Name, profile.Iops.Value),
+			}
+			if volume.Capacity != nil {
+				violation.Suggested = []int64{int64(*volume.Capacity) * int64(profile.Iops.Value)}
+			}
+			violations = append(violations, violation)
+		default:
+			violations = appendViolation(violations, ProfileFieldIops, iops, profile.Iops, "IOPS")
+		}
+	}
+	return violations
+}
+
+// CheckExpandVolumeRequest returns the capacity of the expand request if the profile does not allow it
+func CheckExpandVolumeRequest(request ExpandVolumeRequest, profile Profile) []ProfileViolation {
+	return appendViolation(nil, ProfileFieldCapacity, request.Capacity, profile.Capacity, "GiB")
+}
+
+// ValidateVolumeRequest checks the capacity and IOPS of the volume request against the profile, as
+// returned by GetVolumeProfileByName. It fails with reasoncode.ErrorBadRequest if the profile does
+// not allow them, suggesting the nearest values it allows.
+func ValidateVolumeRequest(volume Volume, profile *Profile) error {
+	if profile == nil {
+		return nil
+	}
+	return newProfileError("Volume request", *profile, CheckVolumeRequest(volume, *profile))
+}
+
+// ValidateExpandVolumeRequest checks the capacity of the expand request against the profile, like ValidateVolumeRequest
+func ValidateExpandVolumeRequest(request ExpandVolumeRequest, profile *Profile) error {
+	if profile == nil {
+		return nil
+	}
+	return newProfileError("Expand volume request", *profile, CheckExpandVolumeRequest(request, *profile))
+}
+
+// appendViolation appends a violation if the range does not allow the value
+func appendViolation(violations []ProfileViolation, field string, value int64, bounds CapIops, unit string) []ProfileViolation {
+	if bounds.Allows(value) {
+		return violations
+	}
+	var reason string
+	switch {
+	case bounds.Type == CapIopsTypeFixed:
+		reason = fmt.Sprintf("is not the fixed %d %s", bounds.Value, unit)
+	case (bounds.Min > 0 && value < int64(bounds.Min)) || (bounds.Max > 0 && value > int64(bounds.Max)):
+		reason = fmt.Sprintf("is outside the range %d-%d %s", bounds.Min, bounds.Max, unit)
+	case bounds.Min == 0:
+		reason = fmt.Sprintf("is not a multiple of %d %s", bounds.step(), unit)
+	default:
+		reason = fmt.Sprintf("is not %d plus a multiple of %d %s", bounds.Min, bounds.step(), unit)
+	}
+	return append(violations, ProfileViolation{Field: field, Value: value, Reason: reason, Suggested: bounds.Nearest(value)})
+}
+
+// newProfileError returns nil if there are no violations
+func newProfileError(request string, profile Profile, violations []ProfileViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	properties := map[string]string{ProfileProperty: profile.Name}
+	msgs := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		msgs = append(msgs, violation.String())
+		properties[violation.Field] = strconv.FormatInt(violation.Value, 10)
+		if len(violation.Suggested) > 0 {
+			properties[violation.Field+"Suggested"] = joinInt64(violation.Suggested, ",")
+		}
+	}
+	return Error{
+		Fault: Fault{
+			Message:    fmt.Sprintf("%s does not match profile '%s': %s", request, profile.Name, strings.Join(msgs, "; ")),
+			ReasonCode: reasoncode.ErrorBadRequest,
+			Properties: properties,
+		},
+	}
+}
+
+// joinInt64 ...
+func joinInt64(values []int64, sep string) string {
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		strs = append(strs, strconv.FormatInt(value, 10))
+	}
+	return strings.Join(strs, sep)
+}
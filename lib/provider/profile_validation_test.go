@@ -0,0 +1,103 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapIopsNearest(t *testing.T) {
+	stepped := CapIops{Type: CapIopsTypeRange, Min: 10, Max: 105, Step: 10}
+	testcases := []struct {
+		name    string
+		bounds  CapIops
+		value   int64
+		allowed bool
+		nearest []int64
+	}{
+		{name: "aligned", bounds: stepped, value: 30, allowed: true, nearest: []int64{30}},
+		{name: "between steps", bounds: stepped, value: 35, nearest: []int64{30, 40}},
+		{name: "below minimum", bounds: stepped, value: 5, nearest: []int64{10}},
+		{name: "above maximum", bounds: stepped, value: 200, nearest: []int64{100}},
+		{name: "above last step", bounds: stepped, value: 103, nearest: []int64{100}},
+		{name: "no step", bounds: CapIops{Min: 10, Max: 100}, value: 42, allowed: true, nearest: []int64{42}},
+		{name: "no maximum", bounds: CapIops{Min: 0, Step: 8}, value: 1001, nearest: []int64{1000, 1008}},
+		{name: "fixed", bounds: CapIops{Type: CapIopsTypeFixed, Value: 100}, value: 50, nearest: []int64{100}},
+		{name: "fixed value", bounds: CapIops{Type: CapIopsTypeFixed, Value: 100}, value: 100, allowed: true, nearest: []int64{100}},
+		{name: "dependent", bounds: CapIops{Type: CapIopsTypeDependent, Value: 10}, value: 7, allowed: true, nearest: []int64{7}},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			assert.Equal(t, testcase.allowed, testcase.bounds.Allows(testcase.value))
+			assert.Equal(t, testcase.nearest, testcase.bounds.Nearest(testcase.value))
+		})
+	}
+}
+
+func TestValidateVolumeRequest(t *testing.T) {
+	custom := &Profile{
+		Name:     "custom",
+		Capacity: CapIops{Type: CapIopsTypeRange, Min: 10, Max: 16000, Step: 1},
+		Iops:     CapIops{Type: CapIopsTypeRange, Min: 100, Max: 48000, Step: 100},
+	}
+	capacity := 20
+	iops := "3000"
+	assert.NoError(t, ValidateVolumeRequest(Volume{Capacity: &capacity, Iops: &iops}, custom))
+	assert.NoError(t, ValidateVolumeRequest(Volume{}, custom))
+	assert.NoError(t, ValidateVolumeRequest(Volume{Capacity: &capacity}, nil))
+
+	capacity = 5
+	iops = "3050"
+	err := ValidateVolumeRequest(Volume{Capacity: &capacity, Iops: &iops}, custom)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, reasoncode.ErrorBadRequest))
+	assert.Equal(t, "Volume request does not match profile 'custom': capacity 5 is outside the range 10-16000 GiB, "+
+		"nearest valid values are 10; iops 3050 is not 100 plus a multiple of 100 IOPS, nearest valid values are 3000, 3100", err.Error())
+
+	var perr Error
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, map[string]string{
+		ProfileProperty: "custom", "capacity": "5", "capacitySuggested": "10", "iops": "3050", "iopsSuggested": "3000,3100",
+	}, perr.Properties())
+
+	iops = "many"
+	violations := CheckVolumeRequest(Volume{Iops: &iops}, *custom)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "iops 0 'many' is not a number", violations[0].String())
+
+	tiered := Profile{Name: "10iops-tier", Iops: CapIops{Type: CapIopsTypeDependent, Value: 10}}
+	capacity = 20
+	iops = "300"
+	violations = CheckVolumeRequest(Volume{Capacity: &capacity, Iops: &iops}, tiered)
+	require.Len(t, violations, 1)
+	assert.Equal(t, []int64{200}, violations[0].Suggested)
+}
+
+func TestValidateExpandVolumeRequest(t *testing.T) {
+	profile := &Profile{Name: "sdp", Capacity: CapIops{Type: CapIopsTypeRange, Min: 1, Max: 32000, Step: 1}}
+	assert.NoError(t, ValidateExpandVolumeRequest(ExpandVolumeRequest{Capacity: 100}, profile))
+
+	err := ValidateExpandVolumeRequest(ExpandVolumeRequest{Capacity: 40000}, profile)
+	assert.True(t, errors.Is(err, reasoncode.ErrorBadRequest))
+	assert.Contains(t, err.Error(), "capacity 40000 is outside the range 1-32000 GiB, nearest valid values are 32000")
+}
@@ -33,4 +33,8 @@ type SnapshotManager interface {
 
 	// Snapshot list by using tags
 	ListSnapshots(limit int, start string, tags map[string]string) (*SnapshotList, error)
+
+	// WaitForSnapshotReady waits for the snapshot to be ReadyToUse
+	// Return error if wait is timed out OR there is other error
+	WaitForSnapshotReady(snapshotID string, sourceVolumeID ...string) (*Snapshot, error)
 }
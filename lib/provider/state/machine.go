@@ -0,0 +1,188 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package state defines the lifecycle statuses of volumes, attachments, access points and snapshots,
+// and the state machines of their legal transitions.
+package state
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Operation is a user operation that is only safe in some states
+type Operation string
+
+const (
+	// OperationDelete deletes the resource
+	OperationDelete Operation = "delete"
+
+	// OperationExpand expands the volume
+	OperationExpand Operation = "expand"
+
+	// OperationAttach attaches the volume to an instance
+	OperationAttach Operation = "attach"
+
+	// OperationDetach detaches the volume from an instance
+	OperationDetach Operation = "detach"
+
+	// OperationSnapshot creates a snapshot of the volume
+	OperationSnapshot Operation = "snapshot"
+
+	// OperationRestore creates a volume from the snapshot
+	OperationRestore Operation = "restore"
+)
+
+// Machine is the state machine of a resource with statuses of type S
+type Machine[S ~string] struct {
+	name        string
+	transitions map[S][]S
+	terminal    map[S]bool
+	allowed     map[Operation]map[S]bool
+}
+
+// Definition describes a Machine
+type Definition[S ~string] struct {
+	// Transitions lists the states each state can move to. Every state must be a key.
+	Transitions map[S][]S
+
+	// Terminal states are not left without user action, so waiting in them for another state is pointless
+	Terminal []S
+
+	// Allowed lists the states each operation is safe in
+	Allowed map[Operation][]S
+}
+
+// NewMachine returns the machine of the definition. It panics if the definition refers to undeclared states.
+func NewMachine[S ~string](name string, def Definition[S]) *Machine[S] {
+	m := &Machine[S]{
+		name:        name,
+		transitions: def.Transitions,
+		terminal:    map[S]bool{},
+		allowed:     map[Operation]map[S]bool{},
+	}
+	for from, targets := range def.Transitions {
+		for _, to := range targets {
+			m.mustBeKnown(to, fmt.Sprintf("transition from %s", from))
+		}
+	}
+	for _, s := range def.Terminal {
+		m.mustBeKnown(s, "terminal")
+		m.terminal[s] = true
+	}
+	for op, states := range def.Allowed {
+		m.allowed[op] = map[S]bool{}
+		for _, s := range states {
+			m.mustBeKnown(s, string(op))
+			m.allowed[op][s] = true
+		}
+	}
+	return m
+}
+
+func (m *Machine[S]) mustBeKnown(s S, where string) {
+	if !m.Known(s) {
+		panic(fmt.Sprintf("%s state machine: %s state '%s' is not declared", m.name, where, s))
+	}
+}
+
+// Name ...
+func (m *Machine[S]) Name() string {
+	return m.name
+}
+
+// States returns the states in sorted order
+func (m *Machine[S]) States() []S {
+	states := make([]S, 0, len(m.transitions))
+	for s := range m.transitions {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i] < states[j] })
+	return states
+}
+
+// Known returns true if s is a state of the machine
+func (m *Machine[S]) Known(s S) bool {
+	_, ok := m.transitions[s]
+	return ok
+}
+
+// IsTerminal returns true if s is a terminal state
+func (m *Machine[S]) IsTerminal(s S) bool {
+	return m.terminal[s]
+}
+
+// CanTransition returns true if the machine can move from one state to the other.
+// Staying in a known state is always legal.
+func (m *Machine[S]) CanTransition(from, to S) bool {
+	if !m.Known(from) || !m.Known(to) {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	for _, s := range m.transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition returns a *TransitionError if the machine cannot move from one state to the other
+func (m *Machine[S]) Transition(from, to S) error {
+	if m.CanTransition(from, to) {
+		return nil
+	}
+	return &TransitionError{Machine: m.name, From: string(from), To: string(to)}
+}
+
+// Allows returns true if the operation is safe in state s
+func (m *Machine[S]) Allows(s S, op Operation) bool {
+	return m.allowed[op][s]
+}
+
+// Check returns an *OperationError if the operation is not safe in state s
+func (m *Machine[S]) Check(s S, op Operation) error {
+	if m.Allows(s, op) {
+		return nil
+	}
+	return &OperationError{Machine: m.name, State: string(s), Operation: op}
+}
+
+// TransitionError is returned for an illegal transition
+type TransitionError struct {
+	Machine string
+	From    string
+	To      string
+}
+
+// Error ...
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s cannot move from state '%s' to '%s'", e.Machine, e.From, e.To)
+}
+
+// OperationError is returned for an operation that is not safe in the current state
+type OperationError struct {
+	Machine   string
+	State     string
+	Operation Operation
+}
+
+// Error ...
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("%s cannot %s in state '%s'", e.Machine, e.Operation, e.State)
+}
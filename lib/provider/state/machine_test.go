@@ -0,0 +1,89 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package state ...
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lightStatus string
+
+func newLightMachine() *Machine[lightStatus] {
+	return NewMachine("light", Definition[lightStatus]{
+		Transitions: map[lightStatus][]lightStatus{
+			"off":    {"on", "broken"},
+			"on":     {"off", "broken"},
+			"broken": {},
+		},
+		Terminal: []lightStatus{"broken"},
+		Allowed: map[Operation][]lightStatus{
+			OperationDelete: {"off", "broken"},
+		},
+	})
+}
+
+func TestMachine(t *testing.T) {
+	m := newLightMachine()
+	assert.Equal(t, "light", m.Name())
+	assert.Equal(t, []lightStatus{"broken", "off", "on"}, m.States())
+	assert.True(t, m.Known("on"))
+	assert.False(t, m.Known("dimmed"))
+	assert.True(t, m.IsTerminal("broken"))
+	assert.False(t, m.IsTerminal("on"))
+
+	assert.True(t, m.CanTransition("off", "on"))
+	assert.True(t, m.CanTransition("on", "on"))
+	assert.False(t, m.CanTransition("broken", "on"))
+	assert.False(t, m.CanTransition("dimmed", "dimmed"))
+	assert.Nil(t, m.Transition("on", "broken"))
+
+	err := m.Transition("broken", "off")
+	var transitionErr *TransitionError
+	assert.True(t, errors.As(err, &transitionErr))
+	assert.Equal(t, &TransitionError{Machine: "light", From: "broken", To: "off"}, transitionErr)
+	assert.Equal(t, "light cannot move from state 'broken' to 'off'", err.Error())
+
+	assert.True(t, m.Allows("off", OperationDelete))
+	assert.False(t, m.Allows("on", OperationDelete))
+	assert.False(t, m.Allows("off", OperationExpand))
+	assert.Nil(t, m.Check("broken", OperationDelete))
+	err = m.Check("on", OperationDelete)
+	assert.Equal(t, &OperationError{Machine: "light", State: "on", Operation: OperationDelete}, err)
+	assert.Equal(t, "light cannot delete in state 'on'", err.Error())
+}
+
+func TestNewMachineUndeclaredState(t *testing.T) {
+	assert.PanicsWithValue(t, "light state machine: transition from off state 'on' is not declared", func() {
+		NewMachine("light", Definition[lightStatus]{Transitions: map[lightStatus][]lightStatus{"off": {"on"}}})
+	})
+	assert.Panics(t, func() {
+		NewMachine("light", Definition[lightStatus]{
+			Transitions: map[lightStatus][]lightStatus{"off": {}},
+			Terminal:    []lightStatus{"broken"},
+		})
+	})
+	assert.Panics(t, func() {
+		NewMachine("light", Definition[lightStatus]{
+			Transitions: map[lightStatus][]lightStatus{"off": {}},
+			Allowed:     map[Operation][]lightStatus{OperationDelete: {"on"}},
+		})
+	})
+}
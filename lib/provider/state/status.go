@@ -0,0 +1,180 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package state ...
+package state
+
+import "strings"
+
+// VolumeStatus is the status of a volume
+type VolumeStatus string
+
+// Volume statuses
+const (
+	VolumePending         VolumeStatus = "pending"
+	VolumeAvailable       VolumeStatus = "available"
+	VolumeUpdating        VolumeStatus = "updating"
+	VolumeUnusable        VolumeStatus = "unusable"
+	VolumeFailed          VolumeStatus = "failed"
+	VolumePendingDeletion VolumeStatus = "pending_deletion"
+	// VolumeDeleted is reported for volumes that no longer exist
+	VolumeDeleted VolumeStatus = "deleted"
+)
+
+// AttachmentStatus is the status of a volume attachment
+type AttachmentStatus string
+
+// Attachment statuses
+const (
+	AttachmentAttaching AttachmentStatus = "attaching"
+	AttachmentAttached  AttachmentStatus = "attached"
+	AttachmentDetaching AttachmentStatus = "detaching"
+	AttachmentDeleting  AttachmentStatus = "deleting"
+	AttachmentFailed    AttachmentStatus = "failed"
+	// AttachmentDetached is reported for attachments that no longer exist
+	AttachmentDetached AttachmentStatus = "detached"
+)
+
+// AccessPointStatus is the status of a volume access point
+type AccessPointStatus string
+
+// Access point statuses
+const (
+	AccessPointPending         AccessPointStatus = "pending"
+	AccessPointWaiting         AccessPointStatus = "waiting"
+	AccessPointStable          AccessPointStatus = "stable"
+	AccessPointUpdating        AccessPointStatus = "updating"
+	AccessPointSuspended       AccessPointStatus = "suspended"
+	AccessPointFailed          AccessPointStatus = "failed"
+	AccessPointPendingDeletion AccessPointStatus = "pending_deletion"
+	AccessPointDeleting        AccessPointStatus = "deleting"
+	AccessPointDeleted         AccessPointStatus = "deleted"
+)
+
+// SnapshotStatus is the status of a snapshot
+type SnapshotStatus string
+
+// Snapshot statuses
+const (
+	SnapshotPending  SnapshotStatus = "pending"
+	SnapshotStable   SnapshotStatus = "stable"
+	SnapshotUpdating SnapshotStatus = "updating"
+	SnapshotFailed   SnapshotStatus = "failed"
+	SnapshotDeleting SnapshotStatus = "deleting"
+	// SnapshotDeleted is reported for snapshots that no longer exist
+	SnapshotDeleted SnapshotStatus = "deleted"
+)
+
+// Volume is the volume state machine
+var Volume = NewMachine("volume", Definition[VolumeStatus]{
+	Transitions: map[VolumeStatus][]VolumeStatus{
+		VolumePending:         {VolumeAvailable, VolumeFailed},
+		VolumeAvailable:       {VolumeUpdating, VolumeUnusable, VolumePendingDeletion},
+		VolumeUpdating:        {VolumeAvailable, VolumeFailed},
+		VolumeUnusable:        {VolumeAvailable, VolumePendingDeletion},
+		VolumeFailed:          {VolumePendingDeletion},
+		VolumePendingDeletion: {VolumeDeleted},
+		VolumeDeleted:         {},
+	},
+	Terminal: []VolumeStatus{VolumeFailed, VolumeDeleted},
+	Allowed: map[Operation][]VolumeStatus{
+		OperationDelete:   {VolumeAvailable, VolumeUnusable, VolumeFailed},
+		OperationExpand:   {VolumeAvailable},
+		OperationAttach:   {VolumeAvailable},
+		OperationSnapshot: {VolumeAvailable},
+	},
+})
+
+// Attachment is the volume attachment state machine
+var Attachment = NewMachine("volume attachment", Definition[AttachmentStatus]{
+	Transitions: map[AttachmentStatus][]AttachmentStatus{
+		AttachmentAttaching: {AttachmentAttached, AttachmentFailed},
+		AttachmentAttached:  {AttachmentDetaching, AttachmentDeleting},
+		AttachmentDetaching: {AttachmentDetached, AttachmentFailed},
+		AttachmentDeleting:  {AttachmentDetached, AttachmentFailed},
+		AttachmentFailed:    {AttachmentDetaching, AttachmentDeleting, AttachmentDetached},
+		AttachmentDetached:  {},
+	},
+	Terminal: []AttachmentStatus{AttachmentFailed, AttachmentDetached},
+	Allowed: map[Operation][]AttachmentStatus{
+		OperationDetach: {AttachmentAttached, AttachmentFailed},
+	},
+})
+
+// AccessPoint is the volume access point state machine
+var AccessPoint = NewMachine("volume access point", Definition[AccessPointStatus]{
+	Transitions: map[AccessPointStatus][]AccessPointStatus{
+		AccessPointPending:         {AccessPointStable, AccessPointFailed},
+		AccessPointWaiting:         {AccessPointPending, AccessPointStable, AccessPointFailed},
+		AccessPointStable:          {AccessPointUpdating, AccessPointSuspended, AccessPointPendingDeletion, AccessPointDeleting},
+		AccessPointUpdating:        {AccessPointStable, AccessPointFailed},
+		AccessPointSuspended:       {AccessPointStable, AccessPointPendingDeletion, AccessPointDeleting},
+		AccessPointFailed:          {AccessPointPendingDeletion, AccessPointDeleting},
+		AccessPointPendingDeletion: {AccessPointDeleting, AccessPointDeleted},
+		AccessPointDeleting:        {AccessPointDeleted},
+		AccessPointDeleted:         {},
+	},
+	Terminal: []AccessPointStatus{AccessPointFailed, AccessPointDeleted},
+	Allowed: map[Operation][]AccessPointStatus{
+		OperationDelete: {AccessPointStable, AccessPointSuspended, AccessPointFailed},
+	},
+})
+
+// Snapshot is the snapshot state machine
+var Snapshot = NewMachine("snapshot", Definition[SnapshotStatus]{
+	Transitions: map[SnapshotStatus][]SnapshotStatus{
+		SnapshotPending:  {SnapshotStable, SnapshotFailed},
+		SnapshotStable:   {SnapshotUpdating, SnapshotDeleting},
+		SnapshotUpdating: {SnapshotStable, SnapshotFailed},
+		SnapshotFailed:   {SnapshotDeleting},
+		SnapshotDeleting: {SnapshotDeleted},
+		SnapshotDeleted:  {},
+	},
+	Terminal: []SnapshotStatus{SnapshotFailed, SnapshotDeleted},
+	Allowed: map[Operation][]SnapshotStatus{
+		OperationDelete:  {SnapshotStable, SnapshotFailed},
+		OperationRestore: {SnapshotStable},
+	},
+})
+
+// ParseVolumeStatus returns the status named s, ignoring case, and whether it is known
+func ParseVolumeStatus(s string) (VolumeStatus, bool) {
+	status := VolumeStatus(normalize(s))
+	return status, Volume.Known(status)
+}
+
+// ParseAttachmentStatus returns the status named s, ignoring case, and whether it is known
+func ParseAttachmentStatus(s string) (AttachmentStatus, bool) {
+	status := AttachmentStatus(normalize(s))
+	return status, Attachment.Known(status)
+}
+
+// ParseAccessPointStatus returns the status named s, ignoring case, and whether it is known
+func ParseAccessPointStatus(s string) (AccessPointStatus, bool) {
+	status := AccessPointStatus(normalize(s))
+	return status, AccessPoint.Known(status)
+}
+
+// ParseSnapshotStatus returns the status named s, ignoring case, and whether it is known
+func ParseSnapshotStatus(s string) (SnapshotStatus, bool) {
+	status := SnapshotStatus(normalize(s))
+	return status, Snapshot.Known(status)
+}
+
+// normalize ...
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
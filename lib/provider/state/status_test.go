@@ -0,0 +1,79 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package state ...
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVolumeMachine(t *testing.T) {
+	assert.Nil(t, Volume.Transition(VolumePending, VolumeAvailable))
+	assert.Nil(t, Volume.Transition(VolumeAvailable, VolumeUpdating))
+	assert.NotNil(t, Volume.Transition(VolumeDeleted, VolumeAvailable))
+	assert.True(t, Volume.IsTerminal(VolumeFailed))
+	assert.False(t, Volume.IsTerminal(VolumePending))
+	assert.True(t, Volume.Allows(VolumeAvailable, OperationExpand))
+	assert.False(t, Volume.Allows(VolumeUpdating, OperationExpand))
+	assert.False(t, Volume.Allows(VolumePending, OperationAttach))
+	assert.False(t, Volume.Allows(VolumePendingDeletion, OperationDelete))
+}
+
+func TestAttachmentMachine(t *testing.T) {
+	assert.Nil(t, Attachment.Transition(AttachmentAttaching, AttachmentAttached))
+	assert.Nil(t, Attachment.Transition(AttachmentDetaching, AttachmentDetached))
+	assert.NotNil(t, Attachment.Transition(AttachmentDetached, AttachmentAttached))
+	assert.True(t, Attachment.Allows(AttachmentAttached, OperationDetach))
+	assert.False(t, Attachment.Allows(AttachmentAttaching, OperationDetach))
+}
+
+func TestAccessPointMachine(t *testing.T) {
+	assert.Nil(t, AccessPoint.Transition(AccessPointPending, AccessPointStable))
+	assert.Nil(t, AccessPoint.Transition(AccessPointPendingDeletion, AccessPointDeleted))
+	assert.True(t, AccessPoint.IsTerminal(AccessPointDeleted))
+	assert.True(t, AccessPoint.Allows(AccessPointStable, OperationDelete))
+	assert.False(t, AccessPoint.Allows(AccessPointDeleting, OperationDelete))
+}
+
+func TestSnapshotMachine(t *testing.T) {
+	assert.Nil(t, Snapshot.Transition(SnapshotPending, SnapshotStable))
+	assert.NotNil(t, Snapshot.Transition(SnapshotStable, SnapshotPending))
+	assert.True(t, Snapshot.Allows(SnapshotStable, OperationRestore))
+	assert.False(t, Snapshot.Allows(SnapshotPending, OperationRestore))
+}
+
+func TestParseStatus(t *testing.T) {
+	volume, ok := ParseVolumeStatus(" Available")
+	assert.True(t, ok)
+	assert.Equal(t, VolumeAvailable, volume)
+	_, ok = ParseVolumeStatus("stable")
+	assert.False(t, ok)
+
+	attachment, ok := ParseAttachmentStatus("ATTACHED")
+	assert.True(t, ok)
+	assert.Equal(t, AttachmentAttached, attachment)
+
+	accessPoint, ok := ParseAccessPointStatus("pending_deletion")
+	assert.True(t, ok)
+	assert.Equal(t, AccessPointPendingDeletion, accessPoint)
+
+	snapshot, ok := ParseSnapshotStatus("")
+	assert.False(t, ok)
+	assert.Equal(t, SnapshotStatus(""), snapshot)
+}
@@ -0,0 +1,56 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import "github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+
+// State returns the typed Status of the volume
+func (v *Volume) State() state.VolumeStatus {
+	status, _ := state.ParseVolumeStatus(v.Status)
+	return status
+}
+
+// State returns the typed SnapshotStatus of the snapshot if the provider reports a known one.
+// Otherwise it is stable if the snapshot is ReadyToUse, and pending if not.
+func (s *Snapshot) State() state.SnapshotStatus {
+	if status, ok := state.ParseSnapshotStatus(s.SnapshotStatus); ok {
+		return status
+	}
+	if s.ReadyToUse {
+		return state.SnapshotStable
+	}
+	return state.SnapshotPending
+}
+
+// State returns the typed Status of the volume attachment
+func (r *VolumeAttachmentResponse) State() state.AttachmentStatus {
+	status, _ := state.ParseAttachmentStatus(r.Status)
+	return status
+}
+
+// State returns the typed Status of the volume access point
+func (a *VolumeAccessPoint) State() state.AccessPointStatus {
+	status, _ := state.ParseAccessPointStatus(a.Status)
+	return status
+}
+
+// State returns the typed Status of the volume access point
+func (r *VolumeAccessPointResponse) State() state.AccessPointStatus {
+	status, _ := state.ParseAccessPointStatus(r.Status)
+	return status
+}
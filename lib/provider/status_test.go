@@ -0,0 +1,40 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState(t *testing.T) {
+	volume := &Volume{VPCVolume: VPCVolume{Status: "available"}, Snapshot: Snapshot{ReadyToUse: true}}
+	assert.Equal(t, state.VolumeAvailable, volume.State())
+	assert.Equal(t, state.SnapshotStable, volume.Snapshot.State())
+	assert.Equal(t, state.VolumeStatus("unknown"), (&Volume{VPCVolume: VPCVolume{Status: "Unknown"}}).State())
+
+	assert.Equal(t, state.SnapshotPending, (&Snapshot{}).State())
+	assert.Equal(t, state.SnapshotFailed, (&Snapshot{SnapshotStatus: "Failed"}).State())
+	assert.Equal(t, state.SnapshotDeleting, (&Snapshot{SnapshotStatus: "deleting", ReadyToUse: true}).State())
+	assert.Equal(t, state.SnapshotStable, (&Snapshot{SnapshotStatus: "suspended", ReadyToUse: true}).State())
+	assert.Equal(t, state.AttachmentAttaching, (&VolumeAttachmentResponse{Status: "attaching"}).State())
+	assert.Equal(t, state.AccessPointStable, (&VolumeAccessPoint{Status: "stable"}).State())
+	assert.Equal(t, state.AccessPointDeleting, (&VolumeAccessPointResponse{Status: "deleting"}).State())
+}
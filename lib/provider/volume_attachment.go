@@ -55,7 +55,7 @@ type VolumeAttachManager interface {
 // VolumeAttachmentResponse used for both attach and detach operation
 type VolumeAttachmentResponse struct {
 	VolumeAttachmentRequest
-	//Status status of the volume attachment success, failed, attached, attaching, detaching
+	//Status status of the volume attachment success, failed, attached, attaching, detaching, see state.AttachmentStatus and State()
 	Status    string     `json:"status,omitempty"`
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 }
@@ -0,0 +1,282 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+)
+
+// TagOperator is the operator of a TagSelector
+type TagOperator string
+
+const (
+	// TagExists selects volumes which have the tag, whatever its value
+	TagExists = TagOperator("exists")
+
+	// TagIn selects volumes which have the tag with one of the values
+	TagIn = TagOperator("in")
+
+	// TagNotIn selects volumes which do not have the tag with one of the values, including volumes without the tag
+	TagNotIn = TagOperator("notin")
+)
+
+// TagSelector selects volumes by one of their tags. The tags of a volume are its VolumeNotes, and its
+// Tags in "key:value" form, "key" alone having an empty value.
+type TagSelector struct {
+	Key      string      `json:"key"`
+	Operator TagOperator `json:"operator"`
+	Values   []string    `json:"values,omitempty"`
+}
+
+// AttachmentFilter selects volumes by whether they have volume attachments
+type AttachmentFilter string
+
+const (
+	// AttachmentAny selects volumes with or without volume attachments
+	AttachmentAny = AttachmentFilter("")
+
+	// AttachmentAttached selects volumes with at least one volume attachment
+	AttachmentAttached = AttachmentFilter("attached")
+
+	// AttachmentUnattached selects volumes without volume attachments
+	AttachmentUnattached = AttachmentFilter("unattached")
+)
+
+// Tags of ListVolumes the VolumeFilter pushes down to, which are not volume tags
+const (
+	listTagName          = "name"
+	listTagZone          = "zone.name"
+	listTagResourceGroup = "resource_group.id"
+)
+
+// VolumeFilter selects volumes. Unset fields select every volume, and a volume is selected if it
+// matches every field which is set.
+type VolumeFilter struct {
+	// Zones the volume may be in
+	Zones []string `json:"zones,omitempty"`
+
+	// Profiles the volume may have, by name
+	Profiles []string `json:"profiles,omitempty"`
+
+	// Statuses the volume may be in
+	Statuses []state.VolumeStatus `json:"statuses,omitempty"`
+
+	// MinCapacity and MaxCapacity bound the capacity of the volume in GiB, if not 0
+	MinCapacity int `json:"minCapacity,omitempty"`
+	MaxCapacity int `json:"maxCapacity,omitempty"`
+
+	// CreatedAfter and CreatedBefore bound the creation time of the volume, if not zero
+	CreatedAfter  time.Time `json:"createdAfter,omitempty"`
+	CreatedBefore time.Time `json:"createdBefore,omitempty"`
+
+	// Attachment selects volumes by whether they have volume attachments
+	Attachment AttachmentFilter `json:"attachment,omitempty"`
+
+	// NamePrefix the volume name starts with
+	NamePrefix string `json:"namePrefix,omitempty"`
+
+	// Tags selects volumes by their tags
+	Tags []TagSelector `json:"tags,omitempty"`
+}
+
+// VolumeFilterLister is implemented by providers which can filter the volumes they list natively
+type VolumeFilterLister interface {
+	// ListVolumesByFilter lists a page of the volumes matching the filter, like ListVolumes.
+	// It returns the part of the filter it did not apply, which the caller applies to the page.
+	ListVolumesByFilter(limit int, start string, filter VolumeFilter) (*VolumeList, VolumeFilter, error)
+}
+
+// IsEmpty reports whether the filter selects every volume
+func (f VolumeFilter) IsEmpty() bool {
+	return len(f.Zones) == 0 && len(f.Profiles) == 0 && len(f.Statuses) == 0 &&
+		f.MinCapacity == 0 && f.MaxCapacity == 0 && f.CreatedAfter.IsZero() && f.CreatedBefore.IsZero() &&
+		f.Attachment == AttachmentAny && f.NamePrefix == "" && len(f.Tags) == 0
+}
+
+// Validate checks that the filter can select volumes: capacity and time bounds are in order,
+// and tag selectors have a key, a known operator and values for TagIn and TagNotIn only
+func (f VolumeFilter) Validate() error {
+	var problems []string
+	if f.MinCapacity < 0 || f.MaxCapacity < 0 {
+		problems = append(problems, "capacity bounds must not be negative")
+	}
+	if f.MaxCapacity != 0 && f.MinCapacity > f.MaxCapacity {
+		problems = append(problems, fmt.Sprintf("minimum capacity %d GiB is above maximum capacity %d GiB", f.MinCapacity, f.MaxCapacity))
+	}
+	if !f.CreatedAfter.IsZero() && !f.CreatedBefore.IsZero() && !f.CreatedAfter.Before(f.CreatedBefore) {
+		problems = append(problems, "creation time lower bound is not before its upper bound")
+	}
+	switch f.Attachment {
+	case AttachmentAny, AttachmentAttached, AttachmentUnattached:
+	default:
+		problems = append(problems, fmt.Sprintf("attachment filter '%s' is not known", f.Attachment))
+	}
+	for _, selector := range f.Tags {
+		switch {
+		case selector.Key == "":
+			problems = append(problems, "tag selector key is required")
+		case selector.Operator == TagExists && len(selector.Values) > 0:
+			problems = append(problems, fmt.Sprintf("tag selector '%s' with operator '%s' takes no values", selector.Key, selector.Operator))
+		case (selector.Operator == TagIn || selector.Operator == TagNotIn) && len(selector.Values) == 0:
+			problems = append(problems, fmt.Sprintf("tag selector '%s' with operator '%s' requires values", selector.Key, selector.Operator))
+		case selector.Operator != TagExists && selector.Operator != TagIn && selector.Operator != TagNotIn:
+			problems = append(problems, fmt.Sprintf("tag selector '%s' operator '%s' is not known", selector.Key, selector.Operator))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return Error{
+		Fault: Fault{
+			Message:    "Invalid volume filter: " + strings.Join(problems, "; "),
+			ReasonCode: reasoncode.ErrorBadRequest,
+		},
+	}
+}
+
+// Matches reports whether the filter selects the volume
+func (f VolumeFilter) Matches(volume *Volume) bool {
+	if volume == nil {
+		return false
+	}
+	if len(f.Zones) > 0 && !slices.Contains(f.Zones, volume.Az) {
+		return false
+	}
+	if len(f.Profiles) > 0 && (volume.VPCVolume.Profile == nil || !slices.Contains(f.Profiles, volume.VPCVolume.Profile.Name)) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, volume.State()) {
+		return false
+	}
+	if f.MinCapacity != 0 || f.MaxCapacity != 0 {
+		if volume.Capacity == nil || *volume.Capacity < f.MinCapacity || (f.MaxCapacity != 0 && *volume.Capacity > f.MaxCapacity) {
+			return false
+		}
+	}
+	if !f.CreatedAfter.IsZero() && !volume.CreationTime.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !volume.CreationTime.Before(f.CreatedBefore) {
+		return false
+	}
+	attached := volume.VolumeAttachments != nil && len(*volume.VolumeAttachments) > 0
+	if (f.Attachment == AttachmentAttached && !attached) || (f.Attachment == AttachmentUnattached && attached) {
+		return false
+	}
+	if f.NamePrefix != "" && (volume.Name == nil || !strings.HasPrefix(*volume.Name, f.NamePrefix)) {
+		return false
+	}
+	for _, selector := range f.Tags {
+		if !selector.matches(volume) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListTags returns the tags of ListVolumes which narrow the volumes listed to those matching part of the
+// filter, a single zone and TagIn selectors with a single value, and the rest of the filter. Providers
+// ignore the tags they do not support, so the zone and the tag selectors are kept in the rest of the
+// filter, to be checked against the volumes listed.
+func (f VolumeFilter) ListTags() (map[string]string, VolumeFilter) {
+	tags := map[string]string{}
+	if len(f.Zones) == 1 {
+		tags[listTagZone] = f.Zones[0]
+	}
+	for _, selector := range f.Tags {
+		_, reserved := tags[selector.Key]
+		switch selector.Key {
+		case listTagName, listTagZone, listTagResourceGroup:
+			reserved = true
+		}
+		if selector.Operator == TagIn && len(selector.Values) == 1 && !reserved {
+			tags[selector.Key] = selector.Values[0]
+		}
+	}
+	return tags, f
+}
+
+// matches reports whether the selector selects the volume
+func (selector TagSelector) matches(volume *Volume) bool {
+	value, ok := volumeTag(volume, selector.Key)
+	switch selector.Operator {
+	case TagExists:
+		return ok
+	case TagIn:
+		return ok && slices.Contains(selector.Values, value)
+	case TagNotIn:
+		return !ok || !slices.Contains(selector.Values, value)
+	}
+	return false
+}
+
+// volumeTag returns the value of the tag of the volume, from its VolumeNotes or its "key:value" Tags
+func volumeTag(volume *Volume, key string) (string, bool) {
+	if value, ok := volume.VolumeNotes[key]; ok {
+		return value, true
+	}
+	for _, tag := range volume.VPCVolume.Tags {
+		k, v, _ := strings.Cut(tag, ":")
+		if k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// IterFilteredVolumes returns an iterator over the volumes matching the filter, like IterVolumes.
+// The filter is pushed down to the provider if it implements VolumeFilterLister, or else as far as
+// the tags of ListVolumes can express it, and the rest of it is applied to each page.
+// PageOptions.Tags is not used. An invalid filter yields the error of Validate.
+func IterFilteredVolumes(ctx context.Context, vm VolumeManager, filter VolumeFilter, opts PageOptions) iter.Seq2[*Volume, error] {
+	if err := filter.Validate(); err != nil {
+		return func(yield func(*Volume, error) bool) {
+			yield(nil, err)
+		}
+	}
+	return paginate(ctx, func(limit int, start string) ([]*Volume, int, string, error) {
+		var (
+			list *VolumeList
+			rest VolumeFilter
+			err  error
+		)
+		if lister, ok := vm.(VolumeFilterLister); ok {
+			list, rest, err = lister.ListVolumesByFilter(limit, start, filter)
+		} else {
+			var tags map[string]string
+			tags, rest = filter.ListTags()
+			list, err = vm.ListVolumes(limit, start, tags)
+		}
+		if err != nil || list == nil {
+			return nil, 0, "", err
+		}
+		volumes := list.Volumes
+		if !rest.IsEmpty() {
+			volumes = slices.DeleteFunc(slices.Clone(volumes), func(volume *Volume) bool { return !rest.Matches(volume) })
+		}
+		return volumes, len(list.Volumes), list.Next, nil
+	}, opts)
+}
@@ -0,0 +1,241 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// filterVolume returns a 10 GiB available volume in us-south-1 created ten days ago
+func filterVolume(name string) *Volume {
+	capacity := 10
+	return &Volume{
+		VolumeID:     name,
+		Name:         &name,
+		Capacity:     &capacity,
+		Az:           "us-south-1",
+		CreationTime: time.Now().Add(-10 * 24 * time.Hour),
+		VolumeNotes:  map[string]string{"cluster": "c1"},
+		VPCVolume: VPCVolume{
+			Status:  "available",
+			Profile: &Profile{Name: "10iops-tier"},
+			Tags:    []string{"env:prod", "backup"},
+		},
+	}
+}
+
+func TestVolumeFilterMatches(t *testing.T) {
+	volume := filterVolume("pvc-1")
+	attachments := []VolumeAttachment{{}}
+	attached := filterVolume("pvc-2")
+	attached.VolumeAttachments = &attachments
+
+	testcases := []struct {
+		name    string
+		filter  VolumeFilter
+		volume  *Volume
+		matches bool
+	}{
+		{name: "empty", filter: VolumeFilter{}, volume: volume, matches: true},
+		{name: "nil volume", filter: VolumeFilter{}, volume: nil, matches: false},
+		{name: "zone", filter: VolumeFilter{Zones: []string{"us-south-2", "us-south-1"}}, volume: volume, matches: true},
+		{name: "other zone", filter: VolumeFilter{Zones: []string{"us-south-2"}}, volume: volume, matches: false},
+		{name: "profile", filter: VolumeFilter{Profiles: []string{"10iops-tier"}}, volume: volume, matches: true},
+		{name: "other profile", filter: VolumeFilter{Profiles: []string{"general-purpose"}}, volume: volume, matches: false},
+		{name: "status", filter: VolumeFilter{Statuses: []state.VolumeStatus{state.VolumeAvailable}}, volume: volume, matches: true},
+		{name: "other status", filter: VolumeFilter{Statuses: []state.VolumeStatus{state.VolumePending}}, volume: volume, matches: false},
+		{name: "capacity", filter: VolumeFilter{MinCapacity: 10, MaxCapacity: 20}, volume: volume, matches: true},
+		{name: "capacity too small", filter: VolumeFilter{MinCapacity: 11}, volume: volume, matches: false},
+		{name: "capacity too big", filter: VolumeFilter{MaxCapacity: 9}, volume: volume, matches: false},
+		{name: "created before", filter: VolumeFilter{CreatedBefore: time.Now().Add(-7 * 24 * time.Hour)}, volume: volume, matches: true},
+		{name: "created after", filter: VolumeFilter{CreatedAfter: time.Now().Add(-7 * 24 * time.Hour)}, volume: volume, matches: false},
+		{name: "unattached", filter: VolumeFilter{Attachment: AttachmentUnattached}, volume: volume, matches: true},
+		{name: "attached", filter: VolumeFilter{Attachment: AttachmentAttached}, volume: volume, matches: false},
+		{name: "attached volume", filter: VolumeFilter{Attachment: AttachmentAttached}, volume: attached, matches: true},
+		{name: "name prefix", filter: VolumeFilter{NamePrefix: "pvc-"}, volume: volume, matches: true},
+		{name: "other name prefix", filter: VolumeFilter{NamePrefix: "snap-"}, volume: volume, matches: false},
+		{name: "note exists", filter: VolumeFilter{Tags: []TagSelector{{Key: "cluster", Operator: TagExists}}}, volume: volume, matches: true},
+		{name: "bare tag exists", filter: VolumeFilter{Tags: []TagSelector{{Key: "backup", Operator: TagExists}}}, volume: volume, matches: true},
+		{name: "tag missing", filter: VolumeFilter{Tags: []TagSelector{{Key: "team", Operator: TagExists}}}, volume: volume, matches: false},
+		{name: "tag in", filter: VolumeFilter{Tags: []TagSelector{{Key: "env", Operator: TagIn, Values: []string{"dev", "prod"}}}}, volume: volume, matches: true},
+		{name: "tag not in", filter: VolumeFilter{Tags: []TagSelector{{Key: "env", Operator: TagNotIn, Values: []string{"prod"}}}}, volume: volume, matches: false},
+		{name: "missing tag not in", filter: VolumeFilter{Tags: []TagSelector{{Key: "team", Operator: TagNotIn, Values: []string{"a"}}}}, volume: volume, matches: true},
+		{
+			name: "all",
+			filter: VolumeFilter{
+				Zones:         []string{"us-south-1"},
+				Profiles:      []string{"10iops-tier"},
+				CreatedBefore: time.Now().Add(-7 * 24 * time.Hour),
+				Attachment:    AttachmentUnattached,
+				Tags:          []TagSelector{{Key: "cluster", Operator: TagIn, Values: []string{"c1"}}},
+			},
+			volume:  volume,
+			matches: true,
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			assert.Equal(t, testcase.matches, testcase.filter.Matches(testcase.volume))
+		})
+	}
+}
+
+func TestVolumeFilterValidate(t *testing.T) {
+	assert.NoError(t, VolumeFilter{}.Validate())
+	assert.True(t, VolumeFilter{}.IsEmpty())
+	assert.False(t, VolumeFilter{NamePrefix: "pvc"}.IsEmpty())
+
+	now := time.Now()
+	err := VolumeFilter{
+		MinCapacity:   20,
+		MaxCapacity:   10,
+		CreatedAfter:  now,
+		CreatedBefore: now,
+		Attachment:    "maybe",
+		Tags: []TagSelector{
+			{Operator: TagExists},
+			{Key: "a", Operator: TagExists, Values: []string{"x"}},
+			{Key: "b", Operator: TagIn},
+			{Key: "c", Operator: "like", Values: []string{"x"}},
+		},
+	}.Validate()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, reasoncode.ErrorBadRequest))
+	for _, problem := range []string{"minimum capacity", "creation time", "attachment filter 'maybe'", "key is required",
+		"'a' with operator 'exists' takes no values", "'b' with operator 'in' requires values", "'c' operator 'like'"} {
+		assert.Contains(t, err.Error(), problem)
+	}
+}
+
+func TestVolumeFilterListTags(t *testing.T) {
+	tags, rest := VolumeFilter{
+		Zones:      []string{"us-south-1"},
+		NamePrefix: "pvc-",
+		Tags: []TagSelector{
+			{Key: "cluster", Operator: TagIn, Values: []string{"c1"}},
+			{Key: "env", Operator: TagIn, Values: []string{"dev", "prod"}},
+			{Key: "name", Operator: TagIn, Values: []string{"pvc-1"}},
+			{Key: "cluster", Operator: TagIn, Values: []string{"c2"}},
+		},
+	}.ListTags()
+	assert.Equal(t, map[string]string{"zone.name": "us-south-1", "cluster": "c1"}, tags)
+	assert.Equal(t, []string{"us-south-1"}, rest.Zones)
+	assert.Equal(t, "pvc-", rest.NamePrefix)
+	assert.Equal(t, []string{"cluster", "env", "name", "cluster"}, []string{rest.Tags[0].Key, rest.Tags[1].Key, rest.Tags[2].Key, rest.Tags[3].Key})
+}
+
+// filterSession is a Session listing volumes with ListVolumes, recording the tags it is called with.
+// It filters by the zone.name tag, unless ignoreTags is set.
+type filterSession struct {
+	DefaultVolumeProvider
+	volumes    []*Volume
+	tags       []map[string]string
+	ignoreTags bool
+}
+
+func (s *filterSession) ListVolumes(limit int, start string, tags map[string]string) (*VolumeList, error) {
+	s.tags = append(s.tags, tags)
+	var volumes []*Volume
+	for _, volume := range s.volumes {
+		if zone, ok := tags["zone.name"]; !ok || s.ignoreTags || volume.Az == zone {
+			volumes = append(volumes, volume)
+		}
+	}
+	return &VolumeList{Volumes: volumes}, nil
+}
+
+// filterListerSession is a filterSession which filters by zone natively
+type filterListerSession struct {
+	filterSession
+	filters []VolumeFilter
+}
+
+func (s *filterListerSession) ListVolumesByFilter(limit int, start string, filter VolumeFilter) (*VolumeList, VolumeFilter, error) {
+	s.filters = append(s.filters, filter)
+	rest := filter
+	rest.Zones = nil
+	var volumes []*Volume
+	for _, volume := range s.volumes {
+		if (VolumeFilter{Zones: filter.Zones}).Matches(volume) {
+			volumes = append(volumes, volume)
+		}
+	}
+	return &VolumeList{Volumes: volumes}, rest, nil
+}
+
+func TestIterFilteredVolumes(t *testing.T) {
+	other := filterVolume("pvc-2")
+	other.Az = "us-south-2"
+	old := filterVolume("data-3")
+	volumes := []*Volume{filterVolume("pvc-1"), other, old}
+	filter := VolumeFilter{Zones: []string{"us-south-1"}, NamePrefix: "pvc-"}
+
+	sess := &filterSession{volumes: volumes}
+	var ids []string
+	for volume, err := range IterFilteredVolumes(context.Background(), sess, filter, PageOptions{}) {
+		require.NoError(t, err)
+		ids = append(ids, volume.VolumeID)
+	}
+	assert.Equal(t, []string{"pvc-1"}, ids)
+	assert.Equal(t, []map[string]string{{"zone.name": "us-south-1"}}, sess.tags)
+
+	lister := &filterListerSession{filterSession: filterSession{volumes: volumes}}
+	ids = nil
+	for volume, err := range IterFilteredVolumes(context.Background(), lister, filter, PageOptions{}) {
+		require.NoError(t, err)
+		ids = append(ids, volume.VolumeID)
+	}
+	assert.Equal(t, []string{"pvc-1"}, ids)
+	assert.Equal(t, []VolumeFilter{filter}, lister.filters)
+	assert.Empty(t, lister.tags)
+
+	// Tags ListVolumes ignores are checked against the volumes listed
+	untagged := filterVolume("pvc-4")
+	untagged.VolumeNotes = nil
+	sess = &filterSession{volumes: []*Volume{filterVolume("pvc-1"), untagged}}
+	ids = nil
+	for volume, err := range IterFilteredVolumes(context.Background(), sess, VolumeFilter{Tags: []TagSelector{{Key: "cluster", Operator: TagIn, Values: []string{"c1"}}}}, PageOptions{}) {
+		require.NoError(t, err)
+		ids = append(ids, volume.VolumeID)
+	}
+	assert.Equal(t, []string{"pvc-1"}, ids)
+	assert.Equal(t, []map[string]string{{"cluster": "c1"}}, sess.tags)
+
+	// A provider ignoring every tag lists all the volumes, which are checked against the whole filter
+	sess = &filterSession{volumes: append(volumes, untagged), ignoreTags: true}
+	ids = nil
+	filter.Tags = []TagSelector{{Key: "cluster", Operator: TagIn, Values: []string{"c1"}}}
+	for volume, err := range IterFilteredVolumes(context.Background(), sess, filter, PageOptions{}) {
+		require.NoError(t, err)
+		ids = append(ids, volume.VolumeID)
+	}
+	assert.Equal(t, []string{"pvc-1"}, ids)
+	assert.Equal(t, []map[string]string{{"zone.name": "us-south-1", "cluster": "c1"}}, sess.tags)
+
+	for _, err := range IterFilteredVolumes(context.Background(), sess, VolumeFilter{MinCapacity: -1}, PageOptions{}) {
+		assert.True(t, errors.Is(err, reasoncode.ErrorBadRequest))
+	}
+}
@@ -29,6 +29,9 @@ type VolumeManager interface {
 	// Get the volume profile by using profile name
 	GetVolumeProfileByName(name string) (*Profile, error)
 
+	// List the volume profiles
+	ListVolumeProfiles(limit int, start string) (*ProfileList, error)
+
 	// Create the volume with authorization by passing required information in the volume object
 	CreateVolume(VolumeRequest Volume) (*Volume, error)
 
@@ -62,4 +65,12 @@ type VolumeManager interface {
 	// Volume operations
 	// Expand the volume with authorization by passing required information in the volume object
 	ExpandVolume(expandVolumeRequest ExpandVolumeRequest) (int64, error)
+
+	// WaitForVolumeAvailable waits for the volume to become available, e.g. after CreateVolume or CreateVolumeFromSnapshot
+	// Return error if wait is timed out OR there is other error
+	WaitForVolumeAvailable(volumeID string) (*Volume, error)
+
+	// CloneVolume creates a new volume from the template with the content of the source volume.
+	// Providers without native cloning can use the CloneVolume function of this package.
+	CloneVolume(sourceVolumeID string, template Volume) (*Volume, error)
 }
@@ -89,7 +89,8 @@ type VolumeAccessPoint struct {
 	ID   string `json:"id,omitempty"`
 	Href string `json:"href,omitempty"`
 	Name string `json:"name,omitempty"`
-	// Status of volume target named - deleted, deleting, failed, pending_deletion, stable, updating, waiting, suspended
+	// Status of volume target named - deleted, deleting, failed, pending_deletion, stable, updating, waiting, suspended.
+	// See state.AccessPointStatus and State().
 	Status    string     `json:"status,omitempty"`
 	MountPath *string    `json:"mount_path,omitempty"`
 	VPC       *VPC       `json:"vpc,omitempty"`
@@ -0,0 +1,307 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+)
+
+const (
+	// DefaultWaitTimeout bounds Wait if WaitOptions.Timeout is not set
+	DefaultWaitTimeout = 2 * time.Minute
+
+	// DefaultWaitInterval is the interval between polls if neither WaitOptions.Interval nor Backoff is set
+	DefaultWaitInterval = 2 * time.Second
+)
+
+// Properties of the errors returned by Wait
+const (
+	// WaitLastStateProperty is the last state observed, empty if none was
+	WaitLastStateProperty = "lastState"
+
+	// WaitElapsedProperty is the time waited
+	WaitElapsedProperty = "elapsed"
+
+	// WaitAttemptsProperty is the number of polls
+	WaitAttemptsProperty = "attempts"
+)
+
+// WaitBackoff computes the delay before the next poll. The util backoff policies implement it.
+type WaitBackoff interface {
+	NextDelay(attempt int, previous time.Duration) time.Duration
+}
+
+// WaitEvent reports the progress of a Wait after each poll
+type WaitEvent[S ~string] struct {
+	// Attempt is the number of the poll, from 1
+	Attempt int
+
+	// State is the state observed, empty if the poll failed
+	State S
+
+	// Err is the retryable error of the poll
+	Err error
+
+	// Elapsed is the time since the wait started
+	Elapsed time.Duration
+
+	// Delay is the time until the next poll, 0 if the wait is over
+	Delay time.Duration
+}
+
+// WaitOptions configures Wait
+type WaitOptions[S ~string] struct {
+	// Description names what is waited for in errors, e.g. "volume attachment"
+	Description string
+
+	// Timeout bounds the wait, DefaultWaitTimeout if not set. The context deadline still applies.
+	Timeout time.Duration
+
+	// Interval between polls, DefaultWaitInterval if not set. Ignored if Backoff is set.
+	Interval time.Duration
+
+	// Backoff computes the delays between polls
+	Backoff WaitBackoff
+
+	// Terminal returns true for states that are not left without user action, e.g. state.Volume.IsTerminal.
+	// Reaching one that is not done fails the wait with reasoncode.ErrorWaitFailed.
+	Terminal func(S) bool
+
+	// Retryable returns true for the getter errors that are polled through. By default these are
+	// the provider Errors whose reason code the reasoncode catalog marks retryable.
+	Retryable func(error) bool
+
+	// OnProgress is called after every poll
+	OnProgress func(WaitEvent[S])
+}
+
+// Wait polls get until the state of its result is done, returning the result.
+//
+// The wait fails with the error of get if it is not retryable, or with an Error carrying the last state
+// observed and the time waited: reasoncode.ErrorWaitFailed if a terminal state is reached, and
+// reasoncode.ErrorWaitTimedOut if the timeout elapses or ctx is done.
+func Wait[T any, S ~string](ctx context.Context, get func(ctx context.Context) (T, error), status func(T) S, done func(S) bool, opts WaitOptions[S]) (T, error) {
+	var zero T
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+	retryable := opts.Retryable
+	if retryable == nil {
+		retryable = retryableReasonCode
+	}
+	description := opts.Description
+	if description == "" {
+		description = "resource"
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		lastState S
+		lastErr   error
+		delay     time.Duration
+	)
+	for attempt := 1; ; attempt++ {
+		result, err := get(ctx)
+		event := WaitEvent[S]{Attempt: attempt, Elapsed: time.Since(start)}
+
+		switch {
+		case err != nil && ctx.Err() != nil:
+			lastErr = err
+		case err != nil:
+			if !retryable(err) {
+				event.Err = err
+				opts.progress(event)
+				return zero, err
+			}
+			lastErr = err
+			event.Err = err
+		default:
+			lastErr = nil
+			lastState = status(result)
+			event.State = lastState
+			if done(lastState) {
+				opts.progress(event)
+				return result, nil
+			}
+			if opts.Terminal != nil && opts.Terminal(lastState) {
+				opts.progress(event)
+				return zero, newWaitError(reasoncode.ErrorWaitFailed,
+					fmt.Sprintf("The %s reached the terminal state '%s'", description, lastState),
+					string(lastState), event.Elapsed, attempt)
+			}
+		}
+
+		if ctx.Err() == nil {
+			delay = opts.nextDelay(attempt, delay)
+			event.Delay = delay
+		}
+		opts.progress(event)
+
+		if ctx.Err() == nil {
+			timer := time.NewTimer(event.Delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+				continue
+			}
+		}
+		elapsed := time.Since(start)
+		msg := fmt.Sprintf("Timed out after %s waiting for the %s", elapsed.Round(time.Millisecond), description)
+		if lastState != "" {
+			msg += fmt.Sprintf(", last state '%s'", lastState)
+		}
+		return zero, newWaitError(reasoncode.ErrorWaitTimedOut, msg, string(lastState), elapsed, attempt, ctx.Err(), lastErr)
+	}
+}
+
+// progress ...
+func (opts WaitOptions[S]) progress(event WaitEvent[S]) {
+	if opts.OnProgress != nil {
+		opts.OnProgress(event)
+	}
+}
+
+// nextDelay ...
+func (opts WaitOptions[S]) nextDelay(attempt int, previous time.Duration) time.Duration {
+	if opts.Backoff != nil {
+		return opts.Backoff.NextDelay(attempt, previous)
+	}
+	if opts.Interval > 0 {
+		return opts.Interval
+	}
+	return DefaultWaitInterval
+}
+
+// retryableReasonCode ...
+func retryableReasonCode(err error) bool {
+	var perr Error
+	return errors.As(err, &perr) && reasoncode.IsRetryable(perr.Code())
+}
+
+// newWaitError ...
+func newWaitError(code reasoncode.ReasonCode, msg string, lastState string, elapsed time.Duration, attempts int, wrapped ...error) Error {
+	return Error{
+		Fault: Fault{
+			Message:    msg,
+			ReasonCode: code,
+			Wrapped:    wrappedMessages(wrapped...),
+			Properties: map[string]string{
+				WaitLastStateProperty: lastState,
+				WaitElapsedProperty:   elapsed.String(),
+				WaitAttemptsProperty:  strconv.Itoa(attempts),
+			},
+		},
+	}.WithWrapped(wrapped...)
+}
+
+// WaitForVolumeState waits for the volume returned by get to reach the target state.
+// The state.Volume terminal states fail the wait unless opts.Terminal is set.
+func WaitForVolumeState(ctx context.Context, get func(ctx context.Context) (*Volume, error), target state.VolumeStatus, opts WaitOptions[state.VolumeStatus]) (*Volume, error) {
+	return waitForState(ctx, get, (*Volume).State, target, state.Volume, "volume", opts)
+}
+
+// WaitForAttachmentState waits for the volume attachment returned by get to reach the target state.
+// get can report an attachment that no longer exists as state.AttachmentDetached.
+// The state.Attachment terminal states fail the wait unless opts.Terminal is set.
+func WaitForAttachmentState(ctx context.Context, get func(ctx context.Context) (*VolumeAttachmentResponse, error), target state.AttachmentStatus, opts WaitOptions[state.AttachmentStatus]) (*VolumeAttachmentResponse, error) {
+	return waitForState(ctx, get, (*VolumeAttachmentResponse).State, target, state.Attachment, "volume attachment", opts)
+}
+
+// WaitForAccessPointState waits for the volume access point returned by get to reach the target state.
+// get can report an access point that no longer exists as state.AccessPointDeleted.
+// The state.AccessPoint terminal states fail the wait unless opts.Terminal is set.
+func WaitForAccessPointState(ctx context.Context, get func(ctx context.Context) (*VolumeAccessPointResponse, error), target state.AccessPointStatus, opts WaitOptions[state.AccessPointStatus]) (*VolumeAccessPointResponse, error) {
+	return waitForState(ctx, get, (*VolumeAccessPointResponse).State, target, state.AccessPoint, "volume access point", opts)
+}
+
+// WaitForSnapshotState waits for the snapshot returned by get to reach the target state.
+// The state.Snapshot terminal states fail the wait unless opts.Terminal is set.
+func WaitForSnapshotState(ctx context.Context, get func(ctx context.Context) (*Snapshot, error), target state.SnapshotStatus, opts WaitOptions[state.SnapshotStatus]) (*Snapshot, error) {
+	return waitForState(ctx, get, (*Snapshot).State, target, state.Snapshot, "snapshot", opts)
+}
+
+// WaitForVolumeAvailable polls the GetVolume of sess until the volume is available, for up to DefaultWaitTimeout.
+// Providers can implement VolumeManager.WaitForVolumeAvailable with it.
+func WaitForVolumeAvailable(sess Session, volumeID string) (*Volume, error) {
+	return WaitForVolumeState(context.Background(), func(context.Context) (*Volume, error) {
+		return sess.GetVolume(volumeID)
+	}, state.VolumeAvailable, WaitOptions[state.VolumeStatus]{})
+}
+
+// WaitForVolumeAvailableWithContext is WaitForVolumeAvailable for a ContextSession. The wait stops when ctx is done.
+func WaitForVolumeAvailableWithContext(ctx context.Context, sess ContextSession, volumeID string) (*Volume, error) {
+	return WaitForVolumeState(ctx, func(ctx context.Context) (*Volume, error) {
+		return sess.GetVolume(ctx, volumeID)
+	}, state.VolumeAvailable, WaitOptions[state.VolumeStatus]{})
+}
+
+// WaitForSnapshotReady polls the GetSnapshot of sess until the snapshot is ReadyToUse, for up to DefaultWaitTimeout.
+// Providers can implement SnapshotManager.WaitForSnapshotReady with it.
+func WaitForSnapshotReady(sess Session, snapshotID string, sourceVolumeID ...string) (*Snapshot, error) {
+	return WaitForSnapshotState(context.Background(), func(context.Context) (*Snapshot, error) {
+		return sess.GetSnapshot(snapshotID, sourceVolumeID...)
+	}, state.SnapshotStable, WaitOptions[state.SnapshotStatus]{})
+}
+
+// WaitForSnapshotReadyWithContext is WaitForSnapshotReady for a ContextSession. The wait stops when ctx is done.
+func WaitForSnapshotReadyWithContext(ctx context.Context, sess ContextSession, snapshotID string, sourceVolumeID ...string) (*Snapshot, error) {
+	return WaitForSnapshotState(ctx, func(ctx context.Context) (*Snapshot, error) {
+		return sess.GetSnapshot(ctx, snapshotID, sourceVolumeID...)
+	}, state.SnapshotStable, WaitOptions[state.SnapshotStatus]{})
+}
+
+// waitForState ...
+func waitForState[T any, S ~string](ctx context.Context, get func(ctx context.Context) (*T, error), status func(*T) S, target S, machine *state.Machine[S], description string, opts WaitOptions[S]) (*T, error) {
+	if opts.Terminal == nil {
+		opts.Terminal = machine.IsTerminal
+	}
+	if opts.Description == "" {
+		opts.Description = description
+	}
+	getOrFail := func(ctx context.Context) (*T, error) {
+		result, err := get(ctx)
+		if err == nil && result == nil {
+			return nil, Error{Fault: Fault{Message: fmt.Sprintf("The %s was not found", opts.Description), ReasonCode: reasoncode.ErrorWaitFailed}}
+		}
+		return result, err
+	}
+	return Wait(ctx, getOrFail, status, func(s S) bool { return s == target }, opts)
+}
+
+// wrappedMessages returns the messages of the errors which are not nil, for Fault.Wrapped
+func wrappedMessages(errs ...error) []string {
+	var msgs []string
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return msgs
+}
@@ -0,0 +1,228 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provider ...
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// attachmentSequence returns a getter reporting the statuses in turn, then the last one forever
+func attachmentSequence(statuses ...string) (func(ctx context.Context) (*VolumeAttachmentResponse, error), *int) {
+	calls := 0
+	return func(ctx context.Context) (*VolumeAttachmentResponse, error) {
+		status := statuses[len(statuses)-1]
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+		calls++
+		return &VolumeAttachmentResponse{Status: status}, nil
+	}, &calls
+}
+
+type doublingBackoff struct{}
+
+func (doublingBackoff) NextDelay(attempt int, previous time.Duration) time.Duration {
+	if previous == 0 {
+		return time.Millisecond
+	}
+	return 2 * previous
+}
+
+func TestWaitForAttachmentState(t *testing.T) {
+	get, calls := attachmentSequence("attaching", "attaching", "attached")
+	var events []WaitEvent[state.AttachmentStatus]
+	attachment, err := WaitForAttachmentState(context.Background(), get, state.AttachmentAttached, WaitOptions[state.AttachmentStatus]{
+		Backoff:    doublingBackoff{},
+		OnProgress: func(event WaitEvent[state.AttachmentStatus]) { events = append(events, event) },
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "attached", attachment.Status)
+	assert.Equal(t, 3, *calls)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, state.AttachmentAttaching, events[0].State)
+	assert.Equal(t, time.Millisecond, events[0].Delay)
+	assert.Equal(t, 2*time.Millisecond, events[1].Delay)
+	assert.Equal(t, 3, events[2].Attempt)
+	assert.Equal(t, state.AttachmentAttached, events[2].State)
+	assert.Zero(t, events[2].Delay)
+}
+
+func TestWaitTerminalState(t *testing.T) {
+	get, calls := attachmentSequence("attaching", "failed")
+	_, err := WaitForAttachmentState(context.Background(), get, state.AttachmentAttached, WaitOptions[state.AttachmentStatus]{Interval: time.Millisecond})
+	assert.Equal(t, 2, *calls)
+
+	var perr Error
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, reasoncode.ErrorWaitFailed, perr.Code())
+	assert.Equal(t, "The volume attachment reached the terminal state 'failed'", perr.Error())
+	assert.Equal(t, "failed", perr.Properties()[WaitLastStateProperty])
+	assert.Equal(t, "2", perr.Properties()[WaitAttemptsProperty])
+	assert.NotEmpty(t, perr.Properties()[WaitElapsedProperty])
+}
+
+func TestWaitTimeout(t *testing.T) {
+	get, _ := attachmentSequence("attaching")
+	start := time.Now()
+	_, err := WaitForAttachmentState(context.Background(), get, state.AttachmentAttached, WaitOptions[state.AttachmentStatus]{
+		Timeout:  30 * time.Millisecond,
+		Interval: 5 * time.Millisecond,
+	})
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitTimedOut))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), "waiting for the volume attachment, last state 'attaching'")
+
+	var perr Error
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, "attaching", perr.Properties()[WaitLastStateProperty])
+	elapsed, parseErr := time.ParseDuration(perr.Properties()[WaitElapsedProperty])
+	require.Nil(t, parseErr)
+	assert.GreaterOrEqual(t, elapsed, 30*time.Millisecond)
+
+	// The context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = WaitForAttachmentState(ctx, get, state.AttachmentAttached, WaitOptions[state.AttachmentStatus]{})
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitTimedOut))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestWaitGetterErrors(t *testing.T) {
+	retryable := Error{Fault: Fault{Message: "rate limited", ReasonCode: reasoncode.ErrorRateLimitExceeded}}
+	fatal := errors.New("not found")
+
+	calls := 0
+	var events []WaitEvent[state.VolumeStatus]
+	volume, err := WaitForVolumeState(context.Background(), func(ctx context.Context) (*Volume, error) {
+		calls++
+		if calls == 1 {
+			return nil, retryable
+		}
+		return &Volume{VPCVolume: VPCVolume{Status: "available"}}, nil
+	}, state.VolumeAvailable, WaitOptions[state.VolumeStatus]{
+		Interval:   time.Millisecond,
+		OnProgress: func(event WaitEvent[state.VolumeStatus]) { events = append(events, event) },
+	})
+	require.Nil(t, err)
+	assert.Equal(t, state.VolumeAvailable, volume.State())
+	require.Len(t, events, 2)
+	assert.Equal(t, retryable, events[0].Err)
+	assert.Equal(t, state.VolumeStatus(""), events[0].State)
+
+	_, err = WaitForVolumeState(context.Background(), func(ctx context.Context) (*Volume, error) {
+		return nil, fatal
+	}, state.VolumeAvailable, WaitOptions[state.VolumeStatus]{})
+	assert.Equal(t, fatal, err)
+
+	// The last retryable error is wrapped in the timeout error
+	_, err = WaitForVolumeState(context.Background(), func(ctx context.Context) (*Volume, error) {
+		return nil, retryable
+	}, state.VolumeAvailable, WaitOptions[state.VolumeStatus]{Timeout: 10 * time.Millisecond, Interval: time.Millisecond})
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitTimedOut))
+	assert.True(t, errors.Is(err, reasoncode.ErrorRateLimitExceeded))
+	assert.Equal(t, "Timed out", err.Error()[:len("Timed out")])
+}
+
+func TestWaitForSnapshotAndAccessPointState(t *testing.T) {
+	snapshot, err := WaitForSnapshotState(context.Background(), func(ctx context.Context) (*Snapshot, error) {
+		return &Snapshot{ReadyToUse: true}, nil
+	}, state.SnapshotStable, WaitOptions[state.SnapshotStatus]{})
+	require.Nil(t, err)
+	assert.True(t, snapshot.ReadyToUse)
+
+	_, err = WaitForSnapshotState(context.Background(), func(ctx context.Context) (*Snapshot, error) {
+		return &Snapshot{SnapshotStatus: "failed"}, nil
+	}, state.SnapshotStable, WaitOptions[state.SnapshotStatus]{})
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitFailed))
+
+	_, err = WaitForAccessPointState(context.Background(), func(ctx context.Context) (*VolumeAccessPointResponse, error) {
+		return &VolumeAccessPointResponse{Status: "deleted"}, nil
+	}, state.AccessPointStable, WaitOptions[state.AccessPointStatus]{})
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitFailed))
+
+	// Terminal can be overridden
+	calls := 0
+	accessPoint, err := WaitForAccessPointState(context.Background(), func(ctx context.Context) (*VolumeAccessPointResponse, error) {
+		calls++
+		if calls == 1 {
+			return &VolumeAccessPointResponse{Status: "failed"}, nil
+		}
+		return &VolumeAccessPointResponse{Status: "stable"}, nil
+	}, state.AccessPointStable, WaitOptions[state.AccessPointStatus]{
+		Interval: time.Millisecond,
+		Terminal: func(state.AccessPointStatus) bool { return false },
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "stable", accessPoint.Status)
+}
+
+// pendingSession is a Session whose volumes and snapshots never become ready
+type pendingSession struct {
+	DefaultVolumeProvider
+	polls int
+}
+
+// newPendingSession returns a pendingSession whose DefaultVolumeProvider polls it
+func newPendingSession() *pendingSession {
+	sess := &pendingSession{}
+	sess.DefaultVolumeProvider = NewDefaultVolumeProvider(sess)
+	return sess
+}
+
+func (s *pendingSession) GetVolume(id string) (*Volume, error) {
+	s.polls++
+	return &Volume{VolumeID: id, VPCVolume: VPCVolume{Status: "pending"}}, nil
+}
+
+func (s *pendingSession) GetSnapshot(snapshotID string, sourceVolumeID ...string) (*Snapshot, error) {
+	s.polls++
+	return &Snapshot{SnapshotID: snapshotID}, nil
+}
+
+func TestWaitForVolumeAvailableWithContext(t *testing.T) {
+	sess := newPendingSession()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	volume, err := WaitForVolumeAvailableWithContext(ctx, NewContextSession(sess), "vol-id")
+	assert.Nil(t, volume)
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitTimedOut))
+	assert.Equal(t, "pending", err.(Error).Properties()[WaitLastStateProperty])
+	assert.Equal(t, 1, sess.polls)
+}
+
+func TestWaitForSnapshotReadyWithContext(t *testing.T) {
+	sess := newPendingSession()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	snapshot, err := WaitForSnapshotReadyWithContext(ctx, NewContextSession(sess), "snap-id", "vol-id")
+	assert.Nil(t, snapshot)
+	assert.True(t, errors.Is(err, reasoncode.ErrorWaitTimedOut))
+	assert.Equal(t, 1, sess.polls)
+}
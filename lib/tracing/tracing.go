@@ -0,0 +1,90 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tracing provides the OpenTelemetry instrumentation of the library. Spans are created with
+// the global TracerProvider, so tracing is off (no-op) unless the caller configures one with
+// otel.SetTracerProvider.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the library's tracer
+const InstrumentationName = "github.com/IBM/ibmcloud-volume-interface"
+
+// Span attribute keys
+const (
+	// VolumeIDKey is the ID of the volume the operation is on
+	VolumeIDKey = attribute.Key("ibmcloud.volume.id")
+
+	// InstanceIDKey is the ID of the instance the volume is attached to or detached from
+	InstanceIDKey = attribute.Key("ibmcloud.instance.id")
+
+	// ProviderKey is the name of the volume provider
+	ProviderKey = attribute.Key("ibmcloud.provider")
+
+	// ReasonCodeKey is the reason code of the error the operation failed with
+	ReasonCodeKey = attribute.Key("ibmcloud.reason_code")
+
+	// RequestIDKey is the request ID stored under provider.RequestID in the context
+	RequestIDKey = attribute.Key("ibmcloud.request_id")
+
+	// AttemptKey is the number of a retry attempt, starting at 1
+	AttemptKey = attribute.Key("ibmcloud.retry.attempt")
+)
+
+// Tracer returns the library's tracer from the global TracerProvider
+func Tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(InstrumentationName)
+}
+
+// Start starts a span as a child of the span in ctx, if any. The request ID in ctx is added to the attributes.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if requestID := RequestID(ctx); requestID != "" {
+		attrs = append(attrs, RequestIDKey.String(requestID))
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, recording err and its reason code if the operation failed
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		var perr provider.Error
+		if errors.As(err, &perr) {
+			span.SetAttributes(ReasonCodeKey.String(string(perr.Code())))
+		}
+	}
+	span.End()
+}
+
+// RequestID returns the request ID stored under provider.RequestID in ctx
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(provider.RequestID).(string)
+	return requestID
+}
@@ -0,0 +1,87 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tracing ...
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// useSpanRecorder sets a global TracerProvider which records spans in memory for the test
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	return recorder
+}
+
+func TestStartEnd(t *testing.T) {
+	recorder := useSpanRecorder(t)
+	ctx := context.WithValue(context.Background(), provider.RequestID, "req-1")
+
+	ctx, parent := Start(ctx, "parent", VolumeIDKey.String("vol-1"))
+	_, child := Start(ctx, "child")
+	End(child, errors.New("failed"))
+	End(parent, provider.Error{Fault: provider.Fault{ReasonCode: reasoncode.ErrorRateLimitExceeded, Message: "rate limited"}})
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "child", spans[0].Name())
+		assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Contains(t, spans[0].Attributes(), RequestIDKey.String("req-1"))
+		assert.NotContains(t, attributeKeys(spans[0].Attributes()), ReasonCodeKey)
+
+		assert.Equal(t, "parent", spans[1].Name())
+		assert.Contains(t, spans[1].Attributes(), VolumeIDKey.String("vol-1"))
+		assert.Contains(t, spans[1].Attributes(), ReasonCodeKey.String("ErrorRateLimitExceeded"))
+		assert.Equal(t, "rate limited", spans[1].Status().Description)
+	}
+}
+
+func TestStartNoop(t *testing.T) {
+	// With the default or a no-op TracerProvider spans are no-ops
+	_, span := Start(context.Background(), "noop")
+	assert.False(t, span.SpanContext().IsValid())
+	End(span, nil)
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Equal(t, "", RequestID(context.Background()))
+	assert.Equal(t, "", RequestID(context.WithValue(context.Background(), provider.RequestID, 42)))
+	assert.Equal(t, "req-1", RequestID(context.WithValue(context.Background(), provider.RequestID, "req-1")))
+}
+
+func attributeKeys(attrs []attribute.KeyValue) []attribute.Key {
+	var keys []attribute.Key
+	for _, attr := range attrs {
+		keys = append(keys, attr.Key)
+	}
+	return keys
+}
@@ -0,0 +1,93 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package util ...
+package util
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// BackoffPolicy decides how long ErrorRetrier waits before the next attempt.
+// attempt is the number of attempts made so far (starting at 1) and previous is the
+// delay returned for the previous attempt (0 before the first retry).
+type BackoffPolicy interface {
+	NextDelay(attempt int, previous time.Duration) time.Duration
+}
+
+// ConstantBackoff waits the same Interval between every attempt
+type ConstantBackoff struct {
+	Interval time.Duration
+}
+
+// NextDelay returns Interval
+func (b ConstantBackoff) NextDelay(attempt int, previous time.Duration) time.Duration {
+	return b.Interval
+}
+
+// ExponentialBackoff waits Initial after the first attempt and multiplies the delay by
+// Multiplier (2 if not set) after every further attempt, up to Max (no cap if not set)
+type ExponentialBackoff struct {
+	Initial    time.Duration
+	Multiplier float64
+	Max        time.Duration
+}
+
+// NextDelay returns Initial * Multiplier^(attempt-1), capped at Max, or at the longest
+// time.Duration if Max is not set
+func (b ExponentialBackoff) NextDelay(attempt int, previous time.Duration) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+	limit := b.Max
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	delay := float64(b.Initial)
+	for i := 1; i < attempt; i++ {
+		delay *= multiplier
+		if delay >= float64(limit) {
+			return limit
+		}
+	}
+	if delay >= float64(limit) {
+		return limit
+	}
+	return time.Duration(delay)
+}
+
+// DecorrelatedJitterBackoff waits a random delay between Base and three times the previous
+// delay, capped at Max (no cap if not set), which spreads out retries from many callers
+type DecorrelatedJitterBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// NextDelay returns a random delay in [Base, 3*previous), capped at Max
+func (b DecorrelatedJitterBackoff) NextDelay(attempt int, previous time.Duration) time.Duration {
+	upper := 3 * previous
+	delay := b.Base
+	if upper > b.Base {
+		delay += time.Duration(rand.Int64N(int64(upper - b.Base)))
+	}
+	if b.Max > 0 && delay > b.Max {
+		return b.Max
+	}
+	return delay
+}
@@ -0,0 +1,68 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package util ...
+package util
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstantBackoff(t *testing.T) {
+	b := ConstantBackoff{Interval: time.Second}
+	assert.Equal(t, time.Second, b.NextDelay(1, 0))
+	assert.Equal(t, time.Second, b.NextDelay(10, time.Second))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{Initial: 100 * time.Millisecond, Max: time.Second}
+	var delays []time.Duration
+	for attempt := 1; attempt <= 6; attempt++ {
+		delays = append(delays, b.NextDelay(attempt, 0))
+	}
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second,
+	}, delays)
+
+	b = ExponentialBackoff{Initial: time.Second, Multiplier: 3}
+	assert.Equal(t, 9*time.Second, b.NextDelay(3, 0))
+	assert.Equal(t, time.Second, ExponentialBackoff{Initial: 2 * time.Second, Max: time.Second}.NextDelay(1, 0))
+
+	// Without Max the delay stops growing at the longest time.Duration instead of overflowing
+	b = ExponentialBackoff{Initial: time.Second}
+	assert.Equal(t, time.Duration(math.MaxInt64), b.NextDelay(1000, 0))
+	assert.Equal(t, time.Duration(math.MaxInt64), b.NextDelay(math.MaxInt32, 0))
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	b := DecorrelatedJitterBackoff{Base: 10 * time.Millisecond, Max: time.Second}
+	assert.Equal(t, 10*time.Millisecond, b.NextDelay(1, 0))
+
+	previous := b.NextDelay(1, 0)
+	for attempt := 2; attempt < 50; attempt++ {
+		delay := b.NextDelay(attempt, previous)
+		assert.GreaterOrEqual(t, delay, b.Base)
+		assert.LessOrEqual(t, delay, b.Max)
+		if previous*3 < b.Max {
+			assert.Less(t, delay, previous*3)
+		}
+		previous = delay
+	}
+}
@@ -33,3 +33,11 @@ func TestGetErrorType(t *testing.T) {
 	newErr = NewErrorWithProperties("ProvisioningFailed", "", map[string]string{"properties": "properties"}, errors.New("provisioningFailed"))
 	assert.NotNil(t, GetErrorType(newErr))
 }
+
+func TestMessageNotFound(t *testing.T) {
+	assert.True(t, Message{Type: EntityNotFound}.NotFound())
+	assert.True(t, Message{Type: VolumeAttachFindFailed}.NotFound())
+	assert.True(t, Message{Type: VolumeAccessPointFindFailed}.NotFound())
+	assert.True(t, Message{Type: RetrivalFailed, RC: 404}.NotFound())
+	assert.False(t, Message{Type: RetrivalFailed, RC: 500}.NotFound())
+}
@@ -18,11 +18,13 @@
 package util
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"time"
 
 	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/tracing"
 	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -38,7 +40,8 @@ func NewError(code reasoncode.ReasonCode, msg string, wrapped ...error) error {
 // NewErrorWithProperties returns an error that is implemented provider.Error and
 // which is decorated with diagnostic properties.
 // If optional wrapped errors are a provider.Error, this preserves all child wrapped
-// errors in depth-first order.
+// errors in depth-first order. The wrapped errors themselves remain reachable
+// through errors.Is and errors.As.
 func NewErrorWithProperties(code reasoncode.ReasonCode, msg string, properties map[string]string, wrapped ...error) error {
 	if code == "" {
 		code = "" // TODO: ErrorUnclassified
@@ -59,7 +62,7 @@ func NewErrorWithProperties(code reasoncode.ReasonCode, msg string, properties m
 			Properties: properties,
 			Wrapped:    werrs,
 		},
-	}
+	}.WithWrapped(wrapped...)
 }
 
 // ErrorDeepUnwrapString returns the full list of unwrapped error strings
@@ -137,6 +140,30 @@ type ErrorRetrier struct {
 	MaxAttempts   int
 	RetryInterval time.Duration
 	Logger        *zap.Logger
+
+	// Backoff decides the delay between attempts, ConstantBackoff{RetryInterval} if not set
+	Backoff BackoffPolicy
+
+	// MaxElapsedTime stops retrying once the next attempt would start after it, no limit if not set
+	MaxElapsedTime time.Duration
+
+	// OnAttempt is called after every attempt, e.g. to record metrics
+	OnAttempt func(attempt RetryAttempt)
+}
+
+// RetryAttempt describes one attempt made by ErrorRetrier
+type RetryAttempt struct {
+	// Attempt is the number of the attempt, starting at 1
+	Attempt int
+
+	// Err is the error returned by the attempt
+	Err error
+
+	// Elapsed is the time since the first attempt started
+	Elapsed time.Duration
+
+	// Delay is the wait before the next attempt, 0 if this is the last attempt
+	Delay time.Duration
 }
 
 // NewErrorRetrier return new ErrorRetrier
@@ -150,24 +177,83 @@ func NewErrorRetrier(maxAttempt int, retryInterval time.Duration, logger *zap.Lo
 
 // ErrorRetry path for retry logic with logger passed in
 func (er *ErrorRetrier) ErrorRetry(funcToRetry func() (error, bool)) error {
+	return er.ErrorRetryWithContext(context.Background(), funcToRetry)
+}
+
+// ErrorRetryWithContext retries funcToRetry until it succeeds, asks to stop, runs out of attempts
+// or elapsed time, or ctx is done. If ctx is done while waiting to retry, the last error is returned
+// wrapped together with ctx.Err(), keeping its reason code.
+func (er *ErrorRetrier) ErrorRetryWithContext(ctx context.Context, funcToRetry func() (error, bool)) error {
+	return er.ErrorRetryWithAttemptContext(ctx, func(context.Context) (error, bool) {
+		return funcToRetry()
+	})
+}
+
+// ErrorRetryWithAttemptContext is ErrorRetryWithContext for a funcToRetry which takes the context of the
+// attempt, whose span is the parent of the spans started by funcToRetry
+func (er *ErrorRetrier) ErrorRetryWithAttemptContext(ctx context.Context, funcToRetry func(ctx context.Context) (error, bool)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	backoff := er.Backoff
+	if backoff == nil {
+		backoff = ConstantBackoff{Interval: er.RetryInterval}
+	}
+
 	var err error
 	var shouldStop bool
-	for i := 0; ; i++ {
-		err, shouldStop = funcToRetry()
+	var delay, previous time.Duration
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		attemptCtx, span := tracing.Start(ctx, "ErrorRetrier.attempt", tracing.AttemptKey.Int(attempt))
+		err, shouldStop = funcToRetry(attemptCtx)
+		tracing.End(span, err)
 		er.Logger.Debug("Retry Function Result", zap.Error(err), zap.Bool("shouldStop", shouldStop))
-		if shouldStop {
-			break
+		//Stop on success, if asked to, or if out of retries
+		delay = 0
+		if err != nil && !shouldStop && attempt < er.MaxAttempts {
+			delay = backoff.NextDelay(attempt, previous)
+			previous = delay
+			if er.MaxElapsedTime > 0 && time.Since(start)+delay > er.MaxElapsedTime {
+				er.Logger.Warn("Not retrying, maximum elapsed time would be exceeded", zap.Duration("maxElapsedTime", er.MaxElapsedTime))
+				shouldStop = true
+				delay = 0
+			}
+		} else {
+			shouldStop = true
 		}
-		if err == nil {
-			return err
+		if er.OnAttempt != nil {
+			er.OnAttempt(RetryAttempt{Attempt: attempt, Err: err, Elapsed: time.Since(start), Delay: delay})
 		}
-		//Stop if out of retries
-		if i >= (er.MaxAttempts - 1) {
+		if shouldStop {
 			break
 		}
-		time.Sleep(er.RetryInterval)
-		er.Logger.Warn("retrying after Error:", zap.Error(err))
+
+		er.Logger.Warn("retrying after Error:", zap.Error(err), zap.Duration("delay", delay))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return NewError(ErrorReasonCode(err), err.Error(), err, ctx.Err())
+		}
 	}
 	//error set by name above so no need to explicitly return it
 	return err
 }
+
+// ReasonCodeRetry adapts funcToRetry for ErrorRetrier so that the retry decision comes from the
+// reason code catalog: a failed attempt is retried only if its reason code is retryable
+func ReasonCodeRetry(funcToRetry func() error) func() (error, bool) {
+	return func() (error, bool) {
+		err := funcToRetry()
+		if err == nil {
+			return nil, true
+		}
+		var perr provider.Error
+		if !errors.As(err, &perr) {
+			return err, true
+		}
+		return err, !reasoncode.IsRetryable(perr.Code())
+	}
+}
@@ -18,13 +18,22 @@
 package util
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/tracing"
 	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
 )
 
 func TestNewError(t *testing.T) {
@@ -230,3 +239,199 @@ func TestZapError(t *testing.T) {
 	assert.NotNil(t, ZapError(errors.New("test")))
 	assert.NotNil(t, ZapError(NewError("TEST", "Test")))
 }
+
+func TestNewErrorUnwrap(t *testing.T) {
+	cause := fmt.Errorf("request failed: %w", context.DeadlineExceeded)
+	inner := NewError(reasoncode.ErrorTemporaryConnectionProblem, "connection problem", cause)
+	err := NewErrorWithProperties(reasoncode.ErrorFailedTokenExchange, "token exchange failed", map[string]string{"a": "b"}, inner)
+
+	assert.Equal(t, []string{"connection problem", "request failed: context deadline exceeded"}, ErrorDeepUnwrapString(err))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, errors.Is(err, reasoncode.ErrorFailedTokenExchange))
+	assert.True(t, errors.Is(err, reasoncode.ErrorTemporaryConnectionProblem))
+	assert.False(t, errors.Is(err, reasoncode.ErrorRateLimitExceeded))
+	assert.Equal(t, reasoncode.ErrorFailedTokenExchange, ErrorReasonCode(err))
+}
+
+func TestErrorRetry(t *testing.T) {
+	logger := zap.NewNop()
+	temporary := NewError(reasoncode.ErrorTemporaryConnectionProblem, "connection reset")
+
+	// Succeeds on the third attempt
+	attempts := 0
+	er := NewErrorRetrier(5, time.Millisecond, logger)
+	err := er.ErrorRetry(func() (error, bool) {
+		attempts++
+		if attempts < 3 {
+			return temporary, false
+		}
+		return nil, false
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// Runs out of attempts
+	attempts = 0
+	err = er.ErrorRetry(func() (error, bool) {
+		attempts++
+		return temporary, false
+	})
+	assert.Equal(t, temporary, err)
+	assert.Equal(t, 5, attempts)
+
+	// Asked to stop
+	attempts = 0
+	err = er.ErrorRetry(func() (error, bool) {
+		attempts++
+		return temporary, true
+	})
+	assert.Equal(t, temporary, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestErrorRetryBackoffAndHooks(t *testing.T) {
+	var recorded []RetryAttempt
+	er := NewErrorRetrier(4, time.Hour, zap.NewNop())
+	er.Backoff = ExponentialBackoff{Initial: time.Millisecond}
+	er.OnAttempt = func(attempt RetryAttempt) {
+		recorded = append(recorded, attempt)
+	}
+	err := er.ErrorRetry(func() (error, bool) {
+		return errors.New("failed"), false
+	})
+	assert.EqualError(t, err, "failed")
+	if assert.Len(t, recorded, 4) {
+		assert.Equal(t, []int{1, 2, 3, 4}, []int{recorded[0].Attempt, recorded[1].Attempt, recorded[2].Attempt, recorded[3].Attempt})
+		assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 0},
+			[]time.Duration{recorded[0].Delay, recorded[1].Delay, recorded[2].Delay, recorded[3].Delay})
+		assert.EqualError(t, recorded[3].Err, "failed")
+		assert.GreaterOrEqual(t, recorded[3].Elapsed, 7*time.Millisecond)
+	}
+}
+
+func TestErrorRetryMaxElapsedTime(t *testing.T) {
+	attempts := 0
+	er := NewErrorRetrier(40, 20*time.Millisecond, zap.NewNop())
+	er.MaxElapsedTime = 50 * time.Millisecond
+	start := time.Now()
+	err := er.ErrorRetry(func() (error, bool) {
+		attempts++
+		return errors.New("failed"), false
+	})
+	assert.EqualError(t, err, "failed")
+	assert.GreaterOrEqual(t, attempts, 2)
+	assert.LessOrEqual(t, attempts, 3)
+	assert.Less(t, time.Since(start), er.MaxElapsedTime)
+}
+
+func TestErrorRetryWithContext(t *testing.T) {
+	er := NewErrorRetrier(40, time.Hour, zap.NewNop())
+	temporary := NewError(reasoncode.ErrorTemporaryConnectionProblem, "connection reset")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	attempts := 0
+	err := er.ErrorRetryWithContext(ctx, func() (error, bool) {
+		attempts++
+		return temporary, false
+	})
+	assert.Equal(t, 1, attempts)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, errors.Is(err, reasoncode.ErrorTemporaryConnectionProblem))
+	assert.Equal(t, "connection reset", err.Error())
+
+	// An already cancelled context makes no attempt
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	err = er.ErrorRetryWithContext(cancelled, func() (error, bool) {
+		attempts++
+		return nil, false
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestReasonCodeRetry(t *testing.T) {
+	er := NewErrorRetrier(3, time.Millisecond, zap.NewNop())
+	for _, testCase := range []struct {
+		err      error
+		attempts int
+	}{
+		{err: NewError(reasoncode.ErrorRateLimitExceeded, "rate limited"), attempts: 3},
+		{err: fmt.Errorf("wrapped: %w", NewError(reasoncode.EndpointNotReachable, "unreachable")), attempts: 3},
+		{err: NewError(reasoncode.ErrorBadRequest, "bad request"), attempts: 1},
+		{err: errors.New("not a provider error"), attempts: 1},
+		{err: nil, attempts: 1},
+	} {
+		attempts := 0
+		err := er.ErrorRetry(ReasonCodeRetry(func() error {
+			attempts++
+			return testCase.err
+		}))
+		assert.Equal(t, testCase.err, err)
+		assert.Equal(t, testCase.attempts, attempts, fmt.Sprint(testCase.err))
+	}
+}
+
+func TestErrorRetryTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	attempts := 0
+	err := NewErrorRetrier(3, time.Millisecond, zap.NewNop()).ErrorRetryWithContext(ctx, func() (error, bool) {
+		attempts++
+		if attempts < 2 {
+			return NewError(reasoncode.ErrorTemporaryConnectionProblem, "connection reset"), false
+		}
+		return nil, false
+	})
+	parent.End()
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 3) {
+		for i, span := range spans[:2] {
+			assert.Equal(t, "ErrorRetrier.attempt", span.Name())
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			assert.Contains(t, span.Attributes(), tracing.AttemptKey.Int(i+1))
+		}
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Contains(t, spans[0].Attributes(), tracing.ReasonCodeKey.String("ErrorTemporaryConnectionProblem"))
+		assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	}
+}
+
+func TestErrorRetryWithAttemptContextTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	attempts := 0
+	err := NewErrorRetrier(3, time.Millisecond, zap.NewNop()).ErrorRetryWithAttemptContext(ctx, func(ctx context.Context) (error, bool) {
+		attempts++
+		_, span := tracing.Start(ctx, "child")
+		span.End()
+		if attempts < 2 {
+			return NewError(reasoncode.ErrorTemporaryConnectionProblem, "connection reset"), false
+		}
+		return nil, false
+	})
+	parent.End()
+	assert.NoError(t, err)
+
+	// The spans of each attempt are children of its attempt span, in the trace of the parent
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 5) {
+		for i := 0; i < 2; i++ {
+			child, attempt := spans[2*i], spans[2*i+1]
+			assert.Equal(t, "child", child.Name())
+			assert.Equal(t, "ErrorRetrier.attempt", attempt.Name())
+			assert.Equal(t, attempt.SpanContext().SpanID(), child.Parent().SpanID())
+			assert.Equal(t, parent.SpanContext().SpanID(), attempt.Parent().SpanID())
+			assert.Equal(t, parent.SpanContext().TraceID(), child.SpanContext().TraceID())
+		}
+	}
+}
@@ -19,6 +19,7 @@ package util
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 )
 
@@ -48,3 +49,12 @@ func (msg Message) Info() string {
 		return fmt.Sprintf("{Code:%s, Description:%s.%s, RC:%d}", msg.Code, msg.Description, msg.BackendError, msg.RC)
 	}
 }
+
+// NotFound reports whether the message is for an entity that does not exist
+func (msg Message) NotFound() bool {
+	switch msg.Type {
+	case EntityNotFound, VolumeAttachFindFailed, VolumeAccessPointFindFailed:
+		return true
+	}
+	return msg.RC == http.StatusNotFound
+}
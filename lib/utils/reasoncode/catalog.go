@@ -0,0 +1,192 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package reasoncode ...
+package reasoncode
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// RetryClass describes whether, and for how long, a caller may retry an operation that failed with a ReasonCode
+type RetryClass int
+
+const (
+	// RetryNever indicates a fatal failure which must not be retried
+	RetryNever RetryClass = iota
+
+	// RetryLimited indicates the caller can retry later, but not indefinitely
+	RetryLimited
+
+	// RetryIndefinite indicates the caller can continue to retry indefinitely
+	RetryIndefinite
+)
+
+// String returns the name of the retry class
+func (class RetryClass) String() string {
+	switch class {
+	case RetryNever:
+		return "never"
+	case RetryLimited:
+		return "limited"
+	case RetryIndefinite:
+		return "indefinite"
+	}
+	return fmt.Sprintf("RetryClass(%d)", int(class))
+}
+
+// Info is the catalog entry of a ReasonCode
+type Info struct {
+	// Code is the reason code described
+	Code ReasonCode
+
+	// Retry is the retry class of the reason code
+	Retry RetryClass
+
+	// HTTPStatus is the suggested HTTP status for an error with the reason code
+	HTTPStatus int
+
+	// GRPCCode is the suggested CSI/gRPC status code for an error with the reason code
+	GRPCCode codes.Code
+
+	// Description is a user facing description of the reason code
+	Description string
+}
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = map[ReasonCode]Info{}
+)
+
+func init() {
+	for _, info := range []Info{
+		{ErrorUnclassified, RetryNever, http.StatusInternalServerError, codes.Unknown, "An unclassified error occurred"},
+		{ErrorPanic, RetryNever, http.StatusInternalServerError, codes.Internal, "The provider recovered from an internal error"},
+		{ErrorTemporaryConnectionProblem, RetryIndefinite, http.StatusGatewayTimeout, codes.Unavailable, "The IaaS API timed out or reset the connection, the outcome of the request is unknown"},
+		{ErrorRateLimitExceeded, RetryIndefinite, http.StatusTooManyRequests, codes.ResourceExhausted, "The IaaS API rate limit has been exceeded"},
+
+		{ErrorBadRequest, RetryNever, http.StatusBadRequest, codes.InvalidArgument, "The request is not valid"},
+		{ErrorRequiredFieldMissing, RetryNever, http.StatusBadRequest, codes.InvalidArgument, "A required field is missing from the request"},
+		{ErrorUnsupportedAuthType, RetryNever, http.StatusBadRequest, codes.InvalidArgument, "The requested authentication type is not supported"},
+		{ErrorUnsupportedMethod, RetryNever, http.StatusNotImplemented, codes.Unimplemented, "The requested provider method is not supported"},
+
+		{Timeout, RetryLimited, http.StatusGatewayTimeout, codes.DeadlineExceeded, "The token exchange endpoint did not respond in time"},
+		{EndpointNotReachable, RetryLimited, http.StatusServiceUnavailable, codes.Unavailable, "The token exchange endpoint could not be reached"},
+		{ErrorUnknownProvider, RetryNever, http.StatusBadRequest, codes.InvalidArgument, "The named provider is not known"},
+		{ErrorUnauthorised, RetryNever, http.StatusUnauthorized, codes.Unauthenticated, "The IaaS credentials are not authorised"},
+		{ErrorFailedTokenExchange, RetryLimited, http.StatusUnauthorized, codes.Unauthenticated, "The IAM token exchange failed"},
+		{ErrorProviderAccountTemporarilyLocked, RetryLimited, http.StatusForbidden, codes.PermissionDenied, "The IaaS account has been temporarily locked"},
+		{ErrorInsufficientPermissions, RetryLimited, http.StatusForbidden, codes.PermissionDenied, "The IaaS user does not have the permissions required"},
+
+		{ErrorVolumeAttachFailed, RetryLimited, http.StatusInternalServerError, codes.Internal, "The volume could not be attached to the instance"},
+		{ErrorVolumeDetachFailed, RetryLimited, http.StatusInternalServerError, codes.Internal, "The volume could not be detached from the instance"},
+		{ErrorVolumeCloneFailed, RetryLimited, http.StatusInternalServerError, codes.Internal, "The volume could not be cloned"},
+
+		{ErrorEmptyProviderResult, RetryLimited, http.StatusBadGateway, codes.Internal, "The provider returned neither a result nor an error"},
+
+		{ErrorWaitTimedOut, RetryLimited, http.StatusGatewayTimeout, codes.DeadlineExceeded, "The resource did not reach the expected state in time"},
+		{ErrorWaitFailed, RetryNever, http.StatusConflict, codes.FailedPrecondition, "The resource reached a terminal state other than the expected one"},
+
+		{ErrorRepeatedPageToken, RetryNever, http.StatusBadGateway, codes.Internal, "The provider returned the token of a page that was already listed"},
+
+		{ErrorIdempotencyKeyMismatch, RetryNever, http.StatusConflict, codes.AlreadyExists, "The idempotency key was already used for a request with different parameters"},
+		{ErrorIdempotencyStoreFailed, RetryLimited, http.StatusInternalServerError, codes.Internal, "The idempotency record could not be read or saved"},
+	} {
+		MustRegister(info)
+	}
+}
+
+// Register adds a reason code to the catalog, so that providers can describe their own reason codes.
+// It fails if the code is empty or already registered.
+func Register(info Info) error {
+	if info.Code == "" {
+		return fmt.Errorf("reason code is required")
+	}
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if _, exists := catalog[info.Code]; exists {
+		return fmt.Errorf("reason code %s is already registered", info.Code)
+	}
+	catalog[info.Code] = info
+	return nil
+}
+
+// MustRegister is Register which panics on failure
+func MustRegister(info Info) {
+	if err := Register(info); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the catalog entry of the reason code
+func Lookup(code ReasonCode) (Info, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	info, ok := catalog[code]
+	return info, ok
+}
+
+// Codes returns every registered reason code in sorted order
+func Codes() []ReasonCode {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	registered := make([]ReasonCode, 0, len(catalog))
+	for code := range catalog {
+		registered = append(registered, code)
+	}
+	sort.Slice(registered, func(i, j int) bool { return registered[i] < registered[j] })
+	return registered
+}
+
+// Retry returns the retry class of the reason code, RetryNever if it is not registered
+func Retry(code ReasonCode) RetryClass {
+	info, _ := Lookup(code)
+	return info.Retry
+}
+
+// IsRetryable reports whether an operation which failed with the reason code may be retried
+func IsRetryable(code ReasonCode) bool {
+	return Retry(code) != RetryNever
+}
+
+// HTTPStatus returns the suggested HTTP status of the reason code, 500 if it is not registered
+func HTTPStatus(code ReasonCode) int {
+	if info, ok := Lookup(code); ok {
+		return info.HTTPStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the suggested CSI/gRPC status code of the reason code, codes.Unknown if it is not registered
+func GRPCCode(code ReasonCode) codes.Code {
+	if info, ok := Lookup(code); ok {
+		return info.GRPCCode
+	}
+	return codes.Unknown
+}
+
+// Description returns the user facing description of the reason code, or the code itself if it is not registered
+func Description(code ReasonCode) string {
+	if info, ok := Lookup(code); ok {
+		return info.Description
+	}
+	return string(code)
+}
@@ -0,0 +1,86 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package reasoncode ...
+package reasoncode
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCatalogCoversReasonCodes(t *testing.T) {
+	for _, code := range []ReasonCode{
+		ErrorUnclassified, ErrorPanic, ErrorTemporaryConnectionProblem, ErrorRateLimitExceeded,
+		ErrorBadRequest, ErrorRequiredFieldMissing, ErrorUnsupportedAuthType, ErrorUnsupportedMethod,
+		Timeout, EndpointNotReachable, ErrorUnknownProvider, ErrorUnauthorised, ErrorFailedTokenExchange,
+		ErrorProviderAccountTemporarilyLocked, ErrorInsufficientPermissions,
+		ErrorVolumeAttachFailed, ErrorVolumeDetachFailed, ErrorVolumeCloneFailed,
+		ErrorEmptyProviderResult,
+		ErrorWaitTimedOut, ErrorWaitFailed,
+		ErrorRepeatedPageToken,
+		ErrorIdempotencyKeyMismatch, ErrorIdempotencyStoreFailed,
+	} {
+		info, ok := Lookup(code)
+		if assert.True(t, ok, string(code)) {
+			assert.Equal(t, code, info.Code)
+			assert.NotEmpty(t, info.Description, string(code))
+			assert.NotZero(t, info.HTTPStatus, string(code))
+		}
+	}
+}
+
+func TestCatalogLookups(t *testing.T) {
+	assert.True(t, IsRetryable(ErrorRateLimitExceeded))
+	assert.Equal(t, RetryIndefinite, Retry(ErrorTemporaryConnectionProblem))
+	assert.Equal(t, RetryLimited, Retry(ErrorInsufficientPermissions))
+	assert.False(t, IsRetryable(ErrorBadRequest))
+	assert.Equal(t, http.StatusTooManyRequests, HTTPStatus(ErrorRateLimitExceeded))
+	assert.Equal(t, codes.ResourceExhausted, GRPCCode(ErrorRateLimitExceeded))
+	assert.Equal(t, codes.InvalidArgument, GRPCCode(ErrorRequiredFieldMissing))
+	assert.Equal(t, codes.Unauthenticated, GRPCCode(ErrorUnauthorised))
+
+	// Unregistered reason codes
+	unknown := ReasonCode("ErrorNotInCatalog")
+	assert.False(t, IsRetryable(unknown))
+	assert.Equal(t, RetryNever, Retry(unknown))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus(unknown))
+	assert.Equal(t, codes.Unknown, GRPCCode(unknown))
+	assert.Equal(t, "ErrorNotInCatalog", Description(unknown))
+}
+
+func TestRegister(t *testing.T) {
+	code := ReasonCode("ErrorTestRegister")
+	assert.NoError(t, Register(Info{Code: code, Retry: RetryLimited, HTTPStatus: http.StatusConflict, GRPCCode: codes.Aborted, Description: "Test"}))
+	assert.Contains(t, Codes(), code)
+	assert.True(t, IsRetryable(code))
+	assert.Equal(t, codes.Aborted, GRPCCode(code))
+	assert.Equal(t, "Test", Description(code))
+
+	assert.Error(t, Register(Info{Code: code}))
+	assert.Error(t, Register(Info{}))
+	assert.Panics(t, func() { MustRegister(Info{Code: ErrorBadRequest}) })
+}
+
+func TestRetryClassString(t *testing.T) {
+	assert.Equal(t, "never", RetryNever.String())
+	assert.Equal(t, "limited", RetryLimited.String())
+	assert.Equal(t, "indefinite", RetryIndefinite.String())
+	assert.Equal(t, "RetryClass(7)", RetryClass(7).String())
+}
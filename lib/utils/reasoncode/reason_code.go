@@ -20,6 +20,11 @@ package reasoncode
 // ReasonCode ...
 type ReasonCode string
 
+// Error satisfies the error contract so that a ReasonCode can be the target of errors.Is
+func (code ReasonCode) Error() string {
+	return string(code)
+}
+
 const (
 
 	// ErrorUnclassified indicates a generic unclassified error
@@ -91,4 +96,34 @@ const (
 	ErrorVolumeAttachFailed = ReasonCode("ErrorVolumeAttachFailed")
 	//ErrorVolumeDetachFailed indicates if volume detach from instance is failed
 	ErrorVolumeDetachFailed = ReasonCode("ErrorVolumeDetachFailed")
+	//ErrorVolumeCloneFailed indicates if a step of cloning a volume is failed
+	ErrorVolumeCloneFailed = ReasonCode("ErrorVolumeCloneFailed")
+)
+
+// Provider response problems
+const (
+	// ErrorEmptyProviderResult indicates a provider returned neither a result nor an error
+	ErrorEmptyProviderResult = ReasonCode("ErrorEmptyProviderResult")
+)
+
+// Wait problems
+const (
+	// ErrorWaitTimedOut indicates a resource did not reach the expected state before the wait timed out or was cancelled
+	ErrorWaitTimedOut = ReasonCode("ErrorWaitTimedOut")
+	// ErrorWaitFailed indicates a resource reached a terminal state other than the expected one
+	ErrorWaitFailed = ReasonCode("ErrorWaitFailed")
+)
+
+// Pagination problems
+const (
+	// ErrorRepeatedPageToken indicates a provider returned a Next token of a page that was already listed
+	ErrorRepeatedPageToken = ReasonCode("ErrorRepeatedPageToken")
+)
+
+// Idempotency problems
+const (
+	// ErrorIdempotencyKeyMismatch indicates an idempotency key was reused for a request with different parameters
+	ErrorIdempotencyKeyMismatch = ReasonCode("ErrorIdempotencyKeyMismatch")
+	// ErrorIdempotencyStoreFailed indicates an idempotency record could not be read or saved
+	ErrorIdempotencyStoreFailed = ReasonCode("ErrorIdempotencyStoreFailed")
 )
@@ -39,6 +39,23 @@ func NewContextCredentialsFactory(authConfig *iam.AuthConfiguration, k8sClient *
 		return nil, err
 	}
 	return &ContextCredentialsFactory{
-		TokenExchangeService: tokenExchangeService,
+		TokenExchangeService: iam.NewCachingTokenExchangeService(tokenExchangeService, iam.TokenCacheConfig{DefaultTTL: iam.DefaultTokenTTL}),
 	}, nil
 }
+
+// EvictOnError evicts the tokens cached for the credentials if err shows that a token was rejected, e.g. by
+// a provider which received an IMS token or access token from this factory, and reports whether it did.
+// It reports false if the TokenExchangeService does not cache tokens.
+func (ccf *ContextCredentialsFactory) EvictOnError(err error, credentials ...string) bool {
+	cache, ok := ccf.TokenExchangeService.(*iam.CachingTokenExchangeService)
+	if !ok {
+		return false
+	}
+	evicted := false
+	for _, credential := range credentials {
+		if cache.EvictOnError(err, credential) {
+			evicted = true
+		}
+	}
+	return evicted
+}
@@ -45,6 +45,13 @@ func (ccf *ContextCredentialsFactory) ForRefreshToken(refreshToken string, logge
 	}
 
 	imsToken, err := ccf.TokenExchangeService.ExchangeAccessTokenForIMSToken(*accessToken, logger)
+	if err != nil && ccf.EvictOnError(err, refreshToken, accessToken.Token) {
+		// The cached access token was rejected, so retry once with a new one
+		logger.Warn("Access token rejected, retrying with a new access token", local.ZapError(err))
+		if accessToken, err = ccf.TokenExchangeService.ExchangeRefreshTokenForAccessToken(refreshToken, logger); err == nil {
+			imsToken, err = ccf.TokenExchangeService.ExchangeAccessTokenForIMSToken(*accessToken, logger)
+		}
+	}
 	if err != nil {
 		// Must preserve provider error code in the ErrorProviderAccountTemporarilyLocked case
 		logger.Error("Unable to retrieve IAM token from access token", local.ZapError(err))
@@ -74,6 +81,13 @@ func (ccf *ContextCredentialsFactory) ForIAMAccessToken(apiKey string, logger *z
 		return provider.ContextCredentials{}, err
 	}
 	iamAccountID, err := ccf.TokenExchangeService.GetIAMAccountIDFromAccessToken(iam.AccessToken{Token: iamAccessToken.Token}, logger)
+	if err != nil && ccf.EvictOnError(err, apiKey) {
+		// The cached access token was rejected, so retry once with a new one
+		logger.Warn("Access token rejected, retrying with a new access token", local.ZapError(err))
+		if iamAccessToken, err = ccf.TokenExchangeService.ExchangeIAMAPIKeyForAccessToken(apiKey, logger); err == nil {
+			iamAccountID, err = ccf.TokenExchangeService.GetIAMAccountIDFromAccessToken(iam.AccessToken{Token: iamAccessToken.Token}, logger)
+		}
+	}
 	if err != nil {
 		logger.Error("Unable to retrieve IAM access token from IAM API key", local.ZapError(err))
 		return provider.ContextCredentials{}, err
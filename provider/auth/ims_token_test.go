@@ -0,0 +1,151 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package auth ...
+package auth
+
+import (
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/provider/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+)
+
+// rotatingTokenExchangeService issues a new access token for every exchange and rejects the revoked ones.
+// If unavailable is set, exchanges fail like they do while IAM is unavailable.
+type rotatingTokenExchangeService struct {
+	issued      int
+	revoked     map[string]bool
+	unavailable bool
+}
+
+func (s *rotatingTokenExchangeService) accessToken() *iam.AccessToken {
+	s.issued++
+	return &iam.AccessToken{Token: "access" + string(rune('0'+s.issued))}
+}
+
+func (s *rotatingTokenExchangeService) check(accessToken string) error {
+	if s.unavailable {
+		return util.NewError(reasoncode.ErrorFailedTokenExchange, "IAM token exchange request failed: service unavailable")
+	}
+	if s.revoked[accessToken] {
+		return util.NewError(reasoncode.ErrorFailedTokenExchange, "IAM token exchange request failed: access token revoked",
+			util.NewError(reasoncode.ErrorUnauthorised, "IAM rejected the credential"))
+	}
+	return nil
+}
+
+func (s *rotatingTokenExchangeService) ExchangeRefreshTokenForAccessToken(refreshToken string, logger *zap.Logger) (*iam.AccessToken, error) {
+	return s.accessToken(), nil
+}
+
+func (s *rotatingTokenExchangeService) ExchangeAccessTokenForIMSToken(accessToken iam.AccessToken, logger *zap.Logger) (*iam.IMSToken, error) {
+	if err := s.check(accessToken.Token); err != nil {
+		return nil, err
+	}
+	return &iam.IMSToken{UserID: 1, Token: "ims-" + accessToken.Token}, nil
+}
+
+func (s *rotatingTokenExchangeService) ExchangeIAMAPIKeyForIMSToken(iamAPIKey string, logger *zap.Logger) (*iam.IMSToken, error) {
+	return &iam.IMSToken{UserID: 1, Token: "ims"}, nil
+}
+
+func (s *rotatingTokenExchangeService) ExchangeIAMAPIKeyForAccessToken(iamAPIKey string, logger *zap.Logger) (*iam.AccessToken, error) {
+	return s.accessToken(), nil
+}
+
+func (s *rotatingTokenExchangeService) GetIAMAccountIDFromAccessToken(accessToken iam.AccessToken, logger *zap.Logger) (string, error) {
+	if s.revoked[accessToken.Token] {
+		return "", util.NewError(reasoncode.ErrorUnauthorised, "access token invalid")
+	}
+	return "account", nil
+}
+
+func newCachingContextCredentialsFactory() (*ContextCredentialsFactory, *rotatingTokenExchangeService) {
+	tes := &rotatingTokenExchangeService{revoked: map[string]bool{}}
+	return &ContextCredentialsFactory{
+		TokenExchangeService: iam.NewCachingTokenExchangeService(tes, iam.TokenCacheConfig{DefaultTTL: iam.DefaultTokenTTL}),
+	}, tes
+}
+
+func TestForRefreshTokenRetriesRejectedAccessToken(t *testing.T) {
+	ccf, tes := newCachingContextCredentialsFactory()
+
+	contextCredentials, err := ccf.ForRefreshToken("refresh", logger)
+	require.NoError(t, err)
+	assert.Equal(t, "ims-access1", contextCredentials.Credential)
+
+	// The access token is revoked once its IMS token has been rejected, so it is evicted and a new one is exchanged
+	tes.revoked["access1"] = true
+	assert.True(t, ccf.EvictOnError(util.NewError(reasoncode.ErrorUnauthorised, "token rejected"), "access1"))
+	contextCredentials, err = ccf.ForRefreshToken("refresh", logger)
+	require.NoError(t, err)
+	assert.Equal(t, "ims-access2", contextCredentials.Credential)
+	assert.Equal(t, 2, tes.issued)
+
+	// Only one retry is made
+	tes.revoked["access2"] = true
+	tes.revoked["access3"] = true
+	assert.True(t, ccf.EvictOnError(util.NewError(reasoncode.ErrorUnauthorised, "token rejected"), "access2"))
+	_, err = ccf.ForRefreshToken("refresh", logger)
+	assert.Equal(t, reasoncode.ErrorFailedTokenExchange, util.ErrorReasonCode(err))
+	assert.Equal(t, 3, tes.issued)
+}
+
+func TestForRefreshTokenDoesNotRetryUnavailableIAM(t *testing.T) {
+	ccf, tes := newCachingContextCredentialsFactory()
+	tes.unavailable = true
+
+	_, err := ccf.ForRefreshToken("refresh", logger)
+	assert.Equal(t, reasoncode.ErrorFailedTokenExchange, util.ErrorReasonCode(err))
+	assert.Equal(t, 1, tes.issued)
+}
+
+func TestForIAMAccessTokenRetriesRejectedAccessToken(t *testing.T) {
+	ccf, tes := newCachingContextCredentialsFactory()
+
+	contextCredentials, err := ccf.ForIAMAccessToken("apikey", logger)
+	require.NoError(t, err)
+	assert.Equal(t, "access1", contextCredentials.Credential)
+
+	tes.revoked["access1"] = true
+	contextCredentials, err = ccf.ForIAMAccessToken("apikey", logger)
+	require.NoError(t, err)
+	assert.Equal(t, "access2", contextCredentials.Credential)
+	assert.Equal(t, "account", contextCredentials.IAMAccountID)
+}
+
+func TestContextCredentialsFactoryEvictOnError(t *testing.T) {
+	ccf, tes := newCachingContextCredentialsFactory()
+
+	_, err := ccf.ForIAMAccessToken("apikey", logger)
+	require.NoError(t, err)
+
+	assert.False(t, ccf.EvictOnError(util.NewError(reasoncode.ErrorRateLimitExceeded, "rate limited"), "apikey"))
+	assert.True(t, ccf.EvictOnError(util.NewError(reasoncode.ErrorUnauthorised, "token rejected"), "apikey"))
+	contextCredentials, err := ccf.ForIAMAccessToken("apikey", logger)
+	require.NoError(t, err)
+	assert.Equal(t, "access2", contextCredentials.Credential)
+
+	// Nothing is evicted from a TokenExchangeService which does not cache
+	uncached := &ContextCredentialsFactory{TokenExchangeService: tes}
+	assert.False(t, uncached.EvictOnError(util.NewError(reasoncode.ErrorUnauthorised, "token rejected"), "apikey"))
+}
@@ -0,0 +1,186 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package iam ...
+package iam
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// jwksPath is the path of the IAM JSON Web Key Set, relative to AuthConfiguration.IamURL
+	jwksPath = "/identity/keys"
+
+	// jwksMaxAge is how long fetched keys are used before the key set is fetched again
+	jwksMaxAge = time.Hour
+
+	// jwksMinRefreshInterval limits how often an unknown kid can cause the key set to be fetched again
+	jwksMinRefreshInterval = time.Minute
+
+	// jwksFetchFailureBackoff is how long a failed fetch of the key set is reported again, rather than
+	// the key set being fetched on every validation while IAM is unavailable
+	jwksFetchFailureBackoff = 10 * time.Second
+)
+
+// jsonWebKey is an RSA key of a JSON Web Key Set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// jwksKeySet fetches and caches the RSA signing keys of a JSON Web Key Set, by kid.
+// An unknown kid fetches the key set again, so that rotated keys are picked up.
+// Concurrent fetches share one request, and cached keys are returned while a fetch is in progress.
+type jwksKeySet struct {
+	url        string
+	httpClient *http.Client
+	group      singleflight.Group
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+	fetchErr  error
+	failedAt  time.Time
+}
+
+// newJWKSKeySet ...
+func newJWKSKeySet(url string, httpClient *http.Client) *jwksKeySet {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &jwksKeySet{url: url, httpClient: httpClient}
+}
+
+// key returns the public key with the kid, fetching the key set if it is stale or does not have the kid
+func (ks *jwksKeySet) key(kid string, logger *zap.Logger) (*rsa.PublicKey, error) {
+	ks.mu.Lock()
+	age := time.Since(ks.fetchedAt)
+	key, ok := ks.keys[kid]
+	refresh := ks.keys == nil || age >= jwksMinRefreshInterval
+	ks.mu.Unlock()
+
+	if ok && age < jwksMaxAge {
+		return key, nil
+	}
+	if refresh {
+		if err := ks.refresh(kid, logger); err != nil {
+			// Keys which have only gone stale are still used while the key set cannot be fetched
+			if ok {
+				logger.Warn("Using stale IAM token signing key", zap.String("kid", kid), zap.Error(err))
+				return key, nil
+			}
+			return nil, err
+		}
+		ks.mu.Lock()
+		key, ok = ks.keys[kid]
+		ks.mu.Unlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("no IAM token signing key with kid '%s'", kid)
+	}
+	return key, nil
+}
+
+// refresh fetches the key set, unless a fetch failed within jwksFetchFailureBackoff in which case its
+// error is returned again. Concurrent callers share one fetch, which is made without holding ks.mu.
+func (ks *jwksKeySet) refresh(kid string, logger *zap.Logger) error {
+	ks.mu.Lock()
+	fetchErr, failedAt := ks.fetchErr, ks.failedAt
+	ks.mu.Unlock()
+	if fetchErr != nil && time.Since(failedAt) < jwksFetchFailureBackoff {
+		return fetchErr
+	}
+
+	_, err, _ := ks.group.Do(ks.url, func() (interface{}, error) {
+		logger.Info("Fetching IAM token signing keys", zap.String("url", ks.url), zap.String("kid", kid))
+		keys, err := ks.fetch()
+
+		ks.mu.Lock()
+		defer ks.mu.Unlock()
+		if err != nil {
+			ks.fetchErr, ks.failedAt = err, time.Now()
+			return nil, err
+		}
+		ks.keys, ks.fetchedAt = keys, time.Now()
+		ks.fetchErr = nil
+		return nil, nil
+	})
+	return err
+}
+
+// fetch retrieves and parses the key set
+func (ks *jwksKeySet) fetch() (map[string]*rsa.PublicKey, error) {
+	resp, err := ks.httpClient.Get(ks.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // #nosec G307
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching IAM token signing keys", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("invalid IAM token signing keys: %v", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid IAM token signing key '%s': %v", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// rsaPublicKey decodes the base64url encoded modulus and exponent of the key
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(jwk.N, "="))
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(jwk.E, "="))
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid modulus or exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,301 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package iam ...
+package iam
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultTokenRefreshBefore is how long before expiry a cached token is refreshed
+	DefaultTokenRefreshBefore = 5 * time.Minute
+
+	// DefaultTokenTTL is a conservative lifetime for tokens without an exp claim, IAM tokens are valid for an hour
+	DefaultTokenTTL = 20 * time.Minute
+)
+
+// Kinds of cached exchange, part of the cache key
+const (
+	refreshTokenAccessTokenKind = "refresh-token/access-token"
+	accessTokenIMSTokenKind     = "access-token/ims-token"
+	apiKeyIMSTokenKind          = "api-key/ims-token"
+	apiKeyAccessTokenKind       = "api-key/access-token"
+)
+
+// TokenCacheConfig configures a CachingTokenExchangeService
+type TokenCacheConfig struct {
+	// RefreshBefore is how long before expiry a cached token is exchanged again,
+	// DefaultTokenRefreshBefore if not set
+	RefreshBefore time.Duration
+
+	// DefaultTTL is how long to cache tokens whose expiry cannot be read from a JWT exp claim,
+	// such as IMS tokens exchanged for an API key. They are not cached if not set.
+	DefaultTTL time.Duration
+}
+
+// CachingTokenExchangeService is a TokenExchangeService which caches exchanged tokens, keyed by a
+// hash of the credential they were exchanged for, until shortly before they expire.
+// Concurrent exchanges of the same credential share one request to the wrapped service, which is
+// made with the context.Context of the first.
+type CachingTokenExchangeService struct {
+	tes    TokenExchangeService
+	ctes   ContextTokenExchangeService
+	config TokenCacheConfig
+
+	mu      sync.Mutex
+	entries map[string]tokenCacheEntry
+	group   singleflight.Group
+}
+
+// tokenCacheEntry is a cached *AccessToken or *IMSToken
+type tokenCacheEntry struct {
+	token     interface{}
+	refreshAt time.Time
+}
+
+var _ TokenExchangeService = &CachingTokenExchangeService{}
+var _ ContextTokenExchangeService = &CachingTokenExchangeService{}
+
+// NewCachingTokenExchangeService returns a CachingTokenExchangeService wrapping tes
+func NewCachingTokenExchangeService(tes TokenExchangeService, config TokenCacheConfig) *CachingTokenExchangeService {
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = DefaultTokenRefreshBefore
+	}
+	ctes, ok := tes.(ContextTokenExchangeService)
+	if !ok {
+		ctes = contextTokenExchangeServiceAdapter{tes}
+	}
+	return &CachingTokenExchangeService{
+		tes:     tes,
+		ctes:    ctes,
+		config:  config,
+		entries: map[string]tokenCacheEntry{},
+	}
+}
+
+// ExchangeRefreshTokenForAccessToken ...
+func (c *CachingTokenExchangeService) ExchangeRefreshTokenForAccessToken(refreshToken string, logger *zap.Logger) (*AccessToken, error) {
+	return c.ExchangeRefreshTokenForAccessTokenWithContext(context.Background(), refreshToken, logger)
+}
+
+// ExchangeRefreshTokenForAccessTokenWithContext ...
+func (c *CachingTokenExchangeService) ExchangeRefreshTokenForAccessTokenWithContext(ctx context.Context, refreshToken string, logger *zap.Logger) (*AccessToken, error) {
+	token, err := c.exchange(refreshTokenAccessTokenKind, refreshToken, logger, func() (interface{}, time.Time, error) {
+		accessToken, err := c.ctes.ExchangeRefreshTokenForAccessTokenWithContext(ctx, refreshToken, logger)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return accessToken, c.expiry(accessToken.Token), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	accessToken := *token.(*AccessToken)
+	return &accessToken, nil
+}
+
+// ExchangeAccessTokenForIMSToken ...
+// The IMS token is cached until the access token it was exchanged for expires.
+func (c *CachingTokenExchangeService) ExchangeAccessTokenForIMSToken(accessToken AccessToken, logger *zap.Logger) (*IMSToken, error) {
+	return c.ExchangeAccessTokenForIMSTokenWithContext(context.Background(), accessToken, logger)
+}
+
+// ExchangeAccessTokenForIMSTokenWithContext ...
+func (c *CachingTokenExchangeService) ExchangeAccessTokenForIMSTokenWithContext(ctx context.Context, accessToken AccessToken, logger *zap.Logger) (*IMSToken, error) {
+	token, err := c.exchange(accessTokenIMSTokenKind, accessToken.Token, logger, func() (interface{}, time.Time, error) {
+		imsToken, err := c.ctes.ExchangeAccessTokenForIMSTokenWithContext(ctx, accessToken, logger)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return imsToken, c.expiry(accessToken.Token), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	imsToken := *token.(*IMSToken)
+	return &imsToken, nil
+}
+
+// ExchangeIAMAPIKeyForIMSToken ...
+func (c *CachingTokenExchangeService) ExchangeIAMAPIKeyForIMSToken(iamAPIKey string, logger *zap.Logger) (*IMSToken, error) {
+	return c.ExchangeIAMAPIKeyForIMSTokenWithContext(context.Background(), iamAPIKey, logger)
+}
+
+// ExchangeIAMAPIKeyForIMSTokenWithContext ...
+func (c *CachingTokenExchangeService) ExchangeIAMAPIKeyForIMSTokenWithContext(ctx context.Context, iamAPIKey string, logger *zap.Logger) (*IMSToken, error) {
+	token, err := c.exchange(apiKeyIMSTokenKind, iamAPIKey, logger, func() (interface{}, time.Time, error) {
+		imsToken, err := c.ctes.ExchangeIAMAPIKeyForIMSTokenWithContext(ctx, iamAPIKey, logger)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return imsToken, c.expiry(imsToken.Token), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	imsToken := *token.(*IMSToken)
+	return &imsToken, nil
+}
+
+// ExchangeIAMAPIKeyForAccessToken ...
+func (c *CachingTokenExchangeService) ExchangeIAMAPIKeyForAccessToken(iamAPIKey string, logger *zap.Logger) (*AccessToken, error) {
+	return c.ExchangeIAMAPIKeyForAccessTokenWithContext(context.Background(), iamAPIKey, logger)
+}
+
+// ExchangeIAMAPIKeyForAccessTokenWithContext ...
+func (c *CachingTokenExchangeService) ExchangeIAMAPIKeyForAccessTokenWithContext(ctx context.Context, iamAPIKey string, logger *zap.Logger) (*AccessToken, error) {
+	token, err := c.exchange(apiKeyAccessTokenKind, iamAPIKey, logger, func() (interface{}, time.Time, error) {
+		accessToken, err := c.ctes.ExchangeIAMAPIKeyForAccessTokenWithContext(ctx, iamAPIKey, logger)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return accessToken, c.expiry(accessToken.Token), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	accessToken := *token.(*AccessToken)
+	return &accessToken, nil
+}
+
+// GetIAMAccountIDFromAccessToken is not cached
+func (c *CachingTokenExchangeService) GetIAMAccountIDFromAccessToken(accessToken AccessToken, logger *zap.Logger) (string, error) {
+	return c.tes.GetIAMAccountIDFromAccessToken(accessToken, logger)
+}
+
+// Evict removes every token cached for the credential (API key, refresh token or access token)
+func (c *CachingTokenExchangeService) Evict(credential string) {
+	hash := credentialHash(credential)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, kind := range []string{refreshTokenAccessTokenKind, accessTokenIMSTokenKind, apiKeyIMSTokenKind, apiKeyAccessTokenKind} {
+		delete(c.entries, kind+":"+hash)
+	}
+}
+
+// EvictOnError evicts the tokens cached for the credential if err has reasoncode.ErrorUnauthorised, i.e. a
+// token from the cache was rejected, and reports whether it did. Other failures, e.g. IAM being unavailable,
+// are not fixed by a new token. A 401 from the token exchange wraps ErrorUnauthorised in ErrorFailedTokenExchange.
+func (c *CachingTokenExchangeService) EvictOnError(err error, credential string) bool {
+	if !errors.Is(err, reasoncode.ErrorUnauthorised) {
+		return false
+	}
+	c.Evict(credential)
+	return true
+}
+
+// exchange returns the cached token for the credential, or performs the exchange and caches its result
+func (c *CachingTokenExchangeService) exchange(kind, credential string, logger *zap.Logger, exchange func() (interface{}, time.Time, error)) (interface{}, error) {
+	key := kind + ":" + credentialHash(credential)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.refreshAt) {
+		logger.Debug("Using cached token", zap.String("exchange", kind))
+		return entry.token, nil
+	}
+
+	token, err, shared := c.group.Do(key, func() (interface{}, error) {
+		token, expiresAt, err := exchange()
+		if err != nil {
+			if errors.Is(err, reasoncode.ErrorUnauthorised) {
+				c.mu.Lock()
+				delete(c.entries, key)
+				c.mu.Unlock()
+			}
+			return nil, err
+		}
+		now := time.Now()
+		c.mu.Lock()
+		c.prune(now)
+		if refreshAt := expiresAt.Add(-c.config.RefreshBefore); now.Before(refreshAt) {
+			c.entries[key] = tokenCacheEntry{token: token, refreshAt: refreshAt}
+		} else {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+		return token, nil
+	})
+	logger.Debug("Exchanged token", zap.String("exchange", kind), zap.Bool("shared", shared), zap.Error(err))
+	return token, err
+}
+
+// contextTokenExchangeServiceAdapter is the ContextTokenExchangeService of a TokenExchangeService which
+// does not take a context.Context, whose requests are made without ctx
+type contextTokenExchangeServiceAdapter struct {
+	TokenExchangeService
+}
+
+// ExchangeRefreshTokenForAccessTokenWithContext ...
+func (a contextTokenExchangeServiceAdapter) ExchangeRefreshTokenForAccessTokenWithContext(_ context.Context, refreshToken string, logger *zap.Logger) (*AccessToken, error) {
+	return a.ExchangeRefreshTokenForAccessToken(refreshToken, logger)
+}
+
+// ExchangeAccessTokenForIMSTokenWithContext ...
+func (a contextTokenExchangeServiceAdapter) ExchangeAccessTokenForIMSTokenWithContext(_ context.Context, accessToken AccessToken, logger *zap.Logger) (*IMSToken, error) {
+	return a.ExchangeAccessTokenForIMSToken(accessToken, logger)
+}
+
+// ExchangeIAMAPIKeyForIMSTokenWithContext ...
+func (a contextTokenExchangeServiceAdapter) ExchangeIAMAPIKeyForIMSTokenWithContext(_ context.Context, iamAPIKey string, logger *zap.Logger) (*IMSToken, error) {
+	return a.ExchangeIAMAPIKeyForIMSToken(iamAPIKey, logger)
+}
+
+// ExchangeIAMAPIKeyForAccessTokenWithContext ...
+func (a contextTokenExchangeServiceAdapter) ExchangeIAMAPIKeyForAccessTokenWithContext(_ context.Context, iamAPIKey string, logger *zap.Logger) (*AccessToken, error) {
+	return a.ExchangeIAMAPIKeyForAccessToken(iamAPIKey, logger)
+}
+
+// prune removes the entries which are no longer used, e.g. those of access tokens which have been
+// rotated, so that the cache does not grow without bound. It must be called with c.mu held.
+func (c *CachingTokenExchangeService) prune(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.refreshAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// expiry returns the expiry of the token read from its JWT exp claim, or else DefaultTTL from now
+func (c *CachingTokenExchangeService) expiry(token string) time.Time {
+	claims := jwt.StandardClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, &claims); err == nil && claims.ExpiresAt != 0 {
+		return time.Unix(claims.ExpiresAt, 0)
+	}
+	if c.config.DefaultTTL > 0 {
+		return time.Now().Add(c.config.DefaultTTL + c.config.RefreshBefore)
+	}
+	return time.Time{}
+}
+
+// credentialHash returns the hex encoded SHA-256 hash of the credential, so that credentials are not kept as map keys
+func credentialHash(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:])
+}
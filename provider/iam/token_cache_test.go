@@ -0,0 +1,231 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package iam ...
+package iam
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingTokenExchangeService returns the configured tokens and counts exchanges
+type countingTokenExchangeService struct {
+	accessToken string
+	imsToken    string
+	err         error
+	release     chan struct{}
+	exchanges   int32
+}
+
+func (s *countingTokenExchangeService) exchange() error {
+	atomic.AddInt32(&s.exchanges, 1)
+	if s.release != nil {
+		<-s.release
+	}
+	return s.err
+}
+
+func (s *countingTokenExchangeService) ExchangeRefreshTokenForAccessToken(refreshToken string, logger *zap.Logger) (*AccessToken, error) {
+	if err := s.exchange(); err != nil {
+		return nil, err
+	}
+	return &AccessToken{Token: s.accessToken}, nil
+}
+
+func (s *countingTokenExchangeService) ExchangeAccessTokenForIMSToken(accessToken AccessToken, logger *zap.Logger) (*IMSToken, error) {
+	if err := s.exchange(); err != nil {
+		return nil, err
+	}
+	return &IMSToken{UserID: 1, Token: s.imsToken}, nil
+}
+
+func (s *countingTokenExchangeService) ExchangeIAMAPIKeyForIMSToken(iamAPIKey string, logger *zap.Logger) (*IMSToken, error) {
+	if err := s.exchange(); err != nil {
+		return nil, err
+	}
+	return &IMSToken{UserID: 1, Token: s.imsToken}, nil
+}
+
+func (s *countingTokenExchangeService) ExchangeIAMAPIKeyForAccessToken(iamAPIKey string, logger *zap.Logger) (*AccessToken, error) {
+	if err := s.exchange(); err != nil {
+		return nil, err
+	}
+	return &AccessToken{Token: s.accessToken}, nil
+}
+
+func (s *countingTokenExchangeService) GetIAMAccountIDFromAccessToken(accessToken AccessToken, logger *zap.Logger) (string, error) {
+	return "account", nil
+}
+
+func (s *countingTokenExchangeService) count() int {
+	return int(atomic.LoadInt32(&s.exchanges))
+}
+
+func jwtExpiringIn(t *testing.T, d time.Duration) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{ExpiresAt: time.Now().Add(d).Unix()}).SignedString([]byte("key"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestCachingTokenExchangeServiceCachesUntilExpiry(t *testing.T) {
+	tes := &countingTokenExchangeService{accessToken: jwtExpiringIn(t, time.Hour), imsToken: "ims"}
+	c := NewCachingTokenExchangeService(tes, TokenCacheConfig{})
+
+	for i := 0; i < 3; i++ {
+		accessToken, err := c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+		require.NoError(t, err)
+		assert.Equal(t, tes.accessToken, accessToken.Token)
+	}
+	assert.Equal(t, 1, tes.count())
+
+	// Different credentials and exchanges are cached separately
+	_, err := c.ExchangeIAMAPIKeyForAccessToken("other", logger)
+	require.NoError(t, err)
+	_, err = c.ExchangeRefreshTokenForAccessToken("apikey", logger)
+	require.NoError(t, err)
+	assert.Equal(t, 3, tes.count())
+
+	// IMS tokens exchanged for an access token expire with it
+	for i := 0; i < 2; i++ {
+		imsToken, err := c.ExchangeAccessTokenForIMSToken(AccessToken{Token: tes.accessToken}, logger)
+		require.NoError(t, err)
+		assert.Equal(t, "ims", imsToken.Token)
+	}
+	assert.Equal(t, 4, tes.count())
+
+	// Callers get a copy of the cached token
+	accessToken, _ := c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	accessToken.Token = "modified"
+	accessToken, _ = c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	assert.Equal(t, tes.accessToken, accessToken.Token)
+}
+
+func TestCachingTokenExchangeServiceRefreshesBeforeExpiry(t *testing.T) {
+	tes := &countingTokenExchangeService{accessToken: jwtExpiringIn(t, 2*time.Minute)}
+	c := NewCachingTokenExchangeService(tes, TokenCacheConfig{RefreshBefore: 5 * time.Minute})
+
+	_, err := c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	require.NoError(t, err)
+	_, err = c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	require.NoError(t, err)
+	assert.Equal(t, 2, tes.count())
+}
+
+func TestCachingTokenExchangeServiceDefaultTTL(t *testing.T) {
+	tes := &countingTokenExchangeService{imsToken: "not-a-jwt"}
+	c := NewCachingTokenExchangeService(tes, TokenCacheConfig{})
+	_, _ = c.ExchangeIAMAPIKeyForIMSToken("apikey", logger)
+	_, _ = c.ExchangeIAMAPIKeyForIMSToken("apikey", logger)
+	assert.Equal(t, 2, tes.count())
+
+	tes = &countingTokenExchangeService{imsToken: "not-a-jwt"}
+	c = NewCachingTokenExchangeService(tes, TokenCacheConfig{DefaultTTL: time.Minute})
+	_, _ = c.ExchangeIAMAPIKeyForIMSToken("apikey", logger)
+	imsToken, err := c.ExchangeIAMAPIKeyForIMSToken("apikey", logger)
+	require.NoError(t, err)
+	assert.Equal(t, "not-a-jwt", imsToken.Token)
+	assert.Equal(t, 1, tes.count())
+}
+
+func TestCachingTokenExchangeServiceSingleflight(t *testing.T) {
+	tes := &countingTokenExchangeService{accessToken: jwtExpiringIn(t, time.Hour), release: make(chan struct{})}
+	c := NewCachingTokenExchangeService(tes, TokenCacheConfig{})
+
+	var wg sync.WaitGroup
+	tokens := make([]*AccessToken, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+		}(i)
+	}
+	assert.Eventually(t, func() bool { return tes.count() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(tes.release)
+	wg.Wait()
+
+	assert.Equal(t, 1, tes.count())
+	for _, token := range tokens {
+		if assert.NotNil(t, token) {
+			assert.Equal(t, tes.accessToken, token.Token)
+		}
+	}
+}
+
+func TestCachingTokenExchangeServiceEviction(t *testing.T) {
+	tes := &countingTokenExchangeService{accessToken: jwtExpiringIn(t, time.Hour)}
+	c := NewCachingTokenExchangeService(tes, TokenCacheConfig{})
+	_, err := c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	require.NoError(t, err)
+
+	assert.False(t, c.EvictOnError(util.NewError(reasoncode.ErrorRateLimitExceeded, "rate limited"), "apikey"))
+	_, _ = c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	assert.Equal(t, 1, tes.count())
+
+	assert.True(t, c.EvictOnError(util.NewError(reasoncode.ErrorUnauthorised, "token rejected"), "apikey"))
+	_, _ = c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	assert.Equal(t, 2, tes.count())
+
+	// Exchange failures only evict when IAM rejected the credential, not while it is unavailable
+	assert.False(t, c.EvictOnError(util.NewError(reasoncode.ErrorFailedTokenExchange, "IAM unavailable"), "apikey"))
+	assert.True(t, c.EvictOnError(util.NewError(reasoncode.ErrorFailedTokenExchange, "token rejected",
+		util.NewError(reasoncode.ErrorUnauthorised, "IAM rejected the credential")), "apikey"))
+	_, _ = c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	assert.Equal(t, 3, tes.count())
+
+	// Failed exchanges are not cached
+	tes.err = util.NewError(reasoncode.ErrorUnauthorised, "invalid API key")
+	c.Evict("apikey")
+	_, err = c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	assert.Equal(t, tes.err, err)
+	tes.err = nil
+	_, err = c.ExchangeIAMAPIKeyForAccessToken("apikey", logger)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, tes.count())
+
+	// Account ID lookups are passed through
+	accountID, err := c.GetIAMAccountIDFromAccessToken(AccessToken{}, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, "account", accountID)
+}
+
+func TestCachingTokenExchangeServicePrunesExpiredEntries(t *testing.T) {
+	tes := &countingTokenExchangeService{imsToken: "ims"}
+	c := NewCachingTokenExchangeService(tes, TokenCacheConfig{DefaultTTL: 20 * time.Millisecond})
+
+	_, err := c.ExchangeIAMAPIKeyForIMSToken("apikey1", logger)
+	require.NoError(t, err)
+	_, err = c.ExchangeIAMAPIKeyForIMSToken("apikey2", logger)
+	require.NoError(t, err)
+	assert.Len(t, c.entries, 2)
+
+	// The entries of rotated credentials are removed when a new token is cached
+	time.Sleep(30 * time.Millisecond)
+	_, err = c.ExchangeIAMAPIKeyForIMSToken("apikey3", logger)
+	require.NoError(t, err)
+	assert.Len(t, c.entries, 1)
+}
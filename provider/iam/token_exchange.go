@@ -18,21 +18,29 @@
 package iam
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/common/rest"
 	"github.com/IBM/ibmcloud-volume-interface/config"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/tracing"
 	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
 	"github.com/IBM/secret-common-lib/pkg/secret_provider"
 	"github.com/IBM/secret-utils-lib/pkg/k8s_utils"
 	sp "github.com/IBM/secret-utils-lib/pkg/secret_provider"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 const (
@@ -44,11 +52,23 @@ const (
 	Softlayer = secret_provider.Softlayer
 )
 
+// Retry policy of token exchange requests which fail with a connection error, so that an
+// unreachable IAM endpoint fails the caller within tokenExchangeMaxElapsedTime
+const (
+	tokenExchangeMaxAttempts          = 40
+	tokenExchangeInitialRetryInterval = 500 * time.Millisecond
+	tokenExchangeMaxRetryInterval     = 3 * time.Second
+	tokenExchangeMaxElapsedTime       = 30 * time.Second
+)
+
 // tokenExchangeService ...
 type tokenExchangeService struct {
 	authConfig     *AuthConfiguration
 	httpClient     *http.Client
 	secretprovider sp.SecretProviderInterface
+
+	keySetOnce sync.Once
+	keySet     *jwksKeySet
 }
 
 // AuthConfiguration ...
@@ -56,11 +76,36 @@ type AuthConfiguration struct {
 	IamURL          string
 	IamClientID     string
 	IamClientSecret string
+
+	// IamTokenIssuer is the expected iss claim of access tokens, DefaultIamTokenIssuer if not set.
+	// Tokens from the private IAM endpoint are issued by the public one, so it is not derived from IamURL.
+	IamTokenIssuer string
+
+	// SkipTokenVerification disables verification of access token signatures and claims.
+	// Only for test environments whose tokens are not issued by IAM.
+	SkipTokenVerification bool
+}
+
+// NewAuthConfiguration returns the AuthConfiguration of the IAM settings in the Bluemix config
+func NewAuthConfiguration(conf *config.BluemixConfig) *AuthConfiguration {
+	if conf == nil {
+		return &AuthConfiguration{}
+	}
+	return &AuthConfiguration{
+		IamURL:                conf.IamURL,
+		IamClientID:           conf.IamClientID,
+		IamClientSecret:       conf.IamClientSecret,
+		IamTokenIssuer:        conf.IamTokenIssuer,
+		SkipTokenVerification: conf.SkipTokenVerification,
+	}
 }
 
 // TokenExchangeService ...
 var _ TokenExchangeService = &tokenExchangeService{}
 
+// ContextTokenExchangeService ...
+var _ ContextTokenExchangeService = &tokenExchangeService{}
+
 // NewTokenExchangeServiceWithClient ...
 func NewTokenExchangeServiceWithClient(authConfig *AuthConfiguration, httpClient *http.Client) (TokenExchangeService, error) {
 	return &tokenExchangeService{
@@ -71,7 +116,12 @@ func NewTokenExchangeServiceWithClient(authConfig *AuthConfiguration, httpClient
 
 // NewTokenExchangeService ...
 func NewTokenExchangeService(authConfig *AuthConfiguration, k8sClient *k8s_utils.KubernetesClient, providerType ...string) (TokenExchangeService, error) {
-	httpClient, err := config.GeneralCAHttpClient()
+	var iamURL string
+	if authConfig != nil {
+		iamURL = authConfig.IamURL
+	}
+	// The client and its connections are shared by the token exchange services of the IAM endpoint
+	httpClient, err := config.DefaultTransportFactory().Client(iamURL)
 	if err != nil {
 		return nil, err
 	}
@@ -97,6 +147,8 @@ func NewTokenExchangeService(authConfig *AuthConfiguration, k8sClient *k8s_utils
 
 // tokenExchangeRequest ...
 type tokenExchangeRequest struct {
+	// ctx is the parent of the request's spans, and holds its request ID
+	ctx          context.Context
 	tes          *tokenExchangeService
 	request      *rest.Request
 	client       *rest.Client
@@ -113,7 +165,12 @@ type tokenExchangeResponse struct {
 
 // ExchangeRefreshTokenForAccessToken ...
 func (tes *tokenExchangeService) ExchangeRefreshTokenForAccessToken(refreshToken string, logger *zap.Logger) (*AccessToken, error) {
-	r := tes.newTokenExchangeRequest(logger)
+	return tes.ExchangeRefreshTokenForAccessTokenWithContext(context.Background(), refreshToken, logger)
+}
+
+// ExchangeRefreshTokenForAccessTokenWithContext ...
+func (tes *tokenExchangeService) ExchangeRefreshTokenForAccessTokenWithContext(ctx context.Context, refreshToken string, logger *zap.Logger) (*AccessToken, error) {
+	r := tes.newTokenExchangeRequest(ctx, logger)
 
 	r.request.Field("grant_type", "refresh_token")
 	r.request.Field("refresh_token", refreshToken)
@@ -123,7 +180,12 @@ func (tes *tokenExchangeService) ExchangeRefreshTokenForAccessToken(refreshToken
 
 // ExchangeAccessTokenForIMSToken ...
 func (tes *tokenExchangeService) ExchangeAccessTokenForIMSToken(accessToken AccessToken, logger *zap.Logger) (*IMSToken, error) {
-	r := tes.newTokenExchangeRequest(logger)
+	return tes.ExchangeAccessTokenForIMSTokenWithContext(context.Background(), accessToken, logger)
+}
+
+// ExchangeAccessTokenForIMSTokenWithContext ...
+func (tes *tokenExchangeService) ExchangeAccessTokenForIMSTokenWithContext(ctx context.Context, accessToken AccessToken, logger *zap.Logger) (*IMSToken, error) {
+	r := tes.newTokenExchangeRequest(ctx, logger)
 
 	r.request.Field("grant_type", "urn:ibm:params:oauth:grant-type:derive")
 	r.request.Field("response_type", "ims_portal")
@@ -134,7 +196,12 @@ func (tes *tokenExchangeService) ExchangeAccessTokenForIMSToken(accessToken Acce
 
 // ExchangeIAMAPIKeyForIMSToken ...
 func (tes *tokenExchangeService) ExchangeIAMAPIKeyForIMSToken(iamAPIKey string, logger *zap.Logger) (*IMSToken, error) {
-	r := tes.newTokenExchangeRequest(logger)
+	return tes.ExchangeIAMAPIKeyForIMSTokenWithContext(context.Background(), iamAPIKey, logger)
+}
+
+// ExchangeIAMAPIKeyForIMSTokenWithContext ...
+func (tes *tokenExchangeService) ExchangeIAMAPIKeyForIMSTokenWithContext(ctx context.Context, iamAPIKey string, logger *zap.Logger) (*IMSToken, error) {
+	r := tes.newTokenExchangeRequest(ctx, logger)
 
 	r.request.Field("grant_type", "urn:ibm:params:oauth:grant-type:apikey")
 	r.request.Field("response_type", "ims_portal")
@@ -145,22 +212,50 @@ func (tes *tokenExchangeService) ExchangeIAMAPIKeyForIMSToken(iamAPIKey string,
 
 // ExchangeIAMAPIKeyForAccessToken ...
 func (tes *tokenExchangeService) ExchangeIAMAPIKeyForAccessToken(iamAPIKey string, logger *zap.Logger) (*AccessToken, error) {
+	return tes.ExchangeIAMAPIKeyForAccessTokenWithContext(context.Background(), iamAPIKey, logger)
+}
+
+// ExchangeIAMAPIKeyForAccessTokenWithContext fetches the token from the secret provider, which takes no context.
+// If ctx is done first, ctx.Err() is returned and the token fetched is discarded.
+func (tes *tokenExchangeService) ExchangeIAMAPIKeyForAccessTokenWithContext(ctx context.Context, iamAPIKey string, logger *zap.Logger) (_ *AccessToken, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx, span := tracing.Start(ctx, "IAM.GetDefaultIAMToken")
+	defer func() { tracing.End(span, err) }()
+
+	type fetchResult struct {
+		token string
+		err   error
+	}
+	fetched := make(chan fetchResult, 1)
 	logger.Info("Fetching using secret provider")
-	token, _, err := tes.secretprovider.GetDefaultIAMToken(false)
-	if err != nil {
-		logger.Error("Error fetching iam token", zap.Error(err))
+	go func() {
+		token, _, err := tes.secretprovider.GetDefaultIAMToken(false)
+		fetched <- fetchResult{token: token, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+		logger.Error("Stopped waiting for iam token", zap.Error(err))
 		return nil, err
+	case result := <-fetched:
+		if err = result.err; err != nil {
+			logger.Error("Error fetching iam token", zap.Error(err))
+			return nil, err
+		}
+		logger.Info("Successfully fetched iam token")
+		return &AccessToken{Token: result.token}, nil
 	}
-	logger.Info("Successfully fetched iam token")
-	return &AccessToken{Token: token}, nil
 }
 
 // exchangeForAccessToken ...
 func (r *tokenExchangeRequest) exchangeForAccessToken() (*AccessToken, error) {
 	var iamResp *tokenExchangeResponse
 	var err error
-	err = r.errorRetrier.ErrorRetry(func() (error, bool) {
-		iamResp, err = r.sendTokenExchangeRequest()
+	err = r.errorRetrier.ErrorRetryWithAttemptContext(r.ctx, func(ctx context.Context) (error, bool) {
+		iamResp, err = r.sendTokenExchangeRequest(ctx)
 		return err, !IsConnectionError(err) // Skip rettry if its not connection error
 	})
 	if err != nil {
@@ -173,8 +268,8 @@ func (r *tokenExchangeRequest) exchangeForAccessToken() (*AccessToken, error) {
 func (r *tokenExchangeRequest) exchangeForIMSToken() (*IMSToken, error) {
 	var iamResp *tokenExchangeResponse
 	var err error
-	err = r.errorRetrier.ErrorRetry(func() (error, bool) {
-		iamResp, err = r.sendTokenExchangeRequest()
+	err = r.errorRetrier.ErrorRetryWithAttemptContext(r.ctx, func(ctx context.Context) (error, bool) {
+		iamResp, err = r.sendTokenExchangeRequest(ctx)
 		return err, !IsConnectionError(err)
 	})
 
@@ -188,21 +283,24 @@ func (r *tokenExchangeRequest) exchangeForIMSToken() (*IMSToken, error) {
 }
 
 // newTokenExchangeRequest ...
-func (tes *tokenExchangeService) newTokenExchangeRequest(logger *zap.Logger) *tokenExchangeRequest {
+func (tes *tokenExchangeService) newTokenExchangeRequest(ctx context.Context, logger *zap.Logger) *tokenExchangeRequest {
 	client := rest.NewClient()
 	client.HTTPClient = tes.httpClient
-	retyrInterval, _ := time.ParseDuration("3s")
+	errorRetrier := util.NewErrorRetrier(tokenExchangeMaxAttempts, tokenExchangeMaxRetryInterval, logger)
+	errorRetrier.Backoff = util.ExponentialBackoff{Initial: tokenExchangeInitialRetryInterval, Max: tokenExchangeMaxRetryInterval}
+	errorRetrier.MaxElapsedTime = tokenExchangeMaxElapsedTime
 	return &tokenExchangeRequest{
+		ctx:          ctx,
 		tes:          tes,
 		request:      rest.PostRequest(fmt.Sprintf("%s/oidc/token", tes.authConfig.IamURL)),
 		client:       client,
 		logger:       logger,
-		errorRetrier: util.NewErrorRetrier(40, retyrInterval, logger),
+		errorRetrier: errorRetrier,
 	}
 }
 
 // sendTokenExchangeRequest ...
-func (r *tokenExchangeRequest) sendTokenExchangeRequest() (*tokenExchangeResponse, error) {
+func (r *tokenExchangeRequest) sendTokenExchangeRequest(ctx context.Context) (_ *tokenExchangeResponse, err error) {
 	// Set headers
 	basicAuth := fmt.Sprintf("%s:%s", r.tes.authConfig.IamClientID, r.tes.authConfig.IamClientSecret)
 	r.request.Set("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(basicAuth))))
@@ -220,9 +318,16 @@ func (r *tokenExchangeRequest) sendTokenExchangeRequest() (*tokenExchangeRespons
 		} `json:"requirements"`
 	}{}
 
+	ctx, span := tracing.Start(ctx, "IAM.sendTokenExchangeRequest", attribute.String("http.method", http.MethodPost))
+	defer func() { tracing.End(span, err) }()
+	r.injectTraceHeaders(ctx)
+
 	r.logger.Info("Sending IAM token exchange request")
 	r.logger.Info("Request is:=================", zap.Reflect("Request", r.request))
 	resp, err := r.client.Do(r.request, &successV, &errorV)
+	if resp != nil {
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	}
 
 	if err != nil {
 		r.logger.Error("IAM token exchange request failed", zap.Reflect("Response", resp), zap.Error(err))
@@ -254,9 +359,14 @@ func (r *tokenExchangeRequest) sendTokenExchangeRequest() (*tokenExchangeRespons
 			"IAM token exchange request failed: "+errorV.ErrorMessage,
 			errors.New(errorV.ErrorDetails+" "+errorV.Requirements.Code+": "+errorV.Requirements.Error))
 
+		var perr provider.Error
 		if errorV.Requirements.Code == "SoftLayer_Exception_User_Customer_AccountLocked" {
 			err = util.NewError("ErrorProviderAccountTemporarilyLocked",
 				"Infrastructure account is temporarily locked", err)
+		} else if resp.StatusCode == http.StatusUnauthorized && errors.As(err, &perr) {
+			// IAM rejected the credential, rather than failing, so caches evict the tokens exchanged for it.
+			// The reason code stays ErrorFailedTokenExchange, and Fault.Wrapped is unchanged.
+			err = perr.WithWrapped(util.NewError(reasoncode.ErrorUnauthorised, "IAM rejected the credential"))
 		}
 
 		return nil, err
@@ -270,6 +380,18 @@ func (r *tokenExchangeRequest) sendTokenExchangeRequest() (*tokenExchangeRespons
 			"Unexpected IAM token exchange response")
 }
 
+// injectTraceHeaders propagates the span context and request ID in ctx to IAM in the request headers
+func (r *tokenExchangeRequest) injectTraceHeaders(ctx context.Context) {
+	header := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	for key := range header {
+		r.request.Set(key, header.Get(key))
+	}
+	if requestID := tracing.RequestID(ctx); requestID != "" {
+		r.request.Set("X-Request-ID", requestID)
+	}
+}
+
 // IsConnectionError ...
 func IsConnectionError(err error) bool {
 	if err != nil {
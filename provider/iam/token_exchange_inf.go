@@ -18,6 +18,8 @@
 package iam
 
 import (
+	"context"
+
 	"go.uber.org/zap"
 )
 
@@ -51,3 +53,20 @@ type TokenExchangeService interface {
 	// GetIAMAccountIDFromAccessToken ...
 	GetIAMAccountIDFromAccessToken(accessToken AccessToken, logger *zap.Logger) (string, error)
 }
+
+// ContextTokenExchangeService is implemented by a TokenExchangeService whose IAM requests can be made with
+// a context.Context: they are traced as children of the span in ctx, and send the request ID stored under
+// provider.RequestID in ctx
+type ContextTokenExchangeService interface {
+	// ExchangeRefreshTokenForAccessTokenWithContext ...
+	ExchangeRefreshTokenForAccessTokenWithContext(ctx context.Context, refreshToken string, logger *zap.Logger) (*AccessToken, error)
+
+	// ExchangeAccessTokenForIMSTokenWithContext ...
+	ExchangeAccessTokenForIMSTokenWithContext(ctx context.Context, accessToken AccessToken, logger *zap.Logger) (*IMSToken, error)
+
+	// ExchangeIAMAPIKeyForIMSTokenWithContext ...
+	ExchangeIAMAPIKeyForIMSTokenWithContext(ctx context.Context, iamAPIKey string, logger *zap.Logger) (*IMSToken, error)
+
+	// ExchangeIAMAPIKeyForAccessTokenWithContext ...
+	ExchangeIAMAPIKeyForAccessTokenWithContext(ctx context.Context, iamAPIKey string, logger *zap.Logger) (*AccessToken, error)
+}
@@ -18,6 +18,7 @@
 package iam
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -27,12 +28,21 @@ import (
 	"testing"
 	"time"
 
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/tracing"
 	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
 	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
 
 	"github.com/IBM/ibmcloud-volume-interface/config"
 	"github.com/IBM/secret-utils-lib/pkg/k8s_utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -128,6 +138,8 @@ func Test_ExchangeRefreshTokenForAccessToken_FailedDuringRequest(t *testing.T) {
 	if assert.NotNil(t, err) {
 		assert.Equal(t, "IAM token exchange request failed: did not work", err.Error())
 		assert.Equal(t, reasoncode.ReasonCode("ErrorFailedTokenExchange"), util.ErrorReasonCode(err))
+		// The refresh token was rejected, so the tokens cached for it are evicted
+		assert.True(t, errors.Is(err, reasoncode.ErrorUnauthorised))
 	}
 }
 
@@ -387,6 +399,8 @@ func Test_ExchangeAccessTokenForIMSToken_FailedAccountLocked(t *testing.T) {
 		assert.Equal(t, "Infrastructure account is temporarily locked", err.Error())
 		assert.Equal(t, reasoncode.ReasonCode("ErrorProviderAccountTemporarilyLocked"), util.ErrorReasonCode(err))
 		assert.Equal(t, []string{"IAM token exchange request failed: OpenID Connect exception", "Failed external authentication. SoftLayer_Exception_User_Customer_AccountLocked: Account has been locked for 30 minutes"}, util.ErrorDeepUnwrapString(err))
+		// A locked account is not fixed by a new token, so nothing is evicted
+		assert.False(t, errors.Is(err, reasoncode.ErrorUnauthorised))
 	}
 }
 
@@ -494,6 +508,33 @@ func Test_ExchangeIAMAPIKeyForAccessToken(t *testing.T) {
 	}
 }
 
+// blockingSecretProvider is a FakeSecretProvider whose GetDefaultIAMToken waits for release to be closed
+type blockingSecretProvider struct {
+	FakeSecretProvider
+	release chan struct{}
+}
+
+func (bs *blockingSecretProvider) GetDefaultIAMToken(freshTokenRequired bool, reasonForCall ...string) (string, uint64, error) {
+	<-bs.release
+	return bs.FakeSecretProvider.GetDefaultIAMToken(freshTokenRequired, reasonForCall...)
+}
+
+func Test_ExchangeIAMAPIKeyForAccessTokenWithContext(t *testing.T) {
+	secretProvider := &blockingSecretProvider{release: make(chan struct{})}
+	defer close(secretProvider.release)
+	tes := &tokenExchangeService{authConfig: &AuthConfiguration{}, secretprovider: secretProvider}
+	c := NewCachingTokenExchangeService(tes, TokenCacheConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	accessToken, err := c.ExchangeIAMAPIKeyForAccessTokenWithContext(ctx, "apikey1", logger)
+	assert.Nil(t, accessToken)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	_, err = tes.ExchangeIAMAPIKeyForAccessTokenWithContext(ctx, "apikey1", logger)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
 func Test_NewTokenExchangeService(t *testing.T) {
 	authConfig := &AuthConfiguration{
 		IamURL: server.URL,
@@ -512,6 +553,27 @@ func Test_NewTokenExchangeService(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestNewAuthConfiguration(t *testing.T) {
+	assert.Equal(t, &AuthConfiguration{}, NewAuthConfiguration(nil))
+
+	conf, err := config.ParseConfig(logger, `
+[bluemix]
+  iam_url = "https://iam.test"
+  iam_client_id = "bx"
+  iam_client_secret = "bx"
+  iam_token_issuer = "https://iam.test/oidc"
+  skip_token_verification = true
+`)
+	require.NoError(t, err)
+	assert.Equal(t, &AuthConfiguration{
+		IamURL:                "https://iam.test",
+		IamClientID:           "bx",
+		IamClientSecret:       "bx",
+		IamTokenIssuer:        "https://iam.test/oidc",
+		SkipTokenVerification: true,
+	}, NewAuthConfiguration(conf.Bluemix))
+}
+
 const (
 	// fakeEndpoint ...
 	fakeEndpoint = "https://fakehost.com"
@@ -561,3 +623,49 @@ func (fs *FakeSecretProvider) GetPrivateContainerAPIRoute(readConfig bool) (stri
 func (fs *FakeSecretProvider) GetResourceGroupID() string {
 	return "resource-group-id"
 }
+
+func Test_SendTokenExchangeRequest_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+	httpSetup()
+
+	var traceparent, requestID string
+	mux.HandleFunc("/oidc/token",
+		func(w http.ResponseWriter, r *http.Request) {
+			traceparent = r.Header.Get("traceparent")
+			requestID = r.Header.Get("X-Request-ID")
+			w.WriteHeader(200)
+			fmt.Fprint(w, `{"access_token": "at_success"}`)
+		},
+	)
+
+	tes := new(tokenExchangeService)
+	tes.httpClient, _ = config.GeneralCAHttpClient()
+	tes.authConfig = &AuthConfiguration{IamURL: server.URL, IamClientID: "test", IamClientSecret: "secret"}
+	ctx, parent := tracing.Start(context.WithValue(context.Background(), provider.RequestID, "request-1"), "parent")
+	cache := NewCachingTokenExchangeService(tes, TokenCacheConfig{DefaultTTL: DefaultTokenTTL})
+	_, err := cache.ExchangeRefreshTokenForAccessTokenWithContext(ctx, "testrefreshtoken", logger)
+	parent.End()
+	assert.NoError(t, err)
+	assert.Equal(t, "request-1", requestID)
+
+	// The request is traced in the trace of the caller, as a child of its retrier attempt
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 3) {
+		send, attempt := spans[0], spans[1]
+		assert.Equal(t, "IAM.sendTokenExchangeRequest", send.Name())
+		assert.Contains(t, send.Attributes(), attribute.Int("http.status_code", 200))
+		assert.Contains(t, send.Attributes(), tracing.RequestIDKey.String("request-1"))
+		assert.Equal(t, "ErrorRetrier.attempt", attempt.Name())
+		assert.Equal(t, attempt.SpanContext().SpanID(), send.Parent().SpanID())
+		assert.Equal(t, parent.SpanContext().SpanID(), attempt.Parent().SpanID())
+		assert.Equal(t, parent.SpanContext().TraceID(), send.SpanContext().TraceID())
+		assert.Contains(t, traceparent, send.SpanContext().TraceID().String())
+		assert.Contains(t, traceparent, send.SpanContext().SpanID().String())
+	}
+}
@@ -19,12 +19,23 @@ package iam
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
 	"go.uber.org/zap"
 )
 
+// tokenClockSkew is the clock skew allowed when checking the exp and nbf claims of access tokens
+const tokenClockSkew = time.Minute
+
+// DefaultIamTokenIssuer is the iss claim of the access tokens issued by the public and private IAM endpoints
+const DefaultIamTokenIssuer = "https://iam.cloud.ibm.com/identity"
+
 type accessTokenClaims struct {
 	jwt.StandardClaims
 
@@ -34,25 +45,71 @@ type accessTokenClaims struct {
 }
 
 func (r *tokenExchangeService) GetIAMAccountIDFromAccessToken(accessToken AccessToken, logger *zap.Logger) (accountID string, err error) {
-	// TODO - TEMPORARY CODE - VERIFY SIGNATURE HERE
-	token, _, err := new(jwt.Parser).ParseUnverified(accessToken.Token, &accessTokenClaims{})
+	claims, err := r.parseAccessToken(accessToken, logger)
 	if err != nil {
-		return
+		logger.Error("Access token invalid", zap.Error(err))
+		return "", util.NewError(reasoncode.ErrorUnauthorised, "access token invalid", err)
 	}
-	token.Valid = true
-	// TODO - TEMPORARY CODE - DONT OVERRIDE VERIFICATION
 
-	claims, haveClaims := token.Claims.(*accessTokenClaims)
+	accountID = claims.Account.Bss
+	logger.Debug("GetIAMAccountIDFromAccessToken", zap.Reflect("claims.Account.Bss", claims.Account.Bss))
 
-	logger.Debug("Access token parsed", zap.Bool("haveClaims", haveClaims), zap.Bool("valid", token.Valid))
+	return
+}
 
-	if !token.Valid || !haveClaims {
-		err = errors.New("access token invalid")
-		return
+// parseAccessToken verifies the signature of the access token against the IAM signing keys,
+// and its exp, nbf and iss claims, unless SkipTokenVerification is set
+func (r *tokenExchangeService) parseAccessToken(accessToken AccessToken, logger *zap.Logger) (*accessTokenClaims, error) {
+	claims := &accessTokenClaims{}
+	if r.authConfig != nil && r.authConfig.SkipTokenVerification {
+		logger.Warn("Access token verification is disabled")
+		if _, _, err := new(jwt.Parser).ParseUnverified(accessToken.Token, claims); err != nil {
+			return nil, err
+		}
+		return claims, nil
+	}
+	if r.authConfig == nil || r.authConfig.IamURL == "" {
+		return nil, errors.New("IAM URL is required to verify access tokens")
 	}
 
-	accountID = claims.Account.Bss
-	logger.Debug("GetIAMAccountIDFromAccessToken", zap.Reflect("claims.Account.Bss", claims.Account.Bss))
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}), jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(accessToken.Token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return r.signingKeys().key(kid, logger)
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Access token parsed", zap.Bool("valid", token.Valid))
 
-	return
+	now := time.Now()
+	if claims.ExpiresAt == 0 {
+		return nil, errors.New("access token has no expiry")
+	}
+	if !claims.VerifyExpiresAt(now.Add(-tokenClockSkew).Unix(), true) {
+		return nil, fmt.Errorf("access token expired at %s", time.Unix(claims.ExpiresAt, 0).UTC().Format(time.RFC3339))
+	}
+	if !claims.VerifyNotBefore(now.Add(tokenClockSkew).Unix(), false) {
+		return nil, fmt.Errorf("access token not valid before %s", time.Unix(claims.NotBefore, 0).UTC().Format(time.RFC3339))
+	}
+	if issuer := r.tokenIssuer(); claims.Issuer != issuer {
+		return nil, fmt.Errorf("access token issuer '%s' is not '%s'", claims.Issuer, issuer)
+	}
+	return claims, nil
+}
+
+// signingKeys returns the key set of the IAM endpoint, creating it on first use
+func (r *tokenExchangeService) signingKeys() *jwksKeySet {
+	r.keySetOnce.Do(func() {
+		r.keySet = newJWKSKeySet(strings.TrimRight(r.authConfig.IamURL, "/")+jwksPath, r.httpClient)
+	})
+	return r.keySet
+}
+
+// tokenIssuer returns the expected issuer of access tokens
+func (r *tokenExchangeService) tokenIssuer() string {
+	if r.authConfig.IamTokenIssuer != "" {
+		return r.authConfig.IamTokenIssuer
+	}
+	return DefaultIamTokenIssuer
 }
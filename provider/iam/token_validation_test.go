@@ -18,13 +18,24 @@
 package iam
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"math/big"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 
 	"github.com/IBM/ibmcloud-volume-interface/config"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -59,9 +70,10 @@ func Test_GetIAMAccountIDFromAccessToken(t *testing.T) {
 			httpSetup()
 
 			authConfig := &AuthConfiguration{
-				IamURL:          server.URL,
-				IamClientID:     "test",
-				IamClientSecret: "secret",
+				IamURL:                server.URL,
+				IamClientID:           "test",
+				IamClientSecret:       "secret",
+				SkipTokenVerification: true,
 			}
 
 			tes := new(tokenExchangeService)
@@ -76,3 +88,217 @@ func Test_GetIAMAccountIDFromAccessToken(t *testing.T) {
 		})
 	}
 }
+
+// jwksServer serves the public keys of the signing keys from httptest as a JSON Web Key Set
+// If release is set, responses wait for it to be closed.
+type jwksServer struct {
+	keys        map[string]*rsa.PrivateKey
+	fetches     int32
+	unavailable bool
+	release     chan struct{}
+}
+
+func (js *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&js.fetches, 1)
+	if js.release != nil {
+		<-js.release
+	}
+	if js.unavailable {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for kid, key := range js.keys {
+		jwks.Keys = append(jwks.Keys, jsonWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(jwks)
+}
+
+func signedAccessToken(t *testing.T, kid string, key *rsa.PrivateKey, claims accessTokenClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func Test_GetIAMAccountIDFromAccessToken_Verified(t *testing.T) {
+	logger, _ := zap.NewDevelopment(zap.AddCaller())
+	httpSetup()
+
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks := &jwksServer{keys: map[string]*rsa.PrivateKey{"key-1": key1}}
+	mux.Handle("/identity/keys", jwks)
+
+	tes := new(tokenExchangeService)
+	tes.httpClient, _ = config.GeneralCAHttpClient()
+	tes.authConfig = &AuthConfiguration{IamURL: server.URL}
+
+	validClaims := func() accessTokenClaims {
+		claims := accessTokenClaims{StandardClaims: jwt.StandardClaims{
+			Issuer:    DefaultIamTokenIssuer,
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+			NotBefore: time.Now().Add(-time.Minute).Unix(),
+		}}
+		claims.Account.Bss = "12345"
+		return claims
+	}
+
+	accountID, err := tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: signedAccessToken(t, "key-1", key1, validClaims())}, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", accountID)
+
+	expired := validClaims()
+	expired.ExpiresAt = time.Now().Add(-time.Hour).Unix()
+	noExpiry := validClaims()
+	noExpiry.ExpiresAt = 0
+	notYetValid := validClaims()
+	notYetValid.NotBefore = time.Now().Add(time.Hour).Unix()
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "https://attacker.example.com/identity"
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("aabbccdd"))
+
+	for name, token := range map[string]string{
+		"expired":        signedAccessToken(t, "key-1", key1, expired),
+		"no expiry":      signedAccessToken(t, "key-1", key1, noExpiry),
+		"not yet valid":  signedAccessToken(t, "key-1", key1, notYetValid),
+		"wrong issuer":   signedAccessToken(t, "key-1", key1, wrongIssuer),
+		"bad signature":  signedAccessToken(t, "key-1", otherKey, validClaims()),
+		"unknown kid":    signedAccessToken(t, "key-unknown", otherKey, validClaims()),
+		"hmac signature": hmacToken,
+		"malformed":      "invalid",
+	} {
+		t.Run(name, func(t *testing.T) {
+			accountID, err := tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: token}, logger)
+			assert.Empty(t, accountID)
+			assert.True(t, errors.Is(err, reasoncode.ErrorUnauthorised))
+		})
+	}
+}
+
+func Test_GetIAMAccountIDFromAccessToken_KeyRotation(t *testing.T) {
+	logger, _ := zap.NewDevelopment(zap.AddCaller())
+	httpSetup()
+
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks := &jwksServer{keys: map[string]*rsa.PrivateKey{"key-1": key1}}
+	mux.Handle("/identity/keys", jwks)
+
+	tes := new(tokenExchangeService)
+	tes.httpClient, _ = config.GeneralCAHttpClient()
+	tes.authConfig = &AuthConfiguration{IamURL: server.URL, IamTokenIssuer: "https://iam.test/identity"}
+	claims := accessTokenClaims{StandardClaims: jwt.StandardClaims{Issuer: "https://iam.test/identity", ExpiresAt: time.Now().Add(time.Hour).Unix()}}
+	claims.Account.Bss = "12345"
+
+	// Keys are cached
+	for i := 0; i < 3; i++ {
+		_, err = tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: signedAccessToken(t, "key-1", key1, claims)}, logger)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&jwks.fetches))
+
+	// A token signed with a rotated key fetches the key set again, once the minimum refresh interval has passed
+	jwks.keys["key-2"] = key2
+	_, err = tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: signedAccessToken(t, "key-2", key2, claims)}, logger)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&jwks.fetches))
+
+	tes.keySet.fetchedAt = time.Now().Add(-jwksMinRefreshInterval)
+	accountID, err := tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: signedAccessToken(t, "key-2", key2, claims)}, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", accountID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&jwks.fetches))
+}
+
+func Test_GetIAMAccountIDFromAccessToken_KeySetUnavailable(t *testing.T) {
+	logger, _ := zap.NewDevelopment(zap.AddCaller())
+	httpSetup()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks := &jwksServer{keys: map[string]*rsa.PrivateKey{"key-1": key}, unavailable: true}
+	mux.Handle("/identity/keys", jwks)
+
+	tes := new(tokenExchangeService)
+	tes.httpClient, _ = config.GeneralCAHttpClient()
+	tes.authConfig = &AuthConfiguration{IamURL: server.URL, IamTokenIssuer: "https://iam.test/identity"}
+	claims := accessTokenClaims{StandardClaims: jwt.StandardClaims{Issuer: "https://iam.test/identity", ExpiresAt: time.Now().Add(time.Hour).Unix()}}
+	claims.Account.Bss = "12345"
+	token := signedAccessToken(t, "key-1", key, claims)
+
+	// A failed fetch is not repeated until the backoff has passed
+	for i := 0; i < 3; i++ {
+		_, err = tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: token}, logger)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&jwks.fetches))
+
+	jwks.unavailable = false
+	tes.keySet.failedAt = time.Now().Add(-jwksFetchFailureBackoff)
+	_, err = tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: token}, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&jwks.fetches))
+
+	// Stale keys are used while the key set cannot be fetched again
+	jwks.unavailable = true
+	tes.keySet.fetchedAt = time.Now().Add(-jwksMaxAge)
+	accountID, err := tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: token}, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", accountID)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&jwks.fetches))
+}
+
+func Test_GetIAMAccountIDFromAccessToken_ConcurrentFetch(t *testing.T) {
+	logger, _ := zap.NewDevelopment(zap.AddCaller())
+	httpSetup()
+
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks := &jwksServer{keys: map[string]*rsa.PrivateKey{"key-1": key1, "key-2": key2}, release: make(chan struct{})}
+	mux.Handle("/identity/keys", jwks)
+
+	tes := new(tokenExchangeService)
+	tes.httpClient, _ = config.GeneralCAHttpClient()
+	tes.authConfig = &AuthConfiguration{IamURL: server.URL}
+	claims := accessTokenClaims{StandardClaims: jwt.StandardClaims{Issuer: DefaultIamTokenIssuer, ExpiresAt: time.Now().Add(time.Hour).Unix()}}
+	claims.Account.Bss = "12345"
+	keySet := tes.signingKeys()
+	keySet.keys = map[string]*rsa.PublicKey{"key-1": &key1.PublicKey}
+	keySet.fetchedAt = time.Now().Add(-jwksMinRefreshInterval)
+
+	// Validations of an unknown kid share one fetch, while cached keys are used without waiting for it
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: signedAccessToken(t, "key-2", key2, claims)}, logger)
+			assert.NoError(t, err)
+		}()
+	}
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&jwks.fetches) == 1 }, time.Second, time.Millisecond)
+
+	accountID, err := tes.GetIAMAccountIDFromAccessToken(AccessToken{Token: signedAccessToken(t, "key-1", key1, claims)}, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", accountID)
+
+	close(jwks.release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&jwks.fetches))
+}
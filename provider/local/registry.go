@@ -0,0 +1,165 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package local ...
+package local
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/IBM/ibmcloud-volume-interface/config"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"go.uber.org/zap"
+)
+
+// ProviderFactory builds a Provider from the parsed library configuration
+type ProviderFactory func(conf *config.Config, logger *zap.Logger) (Provider, error)
+
+// Registry holds the ProviderFactory registered for each VolumeProvider name.
+// It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[provider.VolumeProvider]ProviderFactory
+}
+
+// DefaultRegistry is the registry used by the package level Register and NewProviders functions
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: map[provider.VolumeProvider]ProviderFactory{},
+	}
+}
+
+// Register registers the factory for the named provider.
+// Registering an empty name, a nil factory or the same name twice is an error.
+func (r *Registry) Register(name provider.VolumeProvider, factory ProviderFactory) error {
+	if name == "" || factory == nil {
+		return util.NewError(reasoncode.ErrorRequiredFieldMissing, "Provider name and factory are required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.factories[name]; exists {
+		return util.NewErrorWithProperties(reasoncode.ErrorBadRequest, "Provider is already registered",
+			map[string]string{"provider": string(name)})
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// Factory returns the factory registered for the named provider
+func (r *Registry) Factory(name provider.VolumeProvider) (ProviderFactory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, util.NewErrorWithProperties(reasoncode.ErrorUnknownProvider, "Provider unknown",
+			map[string]string{"provider": string(name)})
+	}
+	return factory, nil
+}
+
+// Names returns the registered provider names in sorted order
+func (r *Registry) Names() []provider.VolumeProvider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]provider.VolumeProvider, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// NewProvider builds the named provider from the configuration. A nil logger logs nothing.
+func (r *Registry) NewProvider(name provider.VolumeProvider, conf *config.Config, logger *zap.Logger) (Provider, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	factory, err := r.Factory(name)
+	if err != nil {
+		return nil, err
+	}
+	return factory(conf, logger)
+}
+
+// NewProviders builds every provider enabled in the configuration, keyed by its configured name.
+// It fails if an enabled provider has no registered factory or its factory fails. A nil logger logs nothing.
+func (r *Registry) NewProviders(conf *config.Config, logger *zap.Logger) (map[provider.VolumeProvider]Provider, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	providers := map[provider.VolumeProvider]Provider{}
+	for _, name := range EnabledProviders(conf) {
+		logger.Info("Building provider", zap.String("provider", string(name)))
+		prov, err := r.NewProvider(name, conf, logger)
+		if err != nil {
+			logger.Error("Failed to build provider", zap.String("provider", string(name)), ZapError(err))
+			return nil, err
+		}
+		providers[name] = prov
+	}
+	return providers, nil
+}
+
+// Register registers the factory for the named provider in the DefaultRegistry
+func Register(name provider.VolumeProvider, factory ProviderFactory) error {
+	return DefaultRegistry.Register(name, factory)
+}
+
+// NewProviders builds every provider enabled in the configuration using the DefaultRegistry
+func NewProviders(conf *config.Config, logger *zap.Logger) (map[provider.VolumeProvider]Provider, error) {
+	return DefaultRegistry.NewProviders(conf, logger)
+}
+
+// EnabledProviders returns the names of the providers enabled in the configuration:
+// the VPC block provider if VPC is enabled, the IKS block (and file, if named) providers if IKS is
+// enabled, and the Softlayer block and file providers if they are enabled.
+func EnabledProviders(conf *config.Config) []provider.VolumeProvider {
+	var names []provider.VolumeProvider
+	if conf == nil {
+		return names
+	}
+	add := func(name string) {
+		if name != "" {
+			names = append(names, provider.VolumeProvider(name))
+		}
+	}
+	if conf.VPC != nil && conf.VPC.Enabled {
+		add(conf.VPC.VPCBlockProviderName)
+	}
+	if conf.IKS != nil && conf.IKS.Enabled {
+		add(conf.IKS.IKSBlockProviderName)
+		add(conf.IKS.IKSFileProviderName)
+	}
+	if conf.Softlayer != nil {
+		if conf.Softlayer.SoftlayerBlockEnabled {
+			add(conf.Softlayer.SoftlayerBlockProviderName)
+		}
+		if conf.Softlayer.SoftlayerFileEnabled {
+			add(conf.Softlayer.SoftlayerFileProviderName)
+		}
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package local_test ...
+package local_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/config"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/IBM/ibmcloud-volume-interface/provider/local"
+	"github.com/IBM/ibmcloud-volume-interface/provider/local/fakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func fakeFactory(conf *config.Config, logger *zap.Logger) (local.Provider, error) {
+	return &fakes.Provider{}, nil
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		VPC:       &config.VPCProviderConfig{Enabled: true, VPCBlockProviderName: "vpc"},
+		IKS:       &config.IKSConfig{Enabled: true, IKSBlockProviderName: "iks-vpc-classic"},
+		Softlayer: &config.SoftlayerConfig{SoftlayerFileEnabled: true, SoftlayerFileProviderName: "SOFTLAYER-FILE"},
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	registry := local.NewRegistry()
+	require.NoError(t, registry.Register("vpc", fakeFactory))
+	require.NoError(t, registry.Register("iks-vpc-classic", fakeFactory))
+	assert.Equal(t, []provider.VolumeProvider{"iks-vpc-classic", "vpc"}, registry.Names())
+
+	err := registry.Register("vpc", fakeFactory)
+	assert.Equal(t, reasoncode.ErrorBadRequest, util.ErrorReasonCode(err))
+	err = registry.Register("", fakeFactory)
+	assert.Equal(t, reasoncode.ErrorRequiredFieldMissing, util.ErrorReasonCode(err))
+	err = registry.Register("file", nil)
+	assert.Equal(t, reasoncode.ErrorRequiredFieldMissing, util.ErrorReasonCode(err))
+}
+
+func TestRegistryUnknownProvider(t *testing.T) {
+	registry := local.NewRegistry()
+	_, err := registry.Factory("unknown")
+	assert.Equal(t, reasoncode.ErrorUnknownProvider, util.ErrorReasonCode(err))
+
+	prov, err := registry.NewProvider("unknown", testConfig(), zap.NewNop())
+	assert.Nil(t, prov)
+	assert.Equal(t, reasoncode.ErrorUnknownProvider, util.ErrorReasonCode(err))
+}
+
+func TestEnabledProviders(t *testing.T) {
+	assert.Empty(t, local.EnabledProviders(nil))
+	assert.Empty(t, local.EnabledProviders(&config.Config{}))
+	assert.Equal(t, []provider.VolumeProvider{"vpc", "iks-vpc-classic", "SOFTLAYER-FILE"}, local.EnabledProviders(testConfig()))
+
+	conf := testConfig()
+	conf.VPC.Enabled = false
+	conf.IKS.IKSFileProviderName = "iks-vpc-file"
+	assert.Equal(t, []provider.VolumeProvider{"iks-vpc-classic", "iks-vpc-file", "SOFTLAYER-FILE"}, local.EnabledProviders(conf))
+}
+
+func TestRegistryNewProviders(t *testing.T) {
+	logger := zap.NewNop()
+	registry := local.NewRegistry()
+	require.NoError(t, registry.Register("vpc", fakeFactory))
+	require.NoError(t, registry.Register("iks-vpc-classic", fakeFactory))
+
+	// SOFTLAYER-FILE is enabled but not registered
+	providers, err := registry.NewProviders(testConfig(), logger)
+	assert.Nil(t, providers)
+	assert.Equal(t, reasoncode.ErrorUnknownProvider, util.ErrorReasonCode(err))
+
+	require.NoError(t, registry.Register("SOFTLAYER-FILE", func(conf *config.Config, logger *zap.Logger) (local.Provider, error) {
+		return nil, errors.New("softlayer unavailable")
+	}))
+	_, err = registry.NewProviders(testConfig(), logger)
+	assert.EqualError(t, err, "softlayer unavailable")
+
+	conf := testConfig()
+	conf.Softlayer.SoftlayerFileEnabled = false
+	providers, err = registry.NewProviders(conf, logger)
+	require.NoError(t, err)
+	assert.Len(t, providers, 2)
+	assert.Contains(t, providers, provider.VolumeProvider("vpc"))
+	assert.Contains(t, providers, provider.VolumeProvider("iks-vpc-classic"))
+
+	// A nil logger logs nothing, and the factories get a logger they can use
+	registry = local.NewRegistry()
+	require.NoError(t, registry.Register("vpc", func(conf *config.Config, logger *zap.Logger) (local.Provider, error) {
+		require.NotNil(t, logger)
+		return &fakes.Provider{}, nil
+	}))
+	conf.IKS.Enabled = false
+	providers, err = registry.NewProviders(conf, nil)
+	require.NoError(t, err)
+	assert.Len(t, providers, 1)
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	require.NoError(t, local.Register("default-test", fakeFactory))
+	assert.Contains(t, local.DefaultRegistry.Names(), provider.VolumeProvider("default-test"))
+
+	providers, err := local.NewProviders(&config.Config{}, zap.NewNop())
+	require.NoError(t, err)
+	assert.Empty(t, providers)
+}
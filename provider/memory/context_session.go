@@ -0,0 +1,294 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+)
+
+// contextSession is the native provider.ContextSession of an in-memory Session.
+// Calls fail with ctx.Err() once ctx is done, and the Wait* calls stop polling when ctx is done.
+type contextSession struct {
+	sess *Session
+}
+
+var _ provider.ContextSession = &contextSession{}
+var _ provider.ContextSessionProvider = &Session{}
+
+// ContextSession returns the context aware view of the session
+func (s *Session) ContextSession() provider.ContextSession {
+	return &contextSession{sess: s}
+}
+
+// ProviderName returns the provider name
+func (c *contextSession) ProviderName() provider.VolumeProvider {
+	return c.sess.ProviderName()
+}
+
+// Type returns the underlying volume type
+func (c *contextSession) Type() provider.VolumeType {
+	return c.sess.Type()
+}
+
+// GetProviderDisplayName returns the provider name
+func (c *contextSession) GetProviderDisplayName() provider.VolumeProvider {
+	return c.sess.GetProviderDisplayName()
+}
+
+// Close is called when the ContextSession is nolonger required
+func (c *contextSession) Close() {
+	c.sess.Close()
+}
+
+// GetVolumeProfileByName ...
+func (c *contextSession) GetVolumeProfileByName(ctx context.Context, name string) (*provider.Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.GetVolumeProfileByName(name)
+}
+
+// ListVolumeProfiles ...
+func (c *contextSession) ListVolumeProfiles(ctx context.Context, limit int, start string) (*provider.ProfileList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.ListVolumeProfiles(limit, start)
+}
+
+// CreateVolume ...
+func (c *contextSession) CreateVolume(ctx context.Context, volumeRequest provider.Volume) (*provider.Volume, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.CreateVolume(volumeRequest)
+}
+
+// CreateVolumeFromSnapshot ...
+func (c *contextSession) CreateVolumeFromSnapshot(ctx context.Context, snapshot provider.Snapshot, tags map[string]string) (*provider.Volume, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.CreateVolumeFromSnapshot(snapshot, tags)
+}
+
+// UpdateVolume ...
+func (c *contextSession) UpdateVolume(ctx context.Context, volume provider.Volume) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.sess.UpdateVolume(volume)
+}
+
+// DeleteVolume ...
+func (c *contextSession) DeleteVolume(ctx context.Context, volume *provider.Volume) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.sess.DeleteVolume(volume)
+}
+
+// GetVolume ...
+func (c *contextSession) GetVolume(ctx context.Context, id string) (*provider.Volume, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.GetVolume(id)
+}
+
+// GetVolumeByName ...
+func (c *contextSession) GetVolumeByName(ctx context.Context, name string) (*provider.Volume, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.GetVolumeByName(name)
+}
+
+// ListVolumes ...
+func (c *contextSession) ListVolumes(ctx context.Context, limit int, start string, tags map[string]string) (*provider.VolumeList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.ListVolumes(limit, start, tags)
+}
+
+// GetVolumeByRequestID ...
+func (c *contextSession) GetVolumeByRequestID(ctx context.Context, requestID string) (*provider.Volume, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.GetVolumeByRequestID(requestID)
+}
+
+// AuthorizeVolume ...
+func (c *contextSession) AuthorizeVolume(ctx context.Context, volumeAuthorization provider.VolumeAuthorization) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.sess.AuthorizeVolume(volumeAuthorization)
+}
+
+// ExpandVolume ...
+func (c *contextSession) ExpandVolume(ctx context.Context, expandVolumeRequest provider.ExpandVolumeRequest) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+	return c.sess.ExpandVolume(expandVolumeRequest)
+}
+
+// AttachVolume ...
+func (c *contextSession) AttachVolume(ctx context.Context, attachRequest provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.AttachVolume(attachRequest)
+}
+
+// DetachVolume ...
+func (c *contextSession) DetachVolume(ctx context.Context, detachRequest provider.VolumeAttachmentRequest) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.DetachVolume(detachRequest)
+}
+
+// GetVolumeAttachment ...
+func (c *contextSession) GetVolumeAttachment(ctx context.Context, attachRequest provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.GetVolumeAttachment(attachRequest)
+}
+
+// CreateSnapshot ...
+func (c *contextSession) CreateSnapshot(ctx context.Context, sourceVolumeID string, snapshotParameters provider.SnapshotParameters) (*provider.Snapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.CreateSnapshot(sourceVolumeID, snapshotParameters)
+}
+
+// DeleteSnapshot ...
+func (c *contextSession) DeleteSnapshot(ctx context.Context, snapshot *provider.Snapshot) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.sess.DeleteSnapshot(snapshot)
+}
+
+// GetSnapshot ...
+func (c *contextSession) GetSnapshot(ctx context.Context, snapshotID string, sourceVolumeID ...string) (*provider.Snapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.GetSnapshot(snapshotID, sourceVolumeID...)
+}
+
+// GetSnapshotByName ...
+func (c *contextSession) GetSnapshotByName(ctx context.Context, snapshotName string, sourceVolumeID ...string) (*provider.Snapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.GetSnapshotByName(snapshotName, sourceVolumeID...)
+}
+
+// ListSnapshots ...
+func (c *contextSession) ListSnapshots(ctx context.Context, limit int, start string, tags map[string]string) (*provider.SnapshotList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.ListSnapshots(limit, start, tags)
+}
+
+// CreateVolumeAccessPoint ...
+func (c *contextSession) CreateVolumeAccessPoint(ctx context.Context, accessPointRequest provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.CreateVolumeAccessPoint(accessPointRequest)
+}
+
+// DeleteVolumeAccessPoint ...
+func (c *contextSession) DeleteVolumeAccessPoint(ctx context.Context, deleteAccessPointRequest provider.VolumeAccessPointRequest) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.DeleteVolumeAccessPoint(deleteAccessPointRequest)
+}
+
+// GetVolumeAccessPoint ...
+func (c *contextSession) GetVolumeAccessPoint(ctx context.Context, accessPointRequest provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.sess.GetVolumeAccessPoint(accessPointRequest)
+}
+
+// GetSubnetForVolumeAccessPoint ...
+func (c *contextSession) GetSubnetForVolumeAccessPoint(ctx context.Context, subnetRequest provider.SubnetRequest) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return c.sess.GetSubnetForVolumeAccessPoint(subnetRequest)
+}
+
+// GetSecurityGroupForVolumeAccessPoint ...
+func (c *contextSession) GetSecurityGroupForVolumeAccessPoint(ctx context.Context, securityGroupRequest provider.SecurityGroupRequest) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return c.sess.GetSecurityGroupForVolumeAccessPoint(securityGroupRequest)
+}
+
+// WaitForAttachVolume waits for the volume to be attached to the host, or for ctx to be done
+func (c *contextSession) WaitForAttachVolume(ctx context.Context, attachRequest provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	return c.sess.waitForAttachVolume(ctx, attachRequest)
+}
+
+// WaitForDetachVolume waits for the volume to be detached from the host, or for ctx to be done
+func (c *contextSession) WaitForDetachVolume(ctx context.Context, detachRequest provider.VolumeAttachmentRequest) error {
+	return c.sess.waitForDetachVolume(ctx, detachRequest)
+}
+
+// WaitForVolumeAvailable waits for the volume to become available, or for ctx to be done
+func (c *contextSession) WaitForVolumeAvailable(ctx context.Context, volumeID string) (*provider.Volume, error) {
+	return c.sess.waitForVolumeAvailable(ctx, volumeID)
+}
+
+// CloneVolume clones the volume, stopping and rolling back when ctx is done
+func (c *contextSession) CloneVolume(ctx context.Context, sourceVolumeID string, template provider.Volume) (*provider.Volume, error) {
+	return provider.CloneVolumeWithContext(ctx, c, sourceVolumeID, template)
+}
+
+// WaitForSnapshotReady waits for the snapshot to be ReadyToUse, or for ctx to be done
+func (c *contextSession) WaitForSnapshotReady(ctx context.Context, snapshotID string, sourceVolumeID ...string) (*provider.Snapshot, error) {
+	return c.sess.waitForSnapshotReady(ctx, snapshotID, sourceVolumeID...)
+}
+
+// WaitForCreateVolumeAccessPoint waits for the volume access point to be created, or for ctx to be done
+func (c *contextSession) WaitForCreateVolumeAccessPoint(ctx context.Context, accessPointRequest provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	return c.sess.waitForCreateVolumeAccessPoint(ctx, accessPointRequest)
+}
+
+// WaitForDeleteVolumeAccessPoint waits for the volume access point to be deleted, or for ctx to be done
+func (c *contextSession) WaitForDeleteVolumeAccessPoint(ctx context.Context, deleteAccessPointRequest provider.VolumeAccessPointRequest) error {
+	return c.sess.waitForDeleteVolumeAccessPoint(ctx, deleteAccessPointRequest)
+}
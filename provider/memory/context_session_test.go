@@ -0,0 +1,90 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextSession(t *testing.T) {
+	p, sess := openSession(t)
+	cs := provider.NewContextSession(sess)
+	assert.IsType(t, &contextSession{}, cs)
+	assert.Equal(t, p.Name, cs.ProviderName())
+	ctx := context.Background()
+
+	name := "vol"
+	capacity := 10
+	volume, err := cs.CreateVolume(ctx, provider.Volume{Name: &name, Capacity: &capacity})
+	require.NoError(t, err)
+
+	request := provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "instance"}
+	_, err = cs.AttachVolume(ctx, request)
+	require.NoError(t, err)
+	attachment, err := cs.WaitForAttachVolume(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, StatusAttached, attachment.Status)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = cs.GetVolume(cancelled, volume.VolumeID)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, errors.Is(cs.DeleteVolume(cancelled, volume), context.Canceled))
+}
+
+func TestContextSessionWaitDeadline(t *testing.T) {
+	p, sess := openSession(t)
+	cs := provider.NewContextSession(sess)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+	request := provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "instance"}
+
+	p.TransitionDelay = time.Hour
+	_, err := cs.AttachVolume(context.Background(), request)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = cs.WaitForAttachVolume(ctx, request)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), p.WaitTimeout)
+}
+
+func TestContextSessionWaitForSnapshotReadyDeadline(t *testing.T) {
+	p, sess := openSession(t)
+	cs := provider.NewContextSession(sess)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+
+	p.TransitionDelay = time.Hour
+	snapshot, err := cs.CreateSnapshot(context.Background(), volume.VolumeID, provider.SnapshotParameters{Name: "snap"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = cs.WaitForSnapshotReady(ctx, snapshot.SnapshotID)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), p.WaitTimeout)
+}
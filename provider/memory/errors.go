@@ -0,0 +1,60 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"fmt"
+	"net/http"
+
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+)
+
+// newError returns the util.Message a real provider returns to the user.
+// errType is one of the util error types, so util.GetErrorType works as it does for real providers.
+func newError(errType string, code string, rc int, format string, args ...interface{}) error {
+	return util.Message{
+		Code:        code,
+		Type:        errType,
+		Description: fmt.Sprintf(format, args...),
+		RC:          rc,
+	}
+}
+
+// volumeNotFound ...
+func volumeNotFound(volumeID string) error {
+	return newError(util.EntityNotFound, "StorageFindFailedWithVolumeId", http.StatusNotFound,
+		"A volume with the specified volume ID '%s' could not be found", volumeID)
+}
+
+// snapshotNotFound ...
+func snapshotNotFound(snapshotID string) error {
+	return newError(util.EntityNotFound, "StorageFindFailedWithSnapshotId", http.StatusNotFound,
+		"A snapshot with the specified snapshot ID '%s' could not be found", snapshotID)
+}
+
+// attachmentNotFound ...
+func attachmentNotFound(volumeID, instanceID string) error {
+	return newError(util.VolumeAttachFindFailed, "VolumeAttachFindFailed", http.StatusNotFound,
+		"No volume attachment found for volume ID '%s' and instance ID '%s'", volumeID, instanceID)
+}
+
+// accessPointNotFound ...
+func accessPointNotFound(volumeID, accessPointID string) error {
+	return newError(util.VolumeAccessPointFindFailed, "VolumeAccessPointFindFailed", http.StatusNotFound,
+		"No volume access point found for volume ID '%s' and access point ID '%s'", volumeID, accessPointID)
+}
@@ -0,0 +1,65 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+)
+
+// defaultProfiles returns a catalog modelled on the VPC block storage profiles
+func defaultProfiles() map[string]provider.Profile {
+	profiles := []provider.Profile{
+		{
+			Name:     "general-purpose",
+			Family:   "tiered",
+			Capacity: provider.CapIops{Type: "range", Min: 10, Max: 16000, Step: 1, Default: 100},
+			Iops:     provider.CapIops{Type: "dependent", Value: 3},
+		},
+		{
+			Name:     "5iops-tier",
+			Family:   "tiered",
+			Capacity: provider.CapIops{Type: "range", Min: 10, Max: 9600, Step: 1, Default: 100},
+			Iops:     provider.CapIops{Type: "dependent", Value: 5},
+		},
+		{
+			Name:     "10iops-tier",
+			Family:   "tiered",
+			Capacity: provider.CapIops{Type: "range", Min: 10, Max: 4800, Step: 1, Default: 100},
+			Iops:     provider.CapIops{Type: "dependent", Value: 10},
+		},
+		{
+			Name:     "custom",
+			Family:   "custom",
+			Capacity: provider.CapIops{Type: "range", Min: 10, Max: 16000, Step: 1, Default: 100},
+			Iops:     provider.CapIops{Type: "range", Min: 100, Max: 48000, Step: 1, Default: 3000},
+		},
+		{
+			Name:     "sdp",
+			Family:   "defined_performance",
+			Capacity: provider.CapIops{Type: "range", Min: 1, Max: 32000, Step: 1, Default: 100},
+			Iops:     provider.CapIops{Type: "range", Min: 3000, Max: 64000, Step: 1, Default: 3000},
+		},
+	}
+
+	catalog := make(map[string]provider.Profile, len(profiles))
+	for _, profile := range profiles {
+		profile.ResourceType = "volume_profile"
+		catalog[profile.Name] = profile
+	}
+	return catalog
+}
@@ -0,0 +1,153 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/provider/local"
+	"go.uber.org/zap"
+)
+
+const (
+	// ProviderName is the default name of the in-memory provider
+	ProviderName = provider.VolumeProvider("MEMORY")
+
+	// VolumeTypeBlock ...
+	VolumeTypeBlock = provider.VolumeType("block")
+
+	// VolumeTypeFile ...
+	VolumeTypeFile = provider.VolumeType("file")
+
+	defaultWaitTimeout  = 2 * time.Minute
+	defaultPollInterval = 50 * time.Millisecond
+)
+
+// Provider is an in-memory implementation of local.Provider.
+// All sessions opened against the same Provider share its volumes, snapshots,
+// attachments and access points, so it can stand in for a real IaaS provider in tests.
+// Use NewProvider to create one.
+type Provider struct {
+	// Name is reported by ProviderName and GetProviderDisplayName
+	Name provider.VolumeProvider
+
+	// VolumeType is reported by Type. Block volumes can only be attached to one instance at a time.
+	VolumeType provider.VolumeType
+
+	// TransitionDelay is how long a resource stays in a transient state (pending, attaching, detaching...)
+	// before it settles. With zero delay a resource settles the next time it is observed.
+	TransitionDelay time.Duration
+
+	// WaitTimeout bounds the Wait* methods
+	WaitTimeout time.Duration
+
+	// PollInterval is the interval at which the Wait* methods re-check the resource state
+	PollInterval time.Duration
+
+	// Profiles is the catalog served by GetVolumeProfileByName, keyed by profile name
+	Profiles map[string]provider.Profile
+
+	mu           sync.Mutex
+	sequence     int64
+	volumes      map[string]*volumeRecord
+	snapshots    map[string]*snapshotRecord
+	attachments  map[string]*attachmentRecord
+	accessPoints map[string]*accessPointRecord
+}
+
+var _ local.Provider = &Provider{}
+
+// NewProvider returns an empty in-memory provider with the default profile catalog
+func NewProvider(name provider.VolumeProvider, volumeType provider.VolumeType) *Provider {
+	if name == "" {
+		name = ProviderName
+	}
+	if volumeType == "" {
+		volumeType = VolumeTypeBlock
+	}
+	return &Provider{
+		Name:         name,
+		VolumeType:   volumeType,
+		WaitTimeout:  defaultWaitTimeout,
+		PollInterval: defaultPollInterval,
+		Profiles:     defaultProfiles(),
+		volumes:      map[string]*volumeRecord{},
+		snapshots:    map[string]*snapshotRecord{},
+		attachments:  map[string]*attachmentRecord{},
+		accessPoints: map[string]*accessPointRecord{},
+	}
+}
+
+// OpenSession opens a session on the shared in-memory state.
+// The request ID stored in ctx under provider.RequestID is recorded against created volumes
+// so that they can be found again with GetVolumeByRequestID.
+func (p *Provider) OpenSession(ctx context.Context, contextCredentials provider.ContextCredentials, logger *zap.Logger) (provider.Session, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger.Debug("Opening in-memory provider session", zap.String("provider", string(p.Name)), zap.Reflect("contextCredentials", contextCredentials))
+	return &Session{
+		mem:                p,
+		ctx:                ctx,
+		contextCredentials: contextCredentials,
+		logger:             logger,
+	}, nil
+}
+
+// ContextCredentialsFactory returns a factory which builds credentials without contacting IAM
+func (p *Provider) ContextCredentialsFactory(datacenter *string) (local.ContextCredentialsFactory, error) {
+	return &ContextCredentialsFactory{}, nil
+}
+
+// ContextCredentialsFactory is a local.ContextCredentialsFactory that needs no token exchange
+type ContextCredentialsFactory struct{}
+
+var _ local.ContextCredentialsFactory = &ContextCredentialsFactory{}
+
+// ForIaaSAPIKey ...
+func (ccf *ContextCredentialsFactory) ForIaaSAPIKey(iamAccountID, iaasUserID, iaasAPIKey string, logger *zap.Logger) (provider.ContextCredentials, error) {
+	return provider.ContextCredentials{
+		AuthType:     provider.IaaSAPIKey,
+		IAMAccountID: iamAccountID,
+		UserID:       iaasUserID,
+		Credential:   iaasAPIKey,
+	}, nil
+}
+
+// ForIAMAPIKey ...
+func (ccf *ContextCredentialsFactory) ForIAMAPIKey(iamAccountID, iamAPIKey string, logger *zap.Logger) (provider.ContextCredentials, error) {
+	return provider.ContextCredentials{
+		AuthType:     provider.IAMAPIKey,
+		IAMAccountID: iamAccountID,
+		Credential:   iamAPIKey,
+	}, nil
+}
+
+// ForIAMAccessToken ...
+func (ccf *ContextCredentialsFactory) ForIAMAccessToken(apiKey string, logger *zap.Logger) (provider.ContextCredentials, error) {
+	return provider.ContextCredentials{
+		AuthType:   provider.IAMAccessToken,
+		Credential: apiKey,
+	}, nil
+}
@@ -0,0 +1,123 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var (
+	logger *zap.Logger
+)
+
+func init() {
+	logger, _ = zap.NewDevelopment()
+}
+
+// openSession returns a session on a fresh provider
+func openSession(t *testing.T) (*Provider, provider.Session) {
+	p := NewProvider("", "")
+	p.PollInterval = time.Millisecond
+	sess, err := p.OpenSession(context.Background(), provider.ContextCredentials{}, logger)
+	require.NoError(t, err)
+	return p, sess
+}
+
+// createAvailableVolume creates a volume and returns it once it is available
+func createAvailableVolume(t *testing.T, sess provider.Session, name string, capacity int) *provider.Volume {
+	volume, err := sess.CreateVolume(provider.Volume{Name: &name, Capacity: &capacity, Az: "us-south-1"})
+	require.NoError(t, err)
+	volume, err = sess.GetVolume(volume.VolumeID)
+	require.NoError(t, err)
+	require.Equal(t, StatusAvailable, volume.Status)
+	return volume
+}
+
+func TestNewProvider(t *testing.T) {
+	p := NewProvider("", "")
+	assert.Equal(t, ProviderName, p.Name)
+	assert.Equal(t, VolumeTypeBlock, p.VolumeType)
+	assert.NotEmpty(t, p.Profiles)
+
+	p = NewProvider("VPC-SHARE", VolumeTypeFile)
+	assert.Equal(t, provider.VolumeProvider("VPC-SHARE"), p.Name)
+	assert.Equal(t, VolumeTypeFile, p.VolumeType)
+}
+
+func TestOpenSession(t *testing.T) {
+	p, sess := openSession(t)
+	assert.Equal(t, p.Name, sess.ProviderName())
+	assert.Equal(t, p.Name, sess.GetProviderDisplayName())
+	assert.Equal(t, p.VolumeType, sess.Type())
+	sess.Close()
+
+	// Sessions share the provider state
+	volume := createAvailableVolume(t, sess, "shared", 10)
+	other, err := p.OpenSession(context.Background(), provider.ContextCredentials{}, nil)
+	require.NoError(t, err)
+	found, err := other.GetVolume(volume.VolumeID)
+	assert.NoError(t, err)
+	assert.Equal(t, volume.VolumeID, found.VolumeID)
+}
+
+func TestContextCredentialsFactory(t *testing.T) {
+	p := NewProvider("", "")
+	ccf, err := p.ContextCredentialsFactory(nil)
+	require.NoError(t, err)
+
+	creds, err := ccf.ForIaaSAPIKey("account", "user", "key", logger)
+	assert.NoError(t, err)
+	assert.Equal(t, provider.IaaSAPIKey, creds.AuthType)
+	assert.Equal(t, "user", creds.UserID)
+
+	creds, err = ccf.ForIAMAPIKey("account", "key", logger)
+	assert.NoError(t, err)
+	assert.Equal(t, provider.IAMAPIKey, creds.AuthType)
+	assert.Equal(t, "account", creds.IAMAccountID)
+
+	creds, err = ccf.ForIAMAccessToken("key", logger)
+	assert.NoError(t, err)
+	assert.Equal(t, provider.IAMAccessToken, creds.AuthType)
+}
+
+func TestTransitionDelay(t *testing.T) {
+	p, sess := openSession(t)
+	p.TransitionDelay = time.Hour
+
+	name := "slow"
+	capacity := 10
+	volume, err := sess.CreateVolume(provider.Volume{Name: &name, Capacity: &capacity})
+	require.NoError(t, err)
+	volume, err = sess.GetVolume(volume.VolumeID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, volume.Status)
+
+	p.mu.Lock()
+	p.volumes[volume.VolumeID].readyAt = time.Now()
+	p.mu.Unlock()
+	volume, err = sess.GetVolume(volume.VolumeID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusAvailable, volume.Status)
+}
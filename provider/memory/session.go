@@ -0,0 +1,109 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultListLimit is used when ListVolumes or ListSnapshots is called with limit 0
+	defaultListLimit = 50
+
+	// maxListLimit is the largest page ListVolumes or ListSnapshots will return
+	maxListLimit = 100
+)
+
+// Session is a provider.Session backed by the state of an in-memory Provider
+type Session struct {
+	mem                *Provider
+	ctx                context.Context
+	contextCredentials provider.ContextCredentials
+	logger             *zap.Logger
+}
+
+var _ provider.Session = &Session{}
+var _ provider.VolumeFilterLister = &Session{}
+
+// ProviderName returns the provider name
+func (s *Session) ProviderName() provider.VolumeProvider {
+	return s.mem.Name
+}
+
+// Type returns the underlying volume type
+func (s *Session) Type() provider.VolumeType {
+	return s.mem.VolumeType
+}
+
+// GetProviderDisplayName returns the provider name
+func (s *Session) GetProviderDisplayName() provider.VolumeProvider {
+	return s.mem.Name
+}
+
+// Close is called when the Session is nolonger required
+func (s *Session) Close() {
+	s.logger.Debug("Closing in-memory provider session")
+}
+
+// requestID returns the request ID carried by the session context, or a new one
+func (s *Session) requestID() string {
+	if requestID, ok := s.ctx.Value(provider.RequestID).(string); ok && requestID != "" {
+		return requestID
+	}
+	return newID("req")
+}
+
+// waitOptions returns the options of the Wait* methods of the session
+func waitOptions[S ~string](s *Session) provider.WaitOptions[S] {
+	return provider.WaitOptions[S]{Timeout: s.mem.WaitTimeout, Interval: s.mem.PollInterval}
+}
+
+// waitTimedOut reports whether err is a wait that timed out on WaitTimeout rather than because ctx is done
+func (s *Session) waitTimedOut(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && errors.Is(err, reasoncode.ErrorWaitTimedOut)
+}
+
+// paginate returns the page of items beginning at the item whose ID is start, and the
+// ID of the first item of the following page. ok is false if start does not match any item.
+func paginate[T any](items []T, limit int, start string, id func(T) string) (page []T, next string, ok bool) {
+	first := 0
+	if start != "" {
+		first = -1
+		for i, item := range items {
+			if id(item) == start {
+				first = i
+				break
+			}
+		}
+		if first < 0 {
+			return nil, "", false
+		}
+	}
+	last := first + limit
+	if last < len(items) {
+		next = id(items[last])
+	} else {
+		last = len(items)
+	}
+	return items[first:last], next, true
+}
@@ -0,0 +1,199 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"go.uber.org/zap"
+)
+
+// CreateSnapshot creates a snapshot of the volume. The snapshot is not ReadyToUse until it settles.
+func (s *Session) CreateSnapshot(sourceVolumeID string, snapshotParameters provider.SnapshotParameters) (*provider.Snapshot, error) {
+	s.logger.Info("Creating snapshot", zap.String("sourceVolumeID", sourceVolumeID), zap.Reflect("snapshotParameters", snapshotParameters))
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	volume, ok := s.mem.volumes[sourceVolumeID]
+	if !ok {
+		return nil, volumeNotFound(sourceVolumeID)
+	}
+	if !state.Volume.Allows(volume.volume.State(), state.OperationSnapshot) {
+		return nil, newError(util.ProvisioningFailed, "VolumeNotAvailable", http.StatusConflict,
+			"Volume '%s' is in '%s' state and cannot be snapshotted", sourceVolumeID, volume.volume.Status)
+	}
+	if snapshotParameters.Name != "" {
+		for _, rec := range s.mem.snapshots {
+			if rec.snapshot.VPC.Name == snapshotParameters.Name {
+				return nil, newError(util.ProvisioningFailed, "SnapshotNameExists", http.StatusConflict,
+					"A snapshot with the name '%s' already exists", snapshotParameters.Name)
+			}
+		}
+	}
+
+	capacity := int64(0)
+	if volume.volume.Capacity != nil {
+		capacity = int64(*volume.volume.Capacity)
+	}
+	id := newID("snap")
+	rec := &snapshotRecord{
+		snapshot: provider.Snapshot{
+			VolumeID:             sourceVolumeID,
+			SnapshotID:           id,
+			SnapshotCRN:          fmt.Sprintf("crn:v1:memory:public:is:%s::snapshot:%s", volume.volume.Region, id),
+			SnapshotSize:         capacity * gib,
+			SnapshotCreationTime: time.Now(),
+			SnapshotTags:         copyMap(snapshotParameters.SnapshotTags),
+			VPC:                  provider.VPC{Name: snapshotParameters.Name},
+		},
+		sequence: s.mem.nextSequence(),
+		readyAt:  s.mem.readyAt(),
+	}
+	s.mem.snapshots[id] = rec
+	return copySnapshot(rec.snapshot), nil
+}
+
+// DeleteSnapshot deletes the snapshot
+func (s *Session) DeleteSnapshot(snapshot *provider.Snapshot) error {
+	if snapshot == nil || snapshot.SnapshotID == "" {
+		return newError(util.InvalidRequest, "InvalidSnapshotID", http.StatusBadRequest, "Snapshot ID is required")
+	}
+	s.logger.Info("Deleting snapshot", zap.String("snapshotID", snapshot.SnapshotID))
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	if _, ok := s.mem.snapshots[snapshot.SnapshotID]; !ok {
+		return snapshotNotFound(snapshot.SnapshotID)
+	}
+	delete(s.mem.snapshots, snapshot.SnapshotID)
+	return nil
+}
+
+// GetSnapshot gets the snapshot, optionally checking that it belongs to the given source volume
+func (s *Session) GetSnapshot(snapshotID string, sourceVolumeID ...string) (*provider.Snapshot, error) {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	rec, ok := s.mem.snapshots[snapshotID]
+	if !ok || !fromSourceVolume(rec.snapshot, sourceVolumeID) {
+		return nil, snapshotNotFound(snapshotID)
+	}
+	return copySnapshot(rec.snapshot), nil
+}
+
+// GetSnapshotByName gets the snapshot by name, optionally checking that it belongs to the given source volume
+func (s *Session) GetSnapshotByName(snapshotName string, sourceVolumeID ...string) (*provider.Snapshot, error) {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	for _, rec := range s.mem.snapshots {
+		if rec.snapshot.VPC.Name == snapshotName && fromSourceVolume(rec.snapshot, sourceVolumeID) {
+			return copySnapshot(rec.snapshot), nil
+		}
+	}
+	return nil, newError(util.EntityNotFound, "StorageFindFailedWithSnapshotName", http.StatusNotFound,
+		"A snapshot with the specified snapshot name '%s' could not be found", snapshotName)
+}
+
+// ListSnapshots lists snapshots in creation order. Tags "name" and "source_volume.id" filter on the
+// snapshot name and source volume, any other tag filters on the snapshot tags.
+func (s *Session) ListSnapshots(limit int, start string, tags map[string]string) (*provider.SnapshotList, error) {
+	if limit < 0 || limit > maxListLimit {
+		return nil, newError(util.InvalidRequest, "InvalidListSnapshotsLimit", http.StatusBadRequest,
+			"The value '%d' specified in the limit parameter of the list snapshot call is not valid", limit)
+	}
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	var matched []*snapshotRecord
+	for _, rec := range s.mem.sortedSnapshots() {
+		if snapshotMatches(rec.snapshot, tags) {
+			matched = append(matched, rec)
+		}
+	}
+	page, next, ok := paginate(matched, limit, start, func(rec *snapshotRecord) string { return rec.snapshot.SnapshotID })
+	if !ok {
+		return nil, newError(util.InvalidRequest, "StartSnapshotIDNotFound", http.StatusBadRequest,
+			"The snapshot ID '%s' specified in the start parameter of the list snapshot call could not be found", start)
+	}
+	snapshots := &provider.SnapshotList{Next: next, Snapshots: []*provider.Snapshot{}}
+	for _, rec := range page {
+		snapshots.Snapshots = append(snapshots.Snapshots, copySnapshot(rec.snapshot))
+	}
+	return snapshots, nil
+}
+
+// WaitForSnapshotReady waits for the snapshot to be ReadyToUse
+// Return error if wait is timed out OR there is other error
+func (s *Session) WaitForSnapshotReady(snapshotID string, sourceVolumeID ...string) (*provider.Snapshot, error) {
+	return s.waitForSnapshotReady(context.Background(), snapshotID, sourceVolumeID...)
+}
+
+// waitForSnapshotReady ...
+func (s *Session) waitForSnapshotReady(ctx context.Context, snapshotID string, sourceVolumeID ...string) (*provider.Snapshot, error) {
+	snapshot, err := provider.WaitForSnapshotState(ctx, func(context.Context) (*provider.Snapshot, error) {
+		return s.GetSnapshot(snapshotID, sourceVolumeID...)
+	}, state.SnapshotStable, waitOptions[state.SnapshotStatus](s))
+	if s.waitTimedOut(ctx, err) {
+		return nil, newError(util.ProvisioningFailed, "SnapshotReadyTimeout", http.StatusGatewayTimeout,
+			"Snapshot '%s' was not ready to use within %s", snapshotID, s.mem.WaitTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// fromSourceVolume reports whether the snapshot was taken of the optional source volume
+func fromSourceVolume(snapshot provider.Snapshot, sourceVolumeID []string) bool {
+	return len(sourceVolumeID) == 0 || sourceVolumeID[0] == "" || sourceVolumeID[0] == snapshot.VolumeID
+}
+
+// snapshotMatches ...
+func snapshotMatches(snapshot provider.Snapshot, tags map[string]string) bool {
+	for key, value := range tags {
+		switch key {
+		case "name":
+			if snapshot.VPC.Name != value {
+				return false
+			}
+		case "source_volume.id":
+			if snapshot.VolumeID != value {
+				return false
+			}
+		default:
+			if tag, ok := snapshot.SnapshotTags[key]; !ok || tag != value {
+				return false
+			}
+		}
+	}
+	return true
+}
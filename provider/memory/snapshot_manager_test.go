@@ -0,0 +1,136 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotLifecycle(t *testing.T) {
+	_, sess := openSession(t)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+
+	snapshot, err := sess.CreateSnapshot(volume.VolumeID, provider.SnapshotParameters{Name: "snap", SnapshotTags: provider.SnapshotTags{"app": "db"}})
+	require.NoError(t, err)
+	assert.False(t, snapshot.ReadyToUse)
+	assert.Equal(t, int64(10)*gib, snapshot.SnapshotSize)
+
+	snapshot, err = sess.GetSnapshot(snapshot.SnapshotID, volume.VolumeID)
+	require.NoError(t, err)
+	assert.True(t, snapshot.ReadyToUse)
+
+	_, err = sess.GetSnapshot(snapshot.SnapshotID, "other-volume")
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+
+	found, err := sess.GetSnapshotByName("snap")
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.SnapshotID, found.SnapshotID)
+
+	_, err = sess.CreateSnapshot(volume.VolumeID, provider.SnapshotParameters{Name: "snap"})
+	assert.Equal(t, util.ProvisioningFailed, util.GetErrorType(err))
+
+	list, err := sess.ListSnapshots(0, "", map[string]string{"source_volume.id": volume.VolumeID, "app": "db"})
+	require.NoError(t, err)
+	assert.Len(t, list.Snapshots, 1)
+
+	restored, err := sess.CreateVolumeFromSnapshot(*snapshot, map[string]string{"pvc": "restore"})
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.SnapshotID, restored.SnapshotID)
+	assert.Equal(t, 10, *restored.Capacity)
+	assert.Equal(t, "restore", restored.VolumeNotes["pvc"])
+
+	assert.NoError(t, sess.DeleteSnapshot(snapshot))
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(sess.DeleteSnapshot(snapshot)))
+
+	_, err = sess.CreateVolumeFromSnapshot(*snapshot, nil)
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+}
+
+func TestCreateVolumeFromSnapshotRoundsUp(t *testing.T) {
+	p, sess := openSession(t)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+	snapshot, err := sess.CreateSnapshot(volume.VolumeID, provider.SnapshotParameters{Name: "snap"})
+	require.NoError(t, err)
+
+	// A snapshot which is not a whole number of GiB is restored into a volume large enough to hold it
+	p.mu.Lock()
+	p.snapshots[snapshot.SnapshotID].snapshot.SnapshotSize = 10*gib + 1
+	p.mu.Unlock()
+	restored, err := sess.CreateVolumeFromSnapshot(*snapshot, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 11, *restored.Capacity)
+}
+
+func TestCreateVolumeFromSnapshotRequest(t *testing.T) {
+	_, sess := openSession(t)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+	snapshot, err := sess.CreateSnapshot(volume.VolumeID, provider.SnapshotParameters{})
+	require.NoError(t, err)
+
+	small := 5
+	_, err = sess.CreateVolume(provider.Volume{Name: String("small"), Capacity: &small, Snapshot: provider.Snapshot{SnapshotID: snapshot.SnapshotID}})
+	assert.Equal(t, util.InvalidRequest, util.GetErrorType(err))
+
+	capacity := 20
+	restored, err := sess.CreateVolume(provider.Volume{Name: String("big"), Capacity: &capacity, Snapshot: provider.Snapshot{SnapshotID: snapshot.SnapshotID}})
+	assert.NoError(t, err)
+	assert.Equal(t, volume.VolumeID, restored.Snapshot.VolumeID)
+}
+
+func TestListSnapshotsErrors(t *testing.T) {
+	_, sess := openSession(t)
+
+	_, err := sess.ListSnapshots(-1, "", nil)
+	assert.Equal(t, util.InvalidRequest, util.GetErrorType(err))
+
+	_, err = sess.ListSnapshots(10, "missing", nil)
+	assert.Equal(t, util.InvalidRequest, util.GetErrorType(err))
+
+	_, err = sess.CreateSnapshot("missing", provider.SnapshotParameters{})
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+}
+
+func TestWaitForSnapshotReady(t *testing.T) {
+	p, sess := openSession(t)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+
+	p.TransitionDelay = 20 * time.Millisecond
+	snapshot, err := sess.CreateSnapshot(volume.VolumeID, provider.SnapshotParameters{Name: "snap"})
+	require.NoError(t, err)
+	assert.False(t, snapshot.ReadyToUse)
+
+	snapshot, err = sess.WaitForSnapshotReady(snapshot.SnapshotID, volume.VolumeID)
+	require.NoError(t, err)
+	assert.True(t, snapshot.ReadyToUse)
+
+	_, err = sess.WaitForSnapshotReady(snapshot.SnapshotID, "other-volume")
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+
+	p.TransitionDelay = time.Hour
+	p.WaitTimeout = 10 * time.Millisecond
+	snapshot, err = sess.CreateSnapshot(volume.VolumeID, provider.SnapshotParameters{Name: "slow"})
+	require.NoError(t, err)
+	_, err = sess.WaitForSnapshotReady(snapshot.SnapshotID)
+	assert.Equal(t, util.ProvisioningFailed, util.GetErrorType(err))
+}
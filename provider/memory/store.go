@@ -0,0 +1,247 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+)
+
+// Resource states reported by the in-memory provider
+const (
+	// StatusPending volume or access point has been requested but is not usable yet
+	StatusPending = string(state.VolumePending)
+	// StatusAvailable volume is ready for use
+	StatusAvailable = string(state.VolumeAvailable)
+	// StatusAttaching attachment has been requested
+	StatusAttaching = string(state.AttachmentAttaching)
+	// StatusAttached attachment is complete
+	StatusAttached = string(state.AttachmentAttached)
+	// StatusDetaching detachment has been requested
+	StatusDetaching = string(state.AttachmentDetaching)
+	// StatusStable access point is ready for use
+	StatusStable = string(state.AccessPointStable)
+	// StatusDeleting access point deletion has been requested
+	StatusDeleting = string(state.AccessPointDeleting)
+)
+
+// volumeRecord ...
+type volumeRecord struct {
+	volume    provider.Volume
+	requestID string
+	sequence  int64
+	readyAt   time.Time
+}
+
+// snapshotRecord ...
+type snapshotRecord struct {
+	snapshot provider.Snapshot
+	sequence int64
+	readyAt  time.Time
+}
+
+// attachmentRecord ...
+type attachmentRecord struct {
+	attachment provider.VolumeAttachmentResponse
+	readyAt    time.Time
+}
+
+// accessPointRecord ...
+type accessPointRecord struct {
+	accessPoint provider.VolumeAccessPointResponse
+	name        string
+	vpcID       string
+	readyAt     time.Time
+}
+
+// settle moves every resource whose transition delay has elapsed into its final state.
+// Must be called with p.mu held.
+func (p *Provider) settle() {
+	now := time.Now()
+	for _, rec := range p.volumes {
+		if rec.volume.Status == StatusPending && !now.Before(rec.readyAt) {
+			rec.volume.Status = StatusAvailable
+		}
+	}
+	for _, rec := range p.snapshots {
+		if !rec.snapshot.ReadyToUse && !now.Before(rec.readyAt) {
+			rec.snapshot.ReadyToUse = true
+		}
+	}
+	for key, rec := range p.attachments {
+		if now.Before(rec.readyAt) {
+			continue
+		}
+		switch rec.attachment.Status {
+		case StatusAttaching:
+			rec.attachment.Status = StatusAttached
+		case StatusDetaching:
+			delete(p.attachments, key)
+		}
+	}
+	for id, rec := range p.accessPoints {
+		if now.Before(rec.readyAt) {
+			continue
+		}
+		switch rec.accessPoint.Status {
+		case StatusPending:
+			rec.accessPoint.Status = StatusStable
+		case StatusDeleting:
+			delete(p.accessPoints, id)
+		}
+	}
+}
+
+// nextSequence returns an increasing number used to keep listings in creation order.
+// Must be called with p.mu held.
+func (p *Provider) nextSequence() int64 {
+	p.sequence++
+	return p.sequence
+}
+
+// readyAt returns the time at which a resource created now leaves its transient state
+func (p *Provider) readyAt() time.Time {
+	return time.Now().Add(p.TransitionDelay)
+}
+
+// sortedVolumes returns the volume records in creation order. Must be called with p.mu held.
+func (p *Provider) sortedVolumes() []*volumeRecord {
+	records := make([]*volumeRecord, 0, len(p.volumes))
+	for _, rec := range p.volumes {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].sequence < records[j].sequence })
+	return records
+}
+
+// sortedSnapshots returns the snapshot records in creation order. Must be called with p.mu held.
+func (p *Provider) sortedSnapshots() []*snapshotRecord {
+	records := make([]*snapshotRecord, 0, len(p.snapshots))
+	for _, rec := range p.snapshots {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].sequence < records[j].sequence })
+	return records
+}
+
+// attachmentKey ...
+func attachmentKey(volumeID, instanceID string) string {
+	return volumeID + "/" + instanceID
+}
+
+// newID returns a random identifier in the UUID layout used by the IaaS APIs
+func newID(prefix string) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	h := hex.EncodeToString(b)
+	return fmt.Sprintf("%s-%s-%s-%s-%s-%s", prefix, h[0:8], h[8:12], h[12:16], h[16:20], h[20:32])
+}
+
+// copyVolume returns a copy of the volume which shares no mutable state with the original
+func copyVolume(in provider.Volume) *provider.Volume {
+	out := in
+	out.Capacity = copyInt(in.Capacity)
+	out.Iops = copyString(in.Iops)
+	out.Tier = copyString(in.Tier)
+	out.Name = copyString(in.Name)
+	out.ServiceOffering = copyString(in.ServiceOffering)
+	out.VolumeNotes = copyMap(in.VolumeNotes)
+	out.Attributes = copyMap(in.Attributes)
+	out.SnapshotTags = copyMap(in.SnapshotTags)
+	if in.Tags != nil {
+		out.Tags = append([]string{}, in.Tags...)
+	}
+	if in.Profile != nil {
+		profile := *in.Profile
+		out.Profile = &profile
+	}
+	if in.VolumeAttachments != nil {
+		attachments := append([]provider.VolumeAttachment{}, (*in.VolumeAttachments)...)
+		out.VolumeAttachments = &attachments
+	}
+	if in.VolumeAccessPoints != nil {
+		accessPoints := append([]provider.VolumeAccessPoint{}, (*in.VolumeAccessPoints)...)
+		out.VolumeAccessPoints = &accessPoints
+	}
+	return &out
+}
+
+// copySnapshot ...
+func copySnapshot(in provider.Snapshot) *provider.Snapshot {
+	out := in
+	out.SnapshotTags = copyMap(in.SnapshotTags)
+	return &out
+}
+
+// copyAttachment ...
+func copyAttachment(in provider.VolumeAttachmentResponse) *provider.VolumeAttachmentResponse {
+	out := in
+	if in.VPCVolumeAttachment != nil {
+		attachment := *in.VPCVolumeAttachment
+		out.VPCVolumeAttachment = &attachment
+	}
+	out.SoftlayerOptions = copyMap(in.SoftlayerOptions)
+	if in.CreatedAt != nil {
+		createdAt := *in.CreatedAt
+		out.CreatedAt = &createdAt
+	}
+	return &out
+}
+
+// copyAccessPoint ...
+func copyAccessPoint(in provider.VolumeAccessPointResponse) *provider.VolumeAccessPointResponse {
+	out := in
+	if in.CreatedAt != nil {
+		createdAt := *in.CreatedAt
+		out.CreatedAt = &createdAt
+	}
+	return &out
+}
+
+func copyInt(in *int) *int {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+func copyString(in *string) *string {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+func copyMap[M ~map[string]string](in M) M {
+	if in == nil {
+		return nil
+	}
+	out := make(M, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"go.uber.org/zap"
+)
+
+// AttachVolume attaches the volume to the instance. The attachment starts in "attaching" state.
+// Attaching a volume to an instance it is already attached to returns the existing attachment.
+func (s *Session) AttachVolume(attachRequest provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	s.logger.Info("Attaching volume", zap.Reflect("attachRequest", attachRequest))
+	if attachRequest.VolumeID == "" || attachRequest.InstanceID == "" {
+		return nil, newError(util.InvalidRequest, "InvalidAttachRequest", http.StatusBadRequest,
+			"Volume ID and instance ID are required to attach a volume")
+	}
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	volume, ok := s.mem.volumes[attachRequest.VolumeID]
+	if !ok {
+		return nil, newError(util.AttachFailed, "StorageFindFailedWithVolumeId", http.StatusNotFound,
+			"A volume with the specified volume ID '%s' could not be found", attachRequest.VolumeID)
+	}
+	if !state.Volume.Allows(volume.volume.State(), state.OperationAttach) {
+		return nil, newError(util.AttachFailed, "VolumeNotAvailable", http.StatusConflict,
+			"Volume '%s' is in '%s' state and cannot be attached", attachRequest.VolumeID, volume.volume.Status)
+	}
+
+	key := attachmentKey(attachRequest.VolumeID, attachRequest.InstanceID)
+	if existing, ok := s.mem.attachments[key]; ok {
+		if existing.attachment.Status == StatusDetaching {
+			return nil, newError(util.AttachFailed, "VolumeDetaching", http.StatusConflict,
+				"Volume '%s' is being detached from instance '%s'", attachRequest.VolumeID, attachRequest.InstanceID)
+		}
+		return copyAttachment(existing.attachment), nil
+	}
+	if s.mem.VolumeType == VolumeTypeBlock {
+		for _, rec := range s.mem.attachments {
+			if rec.attachment.VolumeID == attachRequest.VolumeID {
+				return nil, newError(util.AttachFailed, "VolumeAlreadyAttached", http.StatusConflict,
+					"Volume '%s' is already attached to instance '%s'", attachRequest.VolumeID, rec.attachment.InstanceID)
+			}
+		}
+	}
+
+	id := newID("att")
+	vpcAttachment := provider.VolumeAttachment{
+		ID:         id,
+		Name:       id,
+		Type:       "data",
+		DevicePath: "/dev/disk/by-id/virtio-" + id[4:24],
+	}
+	if attachRequest.VPCVolumeAttachment != nil {
+		if attachRequest.VPCVolumeAttachment.Name != "" {
+			vpcAttachment.Name = attachRequest.VPCVolumeAttachment.Name
+		}
+		vpcAttachment.DeleteVolumeOnInstanceDelete = attachRequest.VPCVolumeAttachment.DeleteVolumeOnInstanceDelete
+	}
+	createdAt := time.Now()
+	rec := &attachmentRecord{
+		attachment: provider.VolumeAttachmentResponse{
+			VolumeAttachmentRequest: provider.VolumeAttachmentRequest{
+				VolumeID:            attachRequest.VolumeID,
+				InstanceID:          attachRequest.InstanceID,
+				SoftlayerOptions:    copyMap(attachRequest.SoftlayerOptions),
+				VPCVolumeAttachment: &vpcAttachment,
+				IKSVolumeAttachment: attachRequest.IKSVolumeAttachment,
+			},
+			Status:    StatusAttaching,
+			CreatedAt: &createdAt,
+		},
+		readyAt: s.mem.readyAt(),
+	}
+	s.mem.attachments[key] = rec
+	return copyAttachment(rec.attachment), nil
+}
+
+// DetachVolume starts detaching the volume from the instance. The attachment moves to "detaching"
+// state and disappears once the detachment completes.
+func (s *Session) DetachVolume(detachRequest provider.VolumeAttachmentRequest) (*http.Response, error) {
+	s.logger.Info("Detaching volume", zap.Reflect("detachRequest", detachRequest))
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	rec, ok := s.mem.attachments[attachmentKey(detachRequest.VolumeID, detachRequest.InstanceID)]
+	if !ok {
+		return nil, newError(util.DetachFailed, "VolumeAttachFindFailed", http.StatusNotFound,
+			"No volume attachment found for volume ID '%s' and instance ID '%s'", detachRequest.VolumeID, detachRequest.InstanceID)
+	}
+	if rec.attachment.Status != StatusDetaching {
+		rec.attachment.Status = StatusDetaching
+		rec.readyAt = s.mem.readyAt()
+	}
+	return &http.Response{StatusCode: http.StatusAccepted, Status: http.StatusText(http.StatusAccepted), Body: http.NoBody}, nil
+}
+
+// GetVolumeAttachment retirves the current status of given volume attach request
+func (s *Session) GetVolumeAttachment(attachRequest provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	rec, ok := s.mem.attachments[attachmentKey(attachRequest.VolumeID, attachRequest.InstanceID)]
+	if !ok {
+		return nil, attachmentNotFound(attachRequest.VolumeID, attachRequest.InstanceID)
+	}
+	return copyAttachment(rec.attachment), nil
+}
+
+// WaitForAttachVolume waits for the volume to be attached to the host
+// Return error if wait is timed out OR there is other error
+func (s *Session) WaitForAttachVolume(attachRequest provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	return s.waitForAttachVolume(context.Background(), attachRequest)
+}
+
+// waitForAttachVolume ...
+func (s *Session) waitForAttachVolume(ctx context.Context, attachRequest provider.VolumeAttachmentRequest) (*provider.VolumeAttachmentResponse, error) {
+	attachment, err := provider.WaitForAttachmentState(ctx, func(context.Context) (*provider.VolumeAttachmentResponse, error) {
+		return s.GetVolumeAttachment(attachRequest)
+	}, state.AttachmentAttached, waitOptions[state.AttachmentStatus](s))
+	if s.waitTimedOut(ctx, err) {
+		return nil, newError(util.AttachFailed, "AttachTimeout", http.StatusGatewayTimeout,
+			"Volume '%s' was not attached to instance '%s' within %s", attachRequest.VolumeID, attachRequest.InstanceID, s.mem.WaitTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// WaitForDetachVolume waits for the volume to be detached from the host
+// Return error if wait is timed out OR there is other error
+func (s *Session) WaitForDetachVolume(detachRequest provider.VolumeAttachmentRequest) error {
+	return s.waitForDetachVolume(context.Background(), detachRequest)
+}
+
+// waitForDetachVolume ...
+func (s *Session) waitForDetachVolume(ctx context.Context, detachRequest provider.VolumeAttachmentRequest) error {
+	_, err := provider.WaitForAttachmentState(ctx, func(context.Context) (*provider.VolumeAttachmentResponse, error) {
+		attachment, err := s.GetVolumeAttachment(detachRequest)
+		if util.GetErrorType(err) == util.VolumeAttachFindFailed {
+			return &provider.VolumeAttachmentResponse{Status: string(state.AttachmentDetached)}, nil
+		}
+		return attachment, err
+	}, state.AttachmentDetached, waitOptions[state.AttachmentStatus](s))
+	if s.waitTimedOut(ctx, err) {
+		return newError(util.DetachFailed, "DetachTimeout", http.StatusGatewayTimeout,
+			"Volume '%s' was not detached from instance '%s' within %s", detachRequest.VolumeID, detachRequest.InstanceID, s.mem.WaitTimeout)
+	}
+	return err
+}
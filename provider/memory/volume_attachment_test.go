@@ -0,0 +1,116 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachDetachVolume(t *testing.T) {
+	_, sess := openSession(t)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+	request := provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "instance-1"}
+
+	attachment, err := sess.AttachVolume(request)
+	require.NoError(t, err)
+	assert.Equal(t, StatusAttaching, attachment.Status)
+	assert.NotEmpty(t, attachment.VPCVolumeAttachment.DevicePath)
+
+	attachment, err = sess.WaitForAttachVolume(request)
+	require.NoError(t, err)
+	assert.Equal(t, StatusAttached, attachment.Status)
+
+	// Attaching again to the same instance is idempotent
+	again, err := sess.AttachVolume(request)
+	assert.NoError(t, err)
+	assert.Equal(t, attachment.VPCVolumeAttachment.ID, again.VPCVolumeAttachment.ID)
+
+	// Block volumes can only be attached once
+	_, err = sess.AttachVolume(provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "instance-2"})
+	assert.Equal(t, util.AttachFailed, util.GetErrorType(err))
+
+	found, _ := sess.GetVolume(volume.VolumeID)
+	if assert.NotNil(t, found.VolumeAttachments) {
+		assert.Len(t, *found.VolumeAttachments, 1)
+	}
+
+	response, err := sess.DetachVolume(request)
+	require.NoError(t, err)
+	assert.Equal(t, 202, response.StatusCode)
+	assert.NoError(t, sess.WaitForDetachVolume(request))
+
+	_, err = sess.GetVolumeAttachment(request)
+	assert.Equal(t, util.VolumeAttachFindFailed, util.GetErrorType(err))
+
+	_, err = sess.DetachVolume(request)
+	assert.Equal(t, util.DetachFailed, util.GetErrorType(err))
+}
+
+func TestAttachVolumeErrors(t *testing.T) {
+	p, sess := openSession(t)
+
+	_, err := sess.AttachVolume(provider.VolumeAttachmentRequest{VolumeID: "vol"})
+	assert.Equal(t, util.InvalidRequest, util.GetErrorType(err))
+
+	_, err = sess.AttachVolume(provider.VolumeAttachmentRequest{VolumeID: "missing", InstanceID: "instance"})
+	assert.Equal(t, util.AttachFailed, util.GetErrorType(err))
+
+	p.TransitionDelay = time.Hour
+	name := "pending"
+	capacity := 10
+	volume, err := sess.CreateVolume(provider.Volume{Name: &name, Capacity: &capacity})
+	require.NoError(t, err)
+	_, err = sess.AttachVolume(provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "instance"})
+	assert.Equal(t, util.AttachFailed, util.GetErrorType(err))
+}
+
+func TestWaitForAttachVolumeTimeout(t *testing.T) {
+	p, sess := openSession(t)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+	request := provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "instance"}
+
+	p.TransitionDelay = time.Hour
+	p.WaitTimeout = 10 * time.Millisecond
+	_, err := sess.AttachVolume(request)
+	require.NoError(t, err)
+
+	_, err = sess.WaitForAttachVolume(request)
+	assert.Equal(t, util.AttachFailed, util.GetErrorType(err))
+
+	_, err = sess.WaitForAttachVolume(provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "other"})
+	assert.Equal(t, util.VolumeAttachFindFailed, util.GetErrorType(err))
+
+	assert.Equal(t, util.DetachFailed, util.GetErrorType(sess.WaitForDetachVolume(request)))
+}
+
+func TestAttachFileVolume(t *testing.T) {
+	p, sess := openSession(t)
+	p.VolumeType = VolumeTypeFile
+	volume := createAvailableVolume(t, sess, "share", 10)
+
+	for _, instanceID := range []string{"instance-1", "instance-2"} {
+		_, err := sess.AttachVolume(provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: instanceID})
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,219 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"go.uber.org/zap"
+)
+
+// defaultMountAddress is used for the mount path when the request does not carry a primary IP
+const defaultMountAddress = "10.240.64.5"
+
+// CreateVolumeAccessPoint creates an access point for the volume. It starts in "pending" state.
+func (s *Session) CreateVolumeAccessPoint(accessPointRequest provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	s.logger.Info("Creating volume access point", zap.Reflect("accessPointRequest", accessPointRequest))
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	volume, ok := s.mem.volumes[accessPointRequest.VolumeID]
+	if !ok {
+		return nil, volumeNotFound(accessPointRequest.VolumeID)
+	}
+	if volume.volume.Status != StatusAvailable {
+		return nil, newError(util.CreateVolumeAccessPointFailed, "VolumeNotAvailable", http.StatusConflict,
+			"Volume '%s' is in '%s' state and cannot have access points", accessPointRequest.VolumeID, volume.volume.Status)
+	}
+
+	id := newID("ap")
+	name := accessPointRequest.AccessPointName
+	if name == "" {
+		name = id
+	}
+	for _, rec := range s.mem.accessPoints {
+		if rec.accessPoint.VolumeID == accessPointRequest.VolumeID && rec.name == name {
+			return nil, newError(util.CreateVolumeAccessPointFailed, "AccessPointNameExists", http.StatusConflict,
+				"Volume '%s' already has an access point named '%s'", accessPointRequest.VolumeID, name)
+		}
+	}
+
+	address := defaultMountAddress
+	if accessPointRequest.PrimaryIP != nil && accessPointRequest.PrimaryIP.Address != "" {
+		address = accessPointRequest.PrimaryIP.Address
+	}
+	createdAt := time.Now()
+	rec := &accessPointRecord{
+		accessPoint: provider.VolumeAccessPointResponse{
+			VolumeID:      accessPointRequest.VolumeID,
+			AccessPointID: id,
+			Status:        StatusPending,
+			MountPath:     address + ":/" + id,
+			CreatedAt:     &createdAt,
+		},
+		name:    name,
+		vpcID:   accessPointRequest.VPCID,
+		readyAt: s.mem.readyAt(),
+	}
+	s.mem.accessPoints[id] = rec
+	return copyAccessPoint(rec.accessPoint), nil
+}
+
+// DeleteVolumeAccessPoint starts deleting the access point. It moves to "deleting" state and
+// disappears once the deletion completes.
+func (s *Session) DeleteVolumeAccessPoint(deleteAccessPointRequest provider.VolumeAccessPointRequest) (*http.Response, error) {
+	s.logger.Info("Deleting volume access point", zap.Reflect("deleteAccessPointRequest", deleteAccessPointRequest))
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	rec := s.mem.findAccessPoint(deleteAccessPointRequest)
+	if rec == nil {
+		return nil, newError(util.DeleteVolumeAccessPointFailed, "VolumeAccessPointFindFailed", http.StatusNotFound,
+			"No volume access point found for volume ID '%s' and access point ID '%s'",
+			deleteAccessPointRequest.VolumeID, deleteAccessPointRequest.AccessPointID)
+	}
+	if rec.accessPoint.Status != StatusDeleting {
+		rec.accessPoint.Status = StatusDeleting
+		rec.readyAt = s.mem.readyAt()
+	}
+	return &http.Response{StatusCode: http.StatusAccepted, Status: http.StatusText(http.StatusAccepted), Body: http.NoBody}, nil
+}
+
+// GetVolumeAccessPoint retrieves the access point by ID, or else by name, or else by VPC
+func (s *Session) GetVolumeAccessPoint(accessPointRequest provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	rec := s.mem.findAccessPoint(accessPointRequest)
+	if rec == nil {
+		return nil, accessPointNotFound(accessPointRequest.VolumeID, accessPointRequest.AccessPointID)
+	}
+	return copyAccessPoint(rec.accessPoint), nil
+}
+
+// WaitForCreateVolumeAccessPoint waits for the volume access point to be created
+// Return error if wait is timed out OR there is other error
+func (s *Session) WaitForCreateVolumeAccessPoint(accessPointRequest provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	return s.waitForCreateVolumeAccessPoint(context.Background(), accessPointRequest)
+}
+
+// waitForCreateVolumeAccessPoint ...
+func (s *Session) waitForCreateVolumeAccessPoint(ctx context.Context, accessPointRequest provider.VolumeAccessPointRequest) (*provider.VolumeAccessPointResponse, error) {
+	accessPoint, err := provider.WaitForAccessPointState(ctx, func(context.Context) (*provider.VolumeAccessPointResponse, error) {
+		return s.GetVolumeAccessPoint(accessPointRequest)
+	}, state.AccessPointStable, waitOptions[state.AccessPointStatus](s))
+	if s.waitTimedOut(ctx, err) {
+		return nil, newError(util.CreateVolumeAccessPointFailed, "CreateVolumeAccessPointTimeout", http.StatusGatewayTimeout,
+			"Access point for volume '%s' did not become stable within %s", accessPointRequest.VolumeID, s.mem.WaitTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return accessPoint, nil
+}
+
+// WaitForDeleteVolumeAccessPoint waits for the volume access point to be deleted
+// Return error if wait is timed out OR there is other error
+func (s *Session) WaitForDeleteVolumeAccessPoint(deleteAccessPointRequest provider.VolumeAccessPointRequest) error {
+	return s.waitForDeleteVolumeAccessPoint(context.Background(), deleteAccessPointRequest)
+}
+
+// waitForDeleteVolumeAccessPoint ...
+func (s *Session) waitForDeleteVolumeAccessPoint(ctx context.Context, deleteAccessPointRequest provider.VolumeAccessPointRequest) error {
+	_, err := provider.WaitForAccessPointState(ctx, func(context.Context) (*provider.VolumeAccessPointResponse, error) {
+		accessPoint, err := s.GetVolumeAccessPoint(deleteAccessPointRequest)
+		if util.GetErrorType(err) == util.VolumeAccessPointFindFailed {
+			return &provider.VolumeAccessPointResponse{Status: string(state.AccessPointDeleted)}, nil
+		}
+		return accessPoint, err
+	}, state.AccessPointDeleted, waitOptions[state.AccessPointStatus](s))
+	if s.waitTimedOut(ctx, err) {
+		return newError(util.DeleteVolumeAccessPointFailed, "DeleteVolumeAccessPointTimeout", http.StatusGatewayTimeout,
+			"Access point '%s' of volume '%s' was not deleted within %s",
+			deleteAccessPointRequest.AccessPointID, deleteAccessPointRequest.VolumeID, s.mem.WaitTimeout)
+	}
+	return err
+}
+
+// GetSubnetForVolumeAccessPoint returns the first subnet of the comma separated SubnetIDList
+func (s *Session) GetSubnetForVolumeAccessPoint(subnetRequest provider.SubnetRequest) (string, error) {
+	for _, subnetID := range strings.Split(subnetRequest.SubnetIDList, ",") {
+		if subnetID = strings.TrimSpace(subnetID); subnetID != "" {
+			return subnetID, nil
+		}
+	}
+	return "", newError(util.RetrivalFailed, "SubnetFindFailed", http.StatusNotFound,
+		"No subnet found in zone '%s' of VPC '%s'", subnetRequest.ZoneName, subnetRequest.VPCID)
+}
+
+// GetSecurityGroupForVolumeAccessPoint returns a security group ID derived from the requested name
+func (s *Session) GetSecurityGroupForVolumeAccessPoint(securityGroupRequest provider.SecurityGroupRequest) (string, error) {
+	if securityGroupRequest.Name == "" {
+		return "", newError(util.RetrivalFailed, "SecurityGroupFindFailed", http.StatusNotFound,
+			"No security group found in VPC '%s'", securityGroupRequest.VPCID)
+	}
+	return "sg-" + securityGroupRequest.Name, nil
+}
+
+// findAccessPoint looks the access point up by ID, or else by name, or else by VPC. Must be called with p.mu held.
+func (p *Provider) findAccessPoint(request provider.VolumeAccessPointRequest) *accessPointRecord {
+	if request.AccessPointID != "" {
+		rec, ok := p.accessPoints[request.AccessPointID]
+		if !ok || (request.VolumeID != "" && rec.accessPoint.VolumeID != request.VolumeID) {
+			return nil
+		}
+		return rec
+	}
+	for _, rec := range p.accessPoints {
+		if rec.accessPoint.VolumeID != request.VolumeID {
+			continue
+		}
+		if request.AccessPointName != "" && rec.name == request.AccessPointName {
+			return rec
+		}
+		if request.AccessPointName == "" && request.VPCID != "" && rec.vpcID == request.VPCID {
+			return rec
+		}
+	}
+	return nil
+}
+
+// view returns the access point as reported on the volume
+func (rec *accessPointRecord) view() provider.VolumeAccessPoint {
+	mountPath := rec.accessPoint.MountPath
+	accessPoint := provider.VolumeAccessPoint{
+		ID:        rec.accessPoint.AccessPointID,
+		Name:      rec.name,
+		Status:    rec.accessPoint.Status,
+		MountPath: &mountPath,
+		CreatedAt: rec.accessPoint.CreatedAt,
+	}
+	if rec.vpcID != "" {
+		accessPoint.VPC = &provider.VPC{ID: rec.vpcID}
+	}
+	return accessPoint
+}
@@ -0,0 +1,87 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"testing"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumeAccessPointLifecycle(t *testing.T) {
+	p, sess := openSession(t)
+	p.VolumeType = VolumeTypeFile
+	volume := createAvailableVolume(t, sess, "share", 10)
+
+	request := provider.VolumeAccessPointRequest{VolumeID: volume.VolumeID, AccessPointName: "vni", VPCID: "vpc-1"}
+	accessPoint, err := sess.CreateVolumeAccessPoint(request)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, accessPoint.Status)
+	assert.NotEmpty(t, accessPoint.MountPath)
+
+	_, err = sess.CreateVolumeAccessPoint(request)
+	assert.Equal(t, util.CreateVolumeAccessPointFailed, util.GetErrorType(err))
+
+	accessPoint, err = sess.WaitForCreateVolumeAccessPoint(request)
+	require.NoError(t, err)
+	assert.Equal(t, StatusStable, accessPoint.Status)
+
+	byVPC, err := sess.GetVolumeAccessPoint(provider.VolumeAccessPointRequest{VolumeID: volume.VolumeID, VPCID: "vpc-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, accessPoint.AccessPointID, byVPC.AccessPointID)
+
+	found, _ := sess.GetVolume(volume.VolumeID)
+	if assert.NotNil(t, found.VolumeAccessPoints) {
+		assert.Equal(t, "vni", (*found.VolumeAccessPoints)[0].Name)
+	}
+	assert.Equal(t, util.DeletionFailed, util.GetErrorType(sess.DeleteVolume(volume)))
+
+	deleteRequest := provider.VolumeAccessPointRequest{VolumeID: volume.VolumeID, AccessPointID: accessPoint.AccessPointID}
+	response, err := sess.DeleteVolumeAccessPoint(deleteRequest)
+	require.NoError(t, err)
+	assert.Equal(t, 202, response.StatusCode)
+	assert.NoError(t, sess.WaitForDeleteVolumeAccessPoint(deleteRequest))
+
+	_, err = sess.GetVolumeAccessPoint(deleteRequest)
+	assert.Equal(t, util.VolumeAccessPointFindFailed, util.GetErrorType(err))
+
+	_, err = sess.DeleteVolumeAccessPoint(deleteRequest)
+	assert.Equal(t, util.DeleteVolumeAccessPointFailed, util.GetErrorType(err))
+	assert.NoError(t, sess.DeleteVolume(volume))
+}
+
+func TestGetSubnetAndSecurityGroupForVolumeAccessPoint(t *testing.T) {
+	_, sess := openSession(t)
+
+	subnet, err := sess.GetSubnetForVolumeAccessPoint(provider.SubnetRequest{SubnetIDList: " ,subnet-1,subnet-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "subnet-1", subnet)
+
+	_, err = sess.GetSubnetForVolumeAccessPoint(provider.SubnetRequest{})
+	assert.Equal(t, util.RetrivalFailed, util.GetErrorType(err))
+
+	sg, err := sess.GetSecurityGroupForVolumeAccessPoint(provider.SecurityGroupRequest{Name: "kube-cluster"})
+	assert.NoError(t, err)
+	assert.Equal(t, "sg-kube-cluster", sg)
+
+	_, err = sess.GetSecurityGroupForVolumeAccessPoint(provider.SecurityGroupRequest{})
+	assert.Equal(t, util.RetrivalFailed, util.GetErrorType(err))
+}
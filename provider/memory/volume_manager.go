@@ -0,0 +1,444 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider/state"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"go.uber.org/zap"
+)
+
+// gib is the number of bytes in a GiB, used to size snapshots
+const gib = int64(1024 * 1024 * 1024)
+
+// GetVolumeProfileByName gets volume profile by name
+func (s *Session) GetVolumeProfileByName(name string) (*provider.Profile, error) {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	profile, ok := s.mem.Profiles[name]
+	if !ok {
+		return nil, newError(util.EntityNotFound, "VolumeProfileNotFound", http.StatusNotFound,
+			"A volume profile with the specified name '%s' could not be found", name)
+	}
+	return &profile, nil
+}
+
+// ListVolumeProfiles lists the volume profiles in name order
+func (s *Session) ListVolumeProfiles(limit int, start string) (*provider.ProfileList, error) {
+	if limit < 0 || limit > maxListLimit {
+		return nil, newError(util.InvalidRequest, "InvalidListVolumeProfilesLimit", http.StatusBadRequest,
+			"The value '%d' specified in the limit parameter of the list volume profiles call is not valid", limit)
+	}
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	profiles := make([]*provider.Profile, 0, len(s.mem.Profiles))
+	for _, profile := range s.mem.Profiles {
+		profiles = append(profiles, &profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	page, next, ok := paginate(profiles, limit, start, func(profile *provider.Profile) string { return profile.Name })
+	if !ok {
+		return nil, newError(util.InvalidRequest, "StartVolumeProfileNotFound", http.StatusBadRequest,
+			"The volume profile '%s' specified in the start parameter of the list volume profiles call could not be found", start)
+	}
+	return &provider.ProfileList{Next: next, Profiles: append([]*provider.Profile{}, page...)}, nil
+}
+
+// CreateVolume creates a volume. If the request names a snapshot the volume is restored from it.
+func (s *Session) CreateVolume(volumeRequest provider.Volume) (*provider.Volume, error) {
+	s.logger.Info("Creating volume", zap.Reflect("volumeRequest", volumeRequest))
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	return s.createVolume(volumeRequest)
+}
+
+// CreateVolumeFromSnapshot creates a volume from snapshot, with the given tags as volume notes
+func (s *Session) CreateVolumeFromSnapshot(snapshot provider.Snapshot, tags map[string]string) (*provider.Volume, error) {
+	s.logger.Info("Creating volume from snapshot", zap.String("snapshotID", snapshot.SnapshotID))
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	rec, ok := s.mem.snapshots[snapshot.SnapshotID]
+	if !ok {
+		return nil, snapshotNotFound(snapshot.SnapshotID)
+	}
+
+	name := fmt.Sprintf("%s-restore-%d", snapshot.SnapshotID, s.mem.sequence+1)
+	capacity := int((rec.snapshot.SnapshotSize + gib - 1) / gib)
+	volumeRequest := provider.Volume{
+		Name:        &name,
+		Capacity:    &capacity,
+		VolumeNotes: tags,
+		Snapshot:    rec.snapshot,
+	}
+	if source, ok := s.mem.volumes[rec.snapshot.VolumeID]; ok {
+		volumeRequest.Az = source.volume.Az
+		volumeRequest.Region = source.volume.Region
+		volumeRequest.Profile = source.volume.Profile
+	}
+	return s.createVolume(volumeRequest)
+}
+
+// createVolume validates the request and records a new pending volume. Must be called with mu held.
+func (s *Session) createVolume(volumeRequest provider.Volume) (*provider.Volume, error) {
+	if !util.StringHasValue(volumeRequest.Name) {
+		return nil, newError(util.InvalidRequest, "InvalidVolumeName", http.StatusBadRequest, "Volume name is required")
+	}
+	if volumeRequest.Capacity == nil || *volumeRequest.Capacity <= 0 {
+		return nil, newError(util.InvalidRequest, "VolumeCapacityInvalid", http.StatusBadRequest,
+			"Volume capacity must be a positive number of GiB")
+	}
+	for _, rec := range s.mem.volumes {
+		if util.SafeStringValue(rec.volume.Name) == *volumeRequest.Name {
+			return nil, newError(util.ProvisioningFailed, "VolumeNameExists", http.StatusConflict,
+				"A volume with the name '%s' already exists", *volumeRequest.Name)
+		}
+	}
+
+	volume := copyVolume(volumeRequest)
+	if volumeRequest.Profile != nil && volumeRequest.Profile.Name != "" {
+		profile, ok := s.mem.Profiles[volumeRequest.Profile.Name]
+		if !ok {
+			return nil, newError(util.InvalidRequest, "VolumeProfileNotFound", http.StatusBadRequest,
+				"A volume profile with the specified name '%s' could not be found", volumeRequest.Profile.Name)
+		}
+		if err := provider.ValidateVolumeRequest(volumeRequest, &profile); err != nil {
+			// Keeps the reason code and the suggested values of the validator
+			return nil, err
+		}
+		volume.Profile = &profile
+	}
+
+	if volumeRequest.SnapshotID != "" {
+		snapshot, ok := s.mem.snapshots[volumeRequest.SnapshotID]
+		if !ok {
+			return nil, snapshotNotFound(volumeRequest.SnapshotID)
+		}
+		if !snapshot.snapshot.ReadyToUse {
+			return nil, newError(util.ProvisioningFailed, "SnapshotNotReady", http.StatusConflict,
+				"Snapshot '%s' is not ready to use", volumeRequest.SnapshotID)
+		}
+		if int64(*volumeRequest.Capacity)*gib < snapshot.snapshot.SnapshotSize {
+			return nil, newError(util.InvalidRequest, "VolumeCapacityInvalid", http.StatusBadRequest,
+				"Volume capacity %d GiB is smaller than snapshot '%s'", *volumeRequest.Capacity, volumeRequest.SnapshotID)
+		}
+		volume.Snapshot = *copySnapshot(snapshot.snapshot)
+	}
+
+	volume.VolumeID = newID("vol")
+	volume.Provider = s.mem.Name
+	volume.VolumeType = s.mem.VolumeType
+	volume.CreationTime = time.Now()
+	volume.CRN = fmt.Sprintf("crn:v1:memory:public:is:%s::volume:%s", volume.Az, volume.VolumeID)
+	volume.Status = StatusPending
+	volume.VolumeAttachments = nil
+	volume.VolumeAccessPoints = nil
+
+	rec := &volumeRecord{
+		volume:    *volume,
+		requestID: s.requestID(),
+		sequence:  s.mem.nextSequence(),
+		readyAt:   s.mem.readyAt(),
+	}
+	s.mem.volumes[volume.VolumeID] = rec
+	s.logger.Info("Volume created", zap.String("volumeID", volume.VolumeID), zap.String("requestID", rec.requestID))
+	return copyVolume(rec.volume), nil
+}
+
+// UpdateVolume merges the volume notes, attributes and tags of the given volume into the stored volume
+func (s *Session) UpdateVolume(volume provider.Volume) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	rec, ok := s.mem.volumes[volume.VolumeID]
+	if !ok {
+		return volumeNotFound(volume.VolumeID)
+	}
+	rec.volume.VolumeNotes = mergeMap(rec.volume.VolumeNotes, volume.VolumeNotes)
+	rec.volume.Attributes = mergeMap(rec.volume.Attributes, volume.Attributes)
+	if volume.Tags != nil {
+		rec.volume.Tags = append([]string{}, volume.Tags...)
+	}
+	return nil
+}
+
+// DeleteVolume deletes the volume. Volumes with attachments or access points cannot be deleted.
+func (s *Session) DeleteVolume(volume *provider.Volume) error {
+	if volume == nil || volume.VolumeID == "" {
+		return newError(util.InvalidRequest, "InvalidVolumeID", http.StatusBadRequest, "Volume ID is required")
+	}
+	s.logger.Info("Deleting volume", zap.String("volumeID", volume.VolumeID))
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	if _, ok := s.mem.volumes[volume.VolumeID]; !ok {
+		return volumeNotFound(volume.VolumeID)
+	}
+	for _, rec := range s.mem.attachments {
+		if rec.attachment.VolumeID == volume.VolumeID {
+			return newError(util.DeletionFailed, "VolumeDeletionFailed", http.StatusConflict,
+				"Volume '%s' is still attached to instance '%s'", volume.VolumeID, rec.attachment.InstanceID)
+		}
+	}
+	for _, rec := range s.mem.accessPoints {
+		if rec.accessPoint.VolumeID == volume.VolumeID {
+			return newError(util.DeletionFailed, "VolumeDeletionFailed", http.StatusConflict,
+				"Volume '%s' still has access point '%s'", volume.VolumeID, rec.accessPoint.AccessPointID)
+		}
+	}
+	delete(s.mem.volumes, volume.VolumeID)
+	return nil
+}
+
+// GetVolume by using ID
+func (s *Session) GetVolume(id string) (*provider.Volume, error) {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	rec, ok := s.mem.volumes[id]
+	if !ok {
+		return nil, volumeNotFound(id)
+	}
+	return s.mem.volumeView(rec), nil
+}
+
+// GetVolumeByName gets volume by name
+func (s *Session) GetVolumeByName(name string) (*provider.Volume, error) {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	for _, rec := range s.mem.volumes {
+		if util.SafeStringValue(rec.volume.Name) == name {
+			return s.mem.volumeView(rec), nil
+		}
+	}
+	return nil, newError(util.EntityNotFound, "StorageFindFailedWithVolumeName", http.StatusNotFound,
+		"A volume with the specified volume name '%s' could not be found", name)
+}
+
+// ListVolumes lists volumes in creation order. Tags "name", "zone.name" and "resource_group.id"
+// filter on the matching volume fields, any other tag filters on the volume notes.
+func (s *Session) ListVolumes(limit int, start string, tags map[string]string) (*provider.VolumeList, error) {
+	return s.listVolumes(limit, start, func(volume *provider.Volume) bool { return volumeMatches(*volume, tags) })
+}
+
+// ListVolumesByFilter lists volumes in creation order, applying the whole filter
+func (s *Session) ListVolumesByFilter(limit int, start string, filter provider.VolumeFilter) (*provider.VolumeList, provider.VolumeFilter, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, filter, newError(util.InvalidRequest, "InvalidVolumeFilter", http.StatusBadRequest, "%s", err.Error())
+	}
+	volumes, err := s.listVolumes(limit, start, filter.Matches)
+	return volumes, provider.VolumeFilter{}, err
+}
+
+// listVolumes lists the page of the volumes matching match
+func (s *Session) listVolumes(limit int, start string, match func(*provider.Volume) bool) (*provider.VolumeList, error) {
+	if limit < 0 || limit > maxListLimit {
+		return nil, newError(util.InvalidRequest, "InvalidListVolumesLimit", http.StatusBadRequest,
+			"The value '%d' specified in the limit parameter of the list volume call is not valid", limit)
+	}
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	var matched []*provider.Volume
+	for _, rec := range s.mem.sortedVolumes() {
+		if volume := s.mem.volumeView(rec); match(volume) {
+			matched = append(matched, volume)
+		}
+	}
+	page, next, ok := paginate(matched, limit, start, func(volume *provider.Volume) string { return volume.VolumeID })
+	if !ok {
+		return nil, newError(util.InvalidRequest, "StartVolumeIDNotFound", http.StatusBadRequest,
+			"The volume ID '%s' specified in the start parameter of the list volume call could not be found", start)
+	}
+	return &provider.VolumeList{Next: next, Volumes: append([]*provider.Volume{}, page...)}, nil
+}
+
+// GetVolumeByRequestID fetch the volume by the request ID that was in the session context when it was created
+func (s *Session) GetVolumeByRequestID(requestID string) (*provider.Volume, error) {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	for _, rec := range s.mem.volumes {
+		if rec.requestID == requestID {
+			return s.mem.volumeView(rec), nil
+		}
+	}
+	return nil, newError(util.EntityNotFound, "StorageFindFailedWithRequestID", http.StatusNotFound,
+		"No volume found for request ID '%s'", requestID)
+}
+
+// AuthorizeVolume allows aceess to volume  based on given authorization
+func (s *Session) AuthorizeVolume(volumeAuthorization provider.VolumeAuthorization) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	if _, ok := s.mem.volumes[volumeAuthorization.Volume.VolumeID]; !ok {
+		return volumeNotFound(volumeAuthorization.Volume.VolumeID)
+	}
+	return nil
+}
+
+// ExpandVolume grows the volume to the requested capacity and returns the new capacity
+func (s *Session) ExpandVolume(expandVolumeRequest provider.ExpandVolumeRequest) (int64, error) {
+	s.logger.Info("Expanding volume", zap.Reflect("expandVolumeRequest", expandVolumeRequest))
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.settle()
+	rec, ok := s.mem.volumes[expandVolumeRequest.VolumeID]
+	if !ok {
+		return -1, volumeNotFound(expandVolumeRequest.VolumeID)
+	}
+	current := int64(0)
+	if rec.volume.Capacity != nil {
+		current = int64(*rec.volume.Capacity)
+	}
+	if expandVolumeRequest.Capacity < current {
+		return -1, newError(util.InvalidRequest, "VolumeCapacityInvalid", http.StatusBadRequest,
+			"Volume '%s' cannot be shrunk from %d GiB to %d GiB", rec.volume.VolumeID, current, expandVolumeRequest.Capacity)
+	}
+	if !state.Volume.Allows(rec.volume.State(), state.OperationExpand) {
+		return -1, newError(util.ExpansionFailed, "VolumeNotAvailable", http.StatusConflict,
+			"Volume '%s' is in '%s' state and cannot be expanded", rec.volume.VolumeID, rec.volume.Status)
+	}
+	if err := provider.ValidateExpandVolumeRequest(expandVolumeRequest, rec.volume.Profile); err != nil {
+		return -1, err
+	}
+	capacity := int(expandVolumeRequest.Capacity)
+	rec.volume.Capacity = &capacity
+	return expandVolumeRequest.Capacity, nil
+}
+
+// WaitForVolumeAvailable waits for the volume to become available
+// Return error if wait is timed out OR there is other error
+func (s *Session) WaitForVolumeAvailable(volumeID string) (*provider.Volume, error) {
+	return s.waitForVolumeAvailable(context.Background(), volumeID)
+}
+
+// waitForVolumeAvailable ...
+func (s *Session) waitForVolumeAvailable(ctx context.Context, volumeID string) (*provider.Volume, error) {
+	volume, err := provider.WaitForVolumeState(ctx, func(context.Context) (*provider.Volume, error) {
+		return s.GetVolume(volumeID)
+	}, state.VolumeAvailable, waitOptions[state.VolumeStatus](s))
+	if s.waitTimedOut(ctx, err) {
+		return nil, newError(util.ProvisioningFailed, "VolumeAvailableTimeout", http.StatusGatewayTimeout,
+			"Volume '%s' did not become available within %s", volumeID, s.mem.WaitTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return volume, nil
+}
+
+// CloneVolume clones the volume by snapshotting it and restoring the snapshot into a volume created from the template
+func (s *Session) CloneVolume(sourceVolumeID string, template provider.Volume) (*provider.Volume, error) {
+	s.logger.Info("Cloning volume", zap.String("sourceVolumeID", sourceVolumeID), zap.Reflect("template", template))
+	return provider.CloneVolume(s, sourceVolumeID, template)
+}
+
+// volumeView returns a copy of the stored volume with its current attachments and access points.
+// Must be called with p.mu held.
+func (p *Provider) volumeView(rec *volumeRecord) *provider.Volume {
+	volume := copyVolume(rec.volume)
+
+	var attachments []provider.VolumeAttachment
+	for _, att := range p.attachments {
+		if att.attachment.VolumeID == volume.VolumeID && att.attachment.VPCVolumeAttachment != nil {
+			attachments = append(attachments, *att.attachment.VPCVolumeAttachment)
+		}
+	}
+	if len(attachments) > 0 {
+		sort.Slice(attachments, func(i, j int) bool { return attachments[i].ID < attachments[j].ID })
+		volume.VolumeAttachments = &attachments
+	}
+
+	var accessPoints []provider.VolumeAccessPoint
+	for _, ap := range p.accessPoints {
+		if ap.accessPoint.VolumeID == volume.VolumeID {
+			accessPoints = append(accessPoints, ap.view())
+		}
+	}
+	if len(accessPoints) > 0 {
+		sort.Slice(accessPoints, func(i, j int) bool { return accessPoints[i].ID < accessPoints[j].ID })
+		volume.VolumeAccessPoints = &accessPoints
+	}
+	return volume
+}
+
+// volumeMatches ...
+func volumeMatches(volume provider.Volume, tags map[string]string) bool {
+	for key, value := range tags {
+		switch key {
+		case "name":
+			if util.SafeStringValue(volume.Name) != value {
+				return false
+			}
+		case "zone.name":
+			if volume.Az != value {
+				return false
+			}
+		case "resource_group.id":
+			if volume.ResourceGroup == nil || volume.ResourceGroup.ID != value {
+				return false
+			}
+		default:
+			if note, ok := volume.VolumeNotes[key]; !ok || note != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// mergeMap ...
+func mergeMap(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
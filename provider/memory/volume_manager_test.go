@@ -0,0 +1,341 @@
+/**
+ * Copyright 2020 IBM Corp.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory ...
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IBM/ibmcloud-volume-interface/lib/provider"
+	util "github.com/IBM/ibmcloud-volume-interface/lib/utils"
+	"github.com/IBM/ibmcloud-volume-interface/lib/utils/reasoncode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateVolume(t *testing.T) {
+	_, sess := openSession(t)
+	name := "vol-1"
+	capacity := 20
+
+	testcases := []struct {
+		name              string
+		request           provider.Volume
+		expectedErrorType string
+	}{
+		{
+			name:    "valid",
+			request: provider.Volume{Name: &name, Capacity: &capacity, VPCVolume: provider.VPCVolume{Profile: &provider.Profile{Name: "10iops-tier"}}},
+		},
+		{
+			name:              "missing name",
+			request:           provider.Volume{Capacity: &capacity},
+			expectedErrorType: util.InvalidRequest,
+		},
+		{
+			name:              "missing capacity",
+			request:           provider.Volume{Name: &name},
+			expectedErrorType: util.InvalidRequest,
+		},
+		{
+			name:              "duplicate name",
+			request:           provider.Volume{Name: &name, Capacity: &capacity},
+			expectedErrorType: util.ProvisioningFailed,
+		},
+		{
+			name:              "unknown profile",
+			request:           provider.Volume{Name: String("vol-2"), Capacity: &capacity, VPCVolume: provider.VPCVolume{Profile: &provider.Profile{Name: "unknown"}}},
+			expectedErrorType: util.InvalidRequest,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			volume, err := sess.CreateVolume(testcase.request)
+			if testcase.expectedErrorType != "" {
+				assert.Nil(t, volume)
+				assert.Equal(t, testcase.expectedErrorType, util.GetErrorType(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, volume.VolumeID)
+			assert.Equal(t, StatusPending, volume.Status)
+			assert.Equal(t, ProviderName, volume.Provider)
+			assert.Equal(t, int32(4800), volume.Profile.Capacity.Max)
+		})
+	}
+}
+
+func TestGetVolume(t *testing.T) {
+	_, sess := openSession(t)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+
+	found, err := sess.GetVolumeByName("vol")
+	assert.NoError(t, err)
+	assert.Equal(t, volume.VolumeID, found.VolumeID)
+
+	_, err = sess.GetVolume("missing")
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+
+	_, err = sess.GetVolumeByName("missing")
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+
+	// Returned volumes do not share state with the provider
+	*found.Capacity = 1000
+	found, _ = sess.GetVolume(volume.VolumeID)
+	assert.Equal(t, 10, *found.Capacity)
+}
+
+func TestGetVolumeByRequestID(t *testing.T) {
+	p := NewProvider("", "")
+	ctx := context.WithValue(context.Background(), provider.RequestID, "request-1")
+	sess, err := p.OpenSession(ctx, provider.ContextCredentials{}, logger)
+	require.NoError(t, err)
+
+	volume := createAvailableVolume(t, sess, "vol", 10)
+	found, err := sess.GetVolumeByRequestID("request-1")
+	assert.NoError(t, err)
+	assert.Equal(t, volume.VolumeID, found.VolumeID)
+
+	_, err = sess.GetVolumeByRequestID("request-2")
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+}
+
+func TestListVolumes(t *testing.T) {
+	_, sess := openSession(t)
+	for i := 0; i < 5; i++ {
+		createAvailableVolume(t, sess, fmt.Sprintf("vol-%d", i), 10)
+	}
+
+	list, err := sess.ListVolumes(2, "", nil)
+	require.NoError(t, err)
+	assert.Len(t, list.Volumes, 2)
+	assert.Equal(t, "vol-0", *list.Volumes[0].Name)
+	assert.NotEmpty(t, list.Next)
+
+	var names []string
+	start := ""
+	for {
+		list, err = sess.ListVolumes(2, start, nil)
+		require.NoError(t, err)
+		for _, volume := range list.Volumes {
+			names = append(names, *volume.Name)
+		}
+		if list.Next == "" {
+			break
+		}
+		start = list.Next
+	}
+	assert.Equal(t, []string{"vol-0", "vol-1", "vol-2", "vol-3", "vol-4"}, names)
+
+	list, err = sess.ListVolumes(0, "", map[string]string{"name": "vol-3"})
+	require.NoError(t, err)
+	assert.Len(t, list.Volumes, 1)
+
+	_, err = sess.ListVolumes(101, "", nil)
+	assert.Equal(t, util.InvalidRequest, util.GetErrorType(err))
+
+	_, err = sess.ListVolumes(10, "missing", nil)
+	assert.Equal(t, util.InvalidRequest, util.GetErrorType(err))
+}
+
+func TestUpdateAndDeleteVolume(t *testing.T) {
+	_, sess := openSession(t)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+
+	err := sess.UpdateVolume(provider.Volume{VolumeID: volume.VolumeID, VolumeNotes: map[string]string{"pvc": "claim"}})
+	assert.NoError(t, err)
+	found, _ := sess.GetVolume(volume.VolumeID)
+	assert.Equal(t, "claim", found.VolumeNotes["pvc"])
+
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(sess.UpdateVolume(provider.Volume{VolumeID: "missing"})))
+	assert.NoError(t, sess.AuthorizeVolume(provider.VolumeAuthorization{Volume: *volume}))
+
+	_, err = sess.AttachVolume(provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "instance"})
+	require.NoError(t, err)
+	assert.Equal(t, util.DeletionFailed, util.GetErrorType(sess.DeleteVolume(volume)))
+
+	_, err = sess.DetachVolume(provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "instance"})
+	require.NoError(t, err)
+	require.NoError(t, sess.WaitForDetachVolume(provider.VolumeAttachmentRequest{VolumeID: volume.VolumeID, InstanceID: "instance"}))
+	assert.NoError(t, sess.DeleteVolume(volume))
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(sess.DeleteVolume(volume)))
+	assert.Equal(t, util.InvalidRequest, util.GetErrorType(sess.DeleteVolume(nil)))
+}
+
+func TestExpandVolume(t *testing.T) {
+	_, sess := openSession(t)
+	volume := createAvailableVolume(t, sess, "vol", 10)
+
+	capacity, err := sess.ExpandVolume(provider.ExpandVolumeRequest{VolumeID: volume.VolumeID, Capacity: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), capacity)
+
+	_, err = sess.ExpandVolume(provider.ExpandVolumeRequest{VolumeID: volume.VolumeID, Capacity: 5})
+	assert.Equal(t, util.InvalidRequest, util.GetErrorType(err))
+
+	_, err = sess.ExpandVolume(provider.ExpandVolumeRequest{VolumeID: "missing", Capacity: 5})
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+}
+
+func TestGetVolumeProfileByName(t *testing.T) {
+	_, sess := openSession(t)
+
+	profile, err := sess.GetVolumeProfileByName("general-purpose")
+	assert.NoError(t, err)
+	assert.Equal(t, "general-purpose", profile.Name)
+
+	_, err = sess.GetVolumeProfileByName("unknown")
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+}
+
+func TestListVolumeProfiles(t *testing.T) {
+	_, sess := openSession(t)
+
+	list, err := sess.ListVolumeProfiles(3, "")
+	require.NoError(t, err)
+	assert.Len(t, list.Profiles, 3)
+	assert.Equal(t, "10iops-tier", list.Profiles[0].Name)
+	assert.Equal(t, "general-purpose", list.Next)
+
+	list, err = sess.ListVolumeProfiles(0, list.Next)
+	require.NoError(t, err)
+	assert.Len(t, list.Profiles, 2)
+	assert.Equal(t, "sdp", list.Profiles[1].Name)
+	assert.Empty(t, list.Next)
+
+	_, err = sess.ListVolumeProfiles(-1, "")
+	assert.Equal(t, "InvalidListVolumeProfilesLimit", err.(util.Message).Code)
+	_, err = sess.ListVolumeProfiles(0, "unknown")
+	assert.Equal(t, "StartVolumeProfileNotFound", err.(util.Message).Code)
+}
+
+// String returns a pointer to the string value provided
+func String(v string) *string {
+	return &v
+}
+
+func TestWaitForVolumeAvailable(t *testing.T) {
+	p, sess := openSession(t)
+	name := "vol"
+	capacity := 10
+	volume, err := sess.CreateVolume(provider.Volume{Name: &name, Capacity: &capacity, Az: "us-south-1"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, volume.Status)
+
+	volume, err = sess.WaitForVolumeAvailable(volume.VolumeID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusAvailable, volume.Status)
+
+	_, err = sess.WaitForVolumeAvailable("missing")
+	assert.Equal(t, util.EntityNotFound, util.GetErrorType(err))
+
+	p.TransitionDelay = time.Hour
+	p.WaitTimeout = 10 * time.Millisecond
+	slow := "slow"
+	volume, err = sess.CreateVolume(provider.Volume{Name: &slow, Capacity: &capacity, Az: "us-south-1"})
+	require.NoError(t, err)
+	_, err = sess.WaitForVolumeAvailable(volume.VolumeID)
+	assert.Equal(t, util.ProvisioningFailed, util.GetErrorType(err))
+}
+
+func TestCloneVolume(t *testing.T) {
+	_, sess := openSession(t)
+	source := createAvailableVolume(t, sess, "source", 10)
+
+	name := "copy"
+	clone, err := sess.CloneVolume(source.VolumeID, provider.Volume{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, StatusAvailable, clone.Status)
+	assert.Equal(t, 10, *clone.Capacity)
+	assert.Equal(t, source.Az, clone.Az)
+	assert.NotEmpty(t, clone.SnapshotID)
+
+	snapshots, err := sess.ListSnapshots(0, "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, snapshots.Snapshots)
+
+	_, err = sess.CloneVolume(source.VolumeID, provider.Volume{Name: &name})
+	assert.True(t, errors.Is(err, reasoncode.ErrorVolumeCloneFailed))
+	snapshots, err = sess.ListSnapshots(0, "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, snapshots.Snapshots)
+
+	_, err = sess.CloneVolume("missing", provider.Volume{Name: &name})
+	assert.True(t, errors.Is(err, reasoncode.ErrorVolumeCloneFailed))
+}
+
+func TestListVolumesByFilter(t *testing.T) {
+	_, sess := openSession(t)
+	attached := createAvailableVolume(t, sess, "pvc-attached", 10)
+	createAvailableVolume(t, sess, "pvc-big", 100)
+	createAvailableVolume(t, sess, "data", 10)
+	_, err := sess.AttachVolume(provider.VolumeAttachmentRequest{VolumeID: attached.VolumeID, InstanceID: "instance"})
+	require.NoError(t, err)
+
+	filter := provider.VolumeFilter{NamePrefix: "pvc-", MaxCapacity: 50, Attachment: provider.AttachmentUnattached}
+	lister := sess.(provider.VolumeFilterLister)
+	list, rest, err := lister.ListVolumesByFilter(0, "", filter)
+	require.NoError(t, err)
+	assert.Empty(t, list.Volumes)
+	assert.True(t, rest.IsEmpty())
+
+	filter.Attachment = provider.AttachmentAttached
+	var names []string
+	for volume, err := range provider.IterFilteredVolumes(context.Background(), sess, filter, provider.PageOptions{PageSize: 1}) {
+		require.NoError(t, err)
+		names = append(names, *volume.Name)
+	}
+	assert.Equal(t, []string{"pvc-attached"}, names)
+
+	_, _, err = lister.ListVolumesByFilter(0, "", provider.VolumeFilter{MinCapacity: 20, MaxCapacity: 10})
+	assert.Equal(t, util.InvalidRequest, util.GetErrorType(err))
+}
+
+func TestCreateVolumeProfileMismatch(t *testing.T) {
+	_, sess := openSession(t)
+	name := "vol"
+	capacity := 20
+	iops := "50"
+	_, err := sess.CreateVolume(provider.Volume{Name: &name, Capacity: &capacity, Iops: &iops, VPCVolume: provider.VPCVolume{Profile: &provider.Profile{Name: "custom"}}})
+	assert.Equal(t, reasoncode.ErrorBadRequest, util.ErrorReasonCode(err))
+	assert.Contains(t, err.Error(), "iops 50 is outside the range 100-48000 IOPS, nearest valid values are 100")
+	if assert.IsType(t, provider.Error{}, err) {
+		assert.Equal(t, "custom", err.(provider.Error).Properties()[provider.ProfileProperty])
+		assert.Equal(t, "100", err.(provider.Error).Properties()[provider.ProfileFieldIops+"Suggested"])
+	}
+
+	tooBig := 20000
+	_, err = sess.CreateVolume(provider.Volume{Name: &name, Capacity: &tooBig, VPCVolume: provider.VPCVolume{Profile: &provider.Profile{Name: "10iops-tier"}}})
+	assert.Equal(t, reasoncode.ErrorBadRequest, util.ErrorReasonCode(err))
+	if assert.IsType(t, provider.Error{}, err) {
+		assert.Equal(t, "4800", err.(provider.Error).Properties()[provider.ProfileFieldCapacity+"Suggested"])
+	}
+
+	volume, err := sess.CreateVolume(provider.Volume{Name: &name, Capacity: &capacity, VPCVolume: provider.VPCVolume{Profile: &provider.Profile{Name: "10iops-tier"}}})
+	require.NoError(t, err)
+	_, err = sess.ExpandVolume(provider.ExpandVolumeRequest{VolumeID: volume.VolumeID, Capacity: 5000})
+	assert.Equal(t, reasoncode.ErrorBadRequest, util.ErrorReasonCode(err))
+	assert.Contains(t, err.Error(), "nearest valid values are 4800")
+	if assert.IsType(t, provider.Error{}, err) {
+		assert.Equal(t, "4800", err.(provider.Error).Properties()[provider.ProfileFieldCapacity+"Suggested"])
+	}
+}